@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gridctl/gridctl/internal/api"
+	"github.com/gridctl/gridctl/pkg/mcp"
+	"github.com/gridctl/gridctl/pkg/output"
+	"github.com/gridctl/gridctl/pkg/state"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	approveStack   string
+	approveReject  bool
+	approveComment string
+)
+
+var approveCmd = &cobra.Command{
+	Use:   "approve [id]",
+	Short: "Approve or reject tool calls awaiting approval",
+	Long: `Approves a tool call that is waiting for human approval, or rejects it
+with --reject. Without an ID, lists the calls awaiting approval.
+
+Calls wait for approval when they match require_approval on their MCP
+server, or when approvals.destructive is set and the server annotates the
+tool as destructive.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return runApprovals(approveStack)
+		}
+		return runApprove(approveStack, args[0], !approveReject, approveComment)
+	},
+}
+
+func init() {
+	approveCmd.Flags().StringVarP(&approveStack, "stack", "s", "", "Stack whose gateway holds the call (default: the only running stack)")
+	approveCmd.Flags().BoolVar(&approveReject, "reject", false, "Reject the call instead of approving it")
+	approveCmd.Flags().StringVarP(&approveComment, "comment", "m", "", "Comment recorded with the decision and, on rejection, returned to the caller")
+}
+
+func runApprovals(stack string) error {
+	st, err := runningGateway(stack)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Approvals []mcp.Approval `json:"approvals"`
+	}
+	if err := gatewayRequest(st, http.MethodGet, "/api/approvals", nil, &resp); err != nil {
+		return err
+	}
+	if len(resp.Approvals) == 0 {
+		output.New().Info("No tool calls awaiting approval", "stack", st.StackName)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTOOL\tAGENT\tREASON\tEXPIRES IN\tARGUMENTS")
+	for _, a := range resp.Approvals {
+		args, _ := json.Marshal(a.Arguments)
+		agent := a.Agent
+		if agent == "" {
+			agent = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", a.ID, a.Name, agent, a.Reason,
+			time.Until(a.ExpiresAt).Round(time.Second), truncate(string(args), 60))
+	}
+	return w.Flush()
+}
+
+func runApprove(stack, id string, approve bool, comment string) error {
+	st, err := runningGateway(stack)
+	if err != nil {
+		return err
+	}
+	action := "approve"
+	if !approve {
+		action = "reject"
+	}
+	body := map[string]string{"by": "cli", "comment": comment}
+	if user := os.Getenv("USER"); user != "" {
+		body["by"] = "cli:" + user
+	}
+
+	var a mcp.Approval
+	if err := gatewayRequest(st, http.MethodPost, "/api/approvals/"+id+"/"+action, body, &a); err != nil {
+		return err
+	}
+	output.New().Info("Tool call "+string(a.Status), "id", a.ID, "tool", a.Name)
+	return nil
+}
+
+// runningGateway returns the state of a running stack's gateway. With no
+// stack name, it returns the only running gateway.
+func runningGateway(stack string) (*state.DaemonState, error) {
+	if stack != "" {
+		st, err := state.Load(stack)
+		if err != nil || st == nil || !state.IsRunning(st) {
+			return nil, fmt.Errorf("stack %s is not running", stack)
+		}
+		return st, nil
+	}
+
+	states, err := state.List()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading state files: %w", err)
+	}
+	var running []state.DaemonState
+	for _, s := range states {
+		if state.IsRunning(&s) {
+			running = append(running, s)
+		}
+	}
+	switch len(running) {
+	case 0:
+		return nil, fmt.Errorf("no running stacks")
+	case 1:
+		return &running[0], nil
+	default:
+		return nil, fmt.Errorf("%d stacks are running, choose one with --stack", len(running))
+	}
+}
+
+// gatewayRequest calls a running gateway's API and decodes its JSON
+// response into out.
+func gatewayRequest(st *state.DaemonState, method, path string, body, out any) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, fmt.Sprintf("http://localhost:%d%s", st.Port, path), &reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(api.ApprovalTokenHeader, st.ApprovalToken)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("contacting gateway of stack %s: %w", st.StackName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s", apiErr.Error)
+		}
+		return fmt.Errorf("gateway returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// truncate shortens s to at most n characters.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+
+	"github.com/gridctl/gridctl/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	renderEnvFiles []string
+	renderOverlays []string
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect stack files",
+}
+
+var configRenderCmd = &cobra.Command{
+	Use:   "render <stack.yaml>",
+	Short: "Print a stack with its includes, templates and overlays merged",
+	Long: `Prints the stack that deploy would run: the stack file merged with the
+files it extends and includes, its x- templates and extends, and the
+overlays passed with --overlay. The merged stack is validated first.
+
+Variables and secret references are printed as written.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, err := config.RenderStack(args[0], config.LoadOptions{EnvFiles: renderEnvFiles, Overlays: renderOverlays})
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	},
+}
+
+func init() {
+	configRenderCmd.Flags().StringArrayVar(&renderOverlays, "overlay", nil, "Apply an overlay from the stack's overlays, or an overlay file (repeatable, applied in order)")
+	configRenderCmd.Flags().StringArrayVar(&renderEnvFiles, "env-file", nil, "Read variables for ${VAR} expansion from a .env file (repeatable, overrides env_file)")
+	configCmd.AddCommand(configRenderCmd)
+}
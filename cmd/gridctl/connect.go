@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gridctl/gridctl/pkg/config"
+	"github.com/gridctl/gridctl/pkg/connect"
+	"github.com/gridctl/gridctl/pkg/output"
+	"github.com/gridctl/gridctl/pkg/state"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	connectAgent     string
+	connectStack     string
+	connectPort      int
+	connectName      string
+	connectTransport string
+	connectPrint     bool
+)
+
+var connectCmd = &cobra.Command{
+	Use:   "connect <client>",
+	Short: "Point an MCP client at the running gateway",
+	Long: `Adds an entry for the running gateway to an MCP client's config file,
+replacing any entry of the same name and keeping the rest of the file. The
+file is backed up first, next to it, with a timestamp.
+
+The gateway's port is read from the running stack; use --stack when more
+than one is running, or --port to skip the lookup. With --agent, the client
+sees only the tools that agent uses. Restart the client afterwards.
+
+Clients: ` + clientNames() + `
+
+Use --print to print the entry instead of writing it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConnect(args[0])
+	},
+}
+
+func init() {
+	connectCmd.Flags().StringVarP(&connectAgent, "agent", "a", "", "Show the client only the tools this agent uses")
+	connectCmd.Flags().StringVarP(&connectStack, "stack", "s", "", "Running stack to connect to (default: the only one running)")
+	connectCmd.Flags().IntVarP(&connectPort, "port", "p", 0, "Gateway port (default: the running stack's)")
+	connectCmd.Flags().StringVar(&connectName, "name", "gridctl", "Name of the entry in the client's config")
+	connectCmd.Flags().StringVar(&connectTransport, "transport", connect.TransportHTTP, "Gateway transport: http (/mcp) or sse (/sse)")
+	connectCmd.Flags().BoolVar(&connectPrint, "print", false, "Print the config entry instead of writing it")
+}
+
+func clientNames() string {
+	var names []string
+	for _, c := range connect.Clients() {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func runConnect(clientName string) error {
+	client, ok := connect.Lookup(clientName)
+	if !ok {
+		return fmt.Errorf("unknown client '%s' (available: %s)", clientName, clientNames())
+	}
+
+	opts := connect.Options{Name: connectName, Port: connectPort, Agent: connectAgent, Transport: connectTransport}
+	if opts.Port == 0 {
+		s, err := runningStack(connectStack)
+		if err != nil {
+			return err
+		}
+		opts.Port = s.Port
+		if connectAgent != "" {
+			if err := checkAgent(s.StackFile, connectAgent); err != nil {
+				return err
+			}
+		}
+	}
+
+	if connectPrint {
+		out, err := client.Snippet(opts)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("finding home directory: %w", err)
+	}
+	path := client.ConfigPath(home)
+	backup, err := client.Write(path, opts)
+	if err != nil {
+		return err
+	}
+	printer := output.New()
+	if backup != "" {
+		printer.Info("Backed up config", "file", backup)
+	}
+	printer.Info(fmt.Sprintf("Connected %s to the gateway; restart it to pick up the change", client.Title), "file", path, "url", opts.URL())
+	return nil
+}
+
+// runningStack returns the state of the named running stack, or of the
+// only running stack when name is empty.
+func runningStack(name string) (*state.DaemonState, error) {
+	if name != "" {
+		s, err := state.Load(name)
+		if err != nil || !state.IsRunning(s) {
+			return nil, fmt.Errorf("stack '%s' is not running", name)
+		}
+		return s, nil
+	}
+
+	states, err := state.List()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var running []state.DaemonState
+	for _, s := range states {
+		if state.IsRunning(&s) {
+			running = append(running, s)
+		}
+	}
+	switch len(running) {
+	case 0:
+		return nil, fmt.Errorf("no stack is running; deploy one, or pass --port")
+	case 1:
+		return &running[0], nil
+	}
+	var names []string
+	for _, s := range running {
+		names = append(names, s.StackName)
+	}
+	return nil, fmt.Errorf("several stacks are running (%s); choose one with --stack", strings.Join(names, ", "))
+}
+
+// checkAgent reports an error if the stack file has no agent of the given
+// name. A stack file that no longer loads is not checked.
+func checkAgent(stackFile, agent string) error {
+	if stackFile == "" {
+		return nil
+	}
+	stack, err := config.LoadStackWithOptions(stackFile, config.LoadOptions{AllowUnresolved: true})
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, a := range stack.Agents {
+		if a.Name == agent {
+			return nil
+		}
+		names = append(names, a.Name)
+	}
+	return fmt.Errorf("stack '%s' has no agent '%s' (agents: %s)", stack.Name, agent, strings.Join(names, ", "))
+}
@@ -17,12 +17,16 @@ import (
 	"github.com/gridctl/gridctl/internal/api"
 	"github.com/gridctl/gridctl/pkg/a2a"
 	"github.com/gridctl/gridctl/pkg/adapter"
+	"github.com/gridctl/gridctl/pkg/audit"
 	"github.com/gridctl/gridctl/pkg/config"
+	"github.com/gridctl/gridctl/pkg/logging"
 	"github.com/gridctl/gridctl/pkg/mcp"
 	"github.com/gridctl/gridctl/pkg/output"
 	"github.com/gridctl/gridctl/pkg/runtime"
-	_ "github.com/gridctl/gridctl/pkg/runtime/docker" // Register DockerRuntime factory
+	"github.com/gridctl/gridctl/pkg/runtime/docker" // Also registers the DockerRuntime factory
+	"github.com/gridctl/gridctl/pkg/secrets"
 	"github.com/gridctl/gridctl/pkg/state"
+	"github.com/gridctl/gridctl/pkg/tracing"
 
 	"github.com/spf13/cobra"
 )
@@ -33,8 +37,14 @@ var (
 	deployNoCache     bool
 	deployPort        int
 	deployBasePort    int
+	deployParallel    int
 	deployForeground  bool
 	deployDaemonChild bool
+	deployEnvFiles    []string
+	deployOverlays    []string
+
+	// secretMasker hides the values of the stack's secrets in output
+	secretMasker *secrets.Masker
 )
 
 var deployCmd = &cobra.Command{
@@ -58,7 +68,10 @@ func init() {
 	deployCmd.Flags().BoolVar(&deployNoCache, "no-cache", false, "Force rebuild of source-based images")
 	deployCmd.Flags().IntVarP(&deployPort, "port", "p", 8180, "Port for MCP gateway")
 	deployCmd.Flags().IntVar(&deployBasePort, "base-port", 9000, "Base port for MCP server host port allocation")
+	deployCmd.Flags().IntVar(&deployParallel, "parallel", 4, "Max workloads started and images pulled/built at once")
 	deployCmd.Flags().BoolVarP(&deployForeground, "foreground", "f", false, "Run in foreground (don't daemonize)")
+	deployCmd.Flags().StringArrayVar(&deployEnvFiles, "env-file", nil, "Read variables for ${VAR} expansion from a .env file (repeatable, overrides env_file)")
+	deployCmd.Flags().StringArrayVar(&deployOverlays, "overlay", nil, "Apply an overlay from the stack's overlays, or an overlay file (repeatable, applied in order)")
 	deployCmd.Flags().BoolVar(&deployDaemonChild, "daemon-child", false, "Internal flag for daemon process")
 	_ = deployCmd.Flags().MarkHidden("daemon-child")
 }
@@ -70,9 +83,22 @@ func runDeploy(stackPath string) error {
 		return fmt.Errorf("failed to resolve path: %w", err)
 	}
 	stackPath = absPath
+	for i, f := range deployEnvFiles {
+		if deployEnvFiles[i], err = filepath.Abs(f); err != nil {
+			return fmt.Errorf("failed to resolve path: %w", err)
+		}
+	}
+	for i, o := range deployOverlays {
+		if !config.IsOverlayFile(o) {
+			continue
+		}
+		if deployOverlays[i], err = filepath.Abs(o); err != nil {
+			return fmt.Errorf("failed to resolve path: %w", err)
+		}
+	}
 
-	// Load stack
-	stack, err := config.LoadStack(stackPath)
+	// Load stack, fetching git includes; other commands read them as fetched here
+	stack, err := config.LoadStackWithOptions(stackPath, config.LoadOptions{EnvFiles: deployEnvFiles, Overlays: deployOverlays, FetchIncludes: true})
 	if err != nil {
 		return fmt.Errorf("failed to load stack: %w", err)
 	}
@@ -101,6 +127,13 @@ func runDeploy(stackPath string) error {
 			stack.Name, existingState.Port, existingState.PID, stackPath)
 	}
 
+	// Replace secret references with their values
+	resolver := secrets.NewResolver(stack.Secrets, secrets.DefaultStore())
+	if err := config.ResolveSecrets(stack, resolver.Resolve); err != nil {
+		return fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	secretMasker = resolver.Masker()
+
 	// If we're the daemon child, run the gateway
 	if deployDaemonChild {
 		return runDeployDaemonChild(stackPath, stack)
@@ -114,10 +147,19 @@ func runDeploy(stackPath string) error {
 		printer.Info("Parsing & checking stack", "file", stackPath)
 	}
 
+	// Unset variables do not stop a deploy, but are likely mistakes
+	for _, w := range stack.LoadWarnings() {
+		if printer != nil {
+			printer.Warn(w.Error())
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", w.Error())
+		}
+	}
+
 	if deployVerbose {
 		fmt.Println("\nFull stack (JSON):")
 		data, _ := json.MarshalIndent(stack, "", "  ")
-		fmt.Println(string(data))
+		fmt.Println(secretMasker.Mask(string(data)))
 	}
 
 	// Start containers
@@ -133,8 +175,7 @@ func runDeploy(stackPath string) error {
 		if deployVerbose {
 			logLevel = slog.LevelDebug
 		}
-		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
-		rt.SetLogger(logger)
+		rt.SetLogger(newDeployLogger(&slog.HandlerOptions{Level: logLevel}))
 	}
 
 	ctx := context.Background()
@@ -142,6 +183,12 @@ func runDeploy(stackPath string) error {
 		NoCache:     deployNoCache,
 		BasePort:    deployBasePort,
 		GatewayPort: deployPort,
+		Parallelism: deployParallel,
+	}
+	if printer != nil {
+		opts.Progress = func(ev runtime.ProgressEvent) {
+			printer.Progress(ev.Name, string(ev.Phase), ev.Elapsed, ev.Err)
+		}
 	}
 	result, err := rt.Up(ctx, stack, opts)
 	if err != nil {
@@ -153,7 +200,11 @@ func runDeploy(stackPath string) error {
 
 	// If foreground mode, run gateway directly
 	if deployForeground {
-		return runGateway(ctx, rt, stack, stackPath, legacyResult, deployPort, !deployQuiet, printer)
+		token, err := api.NewApprovalToken()
+		if err != nil {
+			return fmt.Errorf("generating approval token: %w", err)
+		}
+		return runGateway(ctx, rt, stack, stackPath, legacyResult, deployPort, token, !deployQuiet, printer)
 	}
 
 	// Daemon mode: fork child process
@@ -179,10 +230,12 @@ func runDeploy(stackPath string) error {
 		summaries := buildWorkloadSummaries(stack, result)
 		printer.Summary(summaries)
 		printer.Info("Gateway running", "url", fmt.Sprintf("http://localhost:%d", st.Port))
+		printer.Info("Web UI", "url", webUIURL(st.Port, st.ApprovalToken))
 		printer.Print("\nUse 'gridctl destroy %s' to stop\n", stackPath)
 	} else {
 		fmt.Printf("Stack '%s' started successfully\n", stack.Name)
 		fmt.Printf("  Gateway: http://localhost:%d\n", st.Port)
+		fmt.Printf("  Web UI: %s\n", webUIURL(st.Port, st.ApprovalToken))
 		fmt.Printf("  PID: %d\n", pid)
 		fmt.Printf("  Logs: %s\n", state.LogPath(stack.Name))
 		fmt.Printf("\nUse 'gridctl destroy %s' to stop\n", stackPath)
@@ -263,20 +316,32 @@ func runDeployDaemonChild(stackPath string, stack *config.Stack) error {
 		return fmt.Errorf("failed to get container info: %w", err)
 	}
 
+	token, err := api.NewApprovalToken()
+	if err != nil {
+		return fmt.Errorf("generating approval token: %w", err)
+	}
+
 	// Write state file before starting server
 	st := &state.DaemonState{
-		StackName: stack.Name,
-		StackFile: stackPath,
-		PID:       os.Getpid(),
-		Port:      deployPort,
-		StartedAt: time.Now(),
+		StackName:     stack.Name,
+		StackFile:     stackPath,
+		PID:           os.Getpid(),
+		Port:          deployPort,
+		StartedAt:     time.Now(),
+		ApprovalToken: token,
 	}
 	if err := state.Save(st); err != nil {
 		return fmt.Errorf("failed to save state: %w", err)
 	}
 
 	// Run gateway (blocks until shutdown)
-	return runGateway(ctx, rt, stack, stackPath, result, deployPort, false, nil)
+	return runGateway(ctx, rt, stack, stackPath, result, deployPort, token, false, nil)
+}
+
+// webUIURL returns the address of the web UI, carrying the approval token
+// it needs to decide held tool calls.
+func webUIURL(port int, approvalToken string) string {
+	return fmt.Sprintf("http://localhost:%d/#token=%s", port, approvalToken)
 }
 
 // getRunningContainers retrieves info about already-running containers and external servers
@@ -386,12 +451,17 @@ func getRunningContainers(ctx context.Context, rt *runtime.Runtime, stack *confi
 	return result, nil
 }
 
-// runGateway runs the MCP gateway (blocking)
-func runGateway(ctx context.Context, rt *runtime.Runtime, stack *config.Stack, stackPath string, result *runtime.LegacyUpResult, port int, verbose bool, printer *output.Printer) error {
+// runGateway runs the MCP gateway (blocking). approvalToken authorizes
+// approval decisions on its API.
+func runGateway(ctx context.Context, rt *runtime.Runtime, stack *config.Stack, stackPath string, result *runtime.LegacyUpResult, port int, approvalToken string, verbose bool, printer *output.Printer) error {
 	// Create MCP gateway
 	gateway := mcp.NewGateway()
 	gateway.SetDockerClient(rt.DockerClient())
 	gateway.SetVersion(version)
+	gateway.SetToolNamer(mcp.ToolNamer{
+		Separator: stack.ToolNaming.Separator,
+		MaxLength: stack.ToolNaming.MaxLength,
+	})
 
 	// Configure logging for verbose mode
 	if verbose {
@@ -399,7 +469,57 @@ func runGateway(ctx context.Context, rt *runtime.Runtime, stack *config.Stack, s
 		if deployVerbose {
 			logLevel = slog.LevelDebug
 		}
-		gateway.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
+		gateway.SetLogger(newDeployLogger(&slog.HandlerOptions{Level: logLevel}))
+	}
+	gateway.SetMasker(secretMasker.Mask)
+	gateway.SetToolsets(stack.Toolsets)
+	gateway.SetToolSearch(stack.ToolMode == config.ToolModeSearch)
+	gateway.SetCompositeTools(stack.CompositeTools)
+	if err := gateway.SetPolicies(stack.Policies); err != nil {
+		_ = state.Delete(stack.Name)
+		return fmt.Errorf("compiling policies: %w", err)
+	}
+	approvalTimeout, _ := stack.Approvals.TimeoutDuration() // Validated at load
+	gateway.SetApprovalPolicy(mcp.ApprovalPolicy{
+		Timeout:     approvalTimeout,
+		Destructive: stack.Approvals.Destructive,
+		Elicit:      stack.Approvals.Elicit,
+	})
+
+	// Record tool calls to the stack's audit log
+	if stack.Audit.IsEnabled() {
+		maxSize, _ := stack.Audit.MaxSizeBytes() // Validated at load
+		auditLog, err := audit.Open(state.AuditLogPath(stack.Name), audit.Options{
+			MaxSize:  maxSize,
+			MaxFiles: stack.Audit.MaxFiles,
+			Redact:   stack.Audit.Redact,
+		})
+		if err != nil {
+			_ = state.Delete(stack.Name)
+			return fmt.Errorf("opening audit log: %w", err)
+		}
+		defer auditLog.Close()
+		gateway.SetAuditLog(auditLog)
+	}
+
+	// Export traces of tool calls and A2A messages
+	if stack.Tracing.IsEnabled() {
+		shutdown, err := tracing.Setup(ctx, tracing.Options{
+			ServiceName: stack.Tracing.ServiceName,
+			Endpoint:    stack.Tracing.Endpoint,
+			Headers:     stack.Tracing.Headers,
+			File:        stack.Tracing.File,
+			SampleRatio: stack.Tracing.Ratio(),
+		})
+		if err != nil {
+			_ = state.Delete(stack.Name)
+			return fmt.Errorf("setting up tracing: %w", err)
+		}
+		defer func() {
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = shutdown(flushCtx)
+		}()
 	}
 
 	// Create A2A gateway early if needed (for server setup)
@@ -429,6 +549,7 @@ func runGateway(ctx context.Context, rt *runtime.Runtime, stack *config.Stack, s
 	server := api.NewServer(gateway, webFS)
 	server.SetDockerClient(rt.DockerClient())
 	server.SetStackName(stack.Name)
+	server.SetApprovalToken(approvalToken)
 	if a2aGateway != nil {
 		server.SetA2AGateway(a2aGateway)
 	}
@@ -460,6 +581,19 @@ func runGateway(ctx context.Context, rt *runtime.Runtime, stack *config.Stack, s
 	// This allows the health check to succeed even if MCP servers take time to connect
 	registerMCPServers(ctx, gateway, stack, stackPath, result, verbose)
 
+	// Log connections refused by egress proxies
+	for _, s := range stack.MCPServers {
+		if s.EgressMode() == config.EgressAllowlist {
+			egressLogger := newDeployLogger(nil)
+			go func() {
+				if err := docker.WatchEgressLogs(ctx, rt.DockerClient(), stack.Name, egressLogger); err != nil {
+					egressLogger.Warn("could not watch egress proxy logs", "error", err)
+				}
+			}()
+			break
+		}
+	}
+
 	// Register agents with their access permissions
 	if len(result.Agents) > 0 {
 		if verbose {
@@ -523,6 +657,7 @@ func runGateway(ctx context.Context, rt *runtime.Runtime, stack *config.Stack, s
 
 			if a2aAgent.Auth != nil {
 				authType = a2aAgent.Auth.Type
+				authToken = a2aAgent.Auth.Token
 				if a2aAgent.Auth.TokenEnv != "" {
 					authToken = os.Getenv(a2aAgent.Auth.TokenEnv)
 				}
@@ -531,7 +666,7 @@ func runGateway(ctx context.Context, rt *runtime.Runtime, stack *config.Stack, s
 
 			if err := a2aGateway.RegisterRemoteAgent(ctx, a2aAgent.Name, a2aAgent.URL, authType, authToken, authHeader); err != nil {
 				if verbose {
-					fmt.Printf("  Warning: failed to register A2A agent %s: %v\n", a2aAgent.Name, err)
+					fmt.Printf("  Warning: failed to register A2A agent %s: %s\n", a2aAgent.Name, secretMasker.Mask(err.Error()))
 				}
 			}
 		}
@@ -553,7 +688,7 @@ func runGateway(ctx context.Context, rt *runtime.Runtime, stack *config.Stack, s
 			fmt.Printf("  GET  /a2a/{agent}            - Agent card\n")
 			fmt.Printf("  POST /a2a/{agent}            - JSON-RPC endpoint\n")
 		}
-		fmt.Printf("\nWeb UI available at http://localhost%s/\n", addr)
+		fmt.Printf("\nWeb UI available at %s\n", webUIURL(port, approvalToken))
 		fmt.Printf("API endpoints:\n")
 		fmt.Printf("  GET  /api/status      - Gateway status (includes unified agents)\n")
 		fmt.Printf("  GET  /api/mcp-servers - List MCP servers\n")
@@ -614,15 +749,20 @@ func registerMCPServers(ctx context.Context, gateway *mcp.Gateway, stack *config
 				Transport: transport,
 				Endpoint:  server.URL,
 				External:  true,
+				Headers:   serverCfg.Headers,
 				Tools:     serverCfg.Tools,
 			}
 		} else if server.LocalProcess {
-			// Local process server - use command
+			// Local process server - use command, run from workdir or the stack directory
+			workDir := serverCfg.WorkDir
+			if workDir == "" {
+				workDir = filepath.Dir(stackPath)
+			}
 			cfg = mcp.MCPServerConfig{
 				Name:         server.Name,
 				LocalProcess: true,
 				Command:      server.Command,
-				WorkDir:      filepath.Dir(stackPath), // Use stack directory
+				WorkDir:      workDir,
 				Env:          serverCfg.Env,
 				Tools:        serverCfg.Tools,
 			}
@@ -657,14 +797,29 @@ func registerMCPServers(ctx context.Context, gateway *mcp.Gateway, stack *config
 			}
 		}
 
+		cfg.ToolOverrides = serverCfg.ToolOverrides
+		cfg.Limits = serverCfg.CallLimits
+		cfg.ToolLimits = serverCfg.ToolLimits
+		cfg.Cache = serverCfg.Cache
+		cfg.ToolCache = serverCfg.ToolCache
+		cfg.RequireApproval = serverCfg.RequireApproval
+		cfg.ToolPrefix = serverCfg.ToolNamePrefix()
+		cfg.Unprefixed = cfg.ToolPrefix == ""
+
 		if err := gateway.RegisterMCPServer(ctx, cfg); err != nil {
 			if verbose {
-				fmt.Printf("  Warning: failed to register MCP server %s: %v\n", server.Name, err)
+				fmt.Printf("  Warning: failed to register MCP server %s: %s\n", server.Name, secretMasker.Mask(err.Error()))
 			}
 		}
 	}
 }
 
+// newDeployLogger returns a logger that writes to stderr with secret values
+// masked.
+func newDeployLogger(opts *slog.HandlerOptions) *slog.Logger {
+	return slog.New(logging.NewMaskingHandler(slog.NewTextHandler(os.Stderr, opts), secretMasker.Mask))
+}
+
 // forkDeployDaemon starts the daemon child process
 func forkDeployDaemon(stackPath string, port int, basePort int) (int, error) {
 	// Get current executable
@@ -679,7 +834,7 @@ func forkDeployDaemon(stackPath string, port int, basePort int) (int, error) {
 	}
 
 	// Get stack name for log file
-	stack, err := config.LoadStack(stackPath)
+	stack, err := config.LoadStackWithOptions(stackPath, config.LoadOptions{EnvFiles: deployEnvFiles, Overlays: deployOverlays})
 	if err != nil {
 		return 0, fmt.Errorf("loading stack: %w", err)
 	}
@@ -691,10 +846,17 @@ func forkDeployDaemon(stackPath string, port int, basePort int) (int, error) {
 	}
 
 	// Build command with --daemon-child flag
-	cmd := exec.Command(exe, "deploy", stackPath,
+	args := []string{"deploy", stackPath,
 		"--daemon-child",
 		"--port", strconv.Itoa(port),
-		"--base-port", strconv.Itoa(basePort))
+		"--base-port", strconv.Itoa(basePort)}
+	for _, f := range deployEnvFiles {
+		args = append(args, "--env-file", f)
+	}
+	for _, o := range deployOverlays {
+		args = append(args, "--overlay", o)
+	}
+	cmd := exec.Command(exe, args...)
 
 	// Detach from terminal
 	cmd.SysProcAttr = &syscall.SysProcAttr{
@@ -14,23 +14,36 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	destroyVolumes  bool
+	destroyOverlays []string
+)
+
 var destroyCmd = &cobra.Command{
 	Use:   "destroy <stack.yaml>",
 	Short: "Stop gateway daemon and remove containers",
 	Long: `Stops the MCP gateway daemon and removes all containers for a stack.
 
-Requires the stack file to identify which stack to stop.`,
+Requires the stack file, and any overlays it was deployed with, to identify
+which stack to stop.
+Named volumes are kept unless --volumes is passed.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runDestroy(args[0])
 	},
 }
 
+func init() {
+	destroyCmd.Flags().BoolVar(&destroyVolumes, "volumes", false, "Also remove named volumes declared by the stack")
+	destroyCmd.Flags().StringArrayVar(&destroyOverlays, "overlay", nil, "Overlay the stack was deployed with (repeatable)")
+}
+
 func runDestroy(stackPath string) error {
 	printer := output.New()
 
-	// Load stack to get its name
-	stack, err := config.LoadStack(stackPath)
+	// Load stack to get its name. Variables the stack needs to run may not
+	// be set when stopping it.
+	stack, err := config.LoadStackWithOptions(stackPath, config.LoadOptions{AllowUnresolved: true, Overlays: destroyOverlays})
 	if err != nil {
 		return fmt.Errorf("failed to load stack: %w", err)
 	}
@@ -70,7 +83,7 @@ func runDestroy(stackPath string) error {
 	defer rt.Close()
 
 	ctx := context.Background()
-	if err := rt.Down(ctx, stack.Name); err != nil {
+	if err := rt.Down(ctx, stack.Name, runtime.DownOptions{RemoveVolumes: destroyVolumes}); err != nil {
 		return fmt.Errorf("failed to stop containers: %w", err)
 	}
 
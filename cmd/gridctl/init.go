@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gridctl/gridctl/pkg/catalog"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	initName    string
+	initServers []string
+	initOutput  string
+	initCatalog string
+	initForce   bool
+	initList    bool
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a stack file from a catalog of MCP servers",
+	Long: `Creates a stack file running MCP servers chosen from a catalog of
+well-known servers, such as GitHub, filesystem, Atlassian and Postgres. Each
+comes with its image or command, transport, the variables and secrets it
+needs, and a recommended tools filter.
+
+Servers are chosen with --server, or interactively when none are given.
+Add your own servers, or override built-in ones by name, in
+~/.gridctl/catalog.yaml or a file passed with --catalog; gridctl init --list
+shows the catalog.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runInit()
+	},
+}
+
+func init() {
+	initCmd.Flags().StringVarP(&initName, "name", "n", "", "Stack name (default: the output file's directory name)")
+	initCmd.Flags().StringSliceVarP(&initServers, "server", "s", nil, "Catalog server to include (repeatable or comma-separated)")
+	initCmd.Flags().StringVarP(&initOutput, "output", "o", "stack.yaml", "File to write, or - for stdout")
+	initCmd.Flags().StringVar(&initCatalog, "catalog", "", "Catalog file extending the built-in catalog (default: ~/.gridctl/catalog.yaml)")
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "Overwrite the output file if it exists")
+	initCmd.Flags().BoolVar(&initList, "list", false, "List the catalog's servers and exit")
+}
+
+func runInit() error {
+	c, err := catalog.Load(initCatalog)
+	if err != nil {
+		return err
+	}
+	if initList {
+		for _, e := range c.Servers {
+			fmt.Printf("%-16s %s\n", e.Name, e.Description)
+		}
+		return nil
+	}
+
+	if initOutput != "-" && !initForce {
+		if _, err := os.Stat(initOutput); err == nil {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", initOutput)
+		}
+	}
+
+	name := initName
+	if name == "" {
+		name = defaultStackName(initOutput)
+	}
+	names := initServers
+	if len(names) == 0 {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return errors.New("no servers chosen: pass --server (see --list), or run in a terminal to choose interactively")
+		}
+		in := bufio.NewReader(os.Stdin)
+		if names, err = promptServers(in, os.Stderr, c); err != nil {
+			return err
+		}
+		if initName == "" {
+			if name, err = prompt(in, os.Stderr, "Stack name", name); err != nil {
+				return err
+			}
+		}
+	}
+
+	var entries []catalog.Entry
+	for _, n := range names {
+		e, ok := c.Get(n)
+		if !ok {
+			return fmt.Errorf("unknown server '%s' (available: %s)", n, strings.Join(c.Names(), ", "))
+		}
+		entries = append(entries, e)
+	}
+
+	out, err := catalog.Generate(name, entries)
+	if err != nil {
+		return fmt.Errorf("generated stack is invalid: %w", err)
+	}
+	if initOutput == "-" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if err := os.WriteFile(initOutput, out, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %s\n\nNext steps:\n", initOutput)
+	for _, e := range entries {
+		for _, v := range e.Env {
+			switch {
+			case v.Secret:
+				fmt.Printf("  gridctl secret set %s\n", v.SecretName())
+			case v.Value == "" && v.Default == "":
+				fmt.Printf("  export %s=...   # %s\n", v.Name, v.Description)
+			}
+		}
+	}
+	fmt.Printf("  gridctl deploy %s\n", initOutput)
+	return nil
+}
+
+// stackNamePattern matches the characters not allowed in a default stack name.
+var stackNamePattern = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// defaultStackName names a stack after the directory its file is written to.
+func defaultStackName(output string) string {
+	dir := "."
+	if output != "-" {
+		dir = filepath.Dir(output)
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	name := strings.Trim(stackNamePattern.ReplaceAllString(strings.ToLower(filepath.Base(dir)), "-"), "-")
+	if name == "" {
+		return "my-stack"
+	}
+	return name
+}
+
+// promptServers lists the catalog and asks which servers to include, by
+// number or name.
+func promptServers(in *bufio.Reader, out io.Writer, c *catalog.Catalog) ([]string, error) {
+	fmt.Fprintln(out, "MCP servers:")
+	for i, e := range c.Servers {
+		fmt.Fprintf(out, "  %2d) %-16s %s\n", i+1, e.Name, e.Description)
+	}
+	for {
+		answer, err := prompt(in, out, "Servers to include (numbers or names, comma-separated)", "")
+		if err != nil {
+			return nil, err
+		}
+		names, err := parseServerChoice(answer, c)
+		if err == nil && len(names) > 0 {
+			return names, nil
+		}
+		if err != nil {
+			fmt.Fprintln(out, err)
+		}
+	}
+}
+
+// parseServerChoice turns a comma-separated list of catalog numbers and
+// names into server names.
+func parseServerChoice(answer string, c *catalog.Catalog) ([]string, error) {
+	var names []string
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if n, err := strconv.Atoi(field); err == nil {
+			if n < 1 || n > len(c.Servers) {
+				return nil, fmt.Errorf("no server numbered %d", n)
+			}
+			field = c.Servers[n-1].Name
+		} else if _, ok := c.Get(field); !ok {
+			return nil, fmt.Errorf("unknown server '%s'", field)
+		}
+		names = append(names, field)
+	}
+	return names, nil
+}
+
+// prompt asks a question and returns the answer, or def if it is empty.
+func prompt(in *bufio.Reader, out io.Writer, question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(out, "%s: ", question)
+	}
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("reading answer: %w", err)
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return def, nil
+	}
+	return line, nil
+}
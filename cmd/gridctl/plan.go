@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gridctl/gridctl/pkg/builder"
+	"github.com/gridctl/gridctl/pkg/config"
+	"github.com/gridctl/gridctl/pkg/runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	planPort     int
+	planBasePort int
+	planFormat   string
+	planEnvFiles []string
+	planOverlays []string
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan <stack.yaml>",
+	Short: "Show what deploy would create, without starting anything",
+	Long: `Shows what deploying a stack would do, without starting anything or
+contacting the container runtime: the images pulled or built, the
+networks and volumes created, the host ports allocated, and the tools
+each agent will see.
+
+The output is stable for a given stack, so plans can be diffed in review.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if planFormat != "text" && planFormat != "json" {
+			return fmt.Errorf("unknown format '%s' (expected text or json)", planFormat)
+		}
+		return runPlan(args[0], os.Stdout)
+	},
+}
+
+func init() {
+	planCmd.Flags().IntVarP(&planPort, "port", "p", 8180, "Port for MCP gateway")
+	planCmd.Flags().IntVar(&planBasePort, "base-port", 9000, "Base port for MCP server host port allocation")
+	planCmd.Flags().StringVar(&planFormat, "format", "text", "Output format: text or json")
+	planCmd.Flags().StringArrayVar(&planOverlays, "overlay", nil, "Apply an overlay from the stack's overlays, or an overlay file (repeatable, applied in order)")
+	planCmd.Flags().StringArrayVar(&planEnvFiles, "env-file", nil, "Read variables for ${VAR} expansion from a .env file (repeatable, overrides env_file)")
+}
+
+// stackPlan is the output of gridctl plan.
+type stackPlan struct {
+	Stack       string `json:"stack"`
+	GatewayPort int    `json:"gateway_port"`
+	*runtime.Plan
+	Agents []agentPlan `json:"agents,omitempty"`
+}
+
+// agentPlan lists the tools an agent will see, by the server or agent
+// that provides them.
+type agentPlan struct {
+	Name  string     `json:"name"`
+	Tools []toolPlan `json:"tools"`
+}
+
+// toolPlan describes the tools an agent gets from one server. Tool names
+// are only known once the server runs, so the plan shows the prefix they
+// are exposed with and the patterns that filter them.
+type toolPlan struct {
+	Server      string   `json:"server"`
+	Agent       bool     `json:"agent,omitempty"`        // An A2A agent rather than an MCP server
+	Prefix      string   `json:"prefix,omitempty"`       // Prefix of the exposed tool names
+	ServerTools []string `json:"server_tools,omitempty"` // Server 'tools' filter
+	AgentTools  []string `json:"agent_tools,omitempty"`  // Agent 'uses' filter
+}
+
+func runPlan(stackPath string, w io.Writer) error {
+	stack, err := config.LoadStackWithOptions(stackPath, config.LoadOptions{EnvFiles: planEnvFiles, Overlays: planOverlays})
+	if err != nil {
+		return fmt.Errorf("failed to load stack: %w", err)
+	}
+	for _, warning := range stack.LoadWarnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning.Error())
+	}
+
+	plan, err := runtime.PlanUp(context.Background(), stack, runtime.UpOptions{
+		BasePort:    planBasePort,
+		GatewayPort: planPort,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to plan stack: %w", err)
+	}
+
+	out := stackPlan{Stack: stack.Name, GatewayPort: planPort, Plan: plan, Agents: planAgentTools(stack)}
+	if planFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+	printPlan(w, out)
+	return nil
+}
+
+// planAgentTools lists the tools each agent will see.
+func planAgentTools(stack *config.Stack) []agentPlan {
+	servers := make(map[string]*config.MCPServer, len(stack.MCPServers))
+	for i := range stack.MCPServers {
+		servers[stack.MCPServers[i].Name] = &stack.MCPServers[i]
+	}
+	var agents []agentPlan
+	for _, agent := range stack.Agents {
+		a := agentPlan{Name: agent.Name, Tools: []toolPlan{}}
+		for _, sel := range agent.Uses {
+			t := toolPlan{Server: sel.Server, AgentTools: sel.Tools}
+			if server, ok := servers[sel.Server]; ok {
+				if prefix := server.ToolNamePrefix(); prefix != "" {
+					t.Prefix = prefix + stack.ToolNaming.Separator
+				}
+				t.ServerTools = server.Tools
+			} else {
+				t.Agent = true
+			}
+			a.Tools = append(a.Tools, t)
+		}
+		agents = append(agents, a)
+	}
+	return agents
+}
+
+// printPlan writes a plan as text, one line per item, so that changes to
+// a stack show as small diffs.
+func printPlan(w io.Writer, p stackPlan) {
+	fmt.Fprintf(w, "Stack %s (gateway on port %d)\n", p.Stack, p.GatewayPort)
+
+	fmt.Fprintln(w, "\nImages:")
+	if len(p.Images) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, img := range p.Images {
+		usedBy := strings.Join(img.UsedBy, ", ")
+		if !img.Build {
+			fmt.Fprintf(w, "  pull   %s (%s)\n", img.Image, usedBy)
+			continue
+		}
+		source := img.Source
+		if img.Ref != "" {
+			source += "@" + img.Ref
+		}
+		fmt.Fprintf(w, "  build  %s from %s %s%s (%s)\n", img.Image, img.SourceType, source, repoCacheNote(img), usedBy)
+	}
+
+	fmt.Fprintln(w, "\nNetworks:")
+	for _, n := range p.Networks {
+		line := "  " + n.Name
+		if n.Driver != "" {
+			line += " (" + n.Driver + ")"
+		}
+		if n.Internal {
+			line += " internal"
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	if len(p.Volumes) > 0 {
+		fmt.Fprintln(w, "\nVolumes:")
+		for _, v := range p.Volumes {
+			fmt.Fprintf(w, "  %s\n", v)
+		}
+	}
+
+	fmt.Fprintln(w, "\nWorkloads:")
+	for _, wl := range p.Workloads {
+		switch wl.Kind {
+		case "container":
+			ports := ""
+			if wl.HostPort > 0 {
+				ports = fmt.Sprintf(" localhost:%d -> %d", wl.HostPort, wl.ExposedPort)
+			}
+			networks := wl.Network
+			if len(wl.Networks) > 0 {
+				networks += "," + strings.Join(wl.Networks, ",")
+			}
+			fmt.Fprintf(w, "  %-12s %s %s on %s%s\n", wl.Type, wl.Name, wl.Image, networks, ports)
+		default:
+			fmt.Fprintf(w, "  %-12s %s %s %s\n", wl.Type, wl.Name, wl.Kind, wl.Endpoint)
+		}
+	}
+
+	if len(p.Agents) > 0 {
+		fmt.Fprintln(w, "\nAgent tools:")
+		for _, a := range p.Agents {
+			fmt.Fprintf(w, "  %s\n", a.Name)
+			if len(a.Tools) == 0 {
+				fmt.Fprintln(w, "    (none)")
+			}
+			for _, t := range a.Tools {
+				fmt.Fprintf(w, "    %s\n", describeTools(t))
+			}
+		}
+	}
+}
+
+// describeTools summarizes the tools an agent gets from one server.
+func describeTools(t toolPlan) string {
+	if t.Agent {
+		return fmt.Sprintf("%s: agent (A2A)", t.Server)
+	}
+	desc := t.Server + ": "
+	if t.Prefix != "" {
+		desc += t.Prefix + "*"
+	} else {
+		desc += "unprefixed"
+	}
+	if len(t.ServerTools) > 0 {
+		desc += ", server tools " + strings.Join(t.ServerTools, " ")
+	}
+	if len(t.AgentTools) > 0 {
+		desc += ", agent tools " + strings.Join(t.AgentTools, " ")
+	}
+	if len(t.ServerTools) == 0 && len(t.AgentTools) == 0 {
+		desc += ", all tools"
+	}
+	return desc
+}
+
+// repoCacheNote notes whether a git build's repository is in the
+// builder's cache, which decides whether deploy clones or just updates it.
+func repoCacheNote(img runtime.ImagePlan) string {
+	if img.SourceType != "git" {
+		return ""
+	}
+	path, err := builder.URLToPath(img.Source)
+	if err != nil {
+		return ""
+	}
+	if _, err := os.Stat(path); err == nil {
+		return " [repo cached]"
+	}
+	return " [clone]"
+}
@@ -22,9 +22,17 @@ them via a single MCP gateway.`,
 func init() {
 	initHelp()
 
+	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(deployCmd)
 	rootCmd.AddCommand(destroyCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(connectCmd)
+	rootCmd.AddCommand(approveCmd)
+	rootCmd.AddCommand(secretCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(schemaCmd)
 	rootCmd.AddCommand(serveCmd)
 }
 
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gridctl/gridctl/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for stack files",
+	Long: `Prints a JSON Schema for stack files, generated from gridctl's stack types.
+
+Editors with YAML language support can use it to validate and complete
+stack files. Save it and point a stack file at it:
+
+  gridctl schema > stack.schema.json
+
+  # yaml-language-server: $schema=./stack.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := config.JSONSchema()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(schema))
+		return nil
+	},
+}
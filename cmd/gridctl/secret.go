@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gridctl/gridctl/pkg/config"
+	"github.com/gridctl/gridctl/pkg/output"
+	"github.com/gridctl/gridctl/pkg/secrets"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets in the local encrypted store",
+	Long: `Manages the secrets in the local encrypted store, ~/.gridctl/secrets.enc.
+
+Stack files refer to secrets as ${secret:name} in env values, headers, MCP
+server URLs and A2A auth tokens. Secrets that are not declared in the stack's
+secrets section are read from this store.
+
+The store is encrypted with a key kept in ~/.gridctl/secrets.key, or, if
+GRIDCTL_SECRETS_PASSPHRASE is set when the store is created, with a key
+derived from that passphrase.`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <name> [value]",
+	Short: "Store a secret",
+	Long: `Stores a secret, replacing any value it had. Without a value argument,
+prompts for the value, or reads it from stdin when stdin is not a terminal.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !config.ValidSecretName(args[0]) {
+			return fmt.Errorf("invalid secret name '%s': use letters, digits, '_', '.' and '-'", args[0])
+		}
+		var value string
+		if len(args) == 2 {
+			value = args[1]
+		} else {
+			v, err := readSecretValue(args[0])
+			if err != nil {
+				return err
+			}
+			value = v
+		}
+		if value == "" {
+			return fmt.Errorf("secret value is empty")
+		}
+		if err := secrets.DefaultStore().Set(args[0], value); err != nil {
+			return err
+		}
+		output.New().Info("Secret stored", "name", args[0])
+		return nil
+	},
+}
+
+var secretListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the names of stored secrets",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := secrets.DefaultStore().List()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	},
+}
+
+var secretDeleteCmd = &cobra.Command{
+	Use:     "delete <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a secret from the store",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := secrets.DefaultStore().Delete(args[0])
+		if errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("secret %s is not in the store", args[0])
+		}
+		if err != nil {
+			return err
+		}
+		output.New().Info("Secret deleted", "name", args[0])
+		return nil
+	},
+}
+
+func init() {
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretListCmd)
+	secretCmd.AddCommand(secretDeleteCmd)
+}
+
+// readSecretValue prompts for a secret without echoing it, or reads it
+// from stdin when stdin is not a terminal.
+func readSecretValue(name string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("reading secret from stdin: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+	data, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("reading secret: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gridctl/gridctl/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateFormat   string
+	validateStrict   bool
+	validateEnvFiles []string
+	validateOverlays []string
+)
+
+// Exit codes of gridctl validate
+const (
+	validateExitOK       = 0
+	validateExitErrors   = 1
+	validateExitWarnings = 2
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <stack.yaml>",
+	Short: "Check a stack file for errors and warnings",
+	Long: `Loads a stack file as deploy would, without starting anything, and
+reports every error and warning found, with its file and line.
+
+Warnings are likely mistakes that do not stop a deploy: unset variables,
+images without a pinned tag, credentials written into the stack, and
+secrets that are declared but never referenced.
+
+Exit codes:
+  0  the stack is valid
+  1  the stack has errors, or could not be read
+  2  the stack has warnings and --strict is set`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if validateFormat != "text" && validateFormat != "json" {
+			return fmt.Errorf("unknown format '%s' (expected text or json)", validateFormat)
+		}
+		os.Exit(runValidate(args[0]))
+		return nil
+	},
+}
+
+func init() {
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "Output format: text or json")
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Exit with code 2 when there are warnings")
+	validateCmd.Flags().StringArrayVar(&validateOverlays, "overlay", nil, "Apply an overlay from the stack's overlays, or an overlay file (repeatable, applied in order)")
+	validateCmd.Flags().StringArrayVar(&validateEnvFiles, "env-file", nil, "Read variables for ${VAR} expansion from a .env file (repeatable, overrides env_file)")
+}
+
+// validateReport is the JSON output of gridctl validate.
+type validateReport struct {
+	File     string                   `json:"file"`
+	Valid    bool                     `json:"valid"`
+	Errors   []config.ValidationError `json:"errors"`
+	Warnings []config.ValidationError `json:"warnings"`
+}
+
+// runValidate checks a stack file, prints what it found and returns the
+// exit code.
+func runValidate(stackPath string) int {
+	errs, warnings, err := config.CheckStack(stackPath, config.LoadOptions{EnvFiles: validateEnvFiles, Overlays: validateOverlays})
+	if err != nil {
+		// A file that cannot be parsed is reported like any other error
+		errs = config.ValidationErrors{{Field: "stack", Message: err.Error(), File: stackPath}}
+	}
+
+	code := validateExitOK
+	switch {
+	case len(errs) > 0:
+		code = validateExitErrors
+	case len(warnings) > 0 && validateStrict:
+		code = validateExitWarnings
+	}
+
+	if validateFormat == "json" {
+		report := validateReport{
+			File:     stackPath,
+			Valid:    len(errs) == 0,
+			Errors:   append([]config.ValidationError{}, errs...),
+			Warnings: append([]config.ValidationError{}, warnings...),
+		}
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+		return code
+	}
+
+	for _, e := range errs {
+		fmt.Printf("error: %s\n", e.Error())
+	}
+	for _, w := range warnings {
+		fmt.Printf("warning: %s\n", w.Error())
+	}
+	if len(errs) > 0 {
+		fmt.Printf("%s: %d error(s), %d warning(s)\n", stackPath, len(errs), len(warnings))
+	} else {
+		fmt.Printf("%s: valid, %d warning(s)\n", stackPath, len(warnings))
+	}
+	return code
+}
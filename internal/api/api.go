@@ -3,19 +3,28 @@ package api
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"io/fs"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gridctl/gridctl/pkg/a2a"
+	"github.com/gridctl/gridctl/pkg/audit"
 	"github.com/gridctl/gridctl/pkg/config"
 	"github.com/gridctl/gridctl/pkg/dockerclient"
 	"github.com/gridctl/gridctl/pkg/mcp"
+	"github.com/gridctl/gridctl/pkg/metrics"
 	"github.com/gridctl/gridctl/pkg/runtime/docker"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Server provides the combined API server for gridctl.
@@ -27,6 +36,21 @@ type Server struct {
 	staticFS     fs.FS
 	dockerClient dockerclient.DockerClient
 	stackName    string
+
+	approvalToken string // Required on approval requests; none are accepted when empty
+}
+
+// ApprovalTokenHeader carries the approval token on requests to
+// /api/approvals and the other routes in privateRoutes.
+const ApprovalTokenHeader = "X-Gridctl-Approval-Token"
+
+// NewApprovalToken returns a random token for authorizing approvals.
+func NewApprovalToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
 // NewServer creates a new API server.
@@ -54,6 +78,12 @@ func (s *Server) SetDockerClient(cli dockerclient.DockerClient) {
 	s.dockerClient = cli
 }
 
+// SetApprovalToken sets the token that approval requests must carry in
+// ApprovalTokenHeader.
+func (s *Server) SetApprovalToken(token string) {
+	s.approvalToken = token
+}
+
 // SetStackName sets the stack name for container lookups.
 func (s *Server) SetStackName(name string) {
 	s.stackName = name
@@ -78,8 +108,14 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/api/status", s.handleStatus)
 	mux.HandleFunc("/api/mcp-servers", s.handleMCPServers)
 	mux.HandleFunc("/api/tools", s.handleTools)
+	mux.Handle("/api/calls", s.localAuth(http.HandlerFunc(s.handleCalls)))
+	mux.HandleFunc("/api/cache", s.handleCache)
+	mux.Handle("/api/cache/invalidate", s.localAuth(http.HandlerFunc(s.handleCacheInvalidate)))
+	mux.Handle("/api/approvals", s.localAuth(http.HandlerFunc(s.handleApprovals)))
+	mux.Handle("/api/approvals/", s.localAuth(http.HandlerFunc(s.handleApprovalAction)))
 	mux.HandleFunc("/health", s.handleHealth)
 	mux.HandleFunc("/ready", s.handleReady)
+	mux.Handle("/metrics", metrics.Handler(s.metricsRegistry()))
 
 	// Agent control endpoints (pattern: /api/agents/{name}/action)
 	mux.HandleFunc("/api/agents/", s.handleAgentAction)
@@ -93,6 +129,23 @@ func (s *Server) Handler() http.Handler {
 	return corsMiddleware(mux)
 }
 
+// metricsRegistry collects the gateway, session, and A2A metrics served at
+// /metrics in the Prometheus text format.
+func (s *Server) metricsRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	s.gateway.RegisterMetrics(r)
+	r.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "gridctl_sse_connections",
+		Help: "Connected legacy SSE clients.",
+	}, func() float64 {
+		return float64(s.sseServer.SessionCount())
+	}))
+	if s.a2aGateway != nil {
+		s.a2aGateway.Handler().RegisterMetrics(r)
+	}
+	return r
+}
+
 // handleStatus returns the overall gateway status.
 // Agents are returned as a unified list that merges container and A2A status.
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
@@ -158,7 +211,159 @@ func (s *Server) handleTools(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result, _ := s.gateway.HandleToolsList()
-	writeJSON(w, result)
+
+	// Include each tool's server so the UI does not have to parse
+	// namespaced names, whose prefix and separator are configurable.
+	tools := make([]ToolInfo, len(result.Tools))
+	for i, tool := range result.Tools {
+		tools[i] = ToolInfo{Tool: tool}
+		if server, name, err := s.gateway.Router().ResolveTool(tool.Name); err == nil {
+			tools[i].Server = server
+			tools[i].OriginalName = name
+		}
+	}
+	writeJSON(w, map[string]any{"tools": tools})
+}
+
+// handleCalls returns recorded tool calls, newest first. Query parameters
+// filter by session, agent, server, and tool; error=true returns only
+// failed calls; since takes an RFC 3339 time or a duration such as "15m";
+// limit caps the number of calls.
+func (s *Server) handleCalls(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := audit.Filter{
+		SessionID: q.Get("session"),
+		Agent:     q.Get("agent"),
+		Server:    q.Get("server"),
+		Tool:      q.Get("tool"),
+	}
+	if v := q.Get("error"); v != "" {
+		errorOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			writeJSONError(w, "invalid error parameter: "+v, http.StatusBadRequest)
+			return
+		}
+		filter.ErrorOnly = errorOnly
+	}
+	if v := q.Get("since"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			filter.Since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, v); err == nil {
+			filter.Since = t
+		} else {
+			writeJSONError(w, "invalid since parameter: "+v, http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			writeJSONError(w, "invalid limit parameter: "+v, http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	calls := []audit.Event{}
+	if l := s.gateway.AuditLog(); l != nil {
+		calls = l.Query(filter)
+	}
+	writeJSON(w, map[string]any{"calls": calls})
+}
+
+// handleCache returns the result cache stats of each MCP server that
+// caches tool results.
+func (s *Server) handleCache(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, map[string]any{"servers": s.gateway.CacheStats()})
+}
+
+// handleCacheInvalidate drops cached tool results. The server and tool
+// query parameters narrow what is dropped; without them, all results are.
+func (s *Server) handleCacheInvalidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	server, tool := q.Get("server"), q.Get("tool")
+	if tool != "" && server == "" {
+		writeJSONError(w, "tool requires a server parameter", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]any{"invalidated": s.gateway.InvalidateCache(server, tool)})
+}
+
+// handleApprovals returns tool calls awaiting approval. With status=all,
+// recently decided approvals are included.
+func (s *Server) handleApprovals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status != "" && status != "pending" && status != "all" {
+		writeJSONError(w, "invalid status parameter: "+status, http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, map[string]any{"approvals": s.gateway.Approvals(status == "all")})
+}
+
+// handleApprovalAction approves or rejects a pending tool call.
+// URL pattern: /api/approvals/{id}/{approve|reject}
+func (s *Server) handleApprovalAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/api/approvals/")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" {
+		http.Error(w, "Invalid path: expected /api/approvals/{id}/{action}", http.StatusBadRequest)
+		return
+	}
+	id, action := parts[0], parts[1]
+	if action != "approve" && action != "reject" {
+		http.Error(w, "Unknown action: "+action, http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional
+	var body struct {
+		By      string `json:"by"`
+		Comment string `json:"comment"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			writeJSONError(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if body.By == "" {
+		body.By = "api"
+	}
+
+	approval, err := s.gateway.DecideApproval(id, action == "approve", body.By, body.Comment)
+	if err != nil {
+		writeJSONError(w, "approval "+id+": "+err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, approval)
+}
+
+// ToolInfo extends mcp.Tool with the server that provides it.
+type ToolInfo struct {
+	mcp.Tool
+	Server       string `json:"server,omitempty"`       // MCP server or A2A agent name
+	OriginalName string `json:"originalName,omitempty"` // Tool name on the server
 }
 
 // ServerInfo mirrors the mcp.ServerInfo type for API responses.
@@ -214,9 +419,47 @@ func writeJSONError(w http.ResponseWriter, message string, statusCode int) {
 	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
-// corsMiddleware adds CORS headers to responses.
+// privateRoutes are the API routes guarded by localAuth. They get no CORS
+// headers, so other origins cannot call them from a browser.
+var privateRoutes = []string{"/api/approvals", "/api/calls", "/api/cache/invalidate"}
+
+// isPrivateRoute reports whether path is one of privateRoutes or beneath it.
+func isPrivateRoute(path string) bool {
+	for _, route := range privateRoutes {
+		if path == route || strings.HasPrefix(path, route+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// localAuth admits requests only from this host and with the approval
+// token, so agents, which reach the gateway from their containers, cannot
+// decide their own held calls, read other agents' call arguments or flush
+// caches.
+func (s *Server) localAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			writeJSONError(w, "only accepted from localhost", http.StatusForbidden)
+			return
+		}
+		token := r.Header.Get(ApprovalTokenHeader)
+		if s.approvalToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.approvalToken)) != 1 {
+			writeJSONError(w, "missing or invalid approval token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// corsMiddleware adds CORS headers to responses, except on privateRoutes.
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPrivateRoute(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
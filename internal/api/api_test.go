@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gridctl/gridctl/pkg/mcp"
+)
+
+func TestLocalAuth(t *testing.T) {
+	s := NewServer(mcp.NewGateway(), nil)
+	s.SetApprovalToken("secret")
+	h := s.Handler()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		remoteAddr string
+		token      string
+		want       int
+	}{
+		{name: "list with token", method: http.MethodGet, path: "/api/approvals", remoteAddr: "127.0.0.1:5000", token: "secret", want: http.StatusOK},
+		{name: "list over IPv6 loopback", method: http.MethodGet, path: "/api/approvals", remoteAddr: "[::1]:5000", token: "secret", want: http.StatusOK},
+		{name: "list without token", method: http.MethodGet, path: "/api/approvals", remoteAddr: "127.0.0.1:5000", want: http.StatusUnauthorized},
+		{name: "decide with wrong token", method: http.MethodPost, path: "/api/approvals/abc/approve", remoteAddr: "127.0.0.1:5000", token: "guess", want: http.StatusUnauthorized},
+		{name: "decide from a container", method: http.MethodPost, path: "/api/approvals/abc/approve", remoteAddr: "172.17.0.2:5000", token: "secret", want: http.StatusForbidden},
+		{name: "decide unknown approval", method: http.MethodPost, path: "/api/approvals/abc/approve", remoteAddr: "127.0.0.1:5000", token: "secret", want: http.StatusNotFound},
+		{name: "calls with token", method: http.MethodGet, path: "/api/calls", remoteAddr: "127.0.0.1:5000", token: "secret", want: http.StatusOK},
+		{name: "calls without token", method: http.MethodGet, path: "/api/calls", remoteAddr: "127.0.0.1:5000", want: http.StatusUnauthorized},
+		{name: "invalidate cache with token", method: http.MethodPost, path: "/api/cache/invalidate", remoteAddr: "127.0.0.1:5000", token: "secret", want: http.StatusOK},
+		{name: "invalidate cache without token", method: http.MethodPost, path: "/api/cache/invalidate", remoteAddr: "127.0.0.1:5000", want: http.StatusUnauthorized},
+		{name: "invalidate cache from a container", method: http.MethodPost, path: "/api/cache/invalidate", remoteAddr: "172.17.0.2:5000", token: "secret", want: http.StatusForbidden},
+		{name: "calls from a container", method: http.MethodGet, path: "/api/calls", remoteAddr: "172.17.0.2:5000", token: "secret", want: http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.token != "" {
+				req.Header.Set(ApprovalTokenHeader, tc.token)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tc.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tc.want, rec.Body.String())
+			}
+			if origin := rec.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+				t.Errorf("private route sent Access-Control-Allow-Origin: %s", origin)
+			}
+		})
+	}
+}
+
+func TestLocalAuth_NoToken(t *testing.T) {
+	h := NewServer(mcp.NewGateway(), nil).Handler()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/approvals", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.Header.Set(ApprovalTokenHeader, "")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d when the server has no token", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestCORS_OtherRoutes(t *testing.T) {
+	h := NewServer(mcp.NewGateway(), nil).Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if origin := rec.Header().Get("Access-Control-Allow-Origin"); origin != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", origin)
+	}
+}
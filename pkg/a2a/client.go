@@ -11,6 +11,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/gridctl/gridctl/pkg/tracing"
 )
 
 // Client communicates with a remote A2A agent.
@@ -193,6 +195,7 @@ func (c *Client) call(ctx context.Context, method string, params, result any) er
 		return fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	tracing.InjectHTTP(ctx, req.Header)
 
 	// Add auth header if configured
 	if authType == "bearer" && authToken != "" {
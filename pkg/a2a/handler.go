@@ -10,7 +10,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gridctl/gridctl/pkg/metrics"
+	"github.com/gridctl/gridctl/pkg/tracing"
+
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TaskHandler is a function that processes A2A messages for a local agent.
@@ -28,6 +35,7 @@ type Handler struct {
 	baseURL     string
 	localAgents map[string]*LocalAgent // name -> local agent
 	tasks       map[string]*Task       // taskID -> task
+	messages    *prometheus.CounterVec // messages handled, by agent and resulting task state
 }
 
 // NewHandler creates a new A2A HTTP handler.
@@ -36,9 +44,36 @@ func NewHandler(baseURL string) *Handler {
 		baseURL:     baseURL,
 		localAgents: make(map[string]*LocalAgent),
 		tasks:       make(map[string]*Task),
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gridctl_a2a_messages_total",
+			Help: "A2A messages handled by local agents, by resulting task state.",
+		}, []string{"agent", "state"}),
 	}
 }
 
+// RegisterMetrics adds the handler's metrics to a registry: messages
+// handled per agent and the tasks held in each state.
+func (h *Handler) RegisterMetrics(r prometheus.Registerer) {
+	r.MustRegister(
+		h.messages,
+		metrics.NewGaugeFunc("gridctl_a2a_tasks", "A2A tasks held by the gateway, by state.",
+			[]string{"state"}, func() []metrics.Sample {
+				h.mu.RLock()
+				counts := make(map[TaskState]int)
+				for _, task := range h.tasks {
+					counts[task.Status.State]++
+				}
+				h.mu.RUnlock()
+
+				samples := make([]metrics.Sample, 0, len(counts))
+				for state, n := range counts {
+					samples = append(samples, metrics.Sample{Labels: []string{string(state)}, Value: float64(n)})
+				}
+				return samples
+			}),
+	)
+}
+
 // RegisterLocalAgent registers an agent that this gateway serves.
 func (h *Handler) RegisterLocalAgent(name string, agent *LocalAgent) {
 	h.mu.Lock()
@@ -193,7 +228,8 @@ func (h *Handler) handleAgentRPC(w http.ResponseWriter, r *http.Request, agentNa
 		return
 	}
 
-	resp := h.handleMethod(r.Context(), agent, &req)
+	ctx := tracing.ExtractHTTP(r.Context(), r.Header)
+	resp := h.handleMethod(ctx, agent, &req)
 	h.writeResponse(w, resp)
 }
 
@@ -225,10 +261,15 @@ func (h *Handler) handleSendMessage(ctx context.Context, agent *LocalAgent, req
 		params.Message.ID = uuid.New().String()
 	}
 
+	ctx, span := tracing.Start(ctx, "a2a message/send", trace.SpanKindServer,
+		attribute.String("a2a.agent", agent.Card.Name))
+	defer span.End()
+
 	// Create or get task
 	task := h.createTask(params.ContextID)
 	task.Messages = append(task.Messages, params.Message)
 	task.Status = TaskStatus{State: TaskStateWorking}
+	span.SetAttributes(attribute.String("a2a.task.id", task.ID))
 
 	// If there's a handler, invoke it
 	if agent.Handler != nil {
@@ -248,6 +289,11 @@ func (h *Handler) handleSendMessage(ctx context.Context, agent *LocalAgent, req
 	}
 
 	h.updateTask(task)
+	h.messages.WithLabelValues(agent.Card.Name, string(task.Status.State)).Inc()
+	span.SetAttributes(attribute.String("a2a.task.state", string(task.Status.State)))
+	if task.Status.State == TaskStateFailed {
+		span.SetStatus(codes.Error, task.Status.Message)
+	}
 
 	result := SendMessageResult{Task: task}
 	return NewSuccessResponse(req.ID, result)
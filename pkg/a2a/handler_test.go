@@ -5,7 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/gridctl/gridctl/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestNewHandler(t *testing.T) {
@@ -394,3 +399,35 @@ func mustMarshalRaw(v interface{}) *json.RawMessage {
 	data := mustMarshal(v)
 	return &data
 }
+
+func TestHandler_Metrics(t *testing.T) {
+	h := NewHandler("http://localhost:8080")
+	h.RegisterLocalAgent("test-agent", &LocalAgent{
+		Card: AgentCard{Name: "test-agent"},
+	})
+	reg := prometheus.NewRegistry()
+	h.RegisterMetrics(reg)
+
+	for i := 0; i < 2; i++ {
+		body, _ := json.Marshal(Request{
+			JSONRPC: "2.0",
+			ID:      mustMarshalRaw("1"),
+			Method:  MethodSendMessage,
+			Params:  mustMarshal(SendMessageParams{Message: Message{Role: "user", Parts: []Part{{Text: "Hello"}}}}),
+		})
+		req := httptest.NewRequest(http.MethodPost, "/a2a/test-agent", bytes.NewReader(body))
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler(reg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := rec.Body.String()
+	for _, want := range []string{
+		`gridctl_a2a_messages_total{agent="test-agent",state="completed"} 2`,
+		`gridctl_a2a_tasks{state="completed"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in metrics output:\n%s", want, out)
+		}
+	}
+}
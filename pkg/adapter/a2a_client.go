@@ -9,6 +9,11 @@ import (
 
 	"github.com/gridctl/gridctl/pkg/a2a"
 	"github.com/gridctl/gridctl/pkg/mcp"
+	"github.com/gridctl/gridctl/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // A2AClientAdapter adapts an A2A agent to the mcp.AgentClient interface.
@@ -95,8 +100,18 @@ func (a *A2AClientAdapter) Tools() []mcp.Tool {
 	return a.tools
 }
 
-// CallTool invokes an A2A skill using the message/send method.
-func (a *A2AClientAdapter) CallTool(ctx context.Context, name string, arguments map[string]any) (*mcp.ToolCallResult, error) {
+// CallTool invokes an A2A skill using the message/send method. The trace
+// context is propagated to the agent in the request headers.
+func (a *A2AClientAdapter) CallTool(ctx context.Context, name string, arguments map[string]any) (out *mcp.ToolCallResult, err error) {
+	ctx, span := tracing.Start(ctx, "a2a "+name, trace.SpanKindClient,
+		attribute.String("a2a.agent", a.name), attribute.String("a2a.skill", name))
+	defer func() {
+		if out != nil && out.IsError {
+			span.SetStatus(codes.Error, "skill returned an error")
+		}
+		tracing.End(span, err)
+	}()
+
 	// Build message with tool invocation details
 	argsJSON, err := json.Marshal(arguments)
 	if err != nil {
@@ -0,0 +1,256 @@
+// Package audit records tool calls made through the gateway as JSON lines,
+// for debugging agents and for compliance review.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Defaults for Options fields left at zero.
+const (
+	DefaultMaxSize  = 10 * 1024 * 1024 // Rotate after 10 MiB
+	DefaultMaxFiles = 5                // Rotated files kept besides the current one
+	DefaultRecent   = 1000             // Events kept in memory for queries
+	DefaultLimit    = 100              // Events returned by Query without a limit
+)
+
+// Event is one recorded tool call.
+type Event struct {
+	Timestamp  time.Time      `json:"timestamp"`
+	SessionID  string         `json:"sessionId,omitempty"`
+	Agent      string         `json:"agent,omitempty"`
+	Server     string         `json:"server,omitempty"`
+	Tool       string         `json:"tool,omitempty"` // Tool name on the server
+	Name       string         `json:"name"`           // Tool name as called
+	Arguments  map[string]any `json:"arguments,omitempty"`
+	DurationMS int64          `json:"durationMs"`
+	Error      bool           `json:"error"`
+	ResultSize int            `json:"resultSize"` // Bytes of result content
+	Approval   *Approval      `json:"approval,omitempty"`
+}
+
+// Approval records the human decision on a call that required one.
+type Approval struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"` // approved, rejected, expired, or cancelled
+	DecidedBy string `json:"decidedBy,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// Options configures a Log.
+type Options struct {
+	MaxSize  int64    // Bytes before the file is rotated (default: DefaultMaxSize)
+	MaxFiles int      // Rotated files to keep (default: DefaultMaxFiles)
+	Recent   int      // Events kept in memory for Query (default: DefaultRecent)
+	Redact   []string // Argument field patterns to redact, see NewRedactor
+}
+
+// Log appends events to a JSONL file, rotating it by size, and keeps the
+// most recent events in memory for queries. Rotated files are named
+// <path>.1 (newest) to <path>.<MaxFiles>.
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	opts     Options
+	redactor *Redactor
+	file     *os.File
+	size     int64
+	recent   []Event // Oldest first
+}
+
+// Open opens or creates the audit log at path. Events already in the file
+// are loaded so that queries cover calls from before a restart.
+func Open(path string, opts Options) (*Log, error) {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultMaxFiles
+	}
+	if opts.Recent <= 0 {
+		opts.Recent = DefaultRecent
+	}
+	redactor, err := NewRedactor(opts.Redact)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating audit log directory: %w", err)
+	}
+	l := &Log{path: path, opts: opts, redactor: redactor}
+	l.recent = loadRecent(path, opts.Recent)
+	if err := l.openFile(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Path returns the path of the current log file.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// openFile opens the current log file for appending.
+func (l *Log) openFile() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// Redact returns a copy of args with the log's redacted fields replaced.
+func (l *Log) Redact(args map[string]any) map[string]any {
+	return l.redactor.Redact(args)
+}
+
+// Record redacts an event's arguments and appends it to the log.
+func (l *Log) Record(e Event) error {
+	e.Arguments = l.redactor.Redact(e.Arguments)
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding audit event: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.recent = append(l.recent, e)
+	if len(l.recent) > l.opts.Recent {
+		l.recent = l.recent[len(l.recent)-l.opts.Recent:]
+	}
+
+	if l.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+	if l.size > 0 && l.size+int64(len(data)) > l.opts.MaxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate shifts <path>.N to <path>.N+1, dropping the oldest, moves the
+// current file to <path>.1, and starts a new file. Must be called with l.mu held.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("closing audit log: %w", err)
+	}
+	l.file = nil
+
+	_ = os.Remove(fmt.Sprintf("%s.%d", l.path, l.opts.MaxFiles))
+	for i := l.opts.MaxFiles - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		return fmt.Errorf("rotating audit log: %w", err)
+	}
+	return l.openFile()
+}
+
+// Close closes the log file. Queries keep working on the recent events.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Filter selects events in Query. Zero fields match everything.
+type Filter struct {
+	SessionID string
+	Agent     string
+	Server    string
+	Tool      string // Matches the tool name on the server or as called
+	ErrorOnly bool
+	Since     time.Time
+	Limit     int // Maximum events returned (default: DefaultLimit)
+}
+
+// Match reports whether an event passes the filter.
+func (f *Filter) Match(e *Event) bool {
+	switch {
+	case f.SessionID != "" && e.SessionID != f.SessionID:
+		return false
+	case f.Agent != "" && e.Agent != f.Agent:
+		return false
+	case f.Server != "" && e.Server != f.Server:
+		return false
+	case f.Tool != "" && e.Tool != f.Tool && e.Name != f.Tool:
+		return false
+	case f.ErrorOnly && !e.Error:
+		return false
+	case !f.Since.IsZero() && e.Timestamp.Before(f.Since):
+		return false
+	}
+	return true
+}
+
+// Query returns recent events matching the filter, newest first.
+func (l *Log) Query(f Filter) []Event {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := []Event{}
+	for i := len(l.recent) - 1; i >= 0 && len(events) < limit; i-- {
+		if f.Match(&l.recent[i]) {
+			events = append(events, l.recent[i])
+		}
+	}
+	return events
+}
+
+// loadRecent reads up to n of the last events from an existing log file.
+// Unreadable files and malformed lines are skipped.
+func loadRecent(path string, n int) []Event {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if json.Unmarshal(scanner.Bytes(), &e) != nil {
+			continue
+		}
+		events = append(events, e)
+		if len(events) > n {
+			events = events[1:]
+		}
+	}
+	return events
+}
@@ -0,0 +1,250 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestLog(t *testing.T, opts Options) *Log {
+	t.Helper()
+	l, err := Open(filepath.Join(t.TempDir(), "logs", "stack-audit.jsonl"), opts)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func TestLog_RecordAndQuery(t *testing.T) {
+	l := openTestLog(t, Options{})
+	start := time.Now().Add(-time.Hour)
+
+	events := []Event{
+		{Timestamp: start, SessionID: "s1", Agent: "coder", Server: "github", Tool: "create_issue", Name: "github__create_issue"},
+		{Timestamp: start.Add(time.Minute), SessionID: "s1", Agent: "coder", Server: "github", Tool: "list_issues", Name: "github__list_issues", Error: true},
+		{Timestamp: start.Add(2 * time.Minute), SessionID: "s2", Server: "fs", Tool: "read_file", Name: "fs__read_file"},
+	}
+	for _, e := range events {
+		if err := l.Record(e); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"all newest first", Filter{}, []string{"fs__read_file", "github__list_issues", "github__create_issue"}},
+		{"session", Filter{SessionID: "s1"}, []string{"github__list_issues", "github__create_issue"}},
+		{"agent", Filter{Agent: "coder"}, []string{"github__list_issues", "github__create_issue"}},
+		{"server", Filter{Server: "fs"}, []string{"fs__read_file"}},
+		{"tool on server", Filter{Tool: "create_issue"}, []string{"github__create_issue"}},
+		{"tool as called", Filter{Tool: "fs__read_file"}, []string{"fs__read_file"}},
+		{"errors", Filter{ErrorOnly: true}, []string{"github__list_issues"}},
+		{"since", Filter{Since: start.Add(30 * time.Second)}, []string{"fs__read_file", "github__list_issues"}},
+		{"limit", Filter{Limit: 1}, []string{"fs__read_file"}},
+		{"no match", Filter{Agent: "missing"}, []string{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := l.Query(tc.filter)
+			if got == nil {
+				t.Fatal("Query() returned nil")
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("Query() returned %d events, want %d", len(got), len(tc.want))
+			}
+			for i, name := range tc.want {
+				if got[i].Name != name {
+					t.Errorf("event %d = %s, want %s", i, got[i].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestLog_WritesJSONL(t *testing.T) {
+	l := openTestLog(t, Options{})
+	if err := l.Record(Event{Name: "a", Arguments: map[string]any{"path": "/tmp"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(Event{Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(l.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(lines), data)
+	}
+	if !strings.Contains(lines[0], `"name":"a"`) || !strings.Contains(lines[0], `"path":"/tmp"`) {
+		t.Errorf("unexpected first line: %s", lines[0])
+	}
+
+	info, err := os.Stat(l.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestLog_LoadsExistingEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if err := l.Record(Event{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	// Malformed lines are skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("not json\n")
+	f.Close()
+
+	reopened, err := Open(path, Options{Recent: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	got := reopened.Query(Filter{})
+	if len(got) != 2 || got[0].Name != "c" || got[1].Name != "b" {
+		t.Errorf("Query() after reopen = %+v, want c, b", got)
+	}
+}
+
+func TestLog_Rotate(t *testing.T) {
+	l := openTestLog(t, Options{MaxSize: 200, MaxFiles: 2})
+	args := map[string]any{"padding": strings.Repeat("x", 100)}
+	for i := 0; i < 5; i++ {
+		if err := l.Record(Event{Name: "tool", Arguments: args}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	for _, suffix := range []string{"", ".1", ".2"} {
+		if _, err := os.Stat(l.Path() + suffix); err != nil {
+			t.Errorf("expected %s to exist: %v", l.Path()+suffix, err)
+		}
+	}
+	if _, err := os.Stat(l.Path() + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected %s.3 to be removed, got %v", l.Path(), err)
+	}
+	if got := l.Query(Filter{}); len(got) != 5 {
+		t.Errorf("Query() returned %d events after rotation, want 5", len(got))
+	}
+}
+
+func TestLog_RecordAfterClose(t *testing.T) {
+	l := openTestLog(t, Options{})
+	l.Close()
+	if err := l.Record(Event{Name: "tool"}); err == nil {
+		t.Error("expected error recording to a closed log")
+	}
+	if got := l.Query(Filter{}); len(got) != 1 {
+		t.Errorf("Query() returned %d events, want 1", len(got))
+	}
+}
+
+func TestLog_Redacts(t *testing.T) {
+	l := openTestLog(t, Options{})
+	args := map[string]any{"query": "q", "api_key": "k"}
+	if err := l.Record(Event{Name: "tool", Arguments: args}); err != nil {
+		t.Fatal(err)
+	}
+	if args["api_key"] != "k" {
+		t.Error("Record() modified the caller's arguments")
+	}
+
+	got := l.Query(Filter{})[0].Arguments
+	if got["api_key"] != Redacted || got["query"] != "q" {
+		t.Errorf("recorded arguments = %v", got)
+	}
+	data, _ := os.ReadFile(l.Path())
+	if strings.Contains(string(data), `"k"`) {
+		t.Errorf("redacted value written to file: %s", data)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		args     map[string]any
+		want     map[string]any
+	}{
+		{
+			name:     "defaults",
+			patterns: nil,
+			args:     map[string]any{"GITHUB_TOKEN": "t", "password": "p", "Authorization": "a", "path": "/x"},
+			want:     map[string]any{"GITHUB_TOKEN": Redacted, "password": Redacted, "Authorization": Redacted, "path": "/x"},
+		},
+		{
+			name:     "nested",
+			patterns: []string{"secret"},
+			args: map[string]any{
+				"config": map[string]any{"secret": "s", "name": "n"},
+				"items":  []any{map[string]any{"secret": "s"}, "plain"},
+			},
+			want: map[string]any{
+				"config": map[string]any{"secret": Redacted, "name": "n"},
+				"items":  []any{map[string]any{"secret": Redacted}, "plain"},
+			},
+		},
+		{
+			name:     "redacts whole object",
+			patterns: []string{"credentials"},
+			args:     map[string]any{"credentials": map[string]any{"user": "u"}},
+			want:     map[string]any{"credentials": Redacted},
+		},
+		{
+			name:     "empty list redacts nothing",
+			patterns: []string{},
+			args:     map[string]any{"password": "p"},
+			want:     map[string]any{"password": "p"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewRedactor(tc.patterns)
+			if err != nil {
+				t.Fatalf("NewRedactor() error = %v", err)
+			}
+			got := r.Redact(tc.args)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Redact() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateRedactPattern(t *testing.T) {
+	for _, p := range []string{"token", "*key*", "x-?"} {
+		if err := ValidateRedactPattern(p); err != nil {
+			t.Errorf("ValidateRedactPattern(%q) error = %v", p, err)
+		}
+	}
+	for _, p := range []string{"", "[abc"} {
+		if err := ValidateRedactPattern(p); err == nil {
+			t.Errorf("ValidateRedactPattern(%q) expected error", p)
+		}
+	}
+}
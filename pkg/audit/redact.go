@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Redacted replaces the values of redacted argument fields.
+const Redacted = "[REDACTED]"
+
+// DefaultRedact lists the argument fields redacted when no patterns are
+// configured.
+var DefaultRedact = []string{"*password*", "*secret*", "*token*", "*api_key*", "*apikey*", "authorization", "cookie"}
+
+// Redactor replaces the values of sensitive argument fields.
+type Redactor struct {
+	patterns []string
+}
+
+// NewRedactor compiles redaction patterns. Each pattern is a field name or
+// glob (path.Match syntax) matched case-insensitively against argument keys
+// at any depth. A nil list uses DefaultRedact; an empty list redacts nothing.
+func NewRedactor(patterns []string) (*Redactor, error) {
+	if patterns == nil {
+		patterns = DefaultRedact
+	}
+	r := &Redactor{}
+	for _, p := range patterns {
+		if err := ValidateRedactPattern(p); err != nil {
+			return nil, err
+		}
+		r.patterns = append(r.patterns, strings.ToLower(p))
+	}
+	return r, nil
+}
+
+// ValidateRedactPattern reports whether a redaction pattern is well-formed.
+func ValidateRedactPattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("empty redaction pattern")
+	}
+	if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+		return fmt.Errorf("invalid redaction pattern '%s'", pattern)
+	}
+	return nil
+}
+
+// matches reports whether a field name is redacted.
+func (r *Redactor) matches(key string) bool {
+	key = strings.ToLower(key)
+	for _, p := range r.patterns {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of args with redacted field values replaced.
+// The input is not modified.
+func (r *Redactor) Redact(args map[string]any) map[string]any {
+	if args == nil || len(r.patterns) == 0 {
+		return args
+	}
+	return r.redactValue(args).(map[string]any)
+}
+
+// redactValue copies a value, redacting matching fields in nested objects.
+func (r *Redactor) redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			if r.matches(key) {
+				out[key] = Redacted
+			} else {
+				out[key] = r.redactValue(item)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = r.redactValue(item)
+		}
+		return out
+	}
+	return v
+}
@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/gridctl/gridctl/pkg/dockerclient"
 )
 
 // Builder handles building images from source.
+// It is safe for concurrent use.
 type Builder struct {
 	cli dockerclient.DockerClient
+
+	mu        sync.Mutex
+	repoLocks map[string]*sync.Mutex // Serializes builds that share a cached git checkout
 }
 
 // New creates a new Builder instance.
@@ -26,6 +31,10 @@ func (b *Builder) Build(ctx context.Context, opts BuildOptions) (*BuildResult, e
 
 	switch opts.SourceType {
 	case "git":
+		// The checkout is shared per URL, so hold it until the build has read it
+		lock := b.repoLock(opts.URL)
+		lock.Lock()
+		defer lock.Unlock()
 		contextPath, err = b.prepareGitSource(opts)
 	case "local":
 		contextPath, err = b.prepareLocalSource(opts)
@@ -109,3 +118,18 @@ func (b *Builder) prepareLocalSource(opts BuildOptions) (string, error) {
 func GenerateTag(stack, agentName string) string {
 	return fmt.Sprintf("gridctl-%s-%s:latest", stack, agentName)
 }
+
+// repoLock returns the lock guarding the cached checkout for a git URL.
+func (b *Builder) repoLock(url string) *sync.Mutex {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.repoLocks == nil {
+		b.repoLocks = make(map[string]*sync.Mutex)
+	}
+	lock, ok := b.repoLocks[url]
+	if !ok {
+		lock = &sync.Mutex{}
+		b.repoLocks[url] = lock
+	}
+	return lock
+}
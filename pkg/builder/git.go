@@ -30,6 +30,20 @@ func CloneOrUpdate(url, ref string) (string, error) {
 	return cloneRepo(url, ref, repoPath)
 }
 
+// CachedRepo returns the path to a repository's checkout as last cloned or
+// updated, without contacting its remote. The error wraps fs.ErrNotExist
+// if the repository has not been cloned.
+func CachedRepo(url string) (string, error) {
+	repoPath, err := URLToPath(url)
+	if err != nil {
+		return "", fmt.Errorf("getting cache path: %w", err)
+	}
+	if _, err := os.Stat(repoPath); err != nil {
+		return "", err
+	}
+	return repoPath, nil
+}
+
 func cloneRepo(url, ref, destPath string) (string, error) {
 	fmt.Printf("    Cloning %s...\n", url)
 
@@ -0,0 +1,271 @@
+// Package catalog describes well-known MCP servers and generates stack
+// files that run them.
+package catalog
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gridctl/gridctl/pkg/config"
+	"github.com/gridctl/gridctl/pkg/state"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed catalog.yaml
+var builtin []byte
+
+// Entry is an MCP server in the catalog, with what a stack needs to run it.
+type Entry struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Docs        string   `yaml:"docs,omitempty"`      // Where to read about the server
+	Image       string   `yaml:"image,omitempty"`     // Container image
+	URL         string   `yaml:"url,omitempty"`       // External server
+	Command     []string `yaml:"command,omitempty"`   // Container command, or the local process to run without an image
+	Transport   string   `yaml:"transport,omitempty"` // "http", "stdio" or "sse"
+	Port        int      `yaml:"port,omitempty"`      // For HTTP transport in a container
+	Volumes     []string `yaml:"volumes,omitempty"`   // Mounts, as for mcp-servers
+	Env         []EnvVar `yaml:"env,omitempty"`
+	Tools       []string `yaml:"tools,omitempty"` // Recommended tool filter
+}
+
+// EnvVar is an environment variable a catalog server reads.
+type EnvVar struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Value       string `yaml:"value,omitempty"`   // Fixed value
+	Default     string `yaml:"default,omitempty"` // Default when the variable is unset
+	Secret      bool   `yaml:"secret,omitempty"`  // Read from a gridctl secret
+}
+
+// SecretName returns the name of the gridctl secret a secret variable is
+// read from: its name in lower case, with dashes for underscores.
+func (v EnvVar) SecretName() string {
+	return strings.ToLower(strings.ReplaceAll(v.Name, "_", "-"))
+}
+
+// reference returns the stack env value for the variable.
+func (v EnvVar) reference() string {
+	switch {
+	case v.Value != "":
+		return v.Value
+	case v.Secret:
+		return "${secret:" + v.SecretName() + "}"
+	case v.Default != "":
+		return "${" + v.Name + ":-" + v.Default + "}"
+	case v.Description != "":
+		return "${" + v.Name + ":?" + v.Description + "}"
+	default:
+		return "${" + v.Name + ":?is required}"
+	}
+}
+
+// Catalog is a list of servers, in the order they are offered.
+type Catalog struct {
+	Servers []Entry `yaml:"servers"`
+}
+
+// UserPath returns the path of the user's catalog (~/.gridctl/catalog.yaml).
+func UserPath() string {
+	return filepath.Join(state.BaseDir(), "catalog.yaml")
+}
+
+// Builtin returns the catalog built into gridctl.
+func Builtin() (*Catalog, error) {
+	return parse(builtin, "built-in catalog")
+}
+
+// Load returns the built-in catalog extended by the catalog file at path.
+// Entries in the file replace built-in entries of the same name; others
+// are added after them. A missing file is ignored when it is the user's
+// default catalog.
+func Load(path string) (*Catalog, error) {
+	c, err := Builtin()
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		path = UserPath()
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return c, nil
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading catalog: %w", err)
+	}
+	user, err := parse(data, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range user.Servers {
+		if i := c.index(e.Name); i >= 0 {
+			c.Servers[i] = e
+		} else {
+			c.Servers = append(c.Servers, e)
+		}
+	}
+	return c, nil
+}
+
+// parse reads a catalog file, rejecting unknown keys.
+func parse(data []byte, source string) (*Catalog, error) {
+	var c Catalog
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	seen := make(map[string]bool)
+	for i, e := range c.Servers {
+		switch {
+		case e.Name == "":
+			return nil, fmt.Errorf("%s: servers[%d]: name is required", source, i)
+		case seen[e.Name]:
+			return nil, fmt.Errorf("%s: duplicate server '%s'", source, e.Name)
+		case e.Image == "" && e.URL == "" && len(e.Command) == 0:
+			return nil, fmt.Errorf("%s: server '%s' needs an image, url or command", source, e.Name)
+		}
+		seen[e.Name] = true
+	}
+	return &c, nil
+}
+
+// Get returns the entry with the given name.
+func (c *Catalog) Get(name string) (Entry, bool) {
+	if i := c.index(name); i >= 0 {
+		return c.Servers[i], true
+	}
+	return Entry{}, false
+}
+
+func (c *Catalog) index(name string) int {
+	for i, e := range c.Servers {
+		if e.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Names returns the names of the catalog's servers, in order.
+func (c *Catalog) Names() []string {
+	names := make([]string, len(c.Servers))
+	for i, e := range c.Servers {
+		names[i] = e.Name
+	}
+	return names
+}
+
+// Generate returns a stack file named name that runs the given servers,
+// with comments saying where each comes from and which secrets it needs.
+// The stack is checked with config.Validate before it is returned.
+func Generate(name string, servers []Entry) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	root.HeadComment = "Generated by gridctl init. Deploy with: gridctl deploy <this file>"
+	addScalar(root, "version", "1")
+	addScalar(root, "name", name)
+
+	var volumes []string
+	list := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, e := range servers {
+		item := &yaml.Node{Kind: yaml.MappingNode}
+		comment := e.Description
+		if e.Docs != "" {
+			comment += "\n" + e.Docs
+		}
+		item.HeadComment = comment
+		addScalar(item, "name", e.Name)
+		addScalar(item, "image", e.Image)
+		addScalar(item, "url", e.URL)
+		addScalar(item, "transport", e.Transport)
+		if e.Port > 0 {
+			item.Content = append(item.Content, scalar("port"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(e.Port)})
+		}
+		addList(item, "command", e.Command)
+		addList(item, "volumes", e.Volumes)
+		for _, spec := range e.Volumes {
+			if m, err := config.ParseVolumeMount(spec); err == nil && m.IsNamedVolume() && !slices.Contains(volumes, m.Source) {
+				volumes = append(volumes, m.Source)
+			}
+		}
+		if len(e.Env) > 0 {
+			env := &yaml.Node{Kind: yaml.MappingNode}
+			for _, v := range e.Env {
+				addScalar(env, v.Name, v.reference())
+				if v.Secret {
+					value := env.Content[len(env.Content)-1]
+					value.LineComment = "gridctl secret set " + v.SecretName()
+					if v.Description != "" {
+						value.LineComment += " (" + v.Description + ")"
+					}
+				}
+			}
+			item.Content = append(item.Content, scalar("env"), env)
+		}
+		addList(item, "tools", e.Tools)
+		list.Content = append(list.Content, item)
+	}
+
+	if len(volumes) > 0 {
+		vols := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, v := range volumes {
+			vol := &yaml.Node{Kind: yaml.MappingNode}
+			addScalar(vol, "name", v)
+			vols.Content = append(vols.Content, vol)
+		}
+		root.Content = append(root.Content, scalar("volumes"), vols)
+	}
+	root.Content = append(root.Content, scalar("mcp-servers"), list)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	var stack config.Stack
+	if err := yaml.Unmarshal(buf.Bytes(), &stack); err != nil {
+		return nil, err
+	}
+	stack.SetDefaults()
+	if err := config.Validate(&stack); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// addScalar adds key: value to a mapping node, unless value is empty.
+func addScalar(m *yaml.Node, key, value string) {
+	if value == "" {
+		return
+	}
+	m.Content = append(m.Content, scalar(key), scalar(value))
+}
+
+// addList adds key: [values] to a mapping node, unless values is empty.
+func addList(m *yaml.Node, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	list := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, v := range values {
+		list.Content = append(list.Content, scalar(v))
+	}
+	m.Content = append(m.Content, scalar(key), list)
+}
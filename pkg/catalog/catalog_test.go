@@ -0,0 +1,168 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gridctl/gridctl/pkg/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuiltin_Generates(t *testing.T) {
+	c, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin failed: %v", err)
+	}
+	for _, name := range []string{"github", "filesystem", "atlassian", "postgres"} {
+		if _, ok := c.Get(name); !ok {
+			t.Errorf("expected %s in the catalog", name)
+		}
+	}
+
+	// Every entry makes a valid stack, alone and all together
+	for _, e := range c.Servers {
+		if _, err := Generate("test", []Entry{e}); err != nil {
+			t.Errorf("%s: %v", e.Name, err)
+		}
+	}
+	out, err := Generate("test", c.Servers)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	var stack config.Stack
+	if err := yaml.Unmarshal(out, &stack); err != nil {
+		t.Fatalf("generated stack does not parse: %v", err)
+	}
+	if len(stack.MCPServers) != len(c.Servers) {
+		t.Errorf("expected %d servers, got %d", len(c.Servers), len(stack.MCPServers))
+	}
+}
+
+func TestBuiltin_NoWarnings(t *testing.T) {
+	c, err := Builtin()
+	if err != nil {
+		t.Fatalf("Builtin failed: %v", err)
+	}
+	out, err := Generate("test", c.Servers)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "stack.yaml")
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fresh init stack must pass gridctl validate --strict once its
+	// variables are set
+	_, warnings, err := config.CheckStack(path, config.LoadOptions{AllowUnresolved: true})
+	if err != nil {
+		t.Fatalf("CheckStack failed: %v", err)
+	}
+	for _, w := range warnings {
+		t.Errorf("unexpected warning: %v", w)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	out, err := Generate("demo", []Entry{
+		{
+			Name:        "api",
+			Description: "An API server",
+			Image:       "example/api:1.0",
+			Port:        8080,
+			Volumes:     []string{"data:/data", "./config:/config:ro"},
+			Env: []EnvVar{
+				{Name: "API_TOKEN", Secret: true},
+				{Name: "REGION", Default: "eu"},
+				{Name: "ACCOUNT", Description: "your account ID"},
+				{Name: "MODE", Value: "readonly"},
+			},
+			Tools: []string{"get_*"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for _, want := range []string{
+		"# An API server\n",
+		"port: 8080\n",
+		"volumes:\n  - name: data\n",
+		"API_TOKEN: ${secret:api-token} # gridctl secret set api-token\n",
+		"REGION: ${REGION:-eu}\n",
+		"ACCOUNT: ${ACCOUNT:?your account ID}\n",
+		"MODE: readonly\n",
+		"tools: [get_*]\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestGenerate_Invalid(t *testing.T) {
+	_, err := Generate("demo", []Entry{
+		{Name: "api", Image: "example/api:1.0", Port: 8080},
+		{Name: "api", Image: "example/api:1.0", Port: 8081},
+	})
+	if err == nil || !strings.Contains(err.Error(), "duplicate MCP server name 'api'") {
+		t.Errorf("expected a duplicate server error, got %v", err)
+	}
+}
+
+func TestLoad_UserCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	content := `servers:
+  - name: github
+    description: Our GitHub Enterprise server
+    url: https://mcp.github.example.com
+  - name: internal
+    image: registry.example.com/mcp/internal:2.1
+    port: 3000
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	builtin, _ := Builtin()
+	names := c.Names()
+	if len(names) != len(builtin.Servers)+1 || names[0] != "github" || names[len(names)-1] != "internal" {
+		t.Errorf("expected the user entry added at the end, got %v", names)
+	}
+	if e, _ := c.Get("github"); e.URL != "https://mcp.github.example.com" || e.Image != "" {
+		t.Errorf("expected the user entry to replace the built-in one, got %+v", e)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unknown key", "servers:\n  - name: a\n    image: a:1\n    tool: [x]\n", "field tool not found"},
+		{"no name", "servers:\n  - image: a:1\n", "name is required"},
+		{"duplicate", "servers:\n  - {name: a, image: a:1}\n  - {name: a, image: a:2}\n", "duplicate server 'a'"},
+		{"nothing to run", "servers:\n  - name: a\n", "needs an image, url or command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "catalog.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing catalog file")
+	}
+}
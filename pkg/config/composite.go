@@ -0,0 +1,51 @@
+package config
+
+import (
+	"encoding/json"
+	"text/template"
+)
+
+// CompositeTool is a tool defined in the stack file that runs a sequence of
+// calls to other tools. The gateway serves composite tools under
+// BuiltinToolPrefix, so agents and toolsets select them with that server name.
+type CompositeTool struct {
+	Name        string          `yaml:"name"`
+	Description string          `yaml:"description,omitempty"`
+	InputSchema map[string]any  `yaml:"input_schema,omitempty"` // JSON Schema for the tool's arguments
+	Steps       []CompositeStep `yaml:"steps"`
+	Output      string          `yaml:"output,omitempty" expand:"-"` // Template for the result (default: last step's text)
+}
+
+// CompositeStep is one tool call of a composite tool. Args, If, and Output
+// are Go templates over {{ .inputs }}, the composite's arguments, and
+// {{ .steps.<id> }}, the results of earlier steps with the fields text, json,
+// is_error, and skipped. A string that is a single reference such as
+// "{{ .inputs.count }}" passes the value through with its type.
+type CompositeStep struct {
+	ID      string         `yaml:"id"`
+	Tool    string         `yaml:"tool"` // Exposed tool name, e.g. "github__search_issues"
+	Args    map[string]any `yaml:"args,omitempty" expand:"-"`
+	If      string         `yaml:"if,omitempty" expand:"-"` // Skip the step unless this renders to a true value
+	OnError string         `yaml:"on_error,omitempty"`      // "fail" (default) or "continue"
+}
+
+// Composite step error handling.
+const (
+	OnErrorFail     = "fail"     // Stop and return the step's error
+	OnErrorContinue = "continue" // Record the error and run the next step
+)
+
+// ParseCompositeTemplate parses a composite tool template. Besides the
+// standard functions, templates can use "json" to encode a value as JSON.
+// Missing map keys are errors, so typos in step IDs fail loudly.
+func ParseCompositeTemplate(text string) (*template.Template, error) {
+	return template.New("composite").
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"json": func(v any) (string, error) {
+				b, err := json.Marshal(v)
+				return string(b), err
+			},
+		}).
+		Parse(text)
+}
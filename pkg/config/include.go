@@ -0,0 +1,567 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gridctl/gridctl/pkg/builder"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A stack file may be composed from other stack files:
+//
+//	extends: base.yaml                # a stack this file overrides
+//	include:                          # stacks merged into this one
+//	  - shared/tools.yaml
+//	  - git: https://github.com/org/stacks
+//	    ref: v1.2.0
+//	    path: observability.yaml
+//	overlays:                         # overrides selected with --overlay
+//	  demo:
+//	    security: strict
+//	x-node: &node                     # ignored: for anchors and extends
+//	  image: node:20
+//
+// Items in mcp-servers, resources, agents and a2a-agents may also extend
+// another item of the same list, or an x- key, by name.
+//
+// Files are merged as YAML documents before they are parsed, so only the
+// keys a file sets override those beneath it. Mappings are merged key by
+// key, lists of named items are merged item by item by name, and other
+// values are replaced.
+
+// Include refers to a stack file merged into another. In YAML it is a
+// local path or a mapping naming a file in a git repository.
+type Include struct {
+	Path string `yaml:"path"` // Relative to the including file, or to the repository root for git
+	Git  string `yaml:"git"`  // Repository URL
+	Ref  string `yaml:"ref"`  // Branch, tag or commit
+}
+
+// UnmarshalYAML accepts a path as well as the mapping form.
+func (inc *Include) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		inc.Path = node.Value
+		return nil
+	}
+	type plain Include
+	return node.Decode((*plain)(inc))
+}
+
+// Keys of stack documents that control composition.
+const (
+	keyExtends  = "extends"
+	keyInclude  = "include"
+	keyOverlays = "overlays"
+)
+
+// extendableSections are the lists whose items may extend each other.
+var extendableSections = []string{"mcp-servers", "resources", "agents", "a2a-agents"}
+
+// fetchGit returns a local checkout of a repository, cloned or updated,
+// and cachedGit its checkout as last fetched. Tests replace them.
+var (
+	fetchGit  = builder.CloneOrUpdate
+	cachedGit = builder.CachedRepo
+)
+
+// document is a merged stack document. It records the file each node was
+// read from, so errors can point at their source and relative paths can
+// be resolved against it.
+type document struct {
+	root   *yaml.Node
+	files  map[*yaml.Node]string
+	index  map[string]*yaml.Node // Field path -> key node, built on first use
+	values map[string]*yaml.Node // Field path -> value node, built with index
+	fetch  bool                  // Clone or update git includes
+}
+
+// loadStackDocument reads the stack file at path, merges the files it
+// extends and includes and the overlays in opts, and returns the result.
+func loadStackDocument(path string, opts LoadOptions) (*document, error) {
+	d := &document{files: make(map[*yaml.Node]string), fetch: opts.FetchIncludes}
+	root, err := d.load(path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	overlays := mapDelete(root, keyOverlays)
+	for _, name := range opts.Overlays {
+		var overlay *yaml.Node
+		if IsOverlayFile(name) {
+			if overlay, err = d.load(name, nil); err != nil {
+				return nil, fmt.Errorf("overlay %s: %w", name, err)
+			}
+			mapDelete(overlay, keyOverlays)
+		} else if overlay = mapGet(overlays, name); overlay == nil {
+			return nil, fmt.Errorf("overlay '%s' not found (available: %s)", name, strings.Join(mapKeys(overlays), ", "))
+		} else if overlay.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("overlays.%s: must be a mapping", name)
+		}
+		root = mergeNodes(root, overlay)
+	}
+
+	if err := resolveExtends(root); err != nil {
+		return nil, err
+	}
+
+	// Extension keys have served their purpose as templates
+	for _, key := range mapKeys(root) {
+		if strings.HasPrefix(key, "x-") {
+			mapDelete(root, key)
+		}
+	}
+	d.root = root
+	return d, nil
+}
+
+// IsOverlayFile reports whether an overlay names a file, by its .yaml or
+// .yml extension or a path separator, rather than an overlay in the stack.
+func IsOverlayFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml" || strings.ContainsAny(name, `/\`)
+}
+
+// load reads a stack file and merges in the files it extends and
+// includes. chain holds the files being loaded, to detect cycles.
+func (d *document) load(path string, chain []string) (*yaml.Node, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(chain, abs) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(chain, abs), " -> "))
+	}
+	chain = append(chain, abs)
+
+	doc, err := d.parse(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(abs)
+
+	var includes []Include
+	if n := mapDelete(doc, keyExtends); n != nil {
+		var base Include
+		if err := n.Decode(&base); err != nil {
+			return nil, fmt.Errorf("%s: extends: %w", path, err)
+		}
+		includes = append(includes, base)
+	}
+	if n := mapDelete(doc, keyInclude); n != nil {
+		var more []Include
+		if err := n.Decode(&more); err != nil {
+			return nil, fmt.Errorf("%s: include: %w", path, err)
+		}
+		includes = append(includes, more...)
+	}
+
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, inc := range includes {
+		incPath, err := d.includePath(inc, dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		incDoc, err := d.load(incPath, chain)
+		if err != nil {
+			return nil, fmt.Errorf("including %s: %w", incPath, err)
+		}
+		merged = mergeNodes(merged, incDoc)
+	}
+	return mergeNodes(merged, doc), nil
+}
+
+// includePath returns the local path of an included file. A file in git is
+// read from its repository's cached checkout, which is cloned or updated
+// first only if the document fetches includes.
+func (d *document) includePath(inc Include, dir string) (string, error) {
+	if inc.Path == "" {
+		return "", fmt.Errorf("include: path is required")
+	}
+	if inc.Git == "" {
+		if inc.Ref != "" {
+			return "", fmt.Errorf("include %s: ref only applies to git includes", inc.Path)
+		}
+		return expandTildeAndResolvePath(inc.Path, dir), nil
+	}
+	if filepath.IsAbs(inc.Path) || strings.HasPrefix(filepath.Clean(inc.Path), "..") {
+		return "", fmt.Errorf("include %s: path must be inside the repository", inc.Path)
+	}
+	var repo string
+	var err error
+	if d.fetch {
+		repo, err = fetchGit(inc.Git, inc.Ref)
+	} else if repo, err = cachedGit(inc.Git); errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("include %s: not fetched yet, run gridctl deploy to fetch it", inc.Git)
+	}
+	if err != nil {
+		return "", fmt.Errorf("include %s: %w", inc.Git, err)
+	}
+	return filepath.Join(repo, inc.Path), nil
+}
+
+// parse reads a YAML file into a mapping node, with anchors, aliases and
+// merge keys expanded.
+func (d *document) parse(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading stack file: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing stack YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	root := flattenNode(doc.Content[0])
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing stack YAML: %s: expected a mapping at the top level", path)
+	}
+	d.record(root, path)
+	return root, nil
+}
+
+// record notes that n and the nodes beneath it were read from file.
+func (d *document) record(n *yaml.Node, file string) {
+	d.files[n] = file
+	for _, c := range n.Content {
+		d.record(c, file)
+	}
+}
+
+// position returns the source position of a field, named by its path of
+// YAML keys as in ValidationError, or of the nearest enclosing field in
+// the document. Line is 0 if there is none.
+func (d *document) position(field string) (file string, line, column int) {
+	if n := d.lookup(d.keyIndex(), strings.TrimPrefix(field, "stack.")); n != nil {
+		return d.file(n), n.Line, n.Column
+	}
+	return "", 0, 0
+}
+
+// dir returns the directory of the file a field's value was read from, or
+// of the nearest enclosing field, for resolving relative paths in it. It
+// returns fallback if the field is not in the document.
+func (d *document) dir(field, fallback string) string {
+	d.keyIndex()
+	if n := d.lookup(d.values, field); n != nil {
+		if file := d.file(n); file != "" {
+			if abs, err := filepath.Abs(file); err == nil {
+				return filepath.Dir(abs)
+			}
+		}
+	}
+	return fallback
+}
+
+// lookup returns the node of a field in index, or of the nearest
+// enclosing field, or nil if there is none.
+func (d *document) lookup(index map[string]*yaml.Node, field string) *yaml.Node {
+	for field != "" {
+		if n, ok := index[field]; ok {
+			return n
+		}
+		i := strings.LastIndexAny(field, ".[")
+		if i < 0 {
+			break
+		}
+		field = field[:i]
+	}
+	return nil
+}
+
+// keyIndex returns the index, building it on first use.
+func (d *document) keyIndex() map[string]*yaml.Node {
+	if d.index == nil {
+		d.index = make(map[string]*yaml.Node)
+		d.values = make(map[string]*yaml.Node)
+		d.indexNode(d.root, "")
+	}
+	return d.index
+}
+
+// indexNode adds the nodes beneath n to the index: the key and value of
+// each mapping entry, and each sequence item.
+func (d *document) indexNode(n *yaml.Node, path string) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			field := joinField(path, n.Content[i].Value)
+			d.index[field] = n.Content[i]
+			d.values[field] = n.Content[i+1]
+			d.indexNode(n.Content[i+1], field)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			field := fmt.Sprintf("%s[%d]", path, i)
+			d.index[field] = item
+			d.values[field] = item
+			d.indexNode(item, field)
+		}
+	}
+}
+
+// file returns the file n was read from. Mappings and sequences built
+// by merging were not read from a file; they take the file of their
+// first child.
+func (d *document) file(n *yaml.Node) string {
+	for n != nil {
+		if file, ok := d.files[n]; ok {
+			return file
+		}
+		if len(n.Content) == 0 {
+			break
+		}
+		n = n.Content[0]
+	}
+	return ""
+}
+
+// annotate sets the source positions of validation errors.
+func (d *document) annotate(errs ValidationErrors) {
+	for i := range errs {
+		if errs[i].Line == 0 {
+			errs[i].File, errs[i].Line, errs[i].Column = d.position(errs[i].Field)
+		}
+	}
+}
+
+// joinField appends a key to a field path.
+func joinField(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// flattenNode returns a copy of n with aliases replaced by the nodes they
+// refer to and merge keys (<<) replaced by the keys they merge, so that n
+// no longer depends on anchors elsewhere in its document.
+func flattenNode(n *yaml.Node) *yaml.Node {
+	if n.Kind == yaml.AliasNode {
+		return flattenNode(n.Alias)
+	}
+	out := *n
+	out.Anchor = ""
+	out.Content = nil
+	switch n.Kind {
+	case yaml.MappingNode:
+		var merged []*yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Kind == yaml.ScalarNode && key.Tag == "!!merge" {
+				merged = append(merged, mergeSources(flattenNode(value))...)
+				continue
+			}
+			out.Content = append(out.Content, flattenNode(key), flattenNode(value))
+		}
+		// Explicit keys take precedence over merged ones, and earlier
+		// merge sources over later ones
+		for _, src := range merged {
+			for i := 0; i+1 < len(src.Content); i += 2 {
+				if mapGet(&out, src.Content[i].Value) == nil {
+					out.Content = append(out.Content, src.Content[i], src.Content[i+1])
+				}
+			}
+		}
+	default:
+		for _, c := range n.Content {
+			out.Content = append(out.Content, flattenNode(c))
+		}
+	}
+	return &out
+}
+
+// mergeSources returns the mappings a merge key refers to.
+func mergeSources(n *yaml.Node) []*yaml.Node {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return []*yaml.Node{n}
+	case yaml.SequenceNode:
+		var srcs []*yaml.Node
+		for _, c := range n.Content {
+			if c.Kind == yaml.MappingNode {
+				srcs = append(srcs, c)
+			}
+		}
+		return srcs
+	}
+	return nil
+}
+
+// mergeNodes returns over merged onto base. Mappings are merged key by
+// key and lists of named items by name; anything else in over replaces
+// base. Neither argument is modified.
+func mergeNodes(base, over *yaml.Node) *yaml.Node {
+	switch {
+	case base == nil:
+		return over
+	case base.Kind == yaml.MappingNode && over.Kind == yaml.MappingNode:
+		out := *base
+		out.Content = slices.Clone(base.Content)
+		for i := 0; i+1 < len(over.Content); i += 2 {
+			key, value := over.Content[i], over.Content[i+1]
+			if j := mapIndex(&out, key.Value); j >= 0 {
+				out.Content[j+1] = mergeNodes(out.Content[j+1], value)
+			} else {
+				out.Content = append(out.Content, key, value)
+			}
+		}
+		return &out
+	case base.Kind == yaml.SequenceNode && over.Kind == yaml.SequenceNode && isNamedList(base) && isNamedList(over):
+		out := *base
+		out.Content = slices.Clone(base.Content)
+		for _, item := range over.Content {
+			name := itemName(item)
+			j := slices.IndexFunc(out.Content, func(n *yaml.Node) bool { return itemName(n) == name })
+			if j >= 0 {
+				out.Content[j] = mergeNodes(out.Content[j], item)
+			} else {
+				out.Content = append(out.Content, item)
+			}
+		}
+		return &out
+	default:
+		return over
+	}
+}
+
+// isNamedList reports whether every item of a sequence is a mapping with
+// a name.
+func isNamedList(n *yaml.Node) bool {
+	for _, item := range n.Content {
+		if itemName(item) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// itemName returns the name of a list item, or "" if it has none.
+func itemName(n *yaml.Node) string {
+	if name := mapGet(n, "name"); name != nil && name.Kind == yaml.ScalarNode {
+		return name.Value
+	}
+	return ""
+}
+
+// resolveExtends replaces list items that extend another item, or an x-
+// key, with the result of merging them onto it.
+func resolveExtends(doc *yaml.Node) error {
+	for _, section := range extendableSections {
+		list := mapGet(doc, section)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		byName := make(map[string]*yaml.Node)
+		for _, item := range list.Content {
+			if name := itemName(item); name != "" {
+				byName[name] = item
+			}
+		}
+
+		var resolve func(item *yaml.Node, field string, chain []string) (*yaml.Node, error)
+		resolve = func(item *yaml.Node, field string, chain []string) (*yaml.Node, error) {
+			ext := mapGet(item, keyExtends)
+			if ext == nil {
+				return item, nil
+			}
+			if ext.Kind != yaml.ScalarNode || ext.Value == "" {
+				return nil, fmt.Errorf("%s.extends: must be the name of an item or an x- key", field)
+			}
+			name := ext.Value
+			if slices.Contains(chain, name) {
+				return nil, fmt.Errorf("%s.extends: cycle: %s", field, strings.Join(append(chain, name), " -> "))
+			}
+			var base *yaml.Node
+			if strings.HasPrefix(name, "x-") {
+				base = mapGet(doc, name)
+			} else {
+				base = byName[name]
+			}
+			if base == nil || base.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("%s.extends: '%s' not found in %s or x- keys", field, name, section)
+			}
+			base, err := resolve(base, field, append(chain, name))
+			if err != nil {
+				return nil, err
+			}
+			own := *item
+			own.Content = slices.Clone(item.Content)
+			mapDelete(&own, keyExtends)
+			return mergeNodes(base, &own), nil
+		}
+
+		resolved := make([]*yaml.Node, len(list.Content))
+		for i, item := range list.Content {
+			field := fmt.Sprintf("%s[%d]", section, i)
+			r, err := resolve(item, field, []string{itemName(item)})
+			if err != nil {
+				return err
+			}
+			resolved[i] = r
+		}
+		list.Content = resolved
+	}
+	return nil
+}
+
+// mapIndex returns the index of key in a mapping node's content, or -1.
+func mapIndex(m *yaml.Node, key string) int {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mapGet returns the value of key in a mapping node, or nil.
+func mapGet(m *yaml.Node, key string) *yaml.Node {
+	if i := mapIndex(m, key); i >= 0 {
+		return m.Content[i+1]
+	}
+	return nil
+}
+
+// mapDelete removes key from a mapping node and returns its value, or nil.
+func mapDelete(m *yaml.Node, key string) *yaml.Node {
+	i := mapIndex(m, key)
+	if i < 0 {
+		return nil
+	}
+	value := m.Content[i+1]
+	m.Content = slices.Delete(m.Content, i, i+2)
+	return value
+}
+
+// mapKeys returns the keys of a mapping node, sorted.
+func mapKeys(m *yaml.Node) []string {
+	var keys []string
+	if m != nil && m.Kind == yaml.MappingNode {
+		for i := 0; i < len(m.Content); i += 2 {
+			keys = append(keys, m.Content[i].Value)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// scalarValue returns the value of a scalar node, or "".
+func scalarValue(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return n.Value
+}
@@ -0,0 +1,363 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, keyed by path relative to a new temp
+// directory, and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadStack_Include(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"shared/tools.yaml": `
+network:
+  name: shared-net
+volumes:
+  - name: cache
+mcp-servers:
+  - name: files
+    source:
+      type: local
+      path: ./files-server
+    port: 3000
+    volumes: ["./data:/data", "cache:/cache", "${GRIDCTL_TEST_UNSET_LOGS:-./logs}:/logs"]
+    env_file: files.env
+    env:
+      LOG_LEVEL: info
+      ROOT: /data
+  - name: notes
+    command: ["./notes-server"]
+    workdir: notes
+`,
+		"shared/files.env": "FILES_MODE=shared\n",
+		"stack.yaml": `
+name: app
+include:
+  - shared/tools.yaml
+mcp-servers:
+  - name: files
+    env:
+      LOG_LEVEL: debug
+  - name: search
+    image: search:latest
+    port: 3001
+    volumes: ["./index:/index"]
+`,
+	})
+
+	stack, err := LoadStack(filepath.Join(dir, "stack.yaml"))
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	if stack.Name != "app" || stack.Network.Name != "shared-net" {
+		t.Errorf("unexpected stack: name %q, network %q", stack.Name, stack.Network.Name)
+	}
+
+	var names []string
+	for _, s := range stack.MCPServers {
+		names = append(names, s.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"files", "notes", "search"}) {
+		t.Errorf("expected included servers first, then new ones, got %v", names)
+	}
+
+	files := stack.MCPServers[0]
+	if !reflect.DeepEqual(files.Env, map[string]string{"LOG_LEVEL": "debug", "ROOT": "/data", "FILES_MODE": "shared"}) {
+		t.Errorf("expected env to be merged key by key, got %v", files.Env)
+	}
+	shared := filepath.Join(dir, "shared")
+	if files.Source.Path != filepath.Join(shared, "files-server") {
+		t.Errorf("expected source path relative to the included file, got %q", files.Source.Path)
+	}
+	want := []string{filepath.Join(shared, "data") + ":/data", "cache:/cache", filepath.Join(shared, "logs") + ":/logs"}
+	if !reflect.DeepEqual(files.Volumes, want) {
+		t.Errorf("expected volumes %v, got %v", want, files.Volumes)
+	}
+	if stack.MCPServers[1].WorkDir != filepath.Join(shared, "notes") {
+		t.Errorf("expected workdir relative to the included file, got %q", stack.MCPServers[1].WorkDir)
+	}
+	if want := filepath.Join(dir, "index") + ":/index"; stack.MCPServers[2].Volumes[0] != want {
+		t.Errorf("expected %q, got %q", want, stack.MCPServers[2].Volumes[0])
+	}
+}
+
+func TestLoadStack_ExtendsAndOverlays(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base.yaml": `
+name: app
+network:
+  name: app-net
+tool_mode: all
+mcp-servers:
+  - name: github
+    image: ghcr.io/github/github-mcp-server
+    port: 3000
+overlays:
+  ci:
+    security: strict
+`,
+		"dev.yaml": `
+extends: base.yaml
+tool_mode: search
+overlays:
+  demo:
+    name: app-demo
+    mcp-servers:
+      - name: github
+        port: 4000
+`,
+		"local.yaml": `
+mcp-servers:
+  - name: github
+    env:
+      DEBUG: "1"
+`,
+	})
+	dev := filepath.Join(dir, "dev.yaml")
+
+	stack, err := LoadStack(dev)
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	if stack.Name != "app" || stack.ToolMode != "search" || stack.MCPServers[0].Port != 3000 {
+		t.Errorf("unexpected stack: name %q, tool mode %q, port %d", stack.Name, stack.ToolMode, stack.MCPServers[0].Port)
+	}
+
+	// Overlays apply in order, from either file, or from a file of their own
+	stack, err = LoadStackWithOptions(dev, LoadOptions{Overlays: []string{"demo", "ci", filepath.Join(dir, "local.yaml")}})
+	if err != nil {
+		t.Fatalf("LoadStackWithOptions failed: %v", err)
+	}
+	github := stack.MCPServers[0]
+	if stack.Name != "app-demo" || stack.Security != "strict" || github.Port != 4000 || github.Env["DEBUG"] != "1" {
+		t.Errorf("overlays not applied: name %q, security %q, server %+v", stack.Name, stack.Security, github)
+	}
+	if github.Image != "ghcr.io/github/github-mcp-server" {
+		t.Errorf("expected overlays to keep unset keys, got image %q", github.Image)
+	}
+
+	_, err = LoadStackWithOptions(dev, LoadOptions{Overlays: []string{"prod"}})
+	if err == nil || !strings.Contains(err.Error(), "overlay 'prod' not found (available: ci, demo)") {
+		t.Errorf("expected an unknown overlay error, got %v", err)
+	}
+}
+
+func TestLoadStack_Templates(t *testing.T) {
+	content := `
+name: app
+network:
+  name: app-net
+x-node: &node
+  image: node:20
+  transport: stdio
+  env:
+    NODE_ENV: production
+mcp-servers:
+  - name: a
+    <<: *node
+    command: ["npx", "server-a"]
+  - name: b
+    extends: a
+    command: ["npx", "server-b"]
+    env:
+      EXTRA: "1"
+  - name: c
+    extends: x-node
+    command: ["npx", "server-c"]
+`
+	stack, err := LoadStack(writeTempFile(t, content))
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	if len(stack.MCPServers) != 3 {
+		t.Fatalf("expected 3 servers, got %d", len(stack.MCPServers))
+	}
+	for _, s := range stack.MCPServers {
+		if s.Image != "node:20" || s.Transport != "stdio" || s.Env["NODE_ENV"] != "production" {
+			t.Errorf("server %s: template not applied: %+v", s.Name, s)
+		}
+	}
+	b := stack.MCPServers[1]
+	if b.Name != "b" || b.Command[1] != "server-b" || b.Env["EXTRA"] != "1" {
+		t.Errorf("unexpected server b: %+v", b)
+	}
+}
+
+func TestLoadStack_CompositionErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		errSubstr string
+	}{
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"stack.yaml": "include: [a.yaml]\n",
+				"a.yaml":     "include: [stack.yaml]\n",
+			},
+			errSubstr: "include cycle",
+		},
+		{
+			name:      "missing include",
+			files:     map[string]string{"stack.yaml": "include: [missing.yaml]\n"},
+			errSubstr: "reading stack file",
+		},
+		{
+			name:      "git include without path",
+			files:     map[string]string{"stack.yaml": "include:\n  - git: https://example.com/stacks\n"},
+			errSubstr: "include: path is required",
+		},
+		{
+			name: "extends cycle",
+			files: map[string]string{"stack.yaml": `
+mcp-servers:
+  - name: a
+    extends: b
+  - name: b
+    extends: a
+`},
+			errSubstr: "mcp-servers[0].extends: cycle: a -> b -> a",
+		},
+		{
+			name:      "extends unknown item",
+			files:     map[string]string{"stack.yaml": "mcp-servers:\n  - name: a\n    extends: missing\n"},
+			errSubstr: "'missing' not found in mcp-servers or x- keys",
+		},
+		{
+			name: "merged stack is validated",
+			files: map[string]string{
+				"stack.yaml": "name: app\ninclude: [servers.yaml]\nnetwork:\n  name: app-net\n",
+				"servers.yaml": `
+mcp-servers:
+  - name: broken
+    port: 3000
+`,
+			},
+			errSubstr: "mcp-servers[0]",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+			_, err := LoadStack(filepath.Join(dir, "stack.yaml"))
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_GitInclude(t *testing.T) {
+	repo := writeFiles(t, map[string]string{
+		"stacks/observability.yaml": `
+mcp-servers:
+  - name: metrics
+    source:
+      type: local
+      path: ../servers/metrics
+    port: 3000
+`,
+	})
+	origFetch, origCached := fetchGit, cachedGit
+	defer func() { fetchGit, cachedGit = origFetch, origCached }()
+	var gotURL, gotRef string
+	fetchGit = func(url, ref string) (string, error) {
+		gotURL, gotRef = url, ref
+		return repo, nil
+	}
+	cachedGit = func(url string) (string, error) {
+		return "", fs.ErrNotExist
+	}
+
+	path := writeTempFile(t, `
+name: app
+network:
+  name: app-net
+include:
+  - git: https://example.com/org/stacks
+    ref: v1.2.0
+    path: stacks/observability.yaml
+`)
+
+	// Only loads that fetch includes contact the remote
+	_, err := LoadStack(path)
+	if err == nil || !strings.Contains(err.Error(), "include https://example.com/org/stacks: not fetched yet, run gridctl deploy to fetch it") {
+		t.Errorf("expected a not fetched error, got %v", err)
+	}
+	if gotURL != "" {
+		t.Errorf("expected no fetch, got a fetch of %q", gotURL)
+	}
+
+	stack, err := LoadStackWithOptions(path, LoadOptions{FetchIncludes: true})
+	if err != nil {
+		t.Fatalf("LoadStackWithOptions failed: %v", err)
+	}
+	if gotURL != "https://example.com/org/stacks" || gotRef != "v1.2.0" {
+		t.Errorf("unexpected fetch of %q at %q", gotURL, gotRef)
+	}
+	if want := filepath.Join(repo, "servers", "metrics"); stack.MCPServers[0].Source.Path != want {
+		t.Errorf("expected source path %q, got %q", want, stack.MCPServers[0].Source.Path)
+	}
+
+	// Once fetched, the cached checkout is read
+	gotURL = ""
+	cachedGit = func(url string) (string, error) { return repo, nil }
+	if _, err := LoadStack(path); err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	if gotURL != "" {
+		t.Errorf("expected the cached checkout to be used, got a fetch of %q", gotURL)
+	}
+}
+
+func TestRenderStack(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base.yaml": "network:\n  name: app-net\nx-image: &img node:20\n",
+		"stack.yaml": `
+name: app
+include: [base.yaml]
+x-server: &server
+  image: node:20
+  port: 3000
+mcp-servers:
+  - name: a
+    <<: *server
+    env:
+      TOKEN: ${secret:token}
+`,
+	})
+	out, err := RenderStack(filepath.Join(dir, "stack.yaml"), LoadOptions{})
+	if err != nil {
+		t.Fatalf("RenderStack failed: %v", err)
+	}
+	got := string(out)
+	for _, want := range []string{"name: app-net", "image: node:20", "port: 3000", "TOKEN: ${secret:token}"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected rendered stack to contain %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"include", "x-server", "x-image", "<<", "*server"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("expected rendered stack not to contain %q:\n%s", unwanted, got)
+		}
+	}
+}
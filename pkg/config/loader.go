@@ -1,137 +1,259 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"gopkg.in/yaml.v3"
 )
 
+// LoadOptions adjusts how a stack file is loaded.
+type LoadOptions struct {
+	EnvFiles        []string // .env files for variable expansion, overriding the stack's env_file
+	AllowUnresolved bool     // Expand unset variables to empty strings rather than failing validation
+	Overlays        []string // Overlays to apply, by name in the stack's overlays or as files
+	FetchIncludes   bool     // Clone or update git includes, rather than reading their checkouts as last fetched
+}
+
 // LoadStack reads and parses a stack file.
 func LoadStack(path string) (*Stack, error) {
-	data, err := os.ReadFile(path)
+	return LoadStackWithOptions(path, LoadOptions{})
+}
+
+// LoadStackWithOptions reads and parses a stack file, merging the files
+// it extends and includes and the selected overlays.
+func LoadStackWithOptions(path string, opts LoadOptions) (*Stack, error) {
+	doc, err := loadStackDocument(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return doc.decode(path, opts)
+}
+
+// CheckStack loads a stack file as LoadStackWithOptions does and reports
+// its problems: the errors that keep it from loading, and warnings about
+// likely mistakes. err is set only when the file cannot be read or parsed,
+// so there is nothing to check.
+func CheckStack(path string, opts LoadOptions) (errs, warnings ValidationErrors, err error) {
+	doc, err := loadStackDocument(path, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	stack, err := doc.prepare(path, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := Validate(stack); err != nil {
+		errs = err.(ValidationErrors)
+	}
+	warnings = append(stack.loadWarnings, doc.warnings(stack)...)
+	doc.annotate(errs)
+	doc.annotate(warnings)
+	return errs, warnings, nil
+}
+
+// RenderStack returns a stack file as YAML with the files it extends and
+// includes, its templates and the selected overlays merged. Variables,
+// secret references and relative paths are left as written. The merged
+// stack is validated.
+func RenderStack(path string, opts LoadOptions) ([]byte, error) {
+	doc, err := loadStackDocument(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := doc.decode(path, opts); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc.root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decode prepares the stack in a merged stack document and validates it.
+// Validation errors carry the source positions of their fields.
+func (d *document) decode(path string, opts LoadOptions) (*Stack, error) {
+	stack, err := d.prepare(path, opts)
 	if err != nil {
-		return nil, fmt.Errorf("reading stack file: %w", err)
+		return nil, err
 	}
+	if err := Validate(stack); err != nil {
+		if errs, ok := err.(ValidationErrors); ok {
+			d.annotate(errs)
+		}
+		return nil, err
+	}
+	d.annotate(stack.loadWarnings)
+	return stack, nil
+}
 
+// prepare parses a merged stack document, then expands variables, applies
+// defaults and resolves paths.
+func (d *document) prepare(path string, opts LoadOptions) (*Stack, error) {
 	var stack Stack
-	if err := yaml.Unmarshal(data, &stack); err != nil {
+	if err := d.root.Decode(&stack); err != nil {
 		return nil, fmt.Errorf("parsing stack YAML: %w", err)
 	}
+	stack.loadErrors = checkFields(d.root, reflect.TypeOf(stack), "")
 
 	// Merge equipped_skills alias into uses for each agent
 	mergeEquippedSkills(&stack)
 
-	// Expand environment variables in string values
-	expandEnvVars(&stack)
-
-	// Apply defaults
-	stack.SetDefaults()
-
-	// Resolve relative paths based on stack file location
+	// Relative paths are relative to the file that sets them
 	basePath := filepath.Dir(path)
-	resolveRelativePaths(&stack, basePath)
+	dir := func(field string) string { return d.dir(field, basePath) }
+
+	// Expand variables from the environment and env files in string values
+	if err := expandStackEnv(&stack, dir, opts); err != nil {
+		return nil, err
+	}
 
-	// Validate the stack
-	if err := Validate(&stack); err != nil {
+	// Add variables from workload env files to their environments
+	if err := mergeWorkloadEnvFiles(&stack, dir); err != nil {
 		return nil, err
 	}
 
+	// Apply defaults
+	stack.SetDefaults()
+
+	// Resolve relative paths based on the files they were read from
+	resolveRelativePaths(&stack, dir)
+
 	return &stack, nil
 }
 
-// expandEnvVars expands environment variables in the stack.
-func expandEnvVars(s *Stack) {
-	s.Name = os.ExpandEnv(s.Name)
-	s.Network.Name = os.ExpandEnv(s.Network.Name)
-
-	// Expand networks (advanced mode)
-	for i := range s.Networks {
-		s.Networks[i].Name = os.ExpandEnv(s.Networks[i].Name)
+// expandStackEnv expands variables in the stack. Variables are looked up
+// in the process environment, then in the env files passed in opts, then
+// in the stack's env_file. Missing required variables are recorded for
+// Validate, and unset plain variables as warnings.
+func expandStackEnv(s *Stack, dir func(field string) string, opts LoadOptions) error {
+	paths := make([]string, 0, len(s.EnvFile)+len(opts.EnvFiles))
+	for i, file := range s.EnvFile {
+		paths = append(paths, expandTildeAndResolvePath(file, dir(fmt.Sprintf("env_file[%d]", i))))
+	}
+	paths = append(paths, opts.EnvFiles...)
+	vars, err := readEnvFiles(paths)
+	if err != nil {
+		return err
 	}
 
-	for i := range s.MCPServers {
-		s.MCPServers[i].Name = os.ExpandEnv(s.MCPServers[i].Name)
-		s.MCPServers[i].Image = os.ExpandEnv(s.MCPServers[i].Image)
-		s.MCPServers[i].Network = os.ExpandEnv(s.MCPServers[i].Network)
-
-		if s.MCPServers[i].Source != nil {
-			s.MCPServers[i].Source.URL = os.ExpandEnv(s.MCPServers[i].Source.URL)
-			s.MCPServers[i].Source.Path = os.ExpandEnv(s.MCPServers[i].Source.Path)
-			s.MCPServers[i].Source.Ref = os.ExpandEnv(s.MCPServers[i].Source.Ref)
-		}
-
-		for k, v := range s.MCPServers[i].Env {
-			s.MCPServers[i].Env[k] = os.ExpandEnv(v)
-		}
-		for k, v := range s.MCPServers[i].BuildArgs {
-			s.MCPServers[i].BuildArgs[k] = os.ExpandEnv(v)
+	errs, warnings := expandStackVars(s, func(name string) (string, bool) {
+		if v, ok := os.LookupEnv(name); ok {
+			return v, true
 		}
+		v, ok := vars[name]
+		return v, ok
+	})
+	if !opts.AllowUnresolved {
+		s.loadErrors = append(s.loadErrors, errs...)
+		s.loadWarnings = append(s.loadWarnings, warnings...)
+	}
+	return nil
+}
 
-		// Expand SSH config environment variables
-		if s.MCPServers[i].SSH != nil {
-			s.MCPServers[i].SSH.Host = os.ExpandEnv(s.MCPServers[i].SSH.Host)
-			s.MCPServers[i].SSH.User = os.ExpandEnv(s.MCPServers[i].SSH.User)
-			s.MCPServers[i].SSH.IdentityFile = os.ExpandEnv(s.MCPServers[i].SSH.IdentityFile)
+// mergeWorkloadEnvFiles adds the variables in each workload's env_file to
+// its env. Variables set in env take precedence.
+func mergeWorkloadEnvFiles(s *Stack, dir func(field string) string) error {
+	var err error
+	for i := range s.MCPServers {
+		field := fmt.Sprintf("mcp-servers[%d].env_file", i)
+		if s.MCPServers[i].Env, err = mergeEnvFile(s.MCPServers[i].Env, s.MCPServers[i].EnvFile, dir(field)); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
 		}
 	}
-
 	for i := range s.Resources {
-		s.Resources[i].Name = os.ExpandEnv(s.Resources[i].Name)
-		s.Resources[i].Image = os.ExpandEnv(s.Resources[i].Image)
-		s.Resources[i].Network = os.ExpandEnv(s.Resources[i].Network)
-
-		for k, v := range s.Resources[i].Env {
-			s.Resources[i].Env[k] = os.ExpandEnv(v)
+		field := fmt.Sprintf("resources[%d].env_file", i)
+		if s.Resources[i].Env, err = mergeEnvFile(s.Resources[i].Env, s.Resources[i].EnvFile, dir(field)); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
 		}
 	}
-
 	for i := range s.Agents {
-		s.Agents[i].Name = os.ExpandEnv(s.Agents[i].Name)
-		s.Agents[i].Image = os.ExpandEnv(s.Agents[i].Image)
-		s.Agents[i].Description = os.ExpandEnv(s.Agents[i].Description)
-		s.Agents[i].Network = os.ExpandEnv(s.Agents[i].Network)
-
-		if s.Agents[i].Source != nil {
-			s.Agents[i].Source.URL = os.ExpandEnv(s.Agents[i].Source.URL)
-			s.Agents[i].Source.Path = os.ExpandEnv(s.Agents[i].Source.Path)
-			s.Agents[i].Source.Ref = os.ExpandEnv(s.Agents[i].Source.Ref)
-		}
-
-		for k, v := range s.Agents[i].Env {
-			s.Agents[i].Env[k] = os.ExpandEnv(v)
-		}
-		for k, v := range s.Agents[i].BuildArgs {
-			s.Agents[i].BuildArgs[k] = os.ExpandEnv(v)
+		field := fmt.Sprintf("agents[%d].env_file", i)
+		if s.Agents[i].Env, err = mergeEnvFile(s.Agents[i].Env, s.Agents[i].EnvFile, dir(field)); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
 		}
 	}
+	return nil
 }
 
-// resolveRelativePaths resolves local source paths relative to the stack file.
-func resolveRelativePaths(s *Stack, basePath string) {
+// resolveRelativePaths resolves local source paths, bind mount sources and
+// other host paths relative to the directory dir returns for their field:
+// that of the stack file, or included file, that sets them.
+func resolveRelativePaths(s *Stack, dir func(field string) string) {
 	for i := range s.MCPServers {
+		field := fmt.Sprintf("mcp-servers[%d]", i)
+		resolveVolumePaths(s.MCPServers[i].Volumes, field+".volumes", dir)
+
+		// Local process servers run on the host, so workdir is a host path
+		if s.MCPServers[i].WorkDir != "" && s.MCPServers[i].IsLocalProcess() {
+			s.MCPServers[i].WorkDir = expandTildeAndResolvePath(s.MCPServers[i].WorkDir, dir(field+".workdir"))
+		}
+
 		if s.MCPServers[i].Source != nil && s.MCPServers[i].Source.Type == "local" {
 			if !filepath.IsAbs(s.MCPServers[i].Source.Path) {
-				s.MCPServers[i].Source.Path = filepath.Join(basePath, s.MCPServers[i].Source.Path)
+				s.MCPServers[i].Source.Path = filepath.Join(dir(field+".source.path"), s.MCPServers[i].Source.Path)
 			}
 		}
 
 		// Resolve SSH identity file paths
 		if s.MCPServers[i].SSH != nil && s.MCPServers[i].SSH.IdentityFile != "" {
-			s.MCPServers[i].SSH.IdentityFile = expandTildeAndResolvePath(s.MCPServers[i].SSH.IdentityFile, basePath)
+			s.MCPServers[i].SSH.IdentityFile = expandTildeAndResolvePath(s.MCPServers[i].SSH.IdentityFile, dir(field+".ssh.identityFile"))
+		}
+	}
+
+	for i := range s.Resources {
+		resolveVolumePaths(s.Resources[i].Volumes, fmt.Sprintf("resources[%d].volumes", i), dir)
+	}
+
+	if s.Tracing.File != "" {
+		s.Tracing.File = expandTildeAndResolvePath(s.Tracing.File, dir("tracing.file"))
+	}
+
+	for name, secret := range s.Secrets {
+		if secret.Path != "" {
+			secret.Path = expandTildeAndResolvePath(secret.Path, dir("secrets."+name+".path"))
+			s.Secrets[name] = secret
 		}
 	}
 
 	for i := range s.Agents {
+		field := fmt.Sprintf("agents[%d]", i)
+		resolveVolumePaths(s.Agents[i].Volumes, field+".volumes", dir)
+
 		if s.Agents[i].Source != nil && s.Agents[i].Source.Type == "local" {
 			if !filepath.IsAbs(s.Agents[i].Source.Path) {
-				s.Agents[i].Source.Path = filepath.Join(basePath, s.Agents[i].Source.Path)
+				s.Agents[i].Source.Path = filepath.Join(dir(field+".source.path"), s.Agents[i].Source.Path)
 			}
 		}
 	}
 }
 
+// resolveVolumePaths resolves host path sources of bind mounts in place.
+// Named volumes and malformed specs are left for validation to report.
+func resolveVolumePaths(volumes []string, field string, dir func(field string) string) {
+	for i, spec := range volumes {
+		m, err := ParseVolumeMount(spec)
+		if err != nil || m.IsNamedVolume() {
+			continue
+		}
+		m.Source = expandTildeAndResolvePath(m.Source, dir(fmt.Sprintf("%s[%d]", field, i)))
+		// Docker only accepts absolute bind sources
+		if abs, err := filepath.Abs(m.Source); err == nil {
+			m.Source = abs
+		}
+		volumes[i] = m.String()
+	}
+}
+
 // expandTildeAndResolvePath expands ~ to home directory and resolves relative paths.
 func expandTildeAndResolvePath(path, basePath string) string {
 	// Expand ~ to home directory
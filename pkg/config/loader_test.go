@@ -1,9 +1,13 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadStack_Valid(t *testing.T) {
@@ -314,6 +318,31 @@ mcp-servers:
 	}
 }
 
+func TestValidationError_JSON(t *testing.T) {
+	tests := []struct {
+		err  ValidationError
+		want string
+	}{
+		{
+			err:  ValidationError{Field: "mcp-servers[0].port", Message: "is required", File: "stack.yaml", Line: 7, Column: 5},
+			want: `{"field":"mcp-servers[0].port","message":"is required","file":"stack.yaml","line":7,"column":5}`,
+		},
+		{
+			err:  ValidationError{Field: "name", Message: "is required"},
+			want: `{"field":"name","message":"is required"}`,
+		},
+	}
+	for _, tc := range tests {
+		got, err := json.Marshal(tc.err)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("expected %s, got %s", tc.want, got)
+		}
+	}
+}
+
 func TestValidate_MultiNetwork(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -745,6 +774,219 @@ func TestServerNames(t *testing.T) {
 	}
 }
 
+func TestDependencyUnmarshal(t *testing.T) {
+	content := `
+version: "1"
+name: test
+network:
+  name: test-net
+resources:
+  - name: postgres
+    image: postgres:16
+  - name: migrate
+    image: migrate:latest
+    command: ["up"]
+    depends_on:
+      - name: postgres
+        condition: healthy
+mcp-servers:
+  - name: server1
+    image: alpine
+    port: 3000
+    depends_on:
+      - postgres
+      - name: migrate
+        condition: completed
+`
+	path := writeTempFile(t, content)
+
+	topo, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Dependency{
+		{Name: "postgres", Condition: DependencyStarted},
+		{Name: "migrate", Condition: DependencyCompleted},
+	}
+	got := topo.MCPServers[0].DependsOn
+	if len(got) != len(want) {
+		t.Fatalf("expected %d dependencies, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("dependency %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	if dep := topo.Resources[1].DependsOn[0]; dep.Condition != DependencyHealthy {
+		t.Errorf("expected condition 'healthy', got '%s'", dep.Condition)
+	}
+	if len(topo.Resources[1].Command) != 1 || topo.Resources[1].Command[0] != "up" {
+		t.Errorf("expected resource command [up], got %v", topo.Resources[1].Command)
+	}
+}
+
+func TestWorkloadDependencies(t *testing.T) {
+	topo := &Stack{
+		Resources: []Resource{
+			{Name: "postgres", Image: "postgres"},
+			{Name: "migrate", Image: "migrate", DependsOn: []Dependency{{Name: "postgres", Condition: DependencyHealthy}}},
+		},
+		MCPServers: []MCPServer{
+			{Name: "implicit", Image: "alpine", Port: 3000},
+			{Name: "explicit", Image: "alpine", Port: 3001, DependsOn: []Dependency{{Name: "migrate", Condition: DependencyCompleted}}},
+		},
+		Agents: []Agent{
+			{Name: "agent1", Image: "alpine", Uses: []ToolSelector{{Server: "explicit"}}},
+		},
+	}
+
+	deps := topo.WorkloadDependencies()
+
+	names := func(ds []Dependency) []string {
+		var out []string
+		for _, d := range ds {
+			out = append(out, d.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		workload string
+		want     []string
+	}{
+		{"postgres", nil},
+		{"migrate", []string{"postgres"}},
+		{"implicit", []string{"postgres"}},
+		{"explicit", []string{"migrate"}},
+		{"agent1", []string{"explicit", "postgres"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.workload, func(t *testing.T) {
+			got := names(deps[tc.workload])
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			for i := range tc.want {
+				if got[i] != tc.want[i] {
+					t.Errorf("expected %v, got %v", tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate_DependsOn(t *testing.T) {
+	base := func() *Stack {
+		return &Stack{
+			Name:    "test",
+			Network: Network{Name: "test-net"},
+			Resources: []Resource{
+				{Name: "postgres", Image: "postgres"},
+			},
+			MCPServers: []MCPServer{
+				{Name: "server1", Image: "alpine", Port: 3000},
+				{Name: "remote", URL: "https://example.com/mcp"},
+			},
+			Agents: []Agent{
+				{Name: "agent1", Image: "alpine"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(s *Stack)
+		wantErr   bool
+		errSubstr string
+	}{
+		{
+			name: "valid dependency on resource",
+			modify: func(s *Stack) {
+				s.MCPServers[0].DependsOn = []Dependency{{Name: "postgres", Condition: DependencyHealthy}}
+			},
+		},
+		{
+			name: "valid agent dependency on server",
+			modify: func(s *Stack) {
+				s.Agents[0].DependsOn = []Dependency{{Name: "server1"}}
+			},
+		},
+		{
+			name: "unknown target",
+			modify: func(s *Stack) {
+				s.MCPServers[0].DependsOn = []Dependency{{Name: "missing"}}
+			},
+			wantErr:   true,
+			errSubstr: "'missing' not found",
+		},
+		{
+			name: "self reference",
+			modify: func(s *Stack) {
+				s.Resources[0].DependsOn = []Dependency{{Name: "postgres"}}
+			},
+			wantErr:   true,
+			errSubstr: "cannot depend on itself",
+		},
+		{
+			name: "invalid condition",
+			modify: func(s *Stack) {
+				s.MCPServers[0].DependsOn = []Dependency{{Name: "postgres", Condition: "ready"}}
+			},
+			wantErr:   true,
+			errSubstr: "must be 'started', 'healthy', or 'completed'",
+		},
+		{
+			name: "healthy on external server",
+			modify: func(s *Stack) {
+				s.Agents[0].DependsOn = []Dependency{{Name: "remote", Condition: DependencyHealthy}}
+			},
+			wantErr:   true,
+			errSubstr: "container workload",
+		},
+		{
+			name: "cycle between resource and server",
+			modify: func(s *Stack) {
+				s.Resources[0].DependsOn = []Dependency{{Name: "server1"}}
+				s.MCPServers[0].DependsOn = []Dependency{{Name: "postgres"}}
+			},
+			wantErr:   true,
+			errSubstr: "circular dependency detected: postgres -> server1 -> postgres",
+		},
+		{
+			name: "cycle through agent uses",
+			modify: func(s *Stack) {
+				s.MCPServers[0].DependsOn = []Dependency{{Name: "agent1"}}
+				s.Agents[0].Uses = []ToolSelector{{Server: "server1"}}
+			},
+			wantErr:   true,
+			errSubstr: "circular dependency detected: server1 -> agent1 -> server1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := base()
+			tc.modify(s)
+			err := Validate(s)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected validation error, got nil")
+				}
+				if !strings.Contains(err.Error(), tc.errSubstr) {
+					t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
 func writeTempFile(t *testing.T, content string) string {
 	t.Helper()
 	dir := t.TempDir()
@@ -754,3 +996,1745 @@ func writeTempFile(t *testing.T, content string) string {
 	}
 	return path
 }
+
+func TestParseVolumeMount(t *testing.T) {
+	tests := []struct {
+		spec      string
+		want      VolumeMount
+		wantNamed bool
+		wantErr   bool
+	}{
+		{spec: "./repo:/workspace", want: VolumeMount{Source: "./repo", Target: "/workspace"}},
+		{spec: "/data:/data:ro", want: VolumeMount{Source: "/data", Target: "/data", Mode: "ro"}},
+		{spec: "cache:/cache", want: VolumeMount{Source: "cache", Target: "/cache"}, wantNamed: true},
+		{spec: "~/code:/code", want: VolumeMount{Source: "~/code", Target: "/code"}},
+		{spec: ".:/app", want: VolumeMount{Source: ".", Target: "/app"}},
+		{spec: "/workspace", wantErr: true},
+		{spec: "a:b:c:d", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			got, err := ParseVolumeMount(tc.spec)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tc.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %+v, got %+v", tc.want, got)
+			}
+			if got.IsNamedVolume() != tc.wantNamed {
+				t.Errorf("expected IsNamedVolume() = %v", tc.wantNamed)
+			}
+			if got.String() != tc.spec {
+				t.Errorf("expected String() = %q, got %q", tc.spec, got.String())
+			}
+		})
+	}
+}
+
+func TestLoadStack_Volumes(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+volumes:
+  - name: cache
+mcp-servers:
+  - name: filesystem
+    image: mcp/filesystem:latest
+    transport: stdio
+    volumes:
+      - ./repo:/workspace:ro
+      - cache:/cache
+    tmpfs:
+      - /tmp:size=64m
+    workdir: /workspace
+  - name: local
+    command: ["./server"]
+    workdir: ./tools
+`
+	path := writeTempFile(t, content)
+	baseDir := filepath.Dir(path)
+
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stack.Volumes) != 1 || stack.Volumes[0].Driver != "local" {
+		t.Errorf("expected volume 'cache' with default driver 'local', got %+v", stack.Volumes)
+	}
+
+	fs := stack.MCPServers[0]
+	wantBind := filepath.Join(baseDir, "repo") + ":/workspace:ro"
+	if fs.Volumes[0] != wantBind {
+		t.Errorf("expected bind %q, got %q", wantBind, fs.Volumes[0])
+	}
+	if fs.Volumes[1] != "cache:/cache" {
+		t.Errorf("expected named volume to be left as-is, got %q", fs.Volumes[1])
+	}
+	if fs.WorkDir != "/workspace" {
+		t.Errorf("expected container workdir '/workspace', got %q", fs.WorkDir)
+	}
+
+	if want := filepath.Join(baseDir, "tools"); stack.MCPServers[1].WorkDir != want {
+		t.Errorf("expected local workdir %q, got %q", want, stack.MCPServers[1].WorkDir)
+	}
+}
+
+func TestLoadStack_ResourceVolumesUndeclared(t *testing.T) {
+	// Stacks written before top-level volumes mount Docker volumes by name
+	content := `
+name: test
+network:
+  name: test-net
+resources:
+  - name: postgres
+    image: postgres:16
+    volumes:
+      - pgdata:/var/lib/postgresql/data
+mcp-servers:
+  - name: server1
+    image: alpine
+    port: 3000
+`
+	stack, err := LoadStack(writeTempFile(t, content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := stack.Resources[0].Volumes[0]; got != "pgdata:/var/lib/postgresql/data" {
+		t.Errorf("expected the volume to be left as-is, got %q", got)
+	}
+}
+
+func TestValidate_Volumes(t *testing.T) {
+	base := func() *Stack {
+		return &Stack{
+			Name:    "test",
+			Network: Network{Name: "test-net"},
+			Volumes: []Volume{{Name: "cache", Driver: "local"}},
+			MCPServers: []MCPServer{
+				{Name: "server1", Image: "alpine", Port: 3000},
+				{Name: "remote", URL: "https://example.com/mcp"},
+			},
+			Agents: []Agent{
+				{Name: "agent1", Image: "alpine"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(s *Stack)
+		wantErr   bool
+		errSubstr string
+	}{
+		{
+			name: "valid mounts",
+			modify: func(s *Stack) {
+				s.MCPServers[0].Volumes = []string{"/repo:/workspace:ro", "cache:/cache"}
+				s.MCPServers[0].Tmpfs = []string{"/tmp"}
+				s.MCPServers[0].WorkDir = "/workspace"
+				s.Agents[0].Volumes = []string{"cache:/cache:rw"}
+			},
+		},
+		{
+			name: "undeclared named volume",
+			modify: func(s *Stack) {
+				s.Agents[0].Volumes = []string{"data:/data"}
+			},
+			wantErr:   true,
+			errSubstr: "named volume 'data' not found",
+		},
+		{
+			name: "relative container path",
+			modify: func(s *Stack) {
+				s.MCPServers[0].Volumes = []string{"/repo:workspace"}
+			},
+			wantErr:   true,
+			errSubstr: "container path must be absolute",
+		},
+		{
+			name: "invalid mode",
+			modify: func(s *Stack) {
+				s.MCPServers[0].Volumes = []string{"/repo:/workspace:rx"}
+			},
+			wantErr:   true,
+			errSubstr: "mode must be 'ro' or 'rw'",
+		},
+		{
+			name: "relative workdir",
+			modify: func(s *Stack) {
+				s.MCPServers[0].WorkDir = "workspace"
+			},
+			wantErr:   true,
+			errSubstr: "must be an absolute container path",
+		},
+		{
+			name: "volumes on external server",
+			modify: func(s *Stack) {
+				s.MCPServers[1].Volumes = []string{"/repo:/workspace"}
+			},
+			wantErr:   true,
+			errSubstr: "not applicable",
+		},
+		{
+			name: "duplicate volume name",
+			modify: func(s *Stack) {
+				s.Volumes = append(s.Volumes, Volume{Name: "cache"})
+			},
+			wantErr:   true,
+			errSubstr: "volumes[1].name",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := base()
+			tc.modify(s)
+			err := Validate(s)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected validation error, got nil")
+				}
+				if !strings.Contains(err.Error(), tc.errSubstr) {
+					t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_SecurityStrict(t *testing.T) {
+	content := `
+name: test
+security: strict
+mcp-servers:
+  - name: hardened
+    image: alpine:latest
+    port: 3000
+  - name: tuned
+    image: alpine:latest
+    port: 3001
+    memory: 1g
+    read_only: false
+    cap_drop: [NET_RAW]
+    user: "1000:1000"
+  - name: remote
+    url: https://example.com/mcp
+resources:
+  - name: postgres
+    image: postgres:16
+    memory: 2g
+`
+	path := writeTempFile(t, content)
+
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hardened := stack.MCPServers[0]
+	if hardened.CPUs != StrictCPUs || hardened.Memory != StrictMemory || hardened.PidsLimit != StrictPidsLimit {
+		t.Errorf("expected strict limits, got cpus=%v memory=%q pids=%d", hardened.CPUs, hardened.Memory, hardened.PidsLimit)
+	}
+	if hardened.ReadOnly == nil || !*hardened.ReadOnly {
+		t.Error("expected read_only to default to true")
+	}
+	if hardened.NoNewPrivileges == nil || !*hardened.NoNewPrivileges {
+		t.Error("expected no_new_privileges to default to true")
+	}
+	if len(hardened.CapDrop) != 1 || hardened.CapDrop[0] != "ALL" {
+		t.Errorf("expected cap_drop [ALL], got %v", hardened.CapDrop)
+	}
+	if len(hardened.Tmpfs) != 1 || hardened.Tmpfs[0] != "/tmp" {
+		t.Errorf("expected writable /tmp for read-only rootfs, got %v", hardened.Tmpfs)
+	}
+
+	tuned := stack.MCPServers[1]
+	if tuned.Memory != "1g" {
+		t.Errorf("expected explicit memory to be kept, got %q", tuned.Memory)
+	}
+	if tuned.ReadOnly == nil || *tuned.ReadOnly {
+		t.Error("expected explicit read_only: false to be kept")
+	}
+	if len(tuned.Tmpfs) != 0 {
+		t.Errorf("expected no tmpfs for writable rootfs, got %v", tuned.Tmpfs)
+	}
+	if len(tuned.CapDrop) != 1 || tuned.CapDrop[0] != "NET_RAW" {
+		t.Errorf("expected explicit cap_drop to be kept, got %v", tuned.CapDrop)
+	}
+	if tuned.User != "1000:1000" {
+		t.Errorf("expected user '1000:1000', got %q", tuned.User)
+	}
+
+	if stack.MCPServers[2].ContainerSecurity.IsSet() {
+		t.Error("expected external server to be left alone by the strict profile")
+	}
+
+	postgres := stack.Resources[0]
+	if postgres.Memory != "2g" || postgres.ReadOnly != nil || postgres.CPUs != 0 {
+		t.Errorf("expected resource to keep only explicit settings, got %+v", postgres.ContainerSecurity)
+	}
+}
+
+func TestValidate_Security(t *testing.T) {
+	base := func() *Stack {
+		return &Stack{
+			Name:    "test",
+			Network: Network{Name: "test-net"},
+			MCPServers: []MCPServer{
+				{Name: "server1", Image: "alpine", Port: 3000},
+				{Name: "local", Command: []string{"./server"}},
+			},
+			Resources: []Resource{
+				{Name: "postgres", Image: "postgres"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(s *Stack)
+		wantErr   bool
+		errSubstr string
+	}{
+		{
+			name: "valid options",
+			modify: func(s *Stack) {
+				s.Security = SecurityStrict
+				s.MCPServers[0].ContainerSecurity = ContainerSecurity{
+					CPUs: 0.5, Memory: "256m", PidsLimit: 64,
+					CapDrop: []string{"ALL"}, CapAdd: []string{"NET_BIND_SERVICE"}, User: "nobody",
+				}
+				s.Resources[0].Memory = "1g"
+			},
+		},
+		{
+			name:      "unknown profile",
+			modify:    func(s *Stack) { s.Security = "paranoid" },
+			wantErr:   true,
+			errSubstr: "stack.security",
+		},
+		{
+			name:      "invalid memory",
+			modify:    func(s *Stack) { s.MCPServers[0].Memory = "lots" },
+			wantErr:   true,
+			errSubstr: "mcp-servers[0].memory",
+		},
+		{
+			name:      "negative cpus",
+			modify:    func(s *Stack) { s.Resources[0].CPUs = -1 },
+			wantErr:   true,
+			errSubstr: "resources[0].cpus",
+		},
+		{
+			name:      "invalid capability",
+			modify:    func(s *Stack) { s.MCPServers[0].CapDrop = []string{"net-raw"} },
+			wantErr:   true,
+			errSubstr: "invalid capability 'net-raw'",
+		},
+		{
+			name:      "limits on local process",
+			modify:    func(s *Stack) { s.MCPServers[1].Memory = "512m" },
+			wantErr:   true,
+			errSubstr: "not applicable for local process servers",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := base()
+			tc.modify(s)
+			err := Validate(s)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected validation error, got nil")
+				}
+				if !strings.Contains(err.Error(), tc.errSubstr) {
+					t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestEgressUnmarshal(t *testing.T) {
+	content := `
+name: test
+mcp-servers:
+  - name: sealed
+    image: alpine
+    transport: stdio
+    egress: none
+  - name: github
+    image: alpine
+    transport: stdio
+    egress: ["api.github.com", "*.githubusercontent.com"]
+  - name: object
+    image: alpine
+    transport: stdio
+    egress:
+      allow: ["10.0.0.5"]
+  - name: open
+    image: alpine
+    port: 3000
+`
+	path := writeTempFile(t, content)
+
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := stack.MCPServers[0].EgressMode(); got != EgressNone {
+		t.Errorf("expected mode 'none', got %q", got)
+	}
+	github := stack.MCPServers[1].Egress
+	if github.Mode != EgressAllowlist || len(github.Allow) != 2 || github.Allow[1] != "*.githubusercontent.com" {
+		t.Errorf("expected allowlist from host list, got %+v", github)
+	}
+	if got := stack.MCPServers[2].Egress; got.Mode != EgressAllowlist || got.Allow[0] != "10.0.0.5" {
+		t.Errorf("expected allowlist mode inferred from allow, got %+v", got)
+	}
+	if got := stack.MCPServers[3].EgressMode(); got != "" {
+		t.Errorf("expected unrestricted server, got %q", got)
+	}
+}
+
+func TestValidate_Egress(t *testing.T) {
+	base := func() *Stack {
+		return &Stack{
+			Name:    "test",
+			Network: Network{Name: "test-net"},
+			MCPServers: []MCPServer{
+				{Name: "server1", Image: "alpine", Transport: "stdio"},
+				{Name: "remote", URL: "https://example.com/mcp"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		egress    *Egress
+		server    int
+		wantErr   bool
+		errSubstr string
+	}{
+		{name: "internal", egress: &Egress{Mode: EgressInternal}},
+		{name: "allowlist", egress: &Egress{Mode: EgressAllowlist, Allow: []string{"api.github.com", "*.example.com", "192.168.1.10"}}},
+		{
+			name:      "unknown mode",
+			egress:    &Egress{Mode: "open"},
+			wantErr:   true,
+			errSubstr: "egress.mode",
+		},
+		{
+			name:      "empty allowlist",
+			egress:    &Egress{Mode: EgressAllowlist},
+			wantErr:   true,
+			errSubstr: "at least one host",
+		},
+		{
+			name:      "allow without allowlist mode",
+			egress:    &Egress{Mode: EgressNone, Allow: []string{"example.com"}},
+			wantErr:   true,
+			errSubstr: "only valid with mode 'allowlist'",
+		},
+		{
+			name:      "invalid host",
+			egress:    &Egress{Mode: EgressAllowlist, Allow: []string{"https://example.com"}},
+			wantErr:   true,
+			errSubstr: "invalid host",
+		},
+		{
+			name:      "external server",
+			egress:    &Egress{Mode: EgressNone},
+			server:    1,
+			wantErr:   true,
+			errSubstr: "not applicable for external URL servers",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := base()
+			s.MCPServers[tc.server].Egress = tc.egress
+			err := Validate(s)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected validation error, got nil")
+				}
+				if !strings.Contains(err.Error(), tc.errSubstr) {
+					t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+
+	t.Run("requires stdio", func(t *testing.T) {
+		s := base()
+		s.MCPServers[0].Transport = "http"
+		s.MCPServers[0].Port = 3000
+		s.MCPServers[0].Egress = &Egress{Mode: EgressNone}
+		err := Validate(s)
+		if err == nil || !strings.Contains(err.Error(), "requires transport 'stdio'") {
+			t.Errorf("expected stdio transport error, got: %v", err)
+		}
+	})
+}
+
+func TestValidate_ToolPatterns(t *testing.T) {
+	tests := []struct {
+		name        string
+		serverTools []string
+		agentTools  []string
+		wantErr     bool
+		errSubstr   string
+	}{
+		{name: "exact, glob, regex, and exclusion", serverTools: []string{"get_file", "list_*", "re:^search_", "!*_admin"}, agentTools: []string{"!delete_*"}},
+		{
+			name:        "invalid regex",
+			serverTools: []string{"re:get_("},
+			wantErr:     true,
+			errSubstr:   "mcp-servers[0].tools[0]: invalid regex",
+		},
+		{
+			name:        "invalid glob",
+			serverTools: []string{"ok", "get_[a"},
+			wantErr:     true,
+			errSubstr:   "mcp-servers[0].tools[1]: invalid glob",
+		},
+		{
+			name:       "empty exclusion in agent",
+			agentTools: []string{"!"},
+			wantErr:    true,
+			errSubstr:  "agents[0].uses[0].tools[0]: empty tool pattern",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:    "test",
+				Network: Network{Name: "test-net"},
+				MCPServers: []MCPServer{
+					{Name: "server1", Image: "alpine", Port: 3000, Tools: tc.serverTools},
+				},
+				Agents: []Agent{
+					{Name: "agent1", Image: "alpine", Uses: []ToolSelector{{Server: "server1", Tools: tc.agentTools}}},
+				},
+			}
+			err := Validate(s)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected validation error, got nil")
+				}
+				if !strings.Contains(err.Error(), tc.errSubstr) {
+					t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_ToolOverrides(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+mcp-servers:
+  - name: github
+    image: ghcr.io/github/github-mcp-server
+    transport: stdio
+    tool_overrides:
+      search_code:
+        name: code_search
+        aliases: [find_code]
+        description_append: "Only searches our-org."
+        hidden_params: [per_page]
+        pinned_params:
+          owner: our-org
+        schema:
+          properties:
+            query:
+              maxLength: 200
+`
+	path := writeTempFile(t, content)
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+
+	ov := stack.MCPServers[0].ToolOverrides["search_code"]
+	if got := ov.ExposedNames("search_code"); strings.Join(got, ",") != "code_search,find_code" {
+		t.Errorf("ExposedNames() = %v", got)
+	}
+	if ov.PinnedParams["owner"] != "our-org" {
+		t.Errorf("expected pinned owner, got %v", ov.PinnedParams)
+	}
+	props, ok := ov.Schema["properties"].(map[string]any)
+	if !ok || props["query"] == nil {
+		t.Errorf("expected schema properties to decode as a mapping, got %#v", ov.Schema)
+	}
+}
+
+func TestValidate_ToolOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]ToolOverride
+		errSubstr string
+	}{
+		{name: "valid", overrides: map[string]ToolOverride{"a": {Name: "b", Aliases: []string{"c"}}, "d": {Description: "x"}}},
+		{
+			name:      "invalid name",
+			overrides: map[string]ToolOverride{"a": {Name: "bad name"}},
+			errSubstr: "invalid tool name 'bad name'",
+		},
+		{
+			name:      "duplicate exposed name",
+			overrides: map[string]ToolOverride{"a": {Name: "x"}, "b": {Aliases: []string{"x"}}},
+			errSubstr: "name 'x' is already used by tool 'a'",
+		},
+		{
+			name:      "rename onto another overridden tool",
+			overrides: map[string]ToolOverride{"a": {Description: "x"}, "b": {Name: "a"}},
+			errSubstr: "name 'a' is already used by tool 'a'",
+		},
+		{
+			name:      "hidden and pinned",
+			overrides: map[string]ToolOverride{"a": {HiddenParams: []string{"p"}, PinnedParams: map[string]any{"p": 1}}},
+			errSubstr: "cannot be both hidden and pinned",
+		},
+		{
+			name:      "tighten pinned parameter",
+			overrides: map[string]ToolOverride{"a": {PinnedParams: map[string]any{"p": 1}, Schema: map[string]any{"properties": map[string]any{"p": map[string]any{"enum": []any{1}}}}}},
+			errSubstr: "cannot tighten a hidden or pinned parameter",
+		},
+		{
+			name:      "require hidden parameter",
+			overrides: map[string]ToolOverride{"a": {HiddenParams: []string{"p"}, Schema: map[string]any{"required": []any{"p"}}}},
+			errSubstr: "cannot require hidden or pinned parameter 'p'",
+		},
+		{
+			name:      "properties not a mapping",
+			overrides: map[string]ToolOverride{"a": {Schema: map[string]any{"properties": "x"}}},
+			errSubstr: "schema.properties: must be a mapping",
+		},
+		{
+			name: "constraints",
+			overrides: map[string]ToolOverride{"a": {Schema: map[string]any{
+				"properties":           map[string]any{"q": map[string]any{"enum": []any{"x"}, "pattern": "^x", "maxLength": 10, "minimum": 1, "maximum": 2.5}},
+				"required":             []any{"q"},
+				"additionalProperties": false,
+			}}},
+		},
+		{
+			name:      "replace top-level key",
+			overrides: map[string]ToolOverride{"a": {Schema: map[string]any{"type": "string"}}},
+			errSubstr: "schema.type: not allowed",
+		},
+		{
+			name:      "allow additional properties",
+			overrides: map[string]ToolOverride{"a": {Schema: map[string]any{"additionalProperties": true}}},
+			errSubstr: "schema.additionalProperties: can only be set to false",
+		},
+		{
+			name:      "change parameter type",
+			overrides: map[string]ToolOverride{"a": {Schema: map[string]any{"properties": map[string]any{"q": map[string]any{"type": "integer"}}}}},
+			errSubstr: "schema.properties.q.type: not allowed",
+		},
+		{
+			name:      "negative maxLength",
+			overrides: map[string]ToolOverride{"a": {Schema: map[string]any{"properties": map[string]any{"q": map[string]any{"maxLength": -1}}}}},
+			errSubstr: "schema.properties.q.maxLength: must be a non-negative integer",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:       "test",
+				Network:    Network{Name: "test-net"},
+				MCPServers: []MCPServer{{Name: "server1", URL: "https://example.com/mcp", ToolOverrides: tc.overrides}},
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_ToolNaming(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+tool_naming:
+  separator: "-"
+mcp-servers:
+  - name: github
+    url: https://example.com/mcp
+    prefix: gh
+  - name: linter
+    url: https://example.com/lint
+    prefix: false
+  - name: plain
+    url: https://example.com/plain
+    prefix: true
+  - name: default
+    url: https://example.com/default
+`
+	path := writeTempFile(t, content)
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+
+	if stack.ToolNaming.Separator != "-" {
+		t.Errorf("expected separator '-', got %q", stack.ToolNaming.Separator)
+	}
+	if stack.ToolNaming.MaxLength != DefaultToolNameMaxLength {
+		t.Errorf("expected default max length %d, got %d", DefaultToolNameMaxLength, stack.ToolNaming.MaxLength)
+	}
+
+	want := []string{"gh", "", "plain", "default"}
+	for i, w := range want {
+		if got := stack.MCPServers[i].ToolNamePrefix(); got != w {
+			t.Errorf("server %s: expected prefix %q, got %q", stack.MCPServers[i].Name, w, got)
+		}
+	}
+}
+
+func TestValidate_ToolNaming(t *testing.T) {
+	tests := []struct {
+		name      string
+		naming    ToolNaming
+		prefixes  []*ToolPrefix
+		errSubstr string
+	}{
+		{name: "defaults", prefixes: []*ToolPrefix{nil, nil}},
+		{name: "aliases and unprefixed", naming: ToolNaming{Separator: "-", MaxLength: 48}, prefixes: []*ToolPrefix{{Alias: "a"}, {Disabled: true}}},
+		{name: "multiple unprefixed", prefixes: []*ToolPrefix{{Disabled: true}, {Disabled: true}}},
+		{
+			name:      "invalid separator",
+			naming:    ToolNaming{Separator: "::"},
+			prefixes:  []*ToolPrefix{nil, nil},
+			errSubstr: "tool_naming.separator",
+		},
+		{
+			name:      "max length too short",
+			naming:    ToolNaming{MaxLength: 10},
+			prefixes:  []*ToolPrefix{nil, nil},
+			errSubstr: "tool_naming.max_length: must be at least 16",
+		},
+		{
+			name:      "alias collides with server name",
+			prefixes:  []*ToolPrefix{nil, {Alias: "server1"}},
+			errSubstr: "mcp-servers[1].prefix: tool prefix 'server1' conflicts with mcp-server 'server1'",
+		},
+		{
+			name:      "alias collides with a2a agent",
+			prefixes:  []*ToolPrefix{{Alias: "remote"}, nil},
+			errSubstr: "tool prefix 'remote' conflicts with a2a-agent 'remote'",
+		},
+		{
+			name:      "invalid alias",
+			prefixes:  []*ToolPrefix{{Alias: "a b"}, nil},
+			errSubstr: "invalid prefix 'a b'",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:       "test",
+				Network:    Network{Name: "test-net"},
+				ToolNaming: tc.naming,
+				MCPServers: []MCPServer{
+					{Name: "server1", URL: "https://example.com/1", Prefix: tc.prefixes[0]},
+					{Name: "server2", URL: "https://example.com/2", Prefix: tc.prefixes[1]},
+				},
+				A2AAgents: []A2AAgent{{Name: "remote", URL: "https://example.com/a2a"}},
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_Toolsets(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+mcp-servers:
+  - name: github
+    url: https://example.com/mcp
+  - name: jira
+    url: https://example.com/jira
+toolsets:
+  - name: issues
+    description: Issue tracking
+    tools:
+      - jira
+      - server: github
+        tools: ["*_issue", "!delete_*"]
+`
+	path := writeTempFile(t, content)
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+
+	if len(stack.Toolsets) != 1 {
+		t.Fatalf("expected 1 toolset, got %d", len(stack.Toolsets))
+	}
+	ts := stack.Toolsets[0]
+	if ts.Name != "issues" || ts.Description != "Issue tracking" {
+		t.Errorf("unexpected toolset %+v", ts)
+	}
+	if len(ts.Tools) != 2 || ts.Tools[0].Server != "jira" || len(ts.Tools[1].Tools) != 2 {
+		t.Errorf("unexpected toolset selectors %+v", ts.Tools)
+	}
+}
+
+func TestValidate_Toolsets(t *testing.T) {
+	tests := []struct {
+		name      string
+		toolsets  []Toolset
+		prefix    *ToolPrefix
+		errSubstr string
+	}{
+		{
+			name:     "valid",
+			toolsets: []Toolset{{Name: "code", Tools: []ToolSelector{{Server: "github", Tools: []string{"get_*"}}, {Server: "remote"}}}},
+		},
+		{
+			name:      "missing name",
+			toolsets:  []Toolset{{Tools: []ToolSelector{{Server: "github"}}}},
+			errSubstr: "toolsets[0].name: is required",
+		},
+		{
+			name:      "invalid name",
+			toolsets:  []Toolset{{Name: "my tools", Tools: []ToolSelector{{Server: "github"}}}},
+			errSubstr: "invalid name 'my tools'",
+		},
+		{
+			name: "duplicate name",
+			toolsets: []Toolset{
+				{Name: "code", Tools: []ToolSelector{{Server: "github"}}},
+				{Name: "code", Tools: []ToolSelector{{Server: "github"}}},
+			},
+			errSubstr: "toolsets[1].name: duplicate toolset name 'code'",
+		},
+		{
+			name:      "no tools",
+			toolsets:  []Toolset{{Name: "code"}},
+			errSubstr: "toolsets[0].tools: at least one server is required",
+		},
+		{
+			name:      "unknown server",
+			toolsets:  []Toolset{{Name: "code", Tools: []ToolSelector{{Server: "gitlab"}}}},
+			errSubstr: "toolsets[0].tools[0]: 'gitlab' not found",
+		},
+		{
+			name:      "invalid pattern",
+			toolsets:  []Toolset{{Name: "code", Tools: []ToolSelector{{Server: "github", Tools: []string{"re:("}}}}},
+			errSubstr: "toolsets[0].tools[0].tools",
+		},
+		{
+			name:      "reserved prefix",
+			toolsets:  []Toolset{{Name: "code", Tools: []ToolSelector{{Server: "github"}}}},
+			prefix:    &ToolPrefix{Alias: BuiltinToolPrefix},
+			errSubstr: "conflicts with the gateway's built-in tools",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:       "test",
+				Network:    Network{Name: "test-net"},
+				MCPServers: []MCPServer{{Name: "github", URL: "https://example.com/mcp", Prefix: tc.prefix}},
+				A2AAgents:  []A2AAgent{{Name: "remote", URL: "https://example.com/a2a"}},
+				Toolsets:   tc.toolsets,
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_ToolMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		prefix    *ToolPrefix
+		errSubstr string
+	}{
+		{name: "default"},
+		{name: "all", mode: ToolModeAll},
+		{name: "search", mode: ToolModeSearch},
+		{name: "invalid", mode: "lazy", errSubstr: "stack.tool_mode: must be 'all' or 'search'"},
+		{name: "search reserves prefix", mode: ToolModeSearch, prefix: &ToolPrefix{Alias: BuiltinToolPrefix}, errSubstr: "conflicts with the gateway's built-in tools"},
+		{name: "prefix free without built-in tools", mode: ToolModeAll, prefix: &ToolPrefix{Alias: BuiltinToolPrefix}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:       "test",
+				Network:    Network{Name: "test-net"},
+				ToolMode:   tc.mode,
+				MCPServers: []MCPServer{{Name: "github", URL: "https://example.com/mcp", Prefix: tc.prefix}},
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_Audit(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+audit:
+  enabled: false
+  redact: ["*password*", "session_id"]
+  max_size: 5m
+  max_files: 3
+mcp-servers:
+  - name: github
+    url: https://example.com/mcp
+`
+	stack, err := LoadStack(writeTempFile(t, content))
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	if stack.Audit.IsEnabled() {
+		t.Error("expected audit to be disabled")
+	}
+	if len(stack.Audit.Redact) != 2 || stack.Audit.Redact[1] != "session_id" {
+		t.Errorf("unexpected redact patterns: %v", stack.Audit.Redact)
+	}
+	if n, err := stack.Audit.MaxSizeBytes(); err != nil || n != 5*1024*1024 {
+		t.Errorf("expected max size 5MiB, got %d (%v)", n, err)
+	}
+	if stack.Audit.MaxFiles != 3 {
+		t.Errorf("expected max files 3, got %d", stack.Audit.MaxFiles)
+	}
+
+	var defaults Audit
+	if !defaults.IsEnabled() {
+		t.Error("expected audit to be enabled by default")
+	}
+}
+
+func TestValidate_Audit(t *testing.T) {
+	tests := []struct {
+		name      string
+		audit     Audit
+		errSubstr string
+	}{
+		{name: "default"},
+		{name: "valid", audit: Audit{Redact: []string{"token", "*key*"}, MaxSize: "100k", MaxFiles: 2}},
+		{name: "empty pattern", audit: Audit{Redact: []string{""}}, errSubstr: "audit.redact[0]: invalid pattern"},
+		{name: "malformed pattern", audit: Audit{Redact: []string{"ok", "[abc"}}, errSubstr: "audit.redact[1]: invalid pattern '[abc'"},
+		{name: "invalid size", audit: Audit{MaxSize: "lots"}, errSubstr: "audit.max_size: invalid size 'lots'"},
+		{name: "negative files", audit: Audit{MaxFiles: -1}, errSubstr: "audit.max_files: must not be negative"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:       "test",
+				Network:    Network{Name: "test-net"},
+				Audit:      tc.audit,
+				MCPServers: []MCPServer{{Name: "github", URL: "https://example.com/mcp"}},
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_Tracing(t *testing.T) {
+	t.Setenv("TEST_OTEL_TOKEN", "secret")
+	content := `
+name: test
+network:
+  name: test-net
+tracing:
+  endpoint: http://localhost:4318
+  headers:
+    Authorization: Bearer ${TEST_OTEL_TOKEN}
+  file: traces/spans.jsonl
+mcp-servers:
+  - name: github
+    url: https://example.com/mcp
+`
+	path := writeTempFile(t, content)
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	if !stack.Tracing.IsEnabled() {
+		t.Error("expected tracing to be enabled")
+	}
+	if got := stack.Tracing.Headers["Authorization"]; got != "Bearer secret" {
+		t.Errorf("expected expanded header, got %q", got)
+	}
+	if want := filepath.Join(filepath.Dir(path), "traces", "spans.jsonl"); stack.Tracing.File != want {
+		t.Errorf("expected file %q, got %q", want, stack.Tracing.File)
+	}
+	if stack.Tracing.Ratio() != 1 {
+		t.Errorf("expected default sample ratio 1, got %v", stack.Tracing.Ratio())
+	}
+
+	var defaults Tracing
+	if defaults.IsEnabled() {
+		t.Error("expected tracing to be disabled by default")
+	}
+}
+
+func TestValidate_Tracing(t *testing.T) {
+	ratio := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name      string
+		tracing   Tracing
+		errSubstr string
+	}{
+		{name: "default"},
+		{name: "endpoint", tracing: Tracing{Endpoint: "https://otel.example.com", Headers: map[string]string{"x-api-key": "k"}, SampleRatio: ratio(0.5)}},
+		{name: "file only", tracing: Tracing{File: "/tmp/spans.jsonl", SampleRatio: ratio(0)}},
+		{name: "bad endpoint", tracing: Tracing{Endpoint: "localhost:4318"}, errSubstr: "tracing.endpoint: must be an http or https URL, got 'localhost:4318'"},
+		{name: "headers without endpoint", tracing: Tracing{File: "/tmp/spans.jsonl", Headers: map[string]string{"a": "b"}}, errSubstr: "tracing.headers: requires tracing.endpoint"},
+		{name: "ratio too high", tracing: Tracing{File: "/tmp/spans.jsonl", SampleRatio: ratio(1.5)}, errSubstr: "tracing.sample_ratio: must be between 0 and 1"},
+		{name: "negative ratio", tracing: Tracing{File: "/tmp/spans.jsonl", SampleRatio: ratio(-0.1)}, errSubstr: "tracing.sample_ratio: must be between 0 and 1"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:       "test",
+				Network:    Network{Name: "test-net"},
+				Tracing:    tc.tracing,
+				MCPServers: []MCPServer{{Name: "github", URL: "https://example.com/mcp"}},
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_CallLimits(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+mcp-servers:
+  - name: github
+    url: https://example.com/mcp
+    timeout: 45s
+    max_concurrency: 4
+    rate_limit:
+      rate: 100/m
+    tool_limits:
+      create_issue:
+        timeout: 2m
+        rate_limit:
+          rate: 5/h
+          burst: 2
+          per_agent: true
+`
+	stack, err := LoadStack(writeTempFile(t, content))
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	server := stack.MCPServers[0]
+	if d, err := server.TimeoutDuration(); err != nil || d != 45*time.Second {
+		t.Errorf("expected 45s timeout, got %v (%v)", d, err)
+	}
+	if server.MaxConcurrency != 4 {
+		t.Errorf("expected max concurrency 4, got %d", server.MaxConcurrency)
+	}
+	if server.RateLimit == nil || server.RateLimit.BurstSize() != 100 {
+		t.Errorf("expected default burst of 100, got %+v", server.RateLimit)
+	}
+	tool := server.ToolLimits["create_issue"]
+	if tool.RateLimit == nil || !tool.RateLimit.PerAgent || tool.RateLimit.BurstSize() != 2 {
+		t.Errorf("unexpected tool rate limit: %+v", tool.RateLimit)
+	}
+	if d, _ := tool.TimeoutDuration(); d != 2*time.Minute {
+		t.Errorf("expected 2m tool timeout, got %v", d)
+	}
+}
+
+func TestRateLimit_Parse(t *testing.T) {
+	tests := []struct {
+		rate      string
+		calls     int
+		per       time.Duration
+		errSubstr string
+	}{
+		{rate: "10/s", calls: 10, per: time.Second},
+		{rate: "100/m", calls: 100, per: time.Minute},
+		{rate: "5 / 30s", calls: 5, per: 30 * time.Second},
+		{rate: "1000/h", calls: 1000, per: time.Hour},
+		{rate: "10", errSubstr: "rate must be '<calls>/<interval>'"},
+		{rate: "0/s", errSubstr: "invalid number of calls '0'"},
+		{rate: "x/s", errSubstr: "invalid number of calls 'x'"},
+		{rate: "10/day", errSubstr: "invalid interval 'day'"},
+		{rate: "10/", errSubstr: "invalid interval ''"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.rate, func(t *testing.T) {
+			r := RateLimit{Rate: tc.rate}
+			calls, per, err := r.Parse()
+			if tc.errSubstr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+					t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+				}
+				return
+			}
+			if err != nil || calls != tc.calls || per != tc.per {
+				t.Errorf("expected %d/%v, got %d/%v (%v)", tc.calls, tc.per, calls, per, err)
+			}
+		})
+	}
+}
+
+func TestValidate_CallLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		limits     CallLimits
+		toolLimits map[string]CallLimits
+		errSubstr  string
+	}{
+		{name: "none"},
+		{name: "valid", limits: CallLimits{Timeout: "1m", MaxConcurrency: 2, RateLimit: &RateLimit{Rate: "10/s", Burst: 5}}},
+		{name: "bad timeout", limits: CallLimits{Timeout: "soon"}, errSubstr: "mcp-servers[0].timeout: invalid duration 'soon'"},
+		{name: "negative timeout", limits: CallLimits{Timeout: "-1s"}, errSubstr: "mcp-servers[0].timeout: invalid duration '-1s'"},
+		{name: "negative concurrency", limits: CallLimits{MaxConcurrency: -1}, errSubstr: "mcp-servers[0].max_concurrency: must not be negative"},
+		{name: "missing rate", limits: CallLimits{RateLimit: &RateLimit{Burst: 1}}, errSubstr: "mcp-servers[0].rate_limit.rate: is required"},
+		{name: "bad rate", limits: CallLimits{RateLimit: &RateLimit{Rate: "lots"}}, errSubstr: "mcp-servers[0].rate_limit.rate: rate must be"},
+		{name: "negative burst", limits: CallLimits{RateLimit: &RateLimit{Rate: "1/s", Burst: -1}}, errSubstr: "mcp-servers[0].rate_limit.burst: must not be negative"},
+		{name: "bad tool limit", toolLimits: map[string]CallLimits{"search": {Timeout: "x"}}, errSubstr: "mcp-servers[0].tool_limits.search.timeout: invalid duration 'x'"},
+		{name: "empty tool name", toolLimits: map[string]CallLimits{"": {}}, errSubstr: "mcp-servers[0].tool_limits: tool name must not be empty"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:    "test",
+				Network: Network{Name: "test-net"},
+				MCPServers: []MCPServer{{
+					Name:       "github",
+					URL:        "https://example.com/mcp",
+					CallLimits: tc.limits,
+					ToolLimits: tc.toolLimits,
+				}},
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_Cache(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+mcp-servers:
+  - name: docs
+    url: https://example.com/mcp
+    cache:
+      ttl: 10m
+      max_size: 50m
+    tool_cache:
+      search:
+        ttl: 1h
+      publish:
+        enabled: false
+`
+	stack, err := LoadStack(writeTempFile(t, content))
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	server := stack.MCPServers[0]
+	if !server.Cache.IsEnabled() {
+		t.Error("expected cache to be enabled")
+	}
+	if d, err := server.Cache.TTLDuration(); err != nil || d != 10*time.Minute {
+		t.Errorf("expected 10m TTL, got %v (%v)", d, err)
+	}
+	if n, err := server.Cache.MaxSizeBytes(); err != nil || n != 50*1024*1024 {
+		t.Errorf("expected 50MiB, got %d (%v)", n, err)
+	}
+	search, publish := server.ToolCache["search"], server.ToolCache["publish"]
+	if d, _ := search.TTLDuration(); !search.IsEnabled() || d != time.Hour {
+		t.Errorf("unexpected search cache: %+v", search)
+	}
+	if publish.IsEnabled() {
+		t.Error("expected publish cache to be disabled")
+	}
+
+	var none *Cache
+	if none.IsEnabled() {
+		t.Error("expected no cache by default")
+	}
+}
+
+func TestValidate_Cache(t *testing.T) {
+	tests := []struct {
+		name      string
+		cache     *Cache
+		toolCache map[string]ToolCache
+		errSubstr string
+	}{
+		{name: "none"},
+		{name: "valid", cache: &Cache{TTL: "30s", MaxSize: "1m"}, toolCache: map[string]ToolCache{"search": {TTL: "1h"}}},
+		{name: "bad ttl", cache: &Cache{TTL: "forever"}, errSubstr: "mcp-servers[0].cache.ttl: invalid duration 'forever'"},
+		{name: "bad size", cache: &Cache{MaxSize: "big"}, errSubstr: "mcp-servers[0].cache.max_size: invalid size 'big'"},
+		{name: "bad tool ttl", toolCache: map[string]ToolCache{"search": {TTL: "-5m"}}, errSubstr: "mcp-servers[0].tool_cache.search.ttl: invalid duration '-5m'"},
+		{name: "empty tool name", toolCache: map[string]ToolCache{"": {}}, errSubstr: "mcp-servers[0].tool_cache: tool name must not be empty"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:    "test",
+				Network: Network{Name: "test-net"},
+				MCPServers: []MCPServer{{
+					Name:      "docs",
+					URL:       "https://example.com/mcp",
+					Cache:     tc.cache,
+					ToolCache: tc.toolCache,
+				}},
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_Approvals(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+approvals:
+  timeout: 2m
+  destructive: true
+  elicit: true
+mcp-servers:
+  - name: github
+    url: https://example.com/mcp
+    require_approval: ["delete_*", "merge_pull_request"]
+`
+	stack, err := LoadStack(writeTempFile(t, content))
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	if d, err := stack.Approvals.TimeoutDuration(); err != nil || d != 2*time.Minute {
+		t.Errorf("expected 2m timeout, got %v (%v)", d, err)
+	}
+	if !stack.Approvals.Destructive || !stack.Approvals.Elicit {
+		t.Errorf("unexpected approvals: %+v", stack.Approvals)
+	}
+	if got := stack.MCPServers[0].RequireApproval; len(got) != 2 || got[0] != "delete_*" {
+		t.Errorf("unexpected require_approval: %v", got)
+	}
+
+	var defaults Approvals
+	if d, _ := defaults.TimeoutDuration(); d != 0 || defaults.Elicit || defaults.Destructive {
+		t.Errorf("unexpected defaults: %+v", defaults)
+	}
+}
+
+func TestValidate_Approvals(t *testing.T) {
+	tests := []struct {
+		name      string
+		approvals Approvals
+		patterns  []string
+		errSubstr string
+	}{
+		{name: "none"},
+		{name: "valid", approvals: Approvals{Timeout: "10m"}, patterns: []string{"delete_*", "!delete_branch"}},
+		{name: "bad timeout", approvals: Approvals{Timeout: "soon"}, errSubstr: "approvals.timeout: invalid duration 'soon'"},
+		{name: "negative timeout", approvals: Approvals{Timeout: "-1m"}, errSubstr: "approvals.timeout: invalid duration '-1m'"},
+		{name: "bad pattern", patterns: []string{"delete_["}, errSubstr: "mcp-servers[0].require_approval"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:      "test",
+				Network:   Network{Name: "test-net"},
+				Approvals: tc.approvals,
+				MCPServers: []MCPServer{{
+					Name:            "github",
+					URL:             "https://example.com/mcp",
+					RequireApproval: tc.patterns,
+				}},
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_Policies(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+mcp-servers:
+  - name: filesystem
+    url: https://example.com/mcp
+agents:
+  - name: coder
+    image: agent:latest
+    uses: [filesystem]
+policies:
+  - name: workspace-only
+    action: allow
+    agents: [coder]
+    server: filesystem
+    tools: ["write_*"]
+    when:
+      - path: $.path
+        glob: /workspace/**
+  - name: no-root
+    action: deny
+    when:
+      - path: $.owner
+        in: [root, admin]
+    message: not as root
+`
+	stack, err := LoadStack(writeTempFile(t, content))
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	if len(stack.Policies) != 2 {
+		t.Fatalf("expected 2 policies, got %d", len(stack.Policies))
+	}
+	p := stack.Policies[0]
+	if p.Action != PolicyAllow || p.Server != "filesystem" || p.When[0].Glob != "/workspace/**" {
+		t.Errorf("unexpected policy: %+v", p)
+	}
+	set, err := CompilePolicies(stack.Policies)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := set.Evaluate("coder", "filesystem", "write_file", map[string]any{"path": "/workspace/x", "owner": "admin"}); !d.Denied || d.Policy != "no-root" {
+		t.Errorf("expected no-root to deny, got %+v", d)
+	}
+}
+
+func TestValidate_Policies(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    Policy
+		errSubstr string
+	}{
+		{name: "valid", policy: Policy{Name: "p", Action: PolicyDeny, Agents: []string{"coder"}, Server: "filesystem", Tools: []string{"write_*"}, When: []Condition{{Path: "$.path", Glob: "/etc/**"}}}},
+		{name: "valid redact", policy: Policy{Name: "p", Action: PolicyRedact, Redact: []string{"$.token"}}},
+		{name: "missing name", policy: Policy{Action: PolicyDeny}, errSubstr: "policies[0].name: is required"},
+		{name: "bad action", policy: Policy{Name: "p", Action: "block"}, errSubstr: "policies[0].action: must be 'deny', 'allow' or 'redact'"},
+		{name: "unknown agent", policy: Policy{Name: "p", Action: PolicyDeny, Agents: []string{"ghost"}}, errSubstr: "policies[0].agents[0]: 'ghost' not found in agents"},
+		{name: "unknown server", policy: Policy{Name: "p", Action: PolicyDeny, Server: "ghost"}, errSubstr: "policies[0].server: 'ghost' not found"},
+		{name: "bad tool pattern", policy: Policy{Name: "p", Action: PolicyDeny, Tools: []string{"re:("}}, errSubstr: "policies[0].tools[0]"},
+		{name: "bad path", policy: Policy{Name: "p", Action: PolicyDeny, When: []Condition{{Path: "path", Exists: true}}}, errSubstr: "policies[0].when[0]: invalid path 'path': must start with '$'"},
+		{name: "no test", policy: Policy{Name: "p", Action: PolicyDeny, When: []Condition{{Path: "$.path"}}}, errSubstr: "policies[0].when[0]: set exactly one of"},
+		{name: "redact without paths", policy: Policy{Name: "p", Action: PolicyRedact}, errSubstr: "policies[0].redact: at least one path is required"},
+		{name: "redact paths on deny", policy: Policy{Name: "p", Action: PolicyDeny, Redact: []string{"$.a"}}, errSubstr: "policies[0].redact: is only used with action 'redact'"},
+		{name: "bad redact path", policy: Policy{Name: "p", Action: PolicyRedact, Redact: []string{"$.a["}}, errSubstr: "policies[0].redact[0]: invalid path"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:       "test",
+				Network:    Network{Name: "test-net"},
+				MCPServers: []MCPServer{{Name: "filesystem", URL: "https://example.com/mcp"}},
+				Agents:     []Agent{{Name: "coder", Image: "agent:latest", Uses: []ToolSelector{{Server: "filesystem"}}}},
+				Policies:   []Policy{tc.policy},
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+
+	s := &Stack{
+		Name:     "test",
+		Network:  Network{Name: "test-net"},
+		Policies: []Policy{{Name: "p", Action: PolicyDeny}, {Name: "p", Action: PolicyDeny}},
+	}
+	if err := Validate(s); err == nil || !strings.Contains(err.Error(), "policies[1].name: duplicate policy name 'p'") {
+		t.Errorf("expected duplicate name error, got: %v", err)
+	}
+}
+
+func TestLoadStack_CompositeTools(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+mcp-servers:
+  - name: github
+    url: https://example.com/mcp
+agents:
+  - name: triager
+    image: agent:latest
+    uses:
+      - server: gridctl
+        tools: [triage]
+composite_tools:
+  - name: triage
+    description: Find an issue and fetch its details
+    input_schema:
+      type: object
+      properties:
+        repo: { type: string }
+      required: [repo]
+    steps:
+      - id: search
+        tool: github__search_issues
+        args:
+          query: "repo:{{ .inputs.repo }}"
+          labels: ["bug", "{{ .inputs.repo }}"]
+      - id: details
+        tool: github__get_issue
+        if: "{{ not .steps.search.is_error }}"
+        on_error: continue
+        args:
+          number: "{{ .steps.search.json.number }}"
+    output: "{{ .steps.details.text }}"
+`
+	path := writeTempFile(t, content)
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+
+	if len(stack.CompositeTools) != 1 {
+		t.Fatalf("expected 1 composite tool, got %d", len(stack.CompositeTools))
+	}
+	ct := stack.CompositeTools[0]
+	if len(ct.Steps) != 2 || ct.Steps[1].OnError != OnErrorContinue {
+		t.Errorf("unexpected steps %+v", ct.Steps)
+	}
+	if _, ok := ct.InputSchema["properties"].(map[string]any); !ok {
+		t.Errorf("expected input_schema properties map, got %T", ct.InputSchema["properties"])
+	}
+}
+
+func TestValidate_CompositeTools(t *testing.T) {
+	valid := func() CompositeTool {
+		return CompositeTool{
+			Name:  "triage",
+			Steps: []CompositeStep{{ID: "search", Tool: "github__search", Args: map[string]any{"q": "{{ .inputs.q }}"}}},
+		}
+	}
+	tests := []struct {
+		name      string
+		modify    func(*Stack)
+		errSubstr string
+	}{
+		{name: "valid", modify: func(s *Stack) {}},
+		{
+			name: "agent uses composite server",
+			modify: func(s *Stack) {
+				s.Agents = []Agent{{Name: "a", Image: "a:1", Uses: []ToolSelector{{Server: "gridctl"}}}}
+			},
+		},
+		{
+			name:      "missing name",
+			modify:    func(s *Stack) { s.CompositeTools[0].Name = "" },
+			errSubstr: "composite_tools[0].name: is required",
+		},
+		{
+			name:      "duplicate name",
+			modify:    func(s *Stack) { s.CompositeTools = append(s.CompositeTools, valid()) },
+			errSubstr: "duplicate composite tool name 'triage'",
+		},
+		{
+			name:      "no steps",
+			modify:    func(s *Stack) { s.CompositeTools[0].Steps = nil },
+			errSubstr: "composite_tools[0].steps: at least one step is required",
+		},
+		{
+			name:      "invalid step id",
+			modify:    func(s *Stack) { s.CompositeTools[0].Steps[0].ID = "get-issue" },
+			errSubstr: "invalid id 'get-issue'",
+		},
+		{
+			name: "duplicate step id",
+			modify: func(s *Stack) {
+				s.CompositeTools[0].Steps = append(s.CompositeTools[0].Steps, CompositeStep{ID: "search", Tool: "github__get"})
+			},
+			errSubstr: "duplicate step id 'search'",
+		},
+		{
+			name:      "missing tool",
+			modify:    func(s *Stack) { s.CompositeTools[0].Steps[0].Tool = "" },
+			errSubstr: "steps[0].tool: is required",
+		},
+		{
+			name:      "invalid on_error",
+			modify:    func(s *Stack) { s.CompositeTools[0].Steps[0].OnError = "retry" },
+			errSubstr: "must be 'fail' or 'continue'",
+		},
+		{
+			name:      "invalid arg template",
+			modify:    func(s *Stack) { s.CompositeTools[0].Steps[0].Args = map[string]any{"q": []any{"{{ .inputs.q"}} },
+			errSubstr: "steps[0].args.q[0]: invalid template",
+		},
+		{
+			name:      "invalid if template",
+			modify:    func(s *Stack) { s.CompositeTools[0].Steps[0].If = "{{ if }}" },
+			errSubstr: "steps[0].if: invalid template",
+		},
+		{
+			name:      "invalid input schema type",
+			modify:    func(s *Stack) { s.CompositeTools[0].InputSchema = map[string]any{"type": "string"} },
+			errSubstr: "input_schema.type: must be 'object'",
+		},
+		{
+			name: "server uses built-in prefix",
+			modify: func(s *Stack) {
+				s.MCPServers = append(s.MCPServers, MCPServer{Name: "gridctl", URL: "https://example.com/x"})
+			},
+			errSubstr: "conflicts with the gateway's built-in tools",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Stack{
+				Name:           "test",
+				Network:        Network{Name: "test-net"},
+				MCPServers:     []MCPServer{{Name: "github", URL: "https://example.com/mcp"}},
+				CompositeTools: []CompositeTool{valid()},
+			}
+			tc.modify(s)
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
+
+func TestLoadStack_Secrets(t *testing.T) {
+	t.Setenv("GRIDCTL_TEST_TEAM", "platform")
+	content := `
+name: test
+network:
+  name: test-net
+secrets:
+  github_token:
+    provider: env
+    env: GH_TOKEN
+  db_password:
+    provider: file
+    path: ./secrets/db
+mcp-servers:
+  - name: github
+    url: https://example.com/mcp
+    headers:
+      Authorization: Bearer ${secret:github_token}
+  - name: db
+    image: db-mcp:latest
+    port: 3000
+    env:
+      PASSWORD: ${secret:db_password}
+      TEAM: ${GRIDCTL_TEST_TEAM}
+a2a-agents:
+  - name: remote
+    url: https://agents.example.com
+    auth:
+      type: bearer
+      token: ${secret:remote_token}
+`
+	path := writeTempFile(t, content)
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+
+	// Secret references survive environment expansion
+	if got := stack.MCPServers[0].Headers["Authorization"]; got != "Bearer ${secret:github_token}" {
+		t.Errorf("unexpected header: %q", got)
+	}
+	if env := stack.MCPServers[1].Env; env["PASSWORD"] != "${secret:db_password}" || env["TEAM"] != "platform" {
+		t.Errorf("unexpected env: %v", env)
+	}
+	if want := filepath.Join(filepath.Dir(path), "secrets", "db"); stack.Secrets["db_password"].Path != want {
+		t.Errorf("expected path %q, got %q", want, stack.Secrets["db_password"].Path)
+	}
+	if refs := stack.SecretRefs(); !reflect.DeepEqual(refs, []string{"db_password", "github_token", "remote_token"}) {
+		t.Errorf("unexpected references: %v", refs)
+	}
+}
+
+func TestValidate_Secrets(t *testing.T) {
+	tests := []struct {
+		name      string
+		secrets   map[string]Secret
+		server    MCPServer
+		auth      *A2AAuth
+		errSubstr string
+	}{
+		{name: "valid", secrets: map[string]Secret{"a": {}, "b": {Provider: SecretProviderExec, Command: []string{"pass", "b"}}}},
+		{name: "bad provider", secrets: map[string]Secret{"a": {Provider: "vault"}}, errSubstr: "secrets.a.provider: must be 'store', 'env', 'file', or 'exec'"},
+		{name: "bad name", secrets: map[string]Secret{"a b": {}}, errSubstr: "secrets.a b: name may only contain"},
+		{name: "file without path", secrets: map[string]Secret{"a": {Provider: SecretProviderFile}}, errSubstr: "secrets.a.path: is required for provider 'file'"},
+		{name: "exec without command", secrets: map[string]Secret{"a": {Provider: SecretProviderExec}}, errSubstr: "secrets.a.command: is required for provider 'exec'"},
+		{name: "field of another provider", secrets: map[string]Secret{"a": {Provider: SecretProviderEnv, Path: "/x"}}, errSubstr: "secrets.a.path: only applies to provider 'file'"},
+		{name: "bad reference", server: MCPServer{Name: "s", URL: "https://example.com", Headers: map[string]string{"X": "${secret:a b}"}}, errSubstr: "invalid secret reference '${secret:a b}'"},
+		{name: "headers on container", server: MCPServer{Name: "s", Image: "s:latest", Port: 80, Headers: map[string]string{"X": "y"}}, errSubstr: "mcp-servers[0].headers: only valid for external URL servers"},
+		{name: "auth token", auth: &A2AAuth{Type: "bearer", Token: "${secret:a}"}},
+		{name: "auth without token", auth: &A2AAuth{Type: "bearer"}, errSubstr: "a2a-agents[0].auth.token: token or token_env is required"},
+		{name: "auth with both", auth: &A2AAuth{Type: "bearer", Token: "t", TokenEnv: "T"}, errSubstr: "cannot have both 'token' and 'token_env'"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := tc.server
+			if server.Name == "" {
+				server = MCPServer{Name: "s", URL: "https://example.com/mcp"}
+			}
+			s := &Stack{
+				Name:       "test",
+				Network:    Network{Name: "test-net"},
+				MCPServers: []MCPServer{server},
+				Secrets:    tc.secrets,
+			}
+			if tc.auth != nil {
+				s.A2AAgents = []A2AAgent{{Name: "remote", URL: "https://agents.example.com", Auth: tc.auth}}
+			}
+			err := Validate(s)
+			if tc.errSubstr == "" {
+				if err != nil {
+					t.Errorf("unexpected validation error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errSubstr) {
+				t.Errorf("expected error containing %q, got: %v", tc.errSubstr, err)
+			}
+		})
+	}
+}
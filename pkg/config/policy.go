@@ -0,0 +1,458 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Policy actions.
+const (
+	PolicyDeny   = "deny"   // Reject calls whose arguments meet the policy's conditions
+	PolicyAllow  = "allow"  // Reject calls in scope unless an allow policy's conditions are met
+	PolicyRedact = "redact" // Replace argument values before the call is made
+)
+
+// PolicyRedacted replaces argument values removed by a redact policy.
+const PolicyRedacted = "[REDACTED]"
+
+// Policy is a rule on the arguments of tool calls. It covers calls in its
+// scope, set by Agents, Server and Tools, and applies to those whose
+// arguments meet every condition in When.
+//
+// Deny policies are checked first, in order, and the first that applies
+// rejects the call. If any allow policy covers a call, at least one must
+// apply or the call is rejected. Redact policies that apply then replace
+// the values at their Redact paths.
+type Policy struct {
+	Name    string      `yaml:"name"`
+	Action  string      `yaml:"action"`                    // deny, allow, or redact
+	Agents  []string    `yaml:"agents,omitempty"`          // Calling agents (empty = every caller)
+	Server  string      `yaml:"server,omitempty"`          // MCP server (empty = every server)
+	Tools   []string    `yaml:"tools,omitempty"`           // Tool selector patterns on the server's tool names (empty = every tool)
+	When    []Condition `yaml:"when,omitempty" expand:"-"` // Conditions on the arguments, all of which must hold (empty = always)
+	Redact  []string    `yaml:"redact,omitempty"`          // Argument paths to replace, for action redact
+	Message string      `yaml:"message,omitempty"`         // Explains a denial to the caller
+}
+
+// Condition tests the argument values at a JSONPath. Paths start at $, the
+// arguments object, and use .name, ['name'], [index], and * for every
+// field or element, e.g. $.path, $.files[*].name or $['repo-owner'].
+//
+// A condition sets exactly one test. It holds if any value at the path
+// passes the test; with Not, if any value fails it. Exists holds if the
+// path has a value, or with Not, if it has none.
+type Condition struct {
+	Path     string `yaml:"path"`
+	Equals   any    `yaml:"equals,omitempty"`   // Equal to this value
+	In       []any  `yaml:"in,omitempty"`       // Equal to one of these values
+	Glob     string `yaml:"glob,omitempty"`     // String matching a glob; * stops at "/", ** does not
+	Regex    string `yaml:"regex,omitempty"`    // String matching a regular expression (Go syntax, unanchored)
+	Contains string `yaml:"contains,omitempty"` // String containing this text, ignoring case
+	Exists   bool   `yaml:"exists,omitempty"`   // Path has a value
+	Not      bool   `yaml:"not,omitempty"`      // Negate the test
+}
+
+// PolicySet evaluates compiled policies. A nil PolicySet allows every call.
+type PolicySet struct {
+	policies []compiledPolicy
+}
+
+// PolicyDecision is the outcome of evaluating policies on a tool call.
+type PolicyDecision struct {
+	Denied    bool
+	Policy    string         // Policy that denied the call, if any
+	Message   string         // Why the call was denied
+	Arguments map[string]any // Arguments to call with, after redaction
+	Redacted  []string       // Policies that redacted arguments
+}
+
+// compiledPolicy is a policy ready to evaluate.
+type compiledPolicy struct {
+	Policy
+	tools  *ToolFilter
+	when   []condition
+	redact []jsonPath
+}
+
+// condition is a compiled Condition.
+type condition struct {
+	path jsonPath
+	test func(v any) bool // nil for Exists
+	not  bool
+}
+
+// CompilePolicies compiles policies in order. An empty list returns nil.
+func CompilePolicies(policies []Policy) (*PolicySet, error) {
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	s := &PolicySet{}
+	for _, p := range policies {
+		cp, err := compilePolicy(p)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: %w", p.Name, err)
+		}
+		s.policies = append(s.policies, cp)
+	}
+	return s, nil
+}
+
+func compilePolicy(p Policy) (compiledPolicy, error) {
+	cp := compiledPolicy{Policy: p}
+	switch p.Action {
+	case PolicyDeny, PolicyAllow, PolicyRedact:
+	default:
+		return cp, fmt.Errorf("action must be '%s', '%s' or '%s'", PolicyDeny, PolicyAllow, PolicyRedact)
+	}
+	tools, err := ParseToolFilter(p.Tools)
+	if err != nil {
+		return cp, err
+	}
+	cp.tools = tools
+	for i, c := range p.When {
+		cc, err := compileCondition(c)
+		if err != nil {
+			return cp, fmt.Errorf("when[%d]: %w", i, err)
+		}
+		cp.when = append(cp.when, cc)
+	}
+	for i, raw := range p.Redact {
+		path, err := parseJSONPath(raw)
+		if err != nil {
+			return cp, fmt.Errorf("redact[%d]: %w", i, err)
+		}
+		cp.redact = append(cp.redact, path)
+	}
+	return cp, nil
+}
+
+// compileCondition compiles a condition's path and test.
+func compileCondition(c Condition) (condition, error) {
+	jp, err := parseJSONPath(c.Path)
+	if err != nil {
+		return condition{}, err
+	}
+	cc := condition{path: jp, not: c.Not}
+
+	tests := 0
+	if c.Equals != nil {
+		tests++
+		want := jsonKey(c.Equals)
+		cc.test = func(v any) bool { return jsonKey(v) == want }
+	}
+	if c.In != nil {
+		tests++
+		want := make([]string, len(c.In))
+		for i, v := range c.In {
+			want[i] = jsonKey(v)
+		}
+		cc.test = func(v any) bool { return slices.Contains(want, jsonKey(v)) }
+	}
+	if c.Glob != "" {
+		tests++
+		re, err := globRegexp(c.Glob)
+		if err != nil {
+			return cc, fmt.Errorf("invalid glob '%s': %v", c.Glob, err)
+		}
+		// Match the cleaned path, so that ".." cannot step out of a
+		// directory the glob covers, e.g. /workspace/../etc/passwd
+		cc.test = matchString(func(s string) bool { return re.MatchString(path.Clean(s)) })
+	}
+	if c.Regex != "" {
+		tests++
+		re, err := regexp.Compile(c.Regex)
+		if err != nil {
+			return cc, fmt.Errorf("invalid regex '%s': %v", c.Regex, err)
+		}
+		cc.test = matchString(re.MatchString)
+	}
+	if c.Contains != "" {
+		tests++
+		want := strings.ToLower(c.Contains)
+		cc.test = matchString(func(s string) bool { return strings.Contains(strings.ToLower(s), want) })
+	}
+	if c.Exists {
+		tests++
+	}
+	if tests != 1 {
+		return cc, fmt.Errorf("set exactly one of equals, in, glob, regex, contains or exists")
+	}
+	return cc, nil
+}
+
+// matchString adapts a string test to argument values; other types fail.
+func matchString(fn func(string) bool) func(any) bool {
+	return func(v any) bool {
+		s, ok := v.(string)
+		return ok && fn(s)
+	}
+}
+
+// jsonKey encodes a value as JSON so that YAML and JSON values compare
+// equal, e.g. the YAML int 5 and the JSON number 5.
+func jsonKey(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%#v", v)
+	}
+	return string(b)
+}
+
+// globRegexp compiles a glob in which * and ? do not match "/" and **
+// matches anything.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// holds reports whether the condition holds for a call's arguments.
+func (c *condition) holds(args map[string]any) bool {
+	values := c.path.selectValues(args)
+	if c.test == nil {
+		return (len(values) > 0) != c.not
+	}
+	for _, v := range values {
+		if c.test(v) != c.not {
+			return true
+		}
+	}
+	return false
+}
+
+// covers reports whether a call is in the policy's scope.
+func (p *compiledPolicy) covers(agent, server, tool string) bool {
+	if len(p.Agents) > 0 && !slices.Contains(p.Agents, agent) {
+		return false
+	}
+	if p.Server != "" && p.Server != server {
+		return false
+	}
+	return p.tools.Allows(tool)
+}
+
+// applies reports whether a call's arguments meet all of the policy's
+// conditions.
+func (p *compiledPolicy) applies(args map[string]any) bool {
+	for i := range p.when {
+		if !p.when[i].holds(args) {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate applies the policies to a call by an agent ("" for callers that
+// are not agents) to a server's tool, by its name on the server.
+func (s *PolicySet) Evaluate(agent, server, tool string, args map[string]any) PolicyDecision {
+	d := PolicyDecision{Arguments: args}
+	if s == nil {
+		return d
+	}
+
+	var allows []string
+	allowed := false
+	for i := range s.policies {
+		p := &s.policies[i]
+		if !p.covers(agent, server, tool) {
+			continue
+		}
+		switch p.Action {
+		case PolicyDeny:
+			if p.applies(args) {
+				d.Denied, d.Policy, d.Message = true, p.Name, p.Message
+				return d
+			}
+		case PolicyAllow:
+			allows = append(allows, p.Name)
+			allowed = allowed || p.applies(args)
+		}
+	}
+	if len(allows) > 0 && !allowed {
+		d.Denied = true
+		d.Message = fmt.Sprintf("no policy allows these arguments (checked %s)", strings.Join(allows, ", "))
+		return d
+	}
+
+	for i := range s.policies {
+		p := &s.policies[i]
+		if p.Action != PolicyRedact || !p.covers(agent, server, tool) || !p.applies(args) {
+			continue
+		}
+		if len(d.Redacted) == 0 {
+			d.Arguments, _ = deepCopy(args).(map[string]any)
+		}
+		for _, path := range p.redact {
+			path.replace(d.Arguments, PolicyRedacted)
+		}
+		d.Redacted = append(d.Redacted, p.Name)
+	}
+	return d
+}
+
+// deepCopy copies the maps and slices of a decoded JSON value.
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = deepCopy(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = deepCopy(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// jsonPath is a parsed path into tool arguments.
+type jsonPath []pathSegment
+
+// pathSegment selects an object field, an array index, or with wildcard,
+// every field or element.
+type pathSegment struct {
+	key      string
+	index    int // -1 for a field
+	wildcard bool
+}
+
+// parseJSONPath parses the JSONPath subset described on Condition.
+func parseJSONPath(raw string) (jsonPath, error) {
+	rest, ok := strings.CutPrefix(raw, "$")
+	if !ok {
+		return nil, fmt.Errorf("invalid path '%s': must start with '$'", raw)
+	}
+	var path jsonPath
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			switch name {
+			case "":
+				return nil, fmt.Errorf("invalid path '%s': empty field name", raw)
+			case "*":
+				path = append(path, pathSegment{index: -1, wildcard: true})
+			default:
+				path = append(path, pathSegment{key: name, index: -1})
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path '%s': missing ']'", raw)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				path = append(path, pathSegment{index: -1, wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				path = append(path, pathSegment{key: inner[1 : len(inner)-1], index: -1})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid path '%s': bad index '%s'", raw, inner)
+				}
+				path = append(path, pathSegment{index: n})
+			}
+		default:
+			return nil, fmt.Errorf("invalid path '%s': unexpected '%c'", raw, rest[0])
+		}
+	}
+	return path, nil
+}
+
+// selectValues returns the values at the path, in document order for
+// arrays.
+func (p jsonPath) selectValues(root any) []any {
+	values := []any{root}
+	for _, seg := range p {
+		var next []any
+		for _, v := range values {
+			next = append(next, seg.children(v)...)
+		}
+		values = next
+	}
+	return values
+}
+
+// children returns the values a segment selects from v.
+func (seg pathSegment) children(v any) []any {
+	switch v := v.(type) {
+	case map[string]any:
+		if seg.wildcard {
+			out := make([]any, 0, len(v))
+			for _, k := range sortedKeys(v) {
+				out = append(out, v[k])
+			}
+			return out
+		}
+		if e, ok := v[seg.key]; ok && seg.index < 0 {
+			return []any{e}
+		}
+	case []any:
+		if seg.wildcard {
+			return v
+		}
+		if seg.index >= 0 && seg.index < len(v) {
+			return []any{v[seg.index]}
+		}
+	}
+	return nil
+}
+
+// replace sets every value at the path to replacement, in place.
+func (p jsonPath) replace(root any, replacement any) {
+	if len(p) == 0 {
+		return
+	}
+	parents := p[:len(p)-1].selectValues(root)
+	last := p[len(p)-1]
+	for _, parent := range parents {
+		switch v := parent.(type) {
+		case map[string]any:
+			for k := range v {
+				if last.wildcard || (last.index < 0 && k == last.key) {
+					v[k] = replacement
+				}
+			}
+		case []any:
+			for i := range v {
+				if last.wildcard || i == last.index {
+					v[i] = replacement
+				}
+			}
+		}
+	}
+}
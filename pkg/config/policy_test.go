@@ -0,0 +1,196 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	args := map[string]any{
+		"path":       "/workspace/a.txt",
+		"repo-owner": "our-org",
+		"files": []any{
+			map[string]any{"name": "a"},
+			map[string]any{"name": "b"},
+		},
+		"opts": map[string]any{"y": 2.0, "x": 1.0},
+	}
+	tests := []struct {
+		path string
+		want []any
+	}{
+		{"$", []any{args}},
+		{"$.path", []any{"/workspace/a.txt"}},
+		{"$['repo-owner']", []any{"our-org"}},
+		{`$["repo-owner"]`, []any{"our-org"}},
+		{"$.files[1].name", []any{"b"}},
+		{"$.files[*].name", []any{"a", "b"}},
+		{"$.opts.*", []any{1.0, 2.0}},
+		{"$.files[5]", nil},
+		{"$.missing.name", nil},
+	}
+	for _, tc := range tests {
+		p, err := parseJSONPath(tc.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.path, err)
+			continue
+		}
+		if got := p.selectValues(args); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.path, got, tc.want)
+		}
+	}
+
+	for _, path := range []string{"", "path", "$.", "$.files[", "$.files[-1]", "$.files[x]", "$path"} {
+		if _, err := parseJSONPath(path); err == nil {
+			t.Errorf("expected error for path %q", path)
+		}
+	}
+}
+
+func TestCondition(t *testing.T) {
+	args := map[string]any{
+		"path":   "/workspace/src/main.go",
+		"escape": "/workspace/../etc/passwd",
+		"query":  "select * from users; drop table users",
+		"count":  5.0,
+		"files":  []any{"/workspace/a", "/etc/passwd"},
+	}
+	tests := []struct {
+		name string
+		cond Condition
+		want bool
+	}{
+		{"equals string", Condition{Path: "$.path", Equals: "/workspace/src/main.go"}, true},
+		{"equals yaml int", Condition{Path: "$.count", Equals: 5}, true},
+		{"in", Condition{Path: "$.count", In: []any{1, 5}}, true},
+		{"glob double star", Condition{Path: "$.path", Glob: "/workspace/**"}, true},
+		{"glob single star stops at slash", Condition{Path: "$.path", Glob: "/workspace/*"}, false},
+		{"glob cleans the path", Condition{Path: "$.escape", Glob: "/workspace/**"}, false},
+		{"glob matches the cleaned path", Condition{Path: "$.escape", Glob: "/etc/*"}, true},
+		{"glob on number", Condition{Path: "$.count", Glob: "*"}, false},
+		{"regex", Condition{Path: "$.query", Regex: `(?i)\bDROP\b`}, true},
+		{"contains ignores case", Condition{Path: "$.query", Contains: "DROP TABLE"}, true},
+		{"exists", Condition{Path: "$.path", Exists: true}, true},
+		{"not exists", Condition{Path: "$.owner", Exists: true, Not: true}, true},
+		{"any value passes", Condition{Path: "$.files[*]", Glob: "/workspace/**"}, true},
+		{"not holds if any value fails", Condition{Path: "$.files[*]", Glob: "/workspace/**", Not: true}, true},
+		{"missing path", Condition{Path: "$.owner", Equals: "x"}, false},
+		{"missing path negated", Condition{Path: "$.owner", Equals: "x", Not: true}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := compileCondition(tc.cond)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := c.holds(args); got != tc.want {
+				t.Errorf("holds() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	for _, c := range []Condition{
+		{Path: "$.a"},
+		{Path: "$.a", Glob: "*", Regex: "x"},
+		{Path: "$.a", Regex: "("},
+		{Path: "a", Equals: "x"},
+	} {
+		if _, err := compileCondition(c); err == nil {
+			t.Errorf("expected error for condition %+v", c)
+		}
+	}
+}
+
+func TestPolicySet_Evaluate(t *testing.T) {
+	set, err := CompilePolicies([]Policy{
+		{
+			Name:    "no-drop",
+			Action:  PolicyDeny,
+			Server:  "db",
+			When:    []Condition{{Path: "$.sql", Regex: `(?i)\bdrop\b`}},
+			Message: "DROP statements are not allowed",
+		},
+		{
+			Name:   "workspace-only",
+			Action: PolicyAllow,
+			Agents: []string{"coder"},
+			Server: "filesystem",
+			Tools:  []string{"write_*"},
+			When:   []Condition{{Path: "$.path", Glob: "/workspace/**"}},
+		},
+		{
+			Name:   "tmp-too",
+			Action: PolicyAllow,
+			Agents: []string{"coder"},
+			Server: "filesystem",
+			Tools:  []string{"write_file"},
+			When:   []Condition{{Path: "$.path", Glob: "/tmp/*"}},
+		},
+		{
+			Name:   "hide-password",
+			Action: PolicyRedact,
+			Server: "db",
+			When:   []Condition{{Path: "$.auth.password", Exists: true}},
+			Redact: []string{"$.auth.password", "$.hosts[*].token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d := set.Evaluate("", "db", "query", map[string]any{"sql": "DROP TABLE users"})
+	if !d.Denied || d.Policy != "no-drop" || d.Message != "DROP statements are not allowed" {
+		t.Errorf("expected no-drop to deny, got %+v", d)
+	}
+	if d := set.Evaluate("", "db", "query", map[string]any{"sql": "select dropped from t"}); d.Denied {
+		t.Errorf("expected a word containing drop to pass, got %+v", d)
+	}
+
+	// Allow policies cover only their agents and tools
+	for path, denied := range map[string]bool{
+		"/workspace/a/b":           false,
+		"/tmp/x":                   false,
+		"/etc/passwd":              true,
+		"/workspace/../etc/passwd": true,
+		"/tmp/../etc/passwd":       true,
+	} {
+		d := set.Evaluate("coder", "filesystem", "write_file", map[string]any{"path": path})
+		if d.Denied != denied {
+			t.Errorf("%s: expected denied %v, got %+v", path, denied, d)
+		}
+		if denied && !strings.Contains(d.Message, "checked workspace-only, tmp-too") {
+			t.Errorf("unexpected message: %q", d.Message)
+		}
+	}
+	if d := set.Evaluate("reviewer", "filesystem", "write_file", map[string]any{"path": "/etc/passwd"}); d.Denied {
+		t.Errorf("expected other agents to be unaffected, got %+v", d)
+	}
+	if d := set.Evaluate("coder", "filesystem", "read_file", map[string]any{"path": "/etc/passwd"}); d.Denied {
+		t.Errorf("expected other tools to be unaffected, got %+v", d)
+	}
+
+	// Redaction copies the arguments
+	args := map[string]any{
+		"sql":   "select 1",
+		"auth":  map[string]any{"user": "app", "password": "s3cret"},
+		"hosts": []any{map[string]any{"token": "t1"}, map[string]any{"name": "h2"}},
+	}
+	d = set.Evaluate("", "db", "query", args)
+	want := map[string]any{
+		"sql":   "select 1",
+		"auth":  map[string]any{"user": "app", "password": PolicyRedacted},
+		"hosts": []any{map[string]any{"token": PolicyRedacted}, map[string]any{"name": "h2"}},
+	}
+	if d.Denied || !reflect.DeepEqual(d.Arguments, want) || !reflect.DeepEqual(d.Redacted, []string{"hide-password"}) {
+		t.Errorf("unexpected redaction: %+v", d)
+	}
+	if args["auth"].(map[string]any)["password"] != "s3cret" {
+		t.Error("expected the caller's arguments to be unchanged")
+	}
+
+	var none *PolicySet
+	if d := none.Evaluate("coder", "db", "query", args); d.Denied || !reflect.DeepEqual(d.Arguments, args) {
+		t.Errorf("expected nil set to allow, got %+v", d)
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlField is a struct field as it appears in YAML.
+type yamlField struct {
+	Name string
+	Type reflect.Type
+}
+
+// yamlFields returns the fields of a struct type by YAML key, including
+// the fields of inlined structs, in declaration order.
+func yamlFields(t reflect.Type) []yamlField {
+	var fields []yamlField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		switch {
+		case name == "-":
+			continue
+		case strings.Contains(opts, "inline"):
+			fields = append(fields, yamlFields(f.Type)...)
+			continue
+		case name == "":
+			name = strings.ToLower(f.Name)
+		}
+		fields = append(fields, yamlField{Name: name, Type: f.Type})
+	}
+	return fields
+}
+
+// checkFields reports the keys in n that do not match a field of type t.
+// Keys starting with x- are extensions and allowed anywhere. Nodes whose
+// shape does not fit t are left for decoding to report.
+func checkFields(n *yaml.Node, t reflect.Type, path string) ValidationErrors {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var errs ValidationErrors
+	switch {
+	case t.Kind() == reflect.Struct && n.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		types := make(map[string]reflect.Type, len(fields))
+		names := make([]string, len(fields))
+		for i, f := range fields {
+			types[f.Name] = f.Type
+			names[i] = f.Name
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			field := joinField(path, key)
+			if ft, ok := types[key]; ok {
+				errs = append(errs, checkFields(n.Content[i+1], ft, field)...)
+			} else if !strings.HasPrefix(key, "x-") {
+				errs = append(errs, ValidationError{Field: field, Message: unknownFieldMessage(key, names)})
+			}
+		}
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && n.Kind == yaml.SequenceNode:
+		for i, item := range n.Content {
+			errs = append(errs, checkFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case t.Kind() == reflect.Map && n.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			errs = append(errs, checkFields(n.Content[i+1], t.Elem(), joinField(path, n.Content[i].Value))...)
+		}
+	}
+	return errs
+}
+
+// unknownFieldMessage describes an unknown key, suggesting the known field
+// it most likely meant.
+func unknownFieldMessage(key string, names []string) string {
+	msg := fmt.Sprintf("unknown field '%s'", key)
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(s))
+	}
+	best, bestDist := "", 3 // Suggest only close matches
+	for _, name := range names {
+		d := editDistance(key, name)
+		if normalize(key) == normalize(name) {
+			d = 0
+		}
+		if d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	if best != "" {
+		msg += fmt.Sprintf(" (did you mean '%s'?)", best)
+	}
+	return msg
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// SchemaID identifies the stack file JSON Schema.
+const SchemaID = "https://gridctl.dev/schema/stack.json"
+
+var (
+	stringSchema  = map[string]any{"type": "string"}
+	stringsSchema = map[string]any{"type": "array", "items": stringSchema}
+	extensions    = map[string]any{"^x-": map[string]any{}}
+)
+
+// shorthandSchemas lists the forms that types with custom YAML
+// unmarshaling accept in addition to their own.
+var shorthandSchemas = map[reflect.Type][]any{
+	reflect.TypeOf(ToolSelector{}): {stringSchema},
+	reflect.TypeOf(Dependency{}):   {stringSchema},
+	reflect.TypeOf(Egress{}):       {stringSchema, stringsSchema},
+	reflect.TypeOf(EnvFiles{}):     {stringSchema},
+	reflect.TypeOf(Include{}):      {stringSchema},
+}
+
+// replacedSchemas are the schemas of types whose YAML form is unrelated
+// to their Go fields.
+var replacedSchemas = map[reflect.Type]any{
+	reflect.TypeOf(ToolPrefix{}): map[string]any{"type": []string{"boolean", "string"}},
+}
+
+// JSONSchema returns a JSON Schema (draft 2020-12) for stack files,
+// generated from the Stack type, for editors to validate and complete
+// stack files with.
+func JSONSchema() ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]any)}
+	g.schema(reflect.TypeOf(Stack{}))
+
+	// Composition keys are merged away before a stack is parsed
+	include := g.schema(reflect.TypeOf(Include{}))
+	stack := g.defs["Stack"].(map[string]any)
+	props := stack["properties"].(map[string]any)
+	props[keyExtends] = include
+	props[keyInclude] = map[string]any{"type": "array", "items": include}
+	props[keyOverlays] = map[string]any{
+		"type":                 "object",
+		"additionalProperties": map[string]any{"$ref": "#/$defs/Stack"},
+	}
+	for _, name := range []string{"MCPServer", "Resource", "Agent", "A2AAgent"} {
+		g.defs[name].(map[string]any)["properties"].(map[string]any)[keyExtends] = stringSchema
+	}
+
+	return json.MarshalIndent(map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id":     SchemaID,
+		"title":   "gridctl stack",
+		"$ref":    "#/$defs/Stack",
+		"$defs":   g.defs,
+	}, "", "  ")
+}
+
+// schemaGenerator builds schemas for Go types, with each named struct
+// defined once under $defs.
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if s, ok := replacedSchemas[t]; ok {
+		return s
+	}
+	var s any
+	switch t.Kind() {
+	case reflect.String:
+		s = stringSchema
+	case reflect.Bool:
+		s = map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		s = map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		s = map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		s = map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		s = g.structSchema(t)
+	default:
+		s = map[string]any{} // Any value
+	}
+	if alts, ok := shorthandSchemas[t]; ok {
+		s = map[string]any{"anyOf": append([]any{s}, alts...)}
+	}
+	return s
+}
+
+// structSchema defines a struct type under $defs and returns a reference
+// to it.
+func (g *schemaGenerator) structSchema(t reflect.Type) any {
+	name := t.Name()
+	ref := map[string]any{"$ref": "#/$defs/" + name}
+	if _, ok := g.defs[name]; ok {
+		return ref
+	}
+	props := make(map[string]any)
+	def := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"patternProperties":    extensions,
+		"additionalProperties": false,
+	}
+	g.defs[name] = def // Before the fields, for recursive types
+
+	fields := yamlFields(t)
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	for _, f := range fields {
+		props[f.Name] = g.schema(f.Type)
+	}
+	return ref
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoadStack_UnknownFields(t *testing.T) {
+	content := `name: test
+network:
+  name: test-net
+mcp-servers:
+  - name: github
+    image: ghcr.io/github/server:latest
+    port: 3000
+    tool: ["get_issue"]
+    x-notes: extensions are allowed
+  - name: remote
+    command: ["server"]
+    ssh:
+      host: example.com
+      user: me
+      identity_file: ~/.ssh/id_ed25519
+agents:
+  - name: agent
+    image: agent:latest
+    uses:
+      - server: github
+        tols: ["get_issue"]
+`
+	path := writeTempFile(t, content)
+	_, err := LoadStack(path)
+	if err == nil {
+		t.Fatal("expected unknown fields to fail validation")
+	}
+	for _, want := range []string{
+		path + ":8:5: mcp-servers[0].tool: unknown field 'tool' (did you mean 'tools'?)",
+		path + ":15:7: mcp-servers[1].ssh.identity_file: unknown field 'identity_file' (did you mean 'identityFile'?)",
+		path + ":21:9: agents[0].uses[0].tols: unknown field 'tols' (did you mean 'tools'?)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got: %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "x-notes") {
+		t.Errorf("expected x- keys to be allowed, got: %v", err)
+	}
+}
+
+func TestLoadStack_ErrorPositions(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"servers.yaml": "mcp-servers:\n  - name: github\n    port: 3000\n",
+		"stack.yaml":   "name: test\ninclude: [servers.yaml]\nnetwork:\n  name: test-net\n",
+	})
+	_, err := LoadStack(filepath.Join(dir, "stack.yaml"))
+	errs, ok := err.(ValidationErrors)
+	if !ok || len(errs) == 0 {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	e := errs[0]
+	if e.File != filepath.Join(dir, "servers.yaml") || e.Line != 2 || e.Column != 5 {
+		t.Errorf("expected the error at servers.yaml:2:5, got %s:%d:%d (%v)", e.File, e.Line, e.Column, e)
+	}
+}
+
+func TestJSONSchema_Examples(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema failed: %v", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	paths, err := filepath.Glob("../../examples/*/*.yaml")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no examples found: %v", err)
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var doc any
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		for _, problem := range validateSchema(schema, schema, doc, "") {
+			t.Errorf("%s: %s", path, problem)
+		}
+	}
+
+	// Typos are caught by the schema too
+	problems := validateSchema(schema, schema, map[string]any{"mcp-servers": []any{map[string]any{"name": "a", "tool": []any{}}}}, "")
+	if len(problems) != 1 || !strings.Contains(problems[0], "mcp-servers[0].tool") {
+		t.Errorf("expected an unknown property problem, got %v", problems)
+	}
+}
+
+// validateSchema checks v against the JSON Schema keywords JSONSchema
+// generates, returning a problem for each mismatch.
+func validateSchema(root, s map[string]any, v any, path string) []string {
+	if ref, ok := s["$ref"].(string); ok {
+		defs := root["$defs"].(map[string]any)
+		return validateSchema(root, defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any), v, path)
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok {
+		for _, alt := range anyOf {
+			if len(validateSchema(root, alt.(map[string]any), v, path)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: matches none of the allowed forms", path)}
+	}
+
+	if typ, ok := s["type"]; ok {
+		var types []any
+		if list, ok := typ.([]any); ok {
+			types = list
+		} else {
+			types = []any{typ}
+		}
+		matched := false
+		for _, t := range types {
+			switch t {
+			case "string":
+				_, matched = v.(string)
+			case "boolean":
+				_, matched = v.(bool)
+			case "integer":
+				_, matched = v.(int)
+			case "number":
+				switch v.(type) {
+				case int, float64:
+					matched = true
+				}
+			case "array":
+				_, matched = v.([]any)
+			case "object":
+				_, matched = v.(map[string]any)
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			return []string{fmt.Sprintf("%s: expected %v, got %T", path, typ, v)}
+		}
+	}
+
+	var problems []string
+	switch v := v.(type) {
+	case []any:
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, item := range v {
+				problems = append(problems, validateSchema(root, items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case map[string]any:
+		props, _ := s["properties"].(map[string]any)
+		patterns, _ := s["patternProperties"].(map[string]any)
+		for key, value := range v {
+			field := joinField(path, key)
+			if p, ok := props[key].(map[string]any); ok {
+				problems = append(problems, validateSchema(root, p, value, field)...)
+				continue
+			}
+			matched := false
+			for pattern := range patterns {
+				if regexp.MustCompile(pattern).MatchString(key) {
+					matched = true
+				}
+			}
+			switch additional := s["additionalProperties"].(type) {
+			case bool:
+				if !additional && !matched {
+					problems = append(problems, fmt.Sprintf("%s: unknown property", field))
+				}
+			case map[string]any:
+				if !matched {
+					problems = append(problems, validateSchema(root, additional, value, field)...)
+				}
+			}
+		}
+	}
+	return problems
+}
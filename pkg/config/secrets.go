@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Secret providers.
+const (
+	SecretProviderStore = "store" // The local encrypted store, managed with "gridctl secret"
+	SecretProviderEnv   = "env"   // An environment variable of the gridctl process
+	SecretProviderFile  = "file"  // The contents of a file
+	SecretProviderExec  = "exec"  // The output of a command
+)
+
+// Secret declares where the value of a secret comes from. Stack files
+// refer to secrets as ${secret:name} in env values, headers, MCP server
+// URLs and A2A auth tokens; references are replaced with their values
+// when the stack starts. Secrets that are referenced but not declared are
+// read from the local encrypted store.
+type Secret struct {
+	Provider string   `yaml:"provider,omitempty"` // store (default), env, file, or exec
+	Key      string   `yaml:"key,omitempty"`      // Key in the encrypted store (default: the secret name)
+	Env      string   `yaml:"env,omitempty"`      // Environment variable (default: the secret name, upper-cased)
+	Path     string   `yaml:"path,omitempty"`     // File to read, relative to the stack file
+	Command  []string `yaml:"command,omitempty"`  // Command whose output, less surrounding whitespace, is the value
+}
+
+// ProviderName returns the secret's provider, defaulting to the store.
+func (s Secret) ProviderName() string {
+	if s.Provider == "" {
+		return SecretProviderStore
+	}
+	return s.Provider
+}
+
+// StoreKey returns the key the secret is kept under in the encrypted store.
+func (s Secret) StoreKey(name string) string {
+	if s.Key != "" {
+		return s.Key
+	}
+	return name
+}
+
+// EnvVar returns the environment variable the secret is read from.
+func (s Secret) EnvVar(name string) string {
+	if s.Env != "" {
+		return s.Env
+	}
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+var (
+	secretRefPattern  = regexp.MustCompile(`\$\{secret:([^}]*)\}`)
+	secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// ValidSecretName reports whether name can be used in a ${secret:name}
+// reference.
+func ValidSecretName(name string) bool {
+	return secretNamePattern.MatchString(name)
+}
+
+// secretRefPrefix marks a secret reference inside ${...}.
+const secretRefPrefix = "secret:"
+
+// SecretRefs returns the names of the secrets the stack refers to, sorted.
+func (s *Stack) SecretRefs() []string {
+	seen := make(map[string]bool)
+	rewriteSecretFields(s, func(_, value string) string {
+		for _, m := range secretRefPattern.FindAllStringSubmatch(value, -1) {
+			seen[m[1]] = true
+		}
+		return value
+	})
+	return sortedKeys(seen)
+}
+
+// ResolveSecrets replaces secret references in the stack with the values
+// returned by lookup.
+func ResolveSecrets(s *Stack, lookup func(name string) (string, error)) error {
+	var firstErr error
+	rewriteSecretFields(s, func(field, value string) string {
+		return secretRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+			name := secretRefPattern.FindStringSubmatch(ref)[1]
+			v, err := lookup(name)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: secret '%s': %w", field, name, err)
+				}
+				return ref
+			}
+			return v
+		})
+	})
+	return firstErr
+}
+
+// rewriteSecretFields calls fn on each field that may refer to secrets and
+// stores the value it returns.
+func rewriteSecretFields(s *Stack, fn func(field, value string) string) {
+	rewriteMap := func(prefix string, m map[string]string) {
+		for _, k := range sortedKeys(m) {
+			m[k] = fn(prefix+"."+k, m[k])
+		}
+	}
+
+	for i := range s.MCPServers {
+		prefix := fmt.Sprintf("mcp-servers[%d]", i)
+		s.MCPServers[i].URL = fn(prefix+".url", s.MCPServers[i].URL)
+		rewriteMap(prefix+".env", s.MCPServers[i].Env)
+		rewriteMap(prefix+".headers", s.MCPServers[i].Headers)
+	}
+	for i := range s.Resources {
+		rewriteMap(fmt.Sprintf("resources[%d].env", i), s.Resources[i].Env)
+	}
+	for i := range s.Agents {
+		rewriteMap(fmt.Sprintf("agents[%d].env", i), s.Agents[i].Env)
+	}
+	for i := range s.A2AAgents {
+		if s.A2AAgents[i].Auth != nil {
+			s.A2AAgents[i].Auth.Token = fn(fmt.Sprintf("a2a-agents[%d].auth.token", i), s.A2AAgents[i].Auth.Token)
+		}
+	}
+	rewriteMap("tracing.headers", s.Tracing.Headers)
+}
+
+// validateSecrets checks secret declarations and the names of referenced
+// secrets.
+func validateSecrets(s *Stack) ValidationErrors {
+	var errs ValidationErrors
+	for _, name := range sortedKeys(s.Secrets) {
+		secret := s.Secrets[name]
+		prefix := "secrets." + name
+		if !ValidSecretName(name) {
+			errs = append(errs, ValidationError{Field: prefix, Message: "name may only contain letters, digits, '_', '.' and '-'"})
+		}
+
+		provider := secret.ProviderName()
+		switch provider {
+		case SecretProviderStore, SecretProviderEnv:
+		case SecretProviderFile:
+			if secret.Path == "" {
+				errs = append(errs, ValidationError{Field: prefix + ".path", Message: "is required for provider 'file'"})
+			}
+		case SecretProviderExec:
+			if len(secret.Command) == 0 {
+				errs = append(errs, ValidationError{Field: prefix + ".command", Message: "is required for provider 'exec'"})
+			}
+		default:
+			errs = append(errs, ValidationError{Field: prefix + ".provider", Message: "must be 'store', 'env', 'file', or 'exec'"})
+			continue
+		}
+
+		for _, f := range []struct {
+			field, provider string
+			set             bool
+		}{
+			{"key", SecretProviderStore, secret.Key != ""},
+			{"env", SecretProviderEnv, secret.Env != ""},
+			{"path", SecretProviderFile, secret.Path != ""},
+			{"command", SecretProviderExec, len(secret.Command) > 0},
+		} {
+			if f.set && provider != f.provider {
+				errs = append(errs, ValidationError{Field: prefix + "." + f.field, Message: fmt.Sprintf("only applies to provider '%s'", f.provider)})
+			}
+		}
+	}
+
+	for _, name := range s.SecretRefs() {
+		if !ValidSecretName(name) {
+			errs = append(errs, ValidationError{Field: "secrets", Message: fmt.Sprintf("invalid secret reference '${secret:%s}'", name)})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	s := &Stack{
+		MCPServers: []MCPServer{{
+			Name:    "github",
+			URL:     "https://example.com/mcp?key=${secret:api_key}",
+			Headers: map[string]string{"Authorization": "Bearer ${secret:github_token}"},
+		}},
+		Agents:    []Agent{{Name: "coder", Env: map[string]string{"TOKEN": "${secret:github_token}", "MODE": "dev"}}},
+		A2AAgents: []A2AAgent{{Name: "remote", Auth: &A2AAuth{Type: "bearer", Token: "${secret:remote}"}}},
+		Tracing:   Tracing{Headers: map[string]string{"x-api-key": "${secret:api_key}"}},
+	}
+	values := map[string]string{"api_key": "k1", "github_token": "ghp_x", "remote": "r1"}
+	var lookups []string
+	err := ResolveSecrets(s, func(name string) (string, error) {
+		lookups = append(lookups, name)
+		return values[name], nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.MCPServers[0].URL != "https://example.com/mcp?key=k1" || s.MCPServers[0].Headers["Authorization"] != "Bearer ghp_x" {
+		t.Errorf("unexpected server: %+v", s.MCPServers[0])
+	}
+	if s.Agents[0].Env["TOKEN"] != "ghp_x" || s.Agents[0].Env["MODE"] != "dev" {
+		t.Errorf("unexpected agent env: %v", s.Agents[0].Env)
+	}
+	if s.A2AAgents[0].Auth.Token != "r1" || s.Tracing.Headers["x-api-key"] != "k1" {
+		t.Errorf("unexpected auth token or tracing headers: %q %v", s.A2AAgents[0].Auth.Token, s.Tracing.Headers)
+	}
+	if len(lookups) != 5 {
+		t.Errorf("expected 5 lookups, got %v", lookups)
+	}
+
+	s = &Stack{Resources: []Resource{{Name: "db", Env: map[string]string{"PASSWORD": "${secret:db}"}}}}
+	err = ResolveSecrets(s, func(name string) (string, error) { return "", errors.New("not set") })
+	if err == nil || !strings.Contains(err.Error(), "resources[0].env.PASSWORD: secret 'db': not set") {
+		t.Errorf("expected lookup error, got %v", err)
+	}
+}
+
+func TestSecret_Defaults(t *testing.T) {
+	var s Secret
+	if s.ProviderName() != SecretProviderStore || s.StoreKey("gh") != "gh" || s.EnvVar("github-token.v2") != "GITHUB_TOKEN_V2" {
+		t.Errorf("unexpected defaults: %q %q %q", s.ProviderName(), s.StoreKey("gh"), s.EnvVar("github-token.v2"))
+	}
+	s = Secret{Provider: SecretProviderEnv, Env: "GH_TOKEN", Key: "k"}
+	if s.EnvVar("gh") != "GH_TOKEN" || s.StoreKey("gh") != "k" {
+		t.Errorf("unexpected values: %q %q", s.EnvVar("gh"), s.StoreKey("gh"))
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Tool selector patterns accepted in MCPServer.Tools and ToolSelector.Tools:
+//
+//	get_file_contents   exact tool name
+//	get_*               glob (path.Match syntax: *, ?, [...])
+//	re:^(get|list)_     regular expression (Go syntax, unanchored)
+//	!delete_*           exclusion; any of the forms above prefixed with "!"
+//
+// A tool is allowed if it matches at least one include pattern and no
+// exclusion. A list made only of exclusions starts from all tools.
+
+// ToolFilter matches tool names against a list of selector patterns.
+// A nil ToolFilter allows every tool.
+type ToolFilter struct {
+	patterns []toolPattern
+	includes int // Number of non-exclusion patterns
+}
+
+// toolPattern is a single compiled selector pattern.
+type toolPattern struct {
+	raw     string         // Pattern as written, including any "!" prefix
+	exclude bool           // "!" prefix
+	exact   string         // Exact name (no glob metacharacters)
+	glob    string         // Glob pattern
+	re      *regexp.Regexp // Regular expression ("re:" prefix)
+}
+
+// ParseToolFilter compiles selector patterns into a ToolFilter.
+// An empty list returns nil, which allows every tool.
+func ParseToolFilter(patterns []string) (*ToolFilter, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	f := &ToolFilter{}
+	for _, raw := range patterns {
+		p, err := parseToolPattern(raw)
+		if err != nil {
+			return nil, err
+		}
+		if !p.exclude {
+			f.includes++
+		}
+		f.patterns = append(f.patterns, p)
+	}
+	return f, nil
+}
+
+// ValidateToolPattern reports whether a single selector pattern is well-formed.
+func ValidateToolPattern(raw string) error {
+	_, err := parseToolPattern(raw)
+	return err
+}
+
+// parseToolPattern compiles one selector pattern.
+func parseToolPattern(raw string) (toolPattern, error) {
+	body, exclude := strings.CutPrefix(raw, "!")
+	p := toolPattern{raw: raw, exclude: exclude}
+	if body == "" {
+		return p, fmt.Errorf("empty tool pattern")
+	}
+
+	if expr, ok := strings.CutPrefix(body, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return p, fmt.Errorf("invalid regex '%s': %v", expr, err)
+		}
+		p.re = re
+		return p, nil
+	}
+
+	if !strings.ContainsAny(body, `*?[\`) {
+		p.exact = body
+		return p, nil
+	}
+	if _, err := path.Match(body, ""); err != nil {
+		return p, fmt.Errorf("invalid glob '%s'", body)
+	}
+	p.glob = body
+	return p, nil
+}
+
+// match reports whether name matches the pattern.
+func (p *toolPattern) match(name string) bool {
+	switch {
+	case p.re != nil:
+		return p.re.MatchString(name)
+	case p.glob != "":
+		ok, _ := path.Match(p.glob, name)
+		return ok
+	default:
+		return p.exact == name
+	}
+}
+
+// Allows reports whether the filter allows a tool name.
+func (f *ToolFilter) Allows(name string) bool {
+	if f == nil {
+		return true
+	}
+	included := f.includes == 0
+	for i := range f.patterns {
+		p := &f.patterns[i]
+		if !p.match(name) {
+			continue
+		}
+		if p.exclude {
+			return false
+		}
+		included = true
+	}
+	return included
+}
+
+// Unmatched returns the patterns that match none of the given tool names,
+// in the order they were written. These usually indicate a typo.
+func (f *ToolFilter) Unmatched(names []string) []string {
+	if f == nil {
+		return nil
+	}
+	var unmatched []string
+	for i := range f.patterns {
+		found := false
+		for _, name := range names {
+			if f.patterns[i].match(name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, f.patterns[i].raw)
+		}
+	}
+	return unmatched
+}
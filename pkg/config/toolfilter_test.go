@@ -0,0 +1,62 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToolFilter_Allows(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		allowed  []string
+		denied   []string
+	}{
+		{name: "empty allows all", allowed: []string{"anything"}},
+		{name: "exact", patterns: []string{"get_file"}, allowed: []string{"get_file"}, denied: []string{"get_files", "list"}},
+		{name: "glob", patterns: []string{"get_*"}, allowed: []string{"get_file", "get_"}, denied: []string{"list_get_file"}},
+		{name: "exclusion only", patterns: []string{"!delete_*"}, allowed: []string{"get_file"}, denied: []string{"delete_repo"}},
+		{name: "exclusion wins", patterns: []string{"*_repo", "!delete_*"}, allowed: []string{"create_repo"}, denied: []string{"delete_repo", "get_file"}},
+		{name: "regex is unanchored", patterns: []string{"re:issue"}, allowed: []string{"list_issues", "issue"}, denied: []string{"list_prs"}},
+		{name: "anchored regex", patterns: []string{"re:^(get|list)_"}, allowed: []string{"get_a", "list_b"}, denied: []string{"forget_a"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseToolFilter(tc.patterns)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, name := range tc.allowed {
+				if !f.Allows(name) {
+					t.Errorf("expected %q to be allowed", name)
+				}
+			}
+			for _, name := range tc.denied {
+				if f.Allows(name) {
+					t.Errorf("expected %q to be denied", name)
+				}
+			}
+		})
+	}
+}
+
+func TestToolFilter_Unmatched(t *testing.T) {
+	f, err := ParseToolFilter([]string{"get_*", "re:^serch_", "!delete_*", "list_issues"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := f.Unmatched([]string{"get_file", "list_issues", "search_code"})
+	want := []string{"re:^serch_", "!delete_*"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmatched() = %v, want %v", got, want)
+	}
+}
+
+func TestParseToolFilter_Invalid(t *testing.T) {
+	for _, pattern := range []string{"", "!", "re:(", "get_[a"} {
+		if _, err := ParseToolFilter([]string{pattern}); err == nil {
+			t.Errorf("expected error for pattern %q", pattern)
+		}
+	}
+}
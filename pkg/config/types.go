@@ -1,25 +1,177 @@
 package config
 
-import "gopkg.in/yaml.v3"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"gopkg.in/yaml.v3"
+)
 
 // Stack represents the complete gridctl configuration.
 type Stack struct {
 	Version    string      `yaml:"version"`
 	Name       string      `yaml:"name"`
-	Network    Network     `yaml:"network"`              // Single network (simple mode)
-	Networks   []Network   `yaml:"networks,omitempty"`   // Multiple networks (advanced mode)
+	Network    Network     `yaml:"network"`            // Single network (simple mode)
+	Networks   []Network   `yaml:"networks,omitempty"` // Multiple networks (advanced mode)
 	MCPServers []MCPServer `yaml:"mcp-servers"`
-	Agents     []Agent     `yaml:"agents,omitempty"`     // Active agents that consume MCP tools
+	Agents     []Agent     `yaml:"agents,omitempty"` // Active agents that consume MCP tools
 	Resources  []Resource  `yaml:"resources,omitempty"`
-	A2AAgents  []A2AAgent  `yaml:"a2a-agents,omitempty"` // External A2A agents for agent-to-agent communication
+	A2AAgents  []A2AAgent  `yaml:"a2a-agents,omitempty"`  // External A2A agents for agent-to-agent communication
+	Volumes    []Volume    `yaml:"volumes,omitempty"`     // Named volumes shared by workloads
+	Security   string      `yaml:"security,omitempty"`    // Security profile: "default" or "strict"
+	ToolNaming ToolNaming  `yaml:"tool_naming,omitempty"` // How aggregated tool names are namespaced
+	Toolsets   []Toolset   `yaml:"toolsets,omitempty"`    // Tool groups that sessions enable at runtime
+	ToolMode   string      `yaml:"tool_mode,omitempty"`   // How tools are exposed: "all" (default) or "search"
+
+	CompositeTools []CompositeTool   `yaml:"composite_tools,omitempty"` // Tools that chain calls to other tools
+	Audit          Audit             `yaml:"audit,omitempty"`           // Tool call audit log
+	Tracing        Tracing           `yaml:"tracing,omitempty"`         // OpenTelemetry trace export
+	Approvals      Approvals         `yaml:"approvals,omitempty"`       // Human approval of tool calls
+	Policies       []Policy          `yaml:"policies,omitempty"`        // Rules on tool call arguments
+	Secrets        map[string]Secret `yaml:"secrets,omitempty"`         // Where ${secret:name} references get their values
+	EnvFile        EnvFiles          `yaml:"env_file,omitempty"`        // .env files with variables for ${VAR} expansion
+
+	loadErrors   ValidationErrors // Unknown fields and missing required variables found while loading, reported by Validate
+	loadWarnings ValidationErrors // Unset variables expanded to empty strings while loading
+}
+
+// LoadWarnings returns the problems found while loading the stack that did
+// not keep it from loading: variables that were unset and so expanded to
+// empty strings.
+func (s *Stack) LoadWarnings() ValidationErrors {
+	return s.loadWarnings
+}
+
+// Audit configures the tool call audit log, written as JSON lines to
+// ~/.gridctl/logs/<stack>-audit.jsonl.
+type Audit struct {
+	Enabled  *bool    `yaml:"enabled,omitempty"`   // Record tool calls (default: true)
+	Redact   []string `yaml:"redact,omitempty"`    // Argument field names or globs to redact (default: common secret names)
+	MaxSize  string   `yaml:"max_size,omitempty"`  // Rotate the file after this size, e.g. "10m" (default: 10m)
+	MaxFiles int      `yaml:"max_files,omitempty"` // Rotated files to keep (default: 5)
 }
 
+// IsEnabled reports whether tool calls are recorded.
+func (a *Audit) IsEnabled() bool {
+	return a.Enabled == nil || *a.Enabled
+}
+
+// MaxSizeBytes returns the rotation size in bytes (0 if unset).
+func (a *Audit) MaxSizeBytes() (int64, error) {
+	if a.MaxSize == "" {
+		return 0, nil
+	}
+	return units.RAMInBytes(a.MaxSize)
+}
+
+// Tracing configures OpenTelemetry trace export. Tracing is on when an
+// endpoint or file is set.
+type Tracing struct {
+	Endpoint    string            `yaml:"endpoint,omitempty"`     // OTLP/HTTP collector URL, e.g. http://localhost:4318
+	Headers     map[string]string `yaml:"headers,omitempty"`      // Headers sent to the collector
+	File        string            `yaml:"file,omitempty"`         // Write spans as JSON lines to this file
+	ServiceName string            `yaml:"service_name,omitempty"` // service.name of the spans (default: gridctl)
+	SampleRatio *float64          `yaml:"sample_ratio,omitempty"` // Fraction of traces recorded (default: 1)
+}
+
+// IsEnabled reports whether spans are exported.
+func (t *Tracing) IsEnabled() bool {
+	return t.Endpoint != "" || t.File != ""
+}
+
+// Ratio returns the fraction of new traces recorded.
+func (t *Tracing) Ratio() float64 {
+	if t.SampleRatio == nil {
+		return 1
+	}
+	return *t.SampleRatio
+}
+
+// Approvals configures how tool calls that need human approval wait for
+// it. Which calls need approval is set by require_approval on MCP servers
+// and, optionally, the servers' destructiveHint tool annotations.
+type Approvals struct {
+	Timeout     string `yaml:"timeout,omitempty"`     // Reject calls not decided within this long (default: 5m)
+	Destructive bool   `yaml:"destructive,omitempty"` // Also require approval for tools annotated destructiveHint
+	Elicit      bool   `yaml:"elicit,omitempty"`      // Also ask the calling client via MCP elicitation, if it supports it (default: false)
+}
+
+// TimeoutDuration returns how long calls wait for a decision (0 if unset).
+func (a *Approvals) TimeoutDuration() (time.Duration, error) {
+	if a.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(a.Timeout)
+}
+
+// Tool modes control how the gateway lists tools to clients.
+const (
+	ToolModeAll    = "all"    // Every tool is listed
+	ToolModeSearch = "search" // Only search_tools and call_tool are listed
+)
+
+// BuiltinToolPrefix is the prefix of tools provided by the gateway itself.
+// No server or agent may use it while the gateway exposes built-in tools.
+const BuiltinToolPrefix = "gridctl"
+
+// HasBuiltinTools reports whether the gateway adds its own tools, which
+// happens with toolsets, search mode, or composite tools.
+func (s *Stack) HasBuiltinTools() bool {
+	return len(s.Toolsets) > 0 || s.ToolMode == ToolModeSearch || len(s.CompositeTools) > 0
+}
+
+// Toolset is a named group of tools that a client session can enable or
+// disable at runtime. Tools in any toolset stay hidden until one of their
+// toolsets is enabled; tools in no toolset are always listed.
+type Toolset struct {
+	Name        string         `yaml:"name"`
+	Description string         `yaml:"description,omitempty"`
+	Tools       []ToolSelector `yaml:"tools"` // Servers and tool patterns, as in agent 'uses'
+}
+
+// Tool naming defaults match the "server__tool" scheme and the 64-character
+// tool name limit common to MCP clients.
+const (
+	DefaultToolSeparator     = "__"
+	DefaultToolNameMaxLength = 64
+)
+
+// ToolNaming controls how the gateway namespaces tool names by server.
+type ToolNaming struct {
+	Separator string `yaml:"separator,omitempty"`  // Between server prefix and tool name (default: "__")
+	MaxLength int    `yaml:"max_length,omitempty"` // Longer names are truncated with a hash suffix (default: 64)
+}
+
+// Security profiles apply hardened defaults to container workloads.
+const (
+	SecurityDefault = "default" // No defaults beyond what each workload sets
+	SecurityStrict  = "strict"  // Read-only rootfs, all capabilities dropped, no-new-privileges, limits
+)
+
+// Defaults applied by the strict security profile when a workload leaves them unset.
+const (
+	StrictCPUs      = 1.0
+	StrictMemory    = "512m"
+	StrictPidsLimit = 256
+)
+
 // Network defines the Docker network configuration.
 type Network struct {
 	Name   string `yaml:"name"`
 	Driver string `yaml:"driver"`
 }
 
+// Volume declares a named volume that workloads can mount by name.
+// Named volumes are labeled with the stack and removed by "destroy --volumes".
+type Volume struct {
+	Name       string            `yaml:"name"`
+	Driver     string            `yaml:"driver,omitempty"`      // Volume driver (default: "local")
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty"` // Driver-specific options
+}
+
 // MCPServer defines an MCP server (container-based or external).
 type MCPServer struct {
 	Name      string            `yaml:"name"`
@@ -30,18 +182,288 @@ type MCPServer struct {
 	Transport string            `yaml:"transport,omitempty"` // "http" (default), "stdio", or "sse"
 	Command   []string          `yaml:"command,omitempty"`   // Override container command or remote command for SSH
 	Env       map[string]string `yaml:"env,omitempty"`
+	EnvFile   EnvFiles          `yaml:"env_file,omitempty"` // .env files with more env variables (env takes precedence)
+	Headers   map[string]string `yaml:"headers,omitempty"`  // HTTP headers sent to external servers (e.g. Authorization)
 	BuildArgs map[string]string `yaml:"build_args,omitempty"`
-	Network   string            `yaml:"network,omitempty"`   // Network to join (for multi-network mode)
-	SSH       *SSHConfig        `yaml:"ssh,omitempty"`       // SSH connection config for remote servers
-	Tools     []string          `yaml:"tools,omitempty"`     // Tool whitelist (empty = all tools exposed)
+	Network   string            `yaml:"network,omitempty"`    // Network to join (for multi-network mode)
+	SSH       *SSHConfig        `yaml:"ssh,omitempty"`        // SSH connection config for remote servers
+	Tools     []string          `yaml:"tools,omitempty"`      // Tool patterns: names, globs, "re:" regexes, "!" exclusions (empty = all tools exposed)
+	DependsOn []Dependency      `yaml:"depends_on,omitempty"` // Workloads that must be ready before this server starts
+	Volumes   []string          `yaml:"volumes,omitempty"`    // Mounts: "host-path:container-path[:ro|rw]" or "volume-name:container-path[:ro|rw]"
+	WorkDir   string            `yaml:"workdir,omitempty"`    // Working directory (container path, or host path for local process servers)
+	Tmpfs     []string          `yaml:"tmpfs,omitempty"`      // tmpfs mounts: "container-path[:options]"
+	Egress    *Egress           `yaml:"egress,omitempty"`     // Outbound network policy (default: unrestricted)
+
+	Prefix        *ToolPrefix             `yaml:"prefix,omitempty"`         // Tool name prefix (default: server name)
+	ToolOverrides map[string]ToolOverride `yaml:"tool_overrides,omitempty"` // Original tool name -> how it is exposed
+
+	// Timeout, concurrency and rate limit for all calls to the server, and
+	// for calls to individual tools (by original tool name)
+	CallLimits `yaml:",inline"`
+	ToolLimits map[string]CallLimits `yaml:"tool_limits,omitempty"`
+
+	Cache     *Cache               `yaml:"cache,omitempty"`      // Reuse results of identical calls to the server's tools
+	ToolCache map[string]ToolCache `yaml:"tool_cache,omitempty"` // Original tool name -> caching of that tool's results
+
+	RequireApproval []string `yaml:"require_approval,omitempty"` // Tool patterns whose calls wait for human approval
+
+	ContainerSecurity `yaml:",inline"`
+}
+
+// ToolPrefix sets the prefix on a server's exposed tool names.
+// Supports "prefix: false" to expose tools unprefixed, or a string alias
+// such as "prefix: gh" to use in place of the server name.
+type ToolPrefix struct {
+	Disabled bool
+	Alias    string
+}
+
+// UnmarshalYAML implements custom unmarshaling for ToolPrefix.
+func (p *ToolPrefix) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: prefix must be false or a string", node.Line)
+	}
+	if node.Tag == "!!bool" {
+		var enabled bool
+		if err := node.Decode(&enabled); err != nil {
+			return err
+		}
+		*p = ToolPrefix{Disabled: !enabled}
+		return nil
+	}
+	*p = ToolPrefix{Alias: node.Value}
+	return nil
+}
+
+// ToolNamePrefix returns the prefix for the server's exposed tool names,
+// or "" if its tools are exposed unprefixed.
+func (s *MCPServer) ToolNamePrefix() string {
+	if s.Prefix == nil {
+		return s.Name
+	}
+	if s.Prefix.Disabled {
+		return ""
+	}
+	if s.Prefix.Alias != "" {
+		return s.Prefix.Alias
+	}
+	return s.Name
+}
+
+// ToolOverride customizes how one of a server's tools is exposed through the
+// gateway. Calls to the exposed names are routed back to the original tool.
+type ToolOverride struct {
+	Name              string         `yaml:"name,omitempty"`               // Exposed name, replacing the original
+	Aliases           []string       `yaml:"aliases,omitempty"`            // Additional exposed names
+	Description       string         `yaml:"description,omitempty"`        // Replaces the server's description
+	DescriptionAppend string         `yaml:"description_append,omitempty"` // Appended to the description
+	HiddenParams      []string       `yaml:"hidden_params,omitempty"`      // Removed from the schema and dropped from calls
+	PinnedParams      map[string]any `yaml:"pinned_params,omitempty"`      // Removed from the schema and set on every call
+	Schema            map[string]any `yaml:"schema,omitempty"`             // Tightens the input schema (parameter constraints, "required", "additionalProperties: false")
+}
+
+// Cache configures reuse of a server's tool results for calls with the
+// same arguments. Tools the server annotates as neither read-only nor
+// idempotent are never cached.
+type Cache struct {
+	Enabled *bool  `yaml:"enabled,omitempty"`  // Cache results (default: true)
+	TTL     string `yaml:"ttl,omitempty"`      // How long a result is reused, e.g. "10m" (default: 5m)
+	MaxSize string `yaml:"max_size,omitempty"` // Memory for results, least recently used evicted first, e.g. "10m" (default: 10m)
+}
+
+// IsEnabled reports whether results are cached.
+func (c *Cache) IsEnabled() bool {
+	return c != nil && (c.Enabled == nil || *c.Enabled)
+}
+
+// TTLDuration returns how long results are reused (0 if unset).
+func (c *Cache) TTLDuration() (time.Duration, error) {
+	if c == nil || c.TTL == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.TTL)
+}
+
+// MaxSizeBytes returns the cache size in bytes (0 if unset).
+func (c *Cache) MaxSizeBytes() (int64, error) {
+	if c == nil || c.MaxSize == "" {
+		return 0, nil
+	}
+	return units.RAMInBytes(c.MaxSize)
+}
+
+// ToolCache turns caching on or off for one tool, and sets its TTL.
+type ToolCache struct {
+	Enabled *bool  `yaml:"enabled,omitempty"` // Cache this tool's results (default: true)
+	TTL     string `yaml:"ttl,omitempty"`     // Replaces the server's TTL
+}
+
+// IsEnabled reports whether the tool's results are cached.
+func (c *ToolCache) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// TTLDuration returns how long the tool's results are reused (0 if unset).
+func (c *ToolCache) TTLDuration() (time.Duration, error) {
+	if c.TTL == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.TTL)
+}
+
+// CallLimits guards tool calls to an MCP server, or to one of its tools.
+// Server and tool limits both apply; a tool's timeout replaces the server's.
+type CallLimits struct {
+	Timeout        string     `yaml:"timeout,omitempty"`         // Cancel calls after this long, e.g. "2m" (default: 30s)
+	MaxConcurrency int        `yaml:"max_concurrency,omitempty"` // Calls in flight at once; more are rejected (0 = unlimited)
+	RateLimit      *RateLimit `yaml:"rate_limit,omitempty"`      // Token bucket on the call rate
+}
+
+// IsSet returns true if any limit is configured.
+func (l *CallLimits) IsSet() bool {
+	return l.Timeout != "" || l.MaxConcurrency > 0 || l.RateLimit != nil
+}
+
+// TimeoutDuration returns the call timeout (0 if unset).
+func (l *CallLimits) TimeoutDuration() (time.Duration, error) {
+	if l.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(l.Timeout)
+}
+
+// RateLimit is a token bucket: calls spend a token, and tokens refill at
+// the given rate up to the burst size.
+type RateLimit struct {
+	Rate     string `yaml:"rate"`                // Calls per interval, e.g. "10/s", "100/m", "5/30s"
+	Burst    int    `yaml:"burst,omitempty"`     // Calls allowed back to back (default: calls per interval)
+	PerAgent bool   `yaml:"per_agent,omitempty"` // Separate bucket for each agent, or client session
+}
+
+// Parse returns the number of calls allowed per interval.
+func (r *RateLimit) Parse() (calls int, per time.Duration, err error) {
+	n, unit, ok := strings.Cut(r.Rate, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("rate must be '<calls>/<interval>', e.g. '10/s'")
+	}
+	calls, err = strconv.Atoi(strings.TrimSpace(n))
+	if err != nil || calls <= 0 {
+		return 0, 0, fmt.Errorf("invalid number of calls '%s'", n)
+	}
+	unit = strings.TrimSpace(unit)
+	if unit != "" && (unit[0] < '0' || unit[0] > '9') {
+		unit = "1" + unit // "s" means "1s"
+	}
+	per, err = time.ParseDuration(unit)
+	if err != nil || per <= 0 {
+		return 0, 0, fmt.Errorf("invalid interval '%s'", strings.TrimPrefix(unit, "1"))
+	}
+	return calls, per, nil
+}
+
+// BurstSize returns the bucket size, defaulting to the calls per interval.
+func (r *RateLimit) BurstSize() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	calls, _, err := r.Parse()
+	if err != nil {
+		return 1
+	}
+	return calls
+}
+
+// ExposedNames returns the names a tool is exposed under: its new name (or the
+// original if not renamed), followed by any aliases.
+func (o *ToolOverride) ExposedNames(tool string) []string {
+	name := tool
+	if o.Name != "" {
+		name = o.Name
+	}
+	return append([]string{name}, o.Aliases...)
+}
+
+// Egress modes restrict where a container MCP server can connect.
+const (
+	EgressNone      = "none"      // No network access at all
+	EgressInternal  = "internal"  // Other workloads in the stack only, no internet
+	EgressAllowlist = "allowlist" // Only the listed hosts, through an egress proxy
+)
+
+// Egress defines the outbound network policy for a container MCP server.
+// Supports a mode string, a list of allowed hosts, or object format.
+type Egress struct {
+	Mode  string   `yaml:"mode"`            // "none", "internal", or "allowlist"
+	Allow []string `yaml:"allow,omitempty"` // Allowed hosts, e.g. "api.github.com" or "*.githubusercontent.com"
+}
+
+// EgressMode returns the server's egress mode, or "" if unrestricted.
+func (s *MCPServer) EgressMode() string {
+	if s.Egress == nil {
+		return ""
+	}
+	return s.Egress.Mode
+}
+
+// ContainerSecurity holds resource limits and privilege settings for a
+// container workload. Unset fields fall back to the stack's security profile.
+type ContainerSecurity struct {
+	CPUs            float64  `yaml:"cpus,omitempty"`              // CPU limit in cores (e.g., 0.5)
+	Memory          string   `yaml:"memory,omitempty"`            // Memory limit (e.g., "512m", "1g")
+	PidsLimit       int64    `yaml:"pids_limit,omitempty"`        // Maximum number of processes
+	ReadOnly        *bool    `yaml:"read_only,omitempty"`         // Mount the root filesystem read-only
+	CapAdd          []string `yaml:"cap_add,omitempty"`           // Linux capabilities to add
+	CapDrop         []string `yaml:"cap_drop,omitempty"`          // Linux capabilities to drop ("ALL" for all)
+	NoNewPrivileges *bool    `yaml:"no_new_privileges,omitempty"` // Block privilege escalation via setuid binaries
+	User            string   `yaml:"user,omitempty"`              // Run as "user", "uid", or "uid:gid"
+}
+
+// IsSet returns true if any limit or security option is configured.
+func (c *ContainerSecurity) IsSet() bool {
+	return c.CPUs != 0 || c.Memory != "" || c.PidsLimit != 0 || c.ReadOnly != nil ||
+		len(c.CapAdd) > 0 || len(c.CapDrop) > 0 || c.NoNewPrivileges != nil || c.User != ""
+}
+
+// MemoryBytes returns the memory limit in bytes (0 if unset).
+func (c *ContainerSecurity) MemoryBytes() (int64, error) {
+	if c.Memory == "" {
+		return 0, nil
+	}
+	return units.RAMInBytes(c.Memory)
+}
+
+// applyStrictDefaults fills unset fields with the strict profile's values.
+func (c *ContainerSecurity) applyStrictDefaults() {
+	if c.CPUs == 0 {
+		c.CPUs = StrictCPUs
+	}
+	if c.Memory == "" {
+		c.Memory = StrictMemory
+	}
+	if c.PidsLimit == 0 {
+		c.PidsLimit = StrictPidsLimit
+	}
+	if c.ReadOnly == nil {
+		c.ReadOnly = boolPtr(true)
+	}
+	if c.CapDrop == nil {
+		c.CapDrop = []string{"ALL"}
+	}
+	if c.NoNewPrivileges == nil {
+		c.NoNewPrivileges = boolPtr(true)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
 }
 
 // SSHConfig defines SSH connection parameters for remote MCP servers.
 type SSHConfig struct {
-	Host         string `yaml:"host"`                    // Required: hostname or IP address
-	User         string `yaml:"user"`                    // Required: SSH username
-	Port         int    `yaml:"port,omitempty"`          // Optional: SSH port (default 22)
-	IdentityFile string `yaml:"identityFile,omitempty"`  // Optional: path to SSH private key
+	Host         string `yaml:"host"`                   // Required: hostname or IP address
+	User         string `yaml:"user"`                   // Required: SSH username
+	Port         int    `yaml:"port,omitempty"`         // Optional: SSH port (default 22)
+	IdentityFile string `yaml:"identityFile,omitempty"` // Optional: path to SSH private key
 }
 
 // IsExternal returns true if this is an external MCP server (URL-only, no container).
@@ -59,6 +481,11 @@ func (s *MCPServer) IsSSH() bool {
 	return s.SSH != nil && len(s.Command) > 0 && s.Image == "" && s.Source == nil && s.URL == ""
 }
 
+// IsContainerBased returns true if this MCP server runs as a managed container.
+func (s *MCPServer) IsContainerBased() bool {
+	return !s.IsExternal() && !s.IsLocalProcess() && !s.IsSSH()
+}
+
 // Source defines how to build an MCP server from source code.
 type Source struct {
 	Type       string `yaml:"type"` // "git" or "local"
@@ -68,21 +495,40 @@ type Source struct {
 	Dockerfile string `yaml:"dockerfile,omitempty"`
 }
 
-// Resource defines a supporting container (database, cache, etc).
+// Resource defines a supporting container (database, cache, init job, etc).
 type Resource struct {
-	Name    string            `yaml:"name"`
-	Image   string            `yaml:"image"`
-	Env     map[string]string `yaml:"env,omitempty"`
-	Ports   []string          `yaml:"ports,omitempty"`
-	Volumes []string          `yaml:"volumes,omitempty"`
-	Network string            `yaml:"network,omitempty"` // Network to join (for multi-network mode)
+	Name      string            `yaml:"name"`
+	Image     string            `yaml:"image"`
+	Command   []string          `yaml:"command,omitempty"` // Override container command (e.g., for one-shot init jobs)
+	Env       map[string]string `yaml:"env,omitempty"`
+	EnvFile   EnvFiles          `yaml:"env_file,omitempty"` // .env files with more env variables (env takes precedence)
+	Ports     []string          `yaml:"ports,omitempty"`
+	Volumes   []string          `yaml:"volumes,omitempty"`    // Mounts: "host-path:container-path[:ro|rw]" or "volume-name:container-path[:ro|rw]"
+	Network   string            `yaml:"network,omitempty"`    // Network to join (for multi-network mode)
+	DependsOn []Dependency      `yaml:"depends_on,omitempty"` // Workloads that must be ready before this resource starts
+
+	ContainerSecurity `yaml:",inline"`
+}
+
+// Dependency conditions control when a dependent workload may start.
+const (
+	DependencyStarted   = "started"   // Dependency has been started (default)
+	DependencyHealthy   = "healthy"   // Dependency reports a healthy container healthcheck
+	DependencyCompleted = "completed" // Dependency ran to completion and exited with code 0
+)
+
+// Dependency declares that a workload must wait for another workload.
+// Supports both string format (name only) and object format (name + condition).
+type Dependency struct {
+	Name      string `yaml:"name" json:"name"`                               // MCP server, resource, or agent name
+	Condition string `yaml:"condition,omitempty" json:"condition,omitempty"` // "started" (default), "healthy", or "completed"
 }
 
 // ToolSelector specifies which tools an agent can access from an MCP server.
 // Supports both string format (server name only) and object format (server + tools).
 type ToolSelector struct {
 	Server string   `yaml:"server" json:"server"`                   // MCP server or A2A agent name
-	Tools  []string `yaml:"tools,omitempty" json:"tools,omitempty"` // Tool whitelist (empty = all tools from this server)
+	Tools  []string `yaml:"tools,omitempty" json:"tools,omitempty"` // Tool patterns, as for MCPServer.Tools (empty = all tools from this server)
 }
 
 // Agent defines an active agent container that consumes MCP tools.
@@ -95,19 +541,26 @@ type Agent struct {
 	Uses           []ToolSelector    `yaml:"uses"`                      // References mcp-servers or agents by name
 	EquippedSkills []ToolSelector    `yaml:"equipped_skills,omitempty"` // Alias for Uses (merged during load)
 	Env            map[string]string `yaml:"env,omitempty"`
+	EnvFile        EnvFiles          `yaml:"env_file,omitempty"` // .env files with more env variables (env takes precedence)
 	BuildArgs      map[string]string `yaml:"build_args,omitempty"`
-	Network        string            `yaml:"network,omitempty"`         // Network to join (for multi-network mode)
-	Command        []string          `yaml:"command,omitempty"`         // Override container entrypoint
-	Runtime        string            `yaml:"runtime,omitempty"`         // Headless runtime (e.g., "claude-code")
-	Prompt         string            `yaml:"prompt,omitempty"`          // System prompt for headless agents
-	A2A            *A2AConfig        `yaml:"a2a,omitempty"`             // A2A protocol configuration
+	Network        string            `yaml:"network,omitempty"`    // Network to join (for multi-network mode)
+	Command        []string          `yaml:"command,omitempty"`    // Override container entrypoint
+	Runtime        string            `yaml:"runtime,omitempty"`    // Headless runtime (e.g., "claude-code")
+	Prompt         string            `yaml:"prompt,omitempty"`     // System prompt for headless agents
+	A2A            *A2AConfig        `yaml:"a2a,omitempty"`        // A2A protocol configuration
+	DependsOn      []Dependency      `yaml:"depends_on,omitempty"` // Workloads that must be ready before this agent starts
+	Volumes        []string          `yaml:"volumes,omitempty"`    // Mounts: "host-path:container-path[:ro|rw]" or "volume-name:container-path[:ro|rw]"
+	WorkDir        string            `yaml:"workdir,omitempty"`    // Container working directory
+	Tmpfs          []string          `yaml:"tmpfs,omitempty"`      // tmpfs mounts: "container-path[:options]"
+
+	ContainerSecurity `yaml:",inline"`
 }
 
 // A2AConfig defines A2A protocol settings for exposing an agent via A2A.
 type A2AConfig struct {
-	Enabled  bool       `yaml:"enabled,omitempty"`  // Enable A2A exposure (default: true when block present)
-	Version  string     `yaml:"version,omitempty"`  // Agent version (default: "1.0.0")
-	Skills   []A2ASkill `yaml:"skills,omitempty"`   // Skills this agent exposes
+	Enabled bool       `yaml:"enabled,omitempty"` // Enable A2A exposure (default: true when block present)
+	Version string     `yaml:"version,omitempty"` // Agent version (default: "1.0.0")
+	Skills  []A2ASkill `yaml:"skills,omitempty"`  // Skills this agent exposes
 }
 
 // A2ASkill represents a capability the agent can perform.
@@ -120,14 +573,15 @@ type A2ASkill struct {
 
 // A2AAgent defines an external A2A agent reference.
 type A2AAgent struct {
-	Name string    `yaml:"name"`               // Local alias for this remote agent
-	URL  string    `yaml:"url"`                // Base URL for the remote agent's A2A endpoint
-	Auth *A2AAuth  `yaml:"auth,omitempty"`     // Authentication configuration
+	Name string   `yaml:"name"`           // Local alias for this remote agent
+	URL  string   `yaml:"url"`            // Base URL for the remote agent's A2A endpoint
+	Auth *A2AAuth `yaml:"auth,omitempty"` // Authentication configuration
 }
 
 // A2AAuth contains authentication configuration for A2A connections.
 type A2AAuth struct {
 	Type       string `yaml:"type,omitempty"`        // "bearer", "api_key", or "none"
+	Token      string `yaml:"token,omitempty"`       // Token, usually a ${secret:name} reference
 	TokenEnv   string `yaml:"token_env,omitempty"`   // Environment variable containing the token
 	HeaderName string `yaml:"header_name,omitempty"` // Header name for API key auth (default: "Authorization")
 }
@@ -152,6 +606,12 @@ func (s *Stack) SetDefaults() {
 		s.Version = "1"
 	}
 
+	for i := range s.Volumes {
+		if s.Volumes[i].Driver == "" {
+			s.Volumes[i].Driver = "local"
+		}
+	}
+
 	// Progressive network defaults:
 	// - If networks[] is defined (advanced mode), don't apply single network defaults
 	// - If networks[] is not defined (simple mode), apply single network defaults
@@ -172,7 +632,21 @@ func (s *Stack) SetDefaults() {
 		}
 	}
 
+	if s.Security == "" {
+		s.Security = SecurityDefault
+	}
+	if s.ToolNaming.Separator == "" {
+		s.ToolNaming.Separator = DefaultToolSeparator
+	}
+	if s.ToolNaming.MaxLength == 0 {
+		s.ToolNaming.MaxLength = DefaultToolNameMaxLength
+	}
+
 	for i := range s.MCPServers {
+		setDependencyDefaults(s.MCPServers[i].DependsOn)
+		if s.Security == SecurityStrict && s.MCPServers[i].IsContainerBased() {
+			applyStrictProfile(&s.MCPServers[i].ContainerSecurity, &s.MCPServers[i].Tmpfs)
+		}
 		if s.MCPServers[i].Source != nil {
 			if s.MCPServers[i].Source.Dockerfile == "" {
 				s.MCPServers[i].Source.Dockerfile = "Dockerfile"
@@ -183,7 +657,15 @@ func (s *Stack) SetDefaults() {
 		}
 	}
 
+	for i := range s.Resources {
+		setDependencyDefaults(s.Resources[i].DependsOn)
+	}
+
 	for i := range s.Agents {
+		setDependencyDefaults(s.Agents[i].DependsOn)
+		if s.Security == SecurityStrict {
+			applyStrictProfile(&s.Agents[i].ContainerSecurity, &s.Agents[i].Tmpfs)
+		}
 		if s.Agents[i].Source != nil {
 			if s.Agents[i].Source.Dockerfile == "" {
 				s.Agents[i].Source.Dockerfile = "Dockerfile"
@@ -195,6 +677,32 @@ func (s *Stack) SetDefaults() {
 	}
 }
 
+// applyStrictProfile applies strict security defaults to an MCP server or agent.
+// A writable /tmp is added when the root filesystem ends up read-only, since most
+// images expect one. Resources are not hardened by the profile; databases and
+// similar services usually need a writable filesystem.
+func applyStrictProfile(sec *ContainerSecurity, tmpfs *[]string) {
+	sec.applyStrictDefaults()
+	if !*sec.ReadOnly {
+		return
+	}
+	for _, spec := range *tmpfs {
+		if target, _, _ := strings.Cut(spec, ":"); target == "/tmp" {
+			return
+		}
+	}
+	*tmpfs = append(*tmpfs, "/tmp")
+}
+
+// setDependencyDefaults fills in the default condition for dependencies.
+func setDependencyDefaults(deps []Dependency) {
+	for i := range deps {
+		if deps[i].Condition == "" {
+			deps[i].Condition = DependencyStarted
+		}
+	}
+}
+
 // UnmarshalYAML implements custom YAML unmarshaling for ToolSelector.
 // This allows both string format (legacy) and object format (new).
 //
@@ -230,6 +738,52 @@ func (ts *ToolSelector) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
+// UnmarshalYAML implements custom YAML unmarshaling for Egress.
+// This allows a mode string, a host list, or object format.
+//
+// Mode format:
+//
+//	egress: none
+//
+// Host list format (allowlist mode):
+//
+//	egress: ["api.github.com", "*.githubusercontent.com"]
+//
+// Object format:
+//
+//	egress:
+//	  mode: allowlist
+//	  allow: ["api.github.com"]
+func (e *Egress) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var mode string
+		if err := node.Decode(&mode); err != nil {
+			return err
+		}
+		*e = Egress{Mode: mode}
+		return nil
+	case yaml.SequenceNode:
+		var allow []string
+		if err := node.Decode(&allow); err != nil {
+			return err
+		}
+		*e = Egress{Mode: EgressAllowlist, Allow: allow}
+		return nil
+	}
+
+	type egressAlias Egress
+	var alias egressAlias
+	if err := node.Decode(&alias); err != nil {
+		return err
+	}
+	*e = Egress(alias)
+	if e.Mode == "" && len(e.Allow) > 0 {
+		e.Mode = EgressAllowlist
+	}
+	return nil
+}
+
 // ServerNames returns a slice of server names from a slice of ToolSelectors.
 // This is useful for backward compatibility with code that expects []string.
 func ServerNames(selectors []ToolSelector) []string {
@@ -239,3 +793,148 @@ func ServerNames(selectors []ToolSelector) []string {
 	}
 	return names
 }
+
+// UnmarshalYAML implements custom YAML unmarshaling for Dependency.
+// This allows both string format and object format.
+//
+// String format:
+//
+//	depends_on:
+//	  - postgres
+//
+// Object format:
+//
+//	depends_on:
+//	  - name: migrate
+//	    condition: completed
+func (d *Dependency) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var name string
+		if err := node.Decode(&name); err != nil {
+			return err
+		}
+		d.Name = name
+		d.Condition = ""
+		return nil
+	}
+
+	type dependencyAlias Dependency
+	var alias dependencyAlias
+	if err := node.Decode(&alias); err != nil {
+		return err
+	}
+	*d = Dependency(alias)
+	return nil
+}
+
+// WorkloadDependencies returns the dependencies of every workload in the stack,
+// keyed by workload name. This is the single source of truth for the startup
+// graph used by both validation and the orchestrator.
+//
+// Besides explicit depends_on entries, it includes:
+//   - agent 'uses' references (MCP servers and A2A agents, condition "started")
+//   - for MCP servers and agents without explicit depends_on, an implicit
+//     "started" dependency on every resource that has no dependencies itself,
+//     preserving the historical "resources first" startup order
+func (s *Stack) WorkloadDependencies() map[string][]Dependency {
+	deps := make(map[string][]Dependency)
+
+	var rootResources []string
+	for _, res := range s.Resources {
+		deps[res.Name] = appendDependencies(nil, res.DependsOn...)
+		if len(res.DependsOn) == 0 {
+			rootResources = append(rootResources, res.Name)
+		}
+	}
+
+	implicit := func(explicit []Dependency) []Dependency {
+		if len(explicit) > 0 {
+			return nil
+		}
+		out := make([]Dependency, len(rootResources))
+		for i, name := range rootResources {
+			out[i] = Dependency{Name: name, Condition: DependencyStarted}
+		}
+		return out
+	}
+
+	for _, server := range s.MCPServers {
+		d := appendDependencies(nil, server.DependsOn...)
+		deps[server.Name] = appendDependencies(d, implicit(server.DependsOn)...)
+	}
+
+	workloads := make(map[string]bool, len(deps)+len(s.Agents))
+	for name := range deps {
+		workloads[name] = true
+	}
+	for _, agent := range s.Agents {
+		workloads[agent.Name] = true
+	}
+
+	for _, agent := range s.Agents {
+		d := appendDependencies(nil, agent.DependsOn...)
+		for _, selector := range agent.Uses {
+			if workloads[selector.Server] && selector.Server != agent.Name {
+				d = appendDependencies(d, Dependency{Name: selector.Server, Condition: DependencyStarted})
+			}
+		}
+		deps[agent.Name] = appendDependencies(d, implicit(agent.DependsOn)...)
+	}
+
+	return deps
+}
+
+// appendDependencies appends dependencies, skipping names already present.
+func appendDependencies(deps []Dependency, add ...Dependency) []Dependency {
+	for _, a := range add {
+		seen := false
+		for _, d := range deps {
+			if d.Name == a.Name {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			deps = append(deps, a)
+		}
+	}
+	return deps
+}
+
+// VolumeMount is a parsed volume specification.
+type VolumeMount struct {
+	Source string // Host path or named volume
+	Target string // Path inside the container
+	Mode   string // "ro", "rw", or empty
+}
+
+// ParseVolumeMount parses a "source:target[:mode]" volume specification.
+func ParseVolumeMount(spec string) (VolumeMount, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return VolumeMount{}, fmt.Errorf("must be 'source:target' or 'source:target:mode'")
+	}
+	m := VolumeMount{Source: parts[0], Target: parts[1]}
+	if len(parts) == 3 {
+		m.Mode = parts[2]
+	}
+	return m, nil
+}
+
+// String formats the mount back into "source:target[:mode]" form.
+func (m VolumeMount) String() string {
+	if m.Mode != "" {
+		return m.Source + ":" + m.Target + ":" + m.Mode
+	}
+	return m.Source + ":" + m.Target
+}
+
+// IsNamedVolume returns true if the mount source refers to a named volume
+// rather than a host path. Host paths are absolute, relative ("./", "../")
+// or home-relative ("~").
+func (m VolumeMount) IsNamedVolume() bool {
+	if m.Source == "" || strings.ContainsAny(m.Source, `/\`) {
+		return false
+	}
+	return m.Source != "." && m.Source != ".." && !strings.HasPrefix(m.Source, "~")
+}
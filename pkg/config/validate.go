@@ -2,16 +2,28 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"sort"
 	"strings"
 )
 
 // ValidationError represents a configuration validation error.
 type ValidationError struct {
-	Field   string
-	Message string
+	Field   string `json:"field"`
+	Message string `json:"message"`
+
+	// Source position of the field, when the stack was loaded from a file
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
 }
 
 func (e ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.Field, e.Message)
+	}
 	return fmt.Sprintf("%s: %s", e.Field, e.Message)
 }
 
@@ -31,11 +43,12 @@ func (e ValidationErrors) Error() string {
 
 // Validate checks the stack configuration for errors.
 func Validate(s *Stack) error {
-	var errs ValidationErrors
+	// Unknown fields and unset variables found when the stack was loaded
+	errs := append(ValidationErrors(nil), s.loadErrors...)
 
 	// Stack-level validation
 	if s.Name == "" {
-		errs = append(errs, ValidationError{"stack.name", "is required"})
+		errs = append(errs, ValidationError{Field: "stack.name", Message: "is required"})
 	}
 
 	// Network mode validation
@@ -43,7 +56,7 @@ func Validate(s *Stack) error {
 	hasNetworks := len(s.Networks) > 0
 
 	if hasNetwork && hasNetworks {
-		errs = append(errs, ValidationError{"stack", "cannot have both 'network' and 'networks' - use one or the other"})
+		errs = append(errs, ValidationError{Field: "stack", Message: "cannot have both 'network' and 'networks' - use one or the other"})
 	}
 
 	// Build network name set for advanced mode validation
@@ -53,23 +66,48 @@ func Validate(s *Stack) error {
 		for i, net := range s.Networks {
 			prefix := fmt.Sprintf("networks[%d]", i)
 			if net.Name == "" {
-				errs = append(errs, ValidationError{prefix + ".name", "is required"})
+				errs = append(errs, ValidationError{Field: prefix + ".name", Message: "is required"})
 			} else if networkNames[net.Name] {
-				errs = append(errs, ValidationError{prefix + ".name", fmt.Sprintf("duplicate network name '%s'", net.Name)})
+				errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("duplicate network name '%s'", net.Name)})
 			} else {
 				networkNames[net.Name] = true
 			}
 			if net.Driver != "" && net.Driver != "bridge" && net.Driver != "host" && net.Driver != "none" {
-				errs = append(errs, ValidationError{prefix + ".driver", "must be 'bridge', 'host', or 'none'"})
+				errs = append(errs, ValidationError{Field: prefix + ".driver", Message: "must be 'bridge', 'host', or 'none'"})
 			}
 		}
 	} else {
 		// Simple mode: validate single network
 		if s.Network.Name == "" {
-			errs = append(errs, ValidationError{"stack.network.name", "is required"})
+			errs = append(errs, ValidationError{Field: "stack.network.name", Message: "is required"})
 		}
 		if s.Network.Driver != "" && s.Network.Driver != "bridge" && s.Network.Driver != "host" && s.Network.Driver != "none" {
-			errs = append(errs, ValidationError{"stack.network.driver", "must be 'bridge', 'host', or 'none'"})
+			errs = append(errs, ValidationError{Field: "stack.network.driver", Message: "must be 'bridge', 'host', or 'none'"})
+		}
+	}
+
+	if s.Security != "" && s.Security != SecurityDefault && s.Security != SecurityStrict {
+		errs = append(errs, ValidationError{Field: "stack.security", Message: "must be 'default' or 'strict'"})
+	}
+	if s.ToolMode != "" && s.ToolMode != ToolModeAll && s.ToolMode != ToolModeSearch {
+		errs = append(errs, ValidationError{Field: "stack.tool_mode", Message: "must be 'all' or 'search'"})
+	}
+	errs = append(errs, validateAudit(&s.Audit)...)
+	errs = append(errs, validateTracing(&s.Tracing)...)
+	if d, err := s.Approvals.TimeoutDuration(); err != nil || d < 0 {
+		errs = append(errs, ValidationError{Field: "approvals.timeout", Message: fmt.Sprintf("invalid duration '%s' (use e.g. '5m')", s.Approvals.Timeout)})
+	}
+
+	// Named volume validation
+	volumeNames := make(map[string]bool)
+	for i, vol := range s.Volumes {
+		prefix := fmt.Sprintf("volumes[%d]", i)
+		if vol.Name == "" {
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: "is required"})
+		} else if volumeNames[vol.Name] {
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("duplicate volume name '%s'", vol.Name)})
+		} else {
+			volumeNames[vol.Name] = true
 		}
 	}
 
@@ -79,9 +117,9 @@ func Validate(s *Stack) error {
 		prefix := fmt.Sprintf("mcp-servers[%d]", i)
 
 		if server.Name == "" {
-			errs = append(errs, ValidationError{prefix + ".name", "is required"})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: "is required"})
 		} else if serverNames[server.Name] {
-			errs = append(errs, ValidationError{prefix + ".name", fmt.Sprintf("duplicate MCP server name '%s'", server.Name)})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("duplicate MCP server name '%s'", server.Name)})
 		} else {
 			serverNames[server.Name] = true
 		}
@@ -112,67 +150,75 @@ func Validate(s *Stack) error {
 		}
 
 		if count == 0 {
-			errs = append(errs, ValidationError{prefix, "must have 'image', 'source', 'url', 'command', or 'ssh' with 'command'"})
+			errs = append(errs, ValidationError{Field: prefix, Message: "must have 'image', 'source', 'url', 'command', or 'ssh' with 'command'"})
 		} else if count > 1 {
-			errs = append(errs, ValidationError{prefix, "can only have one of 'image', 'source', 'url', 'command', or 'ssh'"})
+			errs = append(errs, ValidationError{Field: prefix, Message: "can only have one of 'image', 'source', 'url', 'command', or 'ssh'"})
+		}
+
+		if len(server.Headers) > 0 && !server.IsExternal() {
+			errs = append(errs, ValidationError{Field: prefix + ".headers", Message: "only valid for external URL servers"})
 		}
 
 		// External server validation (URL-only)
 		if server.IsExternal() {
 			// Transport must be http or sse for external servers
 			if server.Transport == "stdio" {
-				errs = append(errs, ValidationError{prefix + ".transport", "stdio not valid for external URL servers"})
+				errs = append(errs, ValidationError{Field: prefix + ".transport", Message: "stdio not valid for external URL servers"})
 			}
 			// Validate transport is known
 			if server.Transport != "" && server.Transport != "http" && server.Transport != "sse" {
-				errs = append(errs, ValidationError{prefix + ".transport", "must be 'http' or 'sse' for external servers"})
+				errs = append(errs, ValidationError{Field: prefix + ".transport", Message: "must be 'http' or 'sse' for external servers"})
 			}
 			// Port is not required for URL servers (URL includes the endpoint)
 			if server.Port != 0 {
-				errs = append(errs, ValidationError{prefix + ".port", "should not be set for external URL servers (use url instead)"})
+				errs = append(errs, ValidationError{Field: prefix + ".port", Message: "should not be set for external URL servers (use url instead)"})
 			}
 			// Network is not applicable for external servers
 			if server.Network != "" {
-				errs = append(errs, ValidationError{prefix + ".network", "not applicable for external URL servers"})
+				errs = append(errs, ValidationError{Field: prefix + ".network", Message: "not applicable for external URL servers"})
 			}
+			errs = append(errs, validateNoContainerOptions(prefix, &server, true, "external URL servers")...)
 		} else if server.IsLocalProcess() {
 			// Local process server validation (command-only)
 			// Transport must be stdio for local process servers
 			if server.Transport != "" && server.Transport != "stdio" {
-				errs = append(errs, ValidationError{prefix + ".transport", "must be 'stdio' for local process servers"})
+				errs = append(errs, ValidationError{Field: prefix + ".transport", Message: "must be 'stdio' for local process servers"})
 			}
 			// Port is not applicable for local process servers (they use stdio)
 			if server.Port != 0 {
-				errs = append(errs, ValidationError{prefix + ".port", "should not be set for local process servers (use stdio transport)"})
+				errs = append(errs, ValidationError{Field: prefix + ".port", Message: "should not be set for local process servers (use stdio transport)"})
 			}
 			// Network is not applicable for local process servers
 			if server.Network != "" {
-				errs = append(errs, ValidationError{prefix + ".network", "not applicable for local process servers"})
+				errs = append(errs, ValidationError{Field: prefix + ".network", Message: "not applicable for local process servers"})
 			}
+			// Workdir is a host path for local processes; mounts and limits don't apply
+			errs = append(errs, validateNoContainerOptions(prefix, &server, false, "local process servers")...)
 		} else if server.IsSSH() {
 			// SSH server validation
 			sshPrefix := prefix + ".ssh"
 			if server.SSH.Host == "" {
-				errs = append(errs, ValidationError{sshPrefix + ".host", "is required"})
+				errs = append(errs, ValidationError{Field: sshPrefix + ".host", Message: "is required"})
 			}
 			if server.SSH.User == "" {
-				errs = append(errs, ValidationError{sshPrefix + ".user", "is required"})
+				errs = append(errs, ValidationError{Field: sshPrefix + ".user", Message: "is required"})
 			}
 			if server.SSH.Port < 0 || server.SSH.Port > 65535 {
-				errs = append(errs, ValidationError{sshPrefix + ".port", "must be between 0 and 65535"})
+				errs = append(errs, ValidationError{Field: sshPrefix + ".port", Message: "must be between 0 and 65535"})
 			}
 			// Transport must be stdio for SSH servers (they use stdin/stdout over SSH)
 			if server.Transport != "" && server.Transport != "stdio" {
-				errs = append(errs, ValidationError{prefix + ".transport", "must be 'stdio' for SSH servers"})
+				errs = append(errs, ValidationError{Field: prefix + ".transport", Message: "must be 'stdio' for SSH servers"})
 			}
 			// Port is not applicable for SSH servers (use ssh.port for SSH port)
 			if server.Port != 0 {
-				errs = append(errs, ValidationError{prefix + ".port", "should not be set for SSH servers (use ssh.port for SSH port)"})
+				errs = append(errs, ValidationError{Field: prefix + ".port", Message: "should not be set for SSH servers (use ssh.port for SSH port)"})
 			}
 			// Network is not applicable for SSH servers
 			if server.Network != "" {
-				errs = append(errs, ValidationError{prefix + ".network", "not applicable for SSH servers"})
+				errs = append(errs, ValidationError{Field: prefix + ".network", Message: "not applicable for SSH servers"})
 			}
+			errs = append(errs, validateNoContainerOptions(prefix, &server, true, "SSH servers")...)
 		} else {
 			// Container-based server validation (existing logic)
 			// Source validation
@@ -182,29 +228,50 @@ func Validate(s *Stack) error {
 
 			// Transport validation
 			if server.Transport != "" && server.Transport != "http" && server.Transport != "sse" && server.Transport != "stdio" {
-				errs = append(errs, ValidationError{prefix + ".transport", "must be 'http', 'sse', or 'stdio'"})
+				errs = append(errs, ValidationError{Field: prefix + ".transport", Message: "must be 'http', 'sse', or 'stdio'"})
 			}
 
 			// Port validation (only required for HTTP/SSE transport)
 			if server.Transport != "stdio" {
 				if server.Port <= 0 {
-					errs = append(errs, ValidationError{prefix + ".port", "must be a positive integer"})
+					errs = append(errs, ValidationError{Field: prefix + ".port", Message: "must be a positive integer"})
 				}
 				if server.Port > 65535 {
-					errs = append(errs, ValidationError{prefix + ".port", "must be <= 65535"})
+					errs = append(errs, ValidationError{Field: prefix + ".port", Message: "must be <= 65535"})
 				}
 			}
 
+			// Volume, tmpfs, and workdir validation
+			errs = append(errs, validateMounts(prefix, server.Volumes, server.Tmpfs, server.WorkDir, volumeNames, false)...)
+			errs = append(errs, validateContainerSecurity(prefix, &server.ContainerSecurity)...)
+			if server.Egress != nil {
+				errs = append(errs, validateEgress(prefix, &server)...)
+			}
+
 			// Network validation (only in advanced mode for container servers)
 			if hasNetworks {
 				if server.Network == "" {
-					errs = append(errs, ValidationError{prefix + ".network", "required when 'networks' is defined"})
+					errs = append(errs, ValidationError{Field: prefix + ".network", Message: "required when 'networks' is defined"})
 				} else if !networkNames[server.Network] {
-					errs = append(errs, ValidationError{prefix + ".network", fmt.Sprintf("network '%s' not found in networks list", server.Network)})
+					errs = append(errs, ValidationError{Field: prefix + ".network", Message: fmt.Sprintf("network '%s' not found in networks list", server.Network)})
 				}
 			}
 		}
 		// In simple mode, server.Network is ignored (per design decision)
+
+		errs = append(errs, validateToolPatterns(prefix+".tools", server.Tools)...)
+		errs = append(errs, validateToolOverrides(prefix+".tool_overrides", server.ToolOverrides)...)
+		errs = append(errs, validateCallLimits(prefix, &server.CallLimits)...)
+		for _, tool := range sortedKeys(server.ToolLimits) {
+			if tool == "" {
+				errs = append(errs, ValidationError{Field: prefix + ".tool_limits", Message: "tool name must not be empty"})
+				continue
+			}
+			limits := server.ToolLimits[tool]
+			errs = append(errs, validateCallLimits(prefix+".tool_limits."+tool, &limits)...)
+		}
+		errs = append(errs, validateCache(prefix, server.Cache, server.ToolCache)...)
+		errs = append(errs, validateToolPatterns(prefix+".require_approval", server.RequireApproval)...)
 	}
 
 	// Resource validation
@@ -213,25 +280,30 @@ func Validate(s *Stack) error {
 		prefix := fmt.Sprintf("resources[%d]", i)
 
 		if resource.Name == "" {
-			errs = append(errs, ValidationError{prefix + ".name", "is required"})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: "is required"})
 		} else if resourceNames[resource.Name] {
-			errs = append(errs, ValidationError{prefix + ".name", fmt.Sprintf("duplicate resource name '%s'", resource.Name)})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("duplicate resource name '%s'", resource.Name)})
 		} else if serverNames[resource.Name] {
-			errs = append(errs, ValidationError{prefix + ".name", fmt.Sprintf("name '%s' conflicts with an MCP server", resource.Name)})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("name '%s' conflicts with an MCP server", resource.Name)})
 		} else {
 			resourceNames[resource.Name] = true
 		}
 
 		if resource.Image == "" {
-			errs = append(errs, ValidationError{prefix + ".image", "is required"})
+			errs = append(errs, ValidationError{Field: prefix + ".image", Message: "is required"})
 		}
 
+		// Resources used Docker volumes by name before stacks declared them,
+		// so undeclared names still mount as they are
+		errs = append(errs, validateMounts(prefix, resource.Volumes, nil, "", volumeNames, true)...)
+		errs = append(errs, validateContainerSecurity(prefix, &resource.ContainerSecurity)...)
+
 		// Network validation (only in advanced mode)
 		if hasNetworks {
 			if resource.Network == "" {
-				errs = append(errs, ValidationError{prefix + ".network", "required when 'networks' is defined"})
+				errs = append(errs, ValidationError{Field: prefix + ".network", Message: "required when 'networks' is defined"})
 			} else if !networkNames[resource.Network] {
-				errs = append(errs, ValidationError{prefix + ".network", fmt.Sprintf("network '%s' not found in networks list", resource.Network)})
+				errs = append(errs, ValidationError{Field: prefix + ".network", Message: fmt.Sprintf("network '%s' not found in networks list", resource.Network)})
 			}
 		}
 		// In simple mode, resource.Network is ignored (per design decision)
@@ -244,13 +316,13 @@ func Validate(s *Stack) error {
 		prefix := fmt.Sprintf("agents[%d]", i)
 
 		if agent.Name == "" {
-			errs = append(errs, ValidationError{prefix + ".name", "is required"})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: "is required"})
 		} else if agentNames[agent.Name] {
-			errs = append(errs, ValidationError{prefix + ".name", fmt.Sprintf("duplicate agent name '%s'", agent.Name)})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("duplicate agent name '%s'", agent.Name)})
 		} else if serverNames[agent.Name] {
-			errs = append(errs, ValidationError{prefix + ".name", fmt.Sprintf("name '%s' conflicts with an MCP server", agent.Name)})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("name '%s' conflicts with an MCP server", agent.Name)})
 		} else if resourceNames[agent.Name] {
-			errs = append(errs, ValidationError{prefix + ".name", fmt.Sprintf("name '%s' conflicts with a resource", agent.Name)})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("name '%s' conflicts with a resource", agent.Name)})
 		} else {
 			agentNames[agent.Name] = true
 			if agent.IsA2AEnabled() {
@@ -271,21 +343,21 @@ func Validate(s *Stack) error {
 		if hasRuntime {
 			// Headless agent validation
 			if hasImage {
-				errs = append(errs, ValidationError{prefix, "cannot have both 'runtime' and 'image'"})
+				errs = append(errs, ValidationError{Field: prefix, Message: "cannot have both 'runtime' and 'image'"})
 			}
 			if hasSource {
-				errs = append(errs, ValidationError{prefix, "cannot have both 'runtime' and 'source'"})
+				errs = append(errs, ValidationError{Field: prefix, Message: "cannot have both 'runtime' and 'source'"})
 			}
 			if agent.Prompt == "" {
-				errs = append(errs, ValidationError{prefix + ".prompt", "is required when 'runtime' is set"})
+				errs = append(errs, ValidationError{Field: prefix + ".prompt", Message: "is required when 'runtime' is set"})
 			}
 		} else {
 			// Container-based agent validation
 			if !hasImage && !hasSource {
-				errs = append(errs, ValidationError{prefix, "must have either 'image', 'source', or 'runtime'"})
+				errs = append(errs, ValidationError{Field: prefix, Message: "must have either 'image', 'source', or 'runtime'"})
 			}
 			if hasImage && hasSource {
-				errs = append(errs, ValidationError{prefix, "cannot have both 'image' and 'source'"})
+				errs = append(errs, ValidationError{Field: prefix, Message: "cannot have both 'image' and 'source'"})
 			}
 		}
 
@@ -294,37 +366,42 @@ func Validate(s *Stack) error {
 			errs = append(errs, validateSource(agent.Source, prefix+".source")...)
 		}
 
+		// Volume, tmpfs, and workdir validation
+		errs = append(errs, validateMounts(prefix, agent.Volumes, agent.Tmpfs, agent.WorkDir, volumeNames, false)...)
+		errs = append(errs, validateContainerSecurity(prefix, &agent.ContainerSecurity)...)
+
 		// Validate 'uses' dependencies exist in mcp-servers or A2A-enabled agents
 		for j, selector := range agent.Uses {
 			dep := selector.Server
-			isValidServer := serverNames[dep]
+			isValidServer := serverNames[dep] || s.servesCompositeTools(dep)
 			isValidAgent := a2aEnabledAgents[dep] && dep != agent.Name // Can't reference self
 			if !isValidServer && !isValidAgent {
 				if dep == agent.Name {
 					errs = append(errs, ValidationError{
-						fmt.Sprintf("%s.uses[%d]", prefix, j),
-						"agent cannot reference itself",
+						Field:   fmt.Sprintf("%s.uses[%d]", prefix, j),
+						Message: "agent cannot reference itself",
 					})
 				} else if agentNames[dep] && !a2aEnabledAgents[dep] {
 					errs = append(errs, ValidationError{
-						fmt.Sprintf("%s.uses[%d]", prefix, j),
-						fmt.Sprintf("agent '%s' must have A2A enabled to be used as a skill", dep),
+						Field:   fmt.Sprintf("%s.uses[%d]", prefix, j),
+						Message: fmt.Sprintf("agent '%s' must have A2A enabled to be used as a skill", dep),
 					})
 				} else {
 					errs = append(errs, ValidationError{
-						fmt.Sprintf("%s.uses[%d]", prefix, j),
-						fmt.Sprintf("'%s' not found in mcp-servers or A2A-enabled agents", dep),
+						Field:   fmt.Sprintf("%s.uses[%d]", prefix, j),
+						Message: fmt.Sprintf("'%s' not found in mcp-servers or A2A-enabled agents", dep),
 					})
 				}
 			}
+			errs = append(errs, validateToolPatterns(fmt.Sprintf("%s.uses[%d].tools", prefix, j), selector.Tools)...)
 		}
 
 		// Network validation (only in advanced mode)
 		if hasNetworks {
 			if agent.Network == "" {
-				errs = append(errs, ValidationError{prefix + ".network", "required when 'networks' is defined"})
+				errs = append(errs, ValidationError{Field: prefix + ".network", Message: "required when 'networks' is defined"})
 			} else if !networkNames[agent.Network] {
-				errs = append(errs, ValidationError{prefix + ".network", fmt.Sprintf("network '%s' not found in networks list", agent.Network)})
+				errs = append(errs, ValidationError{Field: prefix + ".network", Message: fmt.Sprintf("network '%s' not found in networks list", agent.Network)})
 			}
 		}
 		// In simple mode, agent.Network is ignored (per design decision)
@@ -339,15 +416,15 @@ func Validate(s *Stack) error {
 				skillPrefix := fmt.Sprintf("%s.skills[%d]", a2aPrefix, j)
 
 				if skill.ID == "" {
-					errs = append(errs, ValidationError{skillPrefix + ".id", "is required"})
+					errs = append(errs, ValidationError{Field: skillPrefix + ".id", Message: "is required"})
 				} else if skillIDs[skill.ID] {
-					errs = append(errs, ValidationError{skillPrefix + ".id", fmt.Sprintf("duplicate skill ID '%s'", skill.ID)})
+					errs = append(errs, ValidationError{Field: skillPrefix + ".id", Message: fmt.Sprintf("duplicate skill ID '%s'", skill.ID)})
 				} else {
 					skillIDs[skill.ID] = true
 				}
 
 				if skill.Name == "" {
-					errs = append(errs, ValidationError{skillPrefix + ".name", "is required"})
+					errs = append(errs, ValidationError{Field: skillPrefix + ".name", Message: "is required"})
 				}
 			}
 		}
@@ -359,36 +436,50 @@ func Validate(s *Stack) error {
 		prefix := fmt.Sprintf("a2a-agents[%d]", i)
 
 		if a2aAgent.Name == "" {
-			errs = append(errs, ValidationError{prefix + ".name", "is required"})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: "is required"})
 		} else if a2aAgentNames[a2aAgent.Name] {
-			errs = append(errs, ValidationError{prefix + ".name", fmt.Sprintf("duplicate A2A agent name '%s'", a2aAgent.Name)})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("duplicate A2A agent name '%s'", a2aAgent.Name)})
 		} else if agentNames[a2aAgent.Name] {
-			errs = append(errs, ValidationError{prefix + ".name", fmt.Sprintf("name '%s' conflicts with a local agent", a2aAgent.Name)})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("name '%s' conflicts with a local agent", a2aAgent.Name)})
 		} else if serverNames[a2aAgent.Name] {
-			errs = append(errs, ValidationError{prefix + ".name", fmt.Sprintf("name '%s' conflicts with an MCP server", a2aAgent.Name)})
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("name '%s' conflicts with an MCP server", a2aAgent.Name)})
 		} else {
 			a2aAgentNames[a2aAgent.Name] = true
 		}
 
 		if a2aAgent.URL == "" {
-			errs = append(errs, ValidationError{prefix + ".url", "is required"})
+			errs = append(errs, ValidationError{Field: prefix + ".url", Message: "is required"})
 		}
 
 		if a2aAgent.Auth != nil {
 			authPrefix := prefix + ".auth"
 			validAuthTypes := map[string]bool{"bearer": true, "api_key": true, "none": true, "": true}
 			if !validAuthTypes[a2aAgent.Auth.Type] {
-				errs = append(errs, ValidationError{authPrefix + ".type", "must be 'bearer', 'api_key', or 'none'"})
+				errs = append(errs, ValidationError{Field: authPrefix + ".type", Message: "must be 'bearer', 'api_key', or 'none'"})
 			}
-			if a2aAgent.Auth.Type != "" && a2aAgent.Auth.Type != "none" && a2aAgent.Auth.TokenEnv == "" {
-				errs = append(errs, ValidationError{authPrefix + ".token_env", "is required when auth.type is set"})
+			hasToken := a2aAgent.Auth.Token != "" || a2aAgent.Auth.TokenEnv != ""
+			if a2aAgent.Auth.Type != "" && a2aAgent.Auth.Type != "none" && !hasToken {
+				errs = append(errs, ValidationError{Field: authPrefix + ".token", Message: "token or token_env is required when auth.type is set"})
+			}
+			if a2aAgent.Auth.Token != "" && a2aAgent.Auth.TokenEnv != "" {
+				errs = append(errs, ValidationError{Field: authPrefix, Message: "cannot have both 'token' and 'token_env'"})
 			}
 		}
 	}
 
-	// Check for circular dependencies between agents
-	if cycleErr := detectAgentCycles(s, a2aEnabledAgents); cycleErr != nil {
-		errs = append(errs, ValidationError{"agents", cycleErr.Error()})
+	// Tool naming and prefix collisions across servers and A2A agents
+	errs = append(errs, validateToolNaming(s)...)
+	errs = append(errs, validateToolsets(s, serverNames, a2aEnabledAgents)...)
+	errs = append(errs, validateCompositeTools(s)...)
+	errs = append(errs, validatePolicies(s, serverNames, a2aEnabledAgents)...)
+	errs = append(errs, validateSecrets(s)...)
+
+	// depends_on validation across all workloads
+	errs = append(errs, validateDependencies(s)...)
+
+	// Check for circular dependencies across the whole workload graph
+	if cycleErr := detectDependencyCycles(s); cycleErr != nil {
+		errs = append(errs, ValidationError{Field: "depends_on", Message: cycleErr.Error()})
 	}
 
 	if len(errs) > 0 {
@@ -397,18 +488,81 @@ func Validate(s *Stack) error {
 	return nil
 }
 
-// detectAgentCycles checks for circular dependencies in agent-to-agent relationships.
-func detectAgentCycles(s *Stack, a2aEnabledAgents map[string]bool) error {
-	// Build adjacency list for agent dependencies (only agent-to-agent, not agent-to-server)
-	deps := make(map[string][]string)
+// validateDependencies checks that depends_on entries reference existing
+// workloads and use a condition the target workload can satisfy.
+func validateDependencies(s *Stack) ValidationErrors {
+	var errs ValidationErrors
+
+	// Track which workloads exist and whether they run as containers.
+	// Only containers report health and exit codes, so "healthy" and
+	// "completed" conditions are limited to them.
+	containers := make(map[string]bool)
+	for _, server := range s.MCPServers {
+		containers[server.Name] = server.IsContainerBased()
+	}
+	for _, resource := range s.Resources {
+		containers[resource.Name] = true
+	}
 	for _, agent := range s.Agents {
-		var agentDeps []string
-		for _, selector := range agent.Uses {
-			if a2aEnabledAgents[selector.Server] {
-				agentDeps = append(agentDeps, selector.Server)
+		containers[agent.Name] = !agent.IsHeadless()
+	}
+
+	check := func(prefix, owner string, deps []Dependency) {
+		for j, dep := range deps {
+			depPrefix := fmt.Sprintf("%s.depends_on[%d]", prefix, j)
+			isContainer, exists := containers[dep.Name]
+			switch {
+			case dep.Name == "":
+				errs = append(errs, ValidationError{Field: depPrefix + ".name", Message: "is required"})
+				continue
+			case dep.Name == owner:
+				errs = append(errs, ValidationError{Field: depPrefix, Message: "workload cannot depend on itself"})
+				continue
+			case !exists:
+				errs = append(errs, ValidationError{Field: depPrefix, Message: fmt.Sprintf("'%s' not found in mcp-servers, resources, or agents", dep.Name)})
+				continue
+			}
+
+			switch dep.Condition {
+			case "", DependencyStarted:
+			case DependencyHealthy, DependencyCompleted:
+				if !isContainer {
+					errs = append(errs, ValidationError{Field: depPrefix + ".condition", Message: fmt.Sprintf("'%s' requires '%s' to be a container workload", dep.Condition, dep.Name)})
+				}
+			default:
+				errs = append(errs, ValidationError{Field: depPrefix + ".condition", Message: "must be 'started', 'healthy', or 'completed'"})
 			}
 		}
-		deps[agent.Name] = agentDeps
+	}
+
+	for i, server := range s.MCPServers {
+		check(fmt.Sprintf("mcp-servers[%d]", i), server.Name, server.DependsOn)
+	}
+	for i, resource := range s.Resources {
+		check(fmt.Sprintf("resources[%d]", i), resource.Name, resource.DependsOn)
+	}
+	for i, agent := range s.Agents {
+		check(fmt.Sprintf("agents[%d]", i), agent.Name, agent.DependsOn)
+	}
+
+	return errs
+}
+
+// detectDependencyCycles checks for circular dependencies across resources,
+// MCP servers, and agents, including agent 'uses' references.
+func detectDependencyCycles(s *Stack) error {
+	graph := s.WorkloadDependencies()
+
+	// Visit workloads in stack order so the reported cycle is deterministic
+	var order []string
+	for _, r := range s.Resources {
+		order = append(order, r.Name)
+	}
+	for _, server := range s.MCPServers {
+		order = append(order, server.Name)
+	}
+	for _, agent := range s.Agents {
+		order = append(order, agent.Name)
 	}
 
 	// DFS-based cycle detection
@@ -419,37 +573,39 @@ func detectAgentCycles(s *Stack, a2aEnabledAgents map[string]bool) error {
 	)
 
 	color := make(map[string]int)
-	var cycle []string
+	var path []string
 
-	var dfs func(node string) bool
-	dfs = func(node string) bool {
+	var dfs func(node string) []string
+	dfs = func(node string) []string {
 		color[node] = gray
-		for _, neighbor := range deps[node] {
-			if color[neighbor] == gray {
-				// Found a back edge - cycle detected
-				cycle = append(cycle, neighbor, node)
-				return true
-			}
-			if color[neighbor] == white {
-				if dfs(neighbor) {
-					if len(cycle) > 0 && cycle[0] != cycle[len(cycle)-1] {
-						cycle = append(cycle, node)
+		path = append(path, node)
+		for _, dep := range graph[node] {
+			if _, ok := graph[dep.Name]; !ok {
+				continue // Unknown targets are reported by validateDependencies
+			}
+			switch color[dep.Name] {
+			case gray:
+				// Found a back edge - extract the cycle from the current path
+				for i, n := range path {
+					if n == dep.Name {
+						cycle := append([]string{}, path[i:]...)
+						return append(cycle, dep.Name)
 					}
-					return true
+				}
+			case white:
+				if cycle := dfs(dep.Name); cycle != nil {
+					return cycle
 				}
 			}
 		}
+		path = path[:len(path)-1]
 		color[node] = black
-		return false
+		return nil
 	}
 
-	for name := range deps {
+	for _, name := range order {
 		if color[name] == white {
-			if dfs(name) {
-				// Reverse the cycle path for readable output
-				for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
-					cycle[i], cycle[j] = cycle[j], cycle[i]
-				}
+			if cycle := dfs(name); cycle != nil {
 				return fmt.Errorf("circular dependency detected: %s", strings.Join(cycle, " -> "))
 			}
 		}
@@ -458,28 +614,664 @@ func detectAgentCycles(s *Stack, a2aEnabledAgents map[string]bool) error {
 	return nil
 }
 
+// validateMounts checks volume, tmpfs, and workdir settings for a container workload.
+// Named volumes must be declared in the stack's volumes list unless allowUndeclared is set.
+func validateMounts(prefix string, volumes, tmpfs []string, workdir string, volumeNames map[string]bool, allowUndeclared bool) ValidationErrors {
+	var errs ValidationErrors
+
+	for j, spec := range volumes {
+		volPrefix := fmt.Sprintf("%s.volumes[%d]", prefix, j)
+		m, err := ParseVolumeMount(spec)
+		if err != nil {
+			errs = append(errs, ValidationError{Field: volPrefix, Message: err.Error()})
+			continue
+		}
+		if m.Source == "" {
+			errs = append(errs, ValidationError{Field: volPrefix, Message: "source is required"})
+		} else if m.IsNamedVolume() && !volumeNames[m.Source] && !allowUndeclared {
+			errs = append(errs, ValidationError{Field: volPrefix, Message: fmt.Sprintf("named volume '%s' not found in volumes list", m.Source)})
+		}
+		if !path.IsAbs(m.Target) {
+			errs = append(errs, ValidationError{Field: volPrefix, Message: "container path must be absolute"})
+		}
+		if m.Mode != "" && m.Mode != "ro" && m.Mode != "rw" {
+			errs = append(errs, ValidationError{Field: volPrefix, Message: "mode must be 'ro' or 'rw'"})
+		}
+	}
+
+	for j, spec := range tmpfs {
+		target, _, _ := strings.Cut(spec, ":")
+		if !path.IsAbs(target) {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("%s.tmpfs[%d]", prefix, j), Message: "container path must be absolute"})
+		}
+	}
+
+	if workdir != "" && !path.IsAbs(workdir) {
+		errs = append(errs, ValidationError{Field: prefix + ".workdir", Message: "must be an absolute container path"})
+	}
+
+	return errs
+}
+
+// validateContainerSecurity checks resource limits and privilege settings.
+func validateContainerSecurity(prefix string, sec *ContainerSecurity) ValidationErrors {
+	var errs ValidationErrors
+
+	if sec.CPUs < 0 {
+		errs = append(errs, ValidationError{Field: prefix + ".cpus", Message: "must not be negative"})
+	}
+	if _, err := sec.MemoryBytes(); err != nil {
+		errs = append(errs, ValidationError{Field: prefix + ".memory", Message: fmt.Sprintf("invalid size '%s' (use e.g. '512m' or '1g')", sec.Memory)})
+	}
+	if sec.PidsLimit < 0 {
+		errs = append(errs, ValidationError{Field: prefix + ".pids_limit", Message: "must not be negative"})
+	}
+	for j, c := range sec.CapAdd {
+		if !validCapability(c) {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("%s.cap_add[%d]", prefix, j), Message: fmt.Sprintf("invalid capability '%s'", c)})
+		}
+	}
+	for j, c := range sec.CapDrop {
+		if !validCapability(c) {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("%s.cap_drop[%d]", prefix, j), Message: fmt.Sprintf("invalid capability '%s'", c)})
+		}
+	}
+
+	return errs
+}
+
+// validateEgress checks an MCP server's outbound network policy.
+func validateEgress(prefix string, server *MCPServer) ValidationErrors {
+	var errs ValidationErrors
+	egressPrefix := prefix + ".egress"
+
+	switch server.Egress.Mode {
+	case EgressNone, EgressInternal:
+		if len(server.Egress.Allow) > 0 {
+			errs = append(errs, ValidationError{Field: egressPrefix + ".allow", Message: "only valid with mode 'allowlist'"})
+		}
+	case EgressAllowlist:
+		if len(server.Egress.Allow) == 0 {
+			errs = append(errs, ValidationError{Field: egressPrefix + ".allow", Message: "at least one host is required for mode 'allowlist'"})
+		}
+		for j, host := range server.Egress.Allow {
+			if !validEgressHost(host) {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("%s.allow[%d]", egressPrefix, j), Message: fmt.Sprintf("invalid host '%s' (use a hostname, '*.domain', or an IP address)", host)})
+			}
+		}
+	default:
+		errs = append(errs, ValidationError{Field: egressPrefix + ".mode", Message: "must be 'none', 'internal', or 'allowlist'"})
+	}
+
+	// Published ports are unreachable on internal networks, so the gateway can
+	// only talk to restricted servers over an attached stdio stream.
+	if server.Transport != "stdio" {
+		errs = append(errs, ValidationError{Field: egressPrefix, Message: "requires transport 'stdio'"})
+	}
+
+	return errs
+}
+
+// validateToolPatterns checks that every tool selector pattern compiles.
+func validateToolPatterns(prefix string, patterns []string) ValidationErrors {
+	var errs ValidationErrors
+	for j, pattern := range patterns {
+		if err := ValidateToolPattern(pattern); err != nil {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("%s[%d]", prefix, j), Message: err.Error()})
+		}
+	}
+	return errs
+}
+
+// minToolNameMaxLength leaves room for a truncated name's hash suffix.
+const minToolNameMaxLength = 16
+
+// validateToolNaming checks the tool naming scheme and reports servers whose
+// tool prefixes collide, since their tools would shadow each other.
+func validateToolNaming(s *Stack) ValidationErrors {
+	var errs ValidationErrors
+
+	if s.ToolNaming.Separator != "" && !validToolName(s.ToolNaming.Separator) {
+		errs = append(errs, ValidationError{Field: "tool_naming.separator", Message: "must only contain letters, digits, '_' and '-'"})
+	}
+	if s.ToolNaming.MaxLength != 0 && s.ToolNaming.MaxLength < minToolNameMaxLength {
+		errs = append(errs, ValidationError{Field: "tool_naming.max_length", Message: fmt.Sprintf("must be at least %d", minToolNameMaxLength)})
+	}
+
+	// A2A agents used as skills are exposed with their name as prefix
+	prefixes := make(map[string]string) // prefix -> owner description
+	if s.HasBuiltinTools() {
+		prefixes[BuiltinToolPrefix] = "the gateway's built-in tools"
+	}
+	for _, agent := range s.Agents {
+		if agent.IsA2AEnabled() {
+			prefixes[agent.Name] = fmt.Sprintf("agent '%s'", agent.Name)
+		}
+	}
+	for _, agent := range s.A2AAgents {
+		prefixes[agent.Name] = fmt.Sprintf("a2a-agent '%s'", agent.Name)
+	}
+
+	for i := range s.MCPServers {
+		server := &s.MCPServers[i]
+		prefix := fmt.Sprintf("mcp-servers[%d].prefix", i)
+		if server.Prefix != nil && server.Prefix.Alias != "" && !validToolName(server.Prefix.Alias) {
+			errs = append(errs, ValidationError{Field: prefix, Message: fmt.Sprintf("invalid prefix '%s' (use letters, digits, '_' and '-')", server.Prefix.Alias)})
+			continue
+		}
+		p := server.ToolNamePrefix()
+		if p == "" {
+			continue // Unprefixed; tool-level collisions are reported at registration
+		}
+		if owner, ok := prefixes[p]; ok {
+			errs = append(errs, ValidationError{Field: prefix, Message: fmt.Sprintf("tool prefix '%s' conflicts with %s", p, owner)})
+			continue
+		}
+		prefixes[p] = fmt.Sprintf("mcp-server '%s'", server.Name)
+	}
+
+	return errs
+}
+
+// validateToolsets checks toolset names and that their selectors reference
+// known servers or A2A agents with valid tool patterns.
+func validateToolsets(s *Stack, serverNames, a2aEnabledAgents map[string]bool) ValidationErrors {
+	var errs ValidationErrors
+
+	a2aAgentNames := make(map[string]bool)
+	for _, agent := range s.A2AAgents {
+		a2aAgentNames[agent.Name] = true
+	}
+
+	names := make(map[string]bool)
+	for i, ts := range s.Toolsets {
+		prefix := fmt.Sprintf("toolsets[%d]", i)
+		if ts.Name == "" {
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: "is required"})
+		} else if !validToolName(ts.Name) {
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("invalid name '%s' (use letters, digits, '_' and '-')", ts.Name)})
+		} else if names[ts.Name] {
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("duplicate toolset name '%s'", ts.Name)})
+		} else {
+			names[ts.Name] = true
+		}
+
+		if len(ts.Tools) == 0 {
+			errs = append(errs, ValidationError{Field: prefix + ".tools", Message: "at least one server is required"})
+		}
+		for j, selector := range ts.Tools {
+			if !serverNames[selector.Server] && !a2aEnabledAgents[selector.Server] && !a2aAgentNames[selector.Server] && !s.servesCompositeTools(selector.Server) {
+				errs = append(errs, ValidationError{
+					Field:   fmt.Sprintf("%s.tools[%d]", prefix, j),
+					Message: fmt.Sprintf("'%s' not found in mcp-servers or A2A agents", selector.Server),
+				})
+			}
+			errs = append(errs, validateToolPatterns(fmt.Sprintf("%s.tools[%d].tools", prefix, j), selector.Tools)...)
+		}
+	}
+
+	return errs
+}
+
+// validateAudit checks the audit log settings.
+func validateAudit(a *Audit) ValidationErrors {
+	var errs ValidationErrors
+	for i, pattern := range a.Redact {
+		if _, err := path.Match(strings.ToLower(pattern), ""); pattern == "" || err != nil {
+			errs = append(errs, ValidationError{Field: fmt.Sprintf("audit.redact[%d]", i), Message: fmt.Sprintf("invalid pattern '%s'", pattern)})
+		}
+	}
+	if n, err := a.MaxSizeBytes(); err != nil || n < 0 {
+		errs = append(errs, ValidationError{Field: "audit.max_size", Message: fmt.Sprintf("invalid size '%s' (use e.g. '10m')", a.MaxSize)})
+	}
+	if a.MaxFiles < 0 {
+		errs = append(errs, ValidationError{Field: "audit.max_files", Message: "must not be negative"})
+	}
+	return errs
+}
+
+// validateCallLimits checks a timeout, concurrency limit and rate limit.
+func validateCallLimits(prefix string, l *CallLimits) ValidationErrors {
+	var errs ValidationErrors
+	if d, err := l.TimeoutDuration(); err != nil || d < 0 {
+		errs = append(errs, ValidationError{Field: prefix + ".timeout", Message: fmt.Sprintf("invalid duration '%s' (use e.g. '30s' or '2m')", l.Timeout)})
+	}
+	if l.MaxConcurrency < 0 {
+		errs = append(errs, ValidationError{Field: prefix + ".max_concurrency", Message: "must not be negative"})
+	}
+	if r := l.RateLimit; r != nil {
+		if r.Rate == "" {
+			errs = append(errs, ValidationError{Field: prefix + ".rate_limit.rate", Message: "is required"})
+		} else if _, _, err := r.Parse(); err != nil {
+			errs = append(errs, ValidationError{Field: prefix + ".rate_limit.rate", Message: err.Error()})
+		}
+		if r.Burst < 0 {
+			errs = append(errs, ValidationError{Field: prefix + ".rate_limit.burst", Message: "must not be negative"})
+		}
+	}
+	return errs
+}
+
+// validateCache checks cache TTLs and size.
+func validateCache(prefix string, c *Cache, tools map[string]ToolCache) ValidationErrors {
+	var errs ValidationErrors
+	if d, err := c.TTLDuration(); err != nil || d < 0 {
+		errs = append(errs, ValidationError{Field: prefix + ".cache.ttl", Message: fmt.Sprintf("invalid duration '%s' (use e.g. '5m')", c.TTL)})
+	}
+	if n, err := c.MaxSizeBytes(); err != nil || n < 0 {
+		errs = append(errs, ValidationError{Field: prefix + ".cache.max_size", Message: fmt.Sprintf("invalid size '%s' (use e.g. '10m')", c.MaxSize)})
+	}
+	for _, tool := range sortedKeys(tools) {
+		if tool == "" {
+			errs = append(errs, ValidationError{Field: prefix + ".tool_cache", Message: "tool name must not be empty"})
+			continue
+		}
+		tc := tools[tool]
+		if d, err := tc.TTLDuration(); err != nil || d < 0 {
+			errs = append(errs, ValidationError{Field: prefix + ".tool_cache." + tool + ".ttl", Message: fmt.Sprintf("invalid duration '%s' (use e.g. '5m')", tc.TTL)})
+		}
+	}
+	return errs
+}
+
+// validateTracing checks the collector URL and sample ratio.
+func validateTracing(t *Tracing) ValidationErrors {
+	var errs ValidationErrors
+	if t.Endpoint != "" {
+		if u, err := url.Parse(t.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, ValidationError{Field: "tracing.endpoint", Message: fmt.Sprintf("must be an http or https URL, got '%s'", t.Endpoint)})
+		}
+	}
+	if len(t.Headers) > 0 && t.Endpoint == "" {
+		errs = append(errs, ValidationError{Field: "tracing.headers", Message: "requires tracing.endpoint"})
+	}
+	if r := t.Ratio(); r < 0 || r > 1 {
+		errs = append(errs, ValidationError{Field: "tracing.sample_ratio", Message: "must be between 0 and 1"})
+	}
+	return errs
+}
+
+// servesCompositeTools reports whether name is the server that composite
+// tools are exposed under.
+func (s *Stack) servesCompositeTools(name string) bool {
+	return name == BuiltinToolPrefix && len(s.CompositeTools) > 0
+}
+
+// validateCompositeTools checks composite tool names, steps, and templates.
+func validateCompositeTools(s *Stack) ValidationErrors {
+	var errs ValidationErrors
+
+	names := make(map[string]bool)
+	for i, tool := range s.CompositeTools {
+		prefix := fmt.Sprintf("composite_tools[%d]", i)
+		if tool.Name == "" {
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: "is required"})
+		} else if !validToolName(tool.Name) {
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("invalid name '%s' (use letters, digits, '_' and '-')", tool.Name)})
+		} else if names[tool.Name] {
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("duplicate composite tool name '%s'", tool.Name)})
+		} else {
+			names[tool.Name] = true
+		}
+
+		if t, ok := tool.InputSchema["type"]; ok && t != "object" {
+			errs = append(errs, ValidationError{Field: prefix + ".input_schema.type", Message: "must be 'object'"})
+		}
+
+		if len(tool.Steps) == 0 {
+			errs = append(errs, ValidationError{Field: prefix + ".steps", Message: "at least one step is required"})
+		}
+		stepIDs := make(map[string]bool)
+		for j, step := range tool.Steps {
+			stepPrefix := fmt.Sprintf("%s.steps[%d]", prefix, j)
+			if step.ID == "" {
+				errs = append(errs, ValidationError{Field: stepPrefix + ".id", Message: "is required"})
+			} else if !validTemplateField(step.ID) {
+				errs = append(errs, ValidationError{Field: stepPrefix + ".id", Message: fmt.Sprintf("invalid id '%s' (use letters, digits and '_', not starting with a digit)", step.ID)})
+			} else if stepIDs[step.ID] {
+				errs = append(errs, ValidationError{Field: stepPrefix + ".id", Message: fmt.Sprintf("duplicate step id '%s'", step.ID)})
+			} else {
+				stepIDs[step.ID] = true
+			}
+
+			if step.Tool == "" {
+				errs = append(errs, ValidationError{Field: stepPrefix + ".tool", Message: "is required"})
+			}
+			if step.OnError != "" && step.OnError != OnErrorFail && step.OnError != OnErrorContinue {
+				errs = append(errs, ValidationError{Field: stepPrefix + ".on_error", Message: "must be 'fail' or 'continue'"})
+			}
+			if step.If != "" {
+				if _, err := ParseCompositeTemplate(step.If); err != nil {
+					errs = append(errs, ValidationError{Field: stepPrefix + ".if", Message: fmt.Sprintf("invalid template: %v", err)})
+				}
+			}
+			errs = append(errs, validateTemplateValues(stepPrefix+".args", step.Args)...)
+		}
+
+		if tool.Output != "" {
+			if _, err := ParseCompositeTemplate(tool.Output); err != nil {
+				errs = append(errs, ValidationError{Field: prefix + ".output", Message: fmt.Sprintf("invalid template: %v", err)})
+			}
+		}
+	}
+
+	return errs
+}
+
+// validatePolicies checks policy names, scopes, conditions and redact
+// paths.
+func validatePolicies(s *Stack, serverNames, a2aEnabledAgents map[string]bool) ValidationErrors {
+	var errs ValidationErrors
+
+	a2aAgentNames := make(map[string]bool)
+	for _, agent := range s.A2AAgents {
+		a2aAgentNames[agent.Name] = true
+	}
+	agentNames := make(map[string]bool)
+	for _, agent := range s.Agents {
+		agentNames[agent.Name] = true
+	}
+
+	names := make(map[string]bool)
+	for i, p := range s.Policies {
+		prefix := fmt.Sprintf("policies[%d]", i)
+		if p.Name == "" {
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: "is required"})
+		} else if names[p.Name] {
+			errs = append(errs, ValidationError{Field: prefix + ".name", Message: fmt.Sprintf("duplicate policy name '%s'", p.Name)})
+		} else {
+			names[p.Name] = true
+		}
+
+		switch p.Action {
+		case PolicyDeny, PolicyAllow:
+			if len(p.Redact) > 0 {
+				errs = append(errs, ValidationError{Field: prefix + ".redact", Message: "is only used with action 'redact'"})
+			}
+		case PolicyRedact:
+			if len(p.Redact) == 0 {
+				errs = append(errs, ValidationError{Field: prefix + ".redact", Message: "at least one path is required"})
+			}
+		default:
+			errs = append(errs, ValidationError{Field: prefix + ".action", Message: "must be 'deny', 'allow' or 'redact'"})
+		}
+
+		for j, agent := range p.Agents {
+			if !agentNames[agent] {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("%s.agents[%d]", prefix, j), Message: fmt.Sprintf("'%s' not found in agents", agent)})
+			}
+		}
+		if p.Server != "" && !serverNames[p.Server] && !a2aEnabledAgents[p.Server] && !a2aAgentNames[p.Server] && !s.servesCompositeTools(p.Server) {
+			errs = append(errs, ValidationError{Field: prefix + ".server", Message: fmt.Sprintf("'%s' not found in mcp-servers or A2A agents", p.Server)})
+		}
+		errs = append(errs, validateToolPatterns(prefix+".tools", p.Tools)...)
+		for j, c := range p.When {
+			if _, err := compileCondition(c); err != nil {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("%s.when[%d]", prefix, j), Message: err.Error()})
+			}
+		}
+		for j, raw := range p.Redact {
+			if _, err := parseJSONPath(raw); err != nil {
+				errs = append(errs, ValidationError{Field: fmt.Sprintf("%s.redact[%d]", prefix, j), Message: err.Error()})
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateTemplateValues checks the templates in composite step arguments,
+// including those nested in maps and lists.
+func validateTemplateValues(field string, value any) ValidationErrors {
+	switch v := value.(type) {
+	case string:
+		if _, err := ParseCompositeTemplate(v); err != nil {
+			return ValidationErrors{{Field: field, Message: fmt.Sprintf("invalid template: %v", err)}}
+		}
+	case map[string]any:
+		var errs ValidationErrors
+		for _, key := range sortedKeys(v) {
+			errs = append(errs, validateTemplateValues(field+"."+key, v[key])...)
+		}
+		return errs
+	case []any:
+		var errs ValidationErrors
+		for i, item := range v {
+			errs = append(errs, validateTemplateValues(fmt.Sprintf("%s[%d]", field, i), item)...)
+		}
+		return errs
+	}
+	return nil
+}
+
+// validTemplateField reports whether name can be used as a template field,
+// as in {{ .steps.name }}.
+func validTemplateField(name string) bool {
+	for i, r := range name {
+		isLetter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_'
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// validateToolOverrides checks exposed names and parameter rewrites.
+func validateToolOverrides(prefix string, overrides map[string]ToolOverride) ValidationErrors {
+	var errs ValidationErrors
+
+	exposed := make(map[string]string) // exposed name -> original tool
+	for _, tool := range sortedKeys(overrides) {
+		ov := overrides[tool]
+		p := prefix + "." + tool
+		if tool == "" {
+			errs = append(errs, ValidationError{Field: prefix, Message: "tool name must not be empty"})
+			continue
+		}
+
+		for i, name := range ov.ExposedNames(tool) {
+			if (i > 0 || ov.Name != "") && !validToolName(name) {
+				errs = append(errs, ValidationError{Field: p, Message: fmt.Sprintf("invalid tool name '%s' (use letters, digits, '_' and '-')", name)})
+			} else if other, ok := exposed[name]; ok {
+				errs = append(errs, ValidationError{Field: p, Message: fmt.Sprintf("name '%s' is already used by tool '%s'", name, other)})
+			} else {
+				exposed[name] = tool
+			}
+		}
+
+		removed := make(map[string]bool)
+		for _, param := range ov.HiddenParams {
+			removed[param] = true
+		}
+		for _, param := range sortedKeys(ov.PinnedParams) {
+			if removed[param] {
+				errs = append(errs, ValidationError{Field: p, Message: fmt.Sprintf("parameter '%s' cannot be both hidden and pinned", param)})
+			}
+			removed[param] = true
+		}
+
+		for _, key := range sortedKeys(ov.Schema) {
+			switch key {
+			case "properties", "required":
+			case "additionalProperties":
+				if ov.Schema[key] != false {
+					errs = append(errs, ValidationError{Field: p + ".schema.additionalProperties", Message: "can only be set to false"})
+				}
+			default:
+				errs = append(errs, ValidationError{Field: p + ".schema." + key, Message: "not allowed (overrides can set properties, required and additionalProperties: false)"})
+			}
+		}
+		if props, ok := ov.Schema["properties"]; ok {
+			m, isMap := props.(map[string]any)
+			if !isMap {
+				errs = append(errs, ValidationError{Field: p + ".schema.properties", Message: "must be a mapping"})
+			}
+			for _, param := range sortedKeys(m) {
+				field := p + ".schema.properties." + param
+				constraints, isMap := m[param].(map[string]any)
+				if !isMap {
+					errs = append(errs, ValidationError{Field: field, Message: "must be a mapping"})
+				}
+				if removed[param] {
+					errs = append(errs, ValidationError{Field: field, Message: "cannot tighten a hidden or pinned parameter"})
+				}
+				for _, key := range sortedKeys(constraints) {
+					if msg := checkSchemaConstraint(key, constraints[key]); msg != "" {
+						errs = append(errs, ValidationError{Field: field + "." + key, Message: msg})
+					}
+				}
+			}
+		}
+		if req, ok := ov.Schema["required"]; ok {
+			list, isList := req.([]any)
+			if !isList {
+				errs = append(errs, ValidationError{Field: p + ".schema.required", Message: "must be a list of parameter names"})
+			}
+			for _, v := range list {
+				if name, isString := v.(string); !isString {
+					errs = append(errs, ValidationError{Field: p + ".schema.required", Message: "must be a list of parameter names"})
+				} else if removed[name] {
+					errs = append(errs, ValidationError{Field: p + ".schema.required", Message: fmt.Sprintf("cannot require hidden or pinned parameter '%s'", name)})
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// checkSchemaConstraint returns why a tool override cannot set a parameter
+// schema key, or "" if it can. Only constraints that narrow what the
+// parameter accepts are allowed, so an override cannot change a parameter's
+// type or loosen the server's schema.
+func checkSchemaConstraint(key string, value any) string {
+	switch key {
+	case "enum":
+		if list, ok := value.([]any); !ok || len(list) == 0 {
+			return "must be a non-empty list"
+		}
+	case "pattern":
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case "minimum", "maximum":
+		switch value.(type) {
+		case int, int64, uint64, float64:
+		default:
+			return "must be a number"
+		}
+	case "minLength", "maxLength", "minItems", "maxItems":
+		if n, ok := value.(int); !ok || n < 0 {
+			return "must be a non-negative integer"
+		}
+	default:
+		return "not allowed (overrides can set enum, pattern, minLength, maxLength, minimum, maximum, minItems and maxItems)"
+	}
+	return ""
+}
+
+// sortedKeys returns a map's keys in sorted order, for deterministic errors.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validToolName reports whether name is usable in an MCP tool name.
+func validToolName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// validEgressHost reports whether host is a hostname, "*.domain" wildcard, or IP address.
+func validEgressHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	host = strings.TrimPrefix(host, "*.")
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// validCapability reports whether c looks like a Linux capability name
+// (e.g., "NET_ADMIN", "CAP_CHOWN", or "ALL").
+func validCapability(c string) bool {
+	if c == "" {
+		return false
+	}
+	for _, r := range c {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateNoContainerOptions rejects container-only settings on servers without a container.
+func validateNoContainerOptions(prefix string, server *MCPServer, rejectWorkDir bool, kind string) ValidationErrors {
+	var errs ValidationErrors
+	if server.ContainerSecurity.IsSet() {
+		errs = append(errs, ValidationError{Field: prefix, Message: "resource limits and security options are not applicable for " + kind})
+	}
+	if server.Egress != nil {
+		errs = append(errs, ValidationError{Field: prefix + ".egress", Message: "not applicable for " + kind})
+	}
+	if len(server.Volumes) > 0 {
+		errs = append(errs, ValidationError{Field: prefix + ".volumes", Message: "not applicable for " + kind})
+	}
+	if len(server.Tmpfs) > 0 {
+		errs = append(errs, ValidationError{Field: prefix + ".tmpfs", Message: "not applicable for " + kind})
+	}
+	if rejectWorkDir && server.WorkDir != "" {
+		errs = append(errs, ValidationError{Field: prefix + ".workdir", Message: "not applicable for " + kind})
+	}
+	return errs
+}
+
 func validateSource(s *Source, prefix string) ValidationErrors {
 	var errs ValidationErrors
 
 	switch s.Type {
 	case "git":
 		if s.URL == "" {
-			errs = append(errs, ValidationError{prefix + ".url", "is required for git source"})
+			errs = append(errs, ValidationError{Field: prefix + ".url", Message: "is required for git source"})
 		}
 		if s.Path != "" {
-			errs = append(errs, ValidationError{prefix + ".path", "should not be set for git source (use 'url' instead)"})
+			errs = append(errs, ValidationError{Field: prefix + ".path", Message: "should not be set for git source (use 'url' instead)"})
 		}
 	case "local":
 		if s.Path == "" {
-			errs = append(errs, ValidationError{prefix + ".path", "is required for local source"})
+			errs = append(errs, ValidationError{Field: prefix + ".path", Message: "is required for local source"})
 		}
 		if s.URL != "" {
-			errs = append(errs, ValidationError{prefix + ".url", "should not be set for local source (use 'path' instead)"})
+			errs = append(errs, ValidationError{Field: prefix + ".url", Message: "should not be set for local source (use 'path' instead)"})
 		}
 	case "":
-		errs = append(errs, ValidationError{prefix + ".type", "is required (must be 'git' or 'local')"})
+		errs = append(errs, ValidationError{Field: prefix + ".type", Message: "is required (must be 'git' or 'local')"})
 	default:
-		errs = append(errs, ValidationError{prefix + ".type", "must be 'git' or 'local'"})
+		errs = append(errs, ValidationError{Field: prefix + ".type", Message: "must be 'git' or 'local'"})
 	}
 
 	return errs
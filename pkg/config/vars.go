@@ -0,0 +1,287 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvFiles lists .env files. In YAML it is a single path or a list.
+type EnvFiles []string
+
+// UnmarshalYAML accepts a single path as well as a list of paths.
+func (f *EnvFiles) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*f = EnvFiles{node.Value}
+		return nil
+	}
+	var paths []string
+	if err := node.Decode(&paths); err != nil {
+		return err
+	}
+	*f = paths
+	return nil
+}
+
+// expandVars expands variables in s:
+//
+//	$VAR, ${VAR}      the value of VAR, or "" with a warning if it is unset
+//	${VAR:-default}   default if VAR is unset or empty (${VAR-default}: if unset)
+//	${VAR:?message}   an error with message if VAR is unset or empty (${VAR?message}: if unset)
+//	$$                a literal $
+//
+// Secret references, ${secret:name}, are left for ResolveSecrets. Problems
+// are returned as messages: errors, which keep the stack from loading, and
+// warnings.
+func expandVars(s string, lookup func(string) (string, bool)) (out string, problems, warnings []string) {
+	out = os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		if strings.HasPrefix(name, secretRefPrefix) {
+			return "${" + name + "}"
+		}
+
+		n := 0
+		for n < len(name) && (name[n] == '_' || isAlpha(name[n]) || (n > 0 && isDigit(name[n]))) {
+			n++
+		}
+		if n == 0 {
+			// Not a variable, such as the "$5" in "costs $5"
+			return "$" + name
+		}
+		key, op := name[:n], name[n:]
+		value, set := lookup(key)
+
+		switch {
+		case op == "":
+			if !set {
+				warnings = append(warnings, fmt.Sprintf("variable %s is not set, using an empty string (use ${%s:-} if that is intended, or ${%s:?} to require it)", key, key, key))
+			}
+			return value
+		case strings.HasPrefix(op, ":-"), strings.HasPrefix(op, "-"):
+			if !set || (op[0] == ':' && value == "") {
+				def, more, moreWarnings := expandVars(strings.TrimPrefix(op, ":")[1:], lookup)
+				problems = append(problems, more...)
+				warnings = append(warnings, moreWarnings...)
+				return def
+			}
+			return value
+		case strings.HasPrefix(op, ":?"), strings.HasPrefix(op, "?"):
+			msg := strings.TrimPrefix(op, ":")[1:]
+			if !set || (op[0] == ':' && value == "") {
+				problem := fmt.Sprintf("required variable %s is not set", key)
+				if set {
+					problem = fmt.Sprintf("required variable %s is empty", key)
+				}
+				if msg != "" {
+					problem += ": " + msg
+				}
+				problems = append(problems, problem)
+			}
+			return value
+		default:
+			problems = append(problems, fmt.Sprintf("invalid variable reference '${%s}'", name))
+			return "${" + name + "}"
+		}
+	})
+	return out, problems, warnings
+}
+
+func isAlpha(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// expandStackVars expands variables in every string of the stack, except
+// in fields tagged expand:"-", and returns the problems with each field's
+// variables: errors for required variables and invalid references, and
+// warnings for unset plain variables.
+func expandStackVars(s *Stack, lookup func(string) (string, bool)) (errs, warnings ValidationErrors) {
+	walkStrings(reflect.ValueOf(s).Elem(), "", func(field, value string) string {
+		expanded, problems, warns := expandVars(value, lookup)
+		for _, p := range problems {
+			errs = append(errs, ValidationError{Field: field, Message: p})
+		}
+		for _, w := range warns {
+			warnings = append(warnings, ValidationError{Field: field, Message: w})
+		}
+		return expanded
+	})
+	return errs, warnings
+}
+
+// walkStrings calls fn on each string reachable from v, named by its path
+// of YAML keys, and stores the value fn returns. Struct fields tagged
+// expand:"-", such as templates and regular expressions, whose $ is not a
+// variable, are skipped.
+func walkStrings(v reflect.Value, path string, fn func(field, value string) string) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(fn(path, v.String()))
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			walkStrings(v.Elem(), path, fn)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		switch elem := v.Elem(); elem.Kind() {
+		case reflect.String:
+			if v.CanSet() {
+				v.Set(reflect.ValueOf(fn(path, elem.String())))
+			}
+		case reflect.Map, reflect.Slice, reflect.Pointer:
+			walkStrings(elem, path, fn)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || f.Tag.Get("expand") == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			switch {
+			case name == "-":
+				continue
+			case strings.Contains(opts, "inline"):
+				walkStrings(v.Field(i), path, fn)
+				continue
+			case name == "":
+				name = strings.ToLower(f.Name)
+			}
+			walkStrings(v.Field(i), join(name), fn)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkStrings(v.Index(i), fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			// Map elements are not addressable: walk a copy and store it back
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			walkStrings(elem, join(key.String()), fn)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
+
+// readEnvFiles reads variables from .env files. Variables in later files
+// take precedence.
+func readEnvFiles(paths []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, path := range paths {
+		fileVars, err := readEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+	}
+	return vars, nil
+}
+
+// readEnvFile reads KEY=VALUE lines from a .env file. Blank lines and
+// lines starting with # are skipped, and an "export " prefix is allowed.
+// Values may be double-quoted, with Go escapes, or single-quoted, taken
+// literally; unquoted values end at " #".
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading env file: %w", err)
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !validEnvName(key) {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid quoted value: %w", path, n, err)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		vars[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading env file %s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// validEnvName reports whether name is a valid environment variable name.
+func validEnvName(name string) bool {
+	if name == "" || isDigit(name[0]) {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] != '_' && !isAlpha(name[i]) && !isDigit(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeEnvFile adds the variables in env files to env. Variables already
+// in env take precedence.
+func mergeEnvFile(env map[string]string, files EnvFiles, basePath string) (map[string]string, error) {
+	if len(files) == 0 {
+		return env, nil
+	}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = expandTildeAndResolvePath(file, basePath)
+	}
+	vars, err := readEnvFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	if env == nil {
+		env = make(map[string]string)
+	}
+	for k, v := range vars {
+		if _, ok := env[k]; !ok {
+			env[k] = v
+		}
+	}
+	return env, nil
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandVars(t *testing.T) {
+	vars := map[string]string{"USER": "alice", "EMPTY": "", "HOST": "db"}
+	lookup := func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+	tests := []struct {
+		in      string
+		want    string
+		problem string
+		warning string
+	}{
+		{in: "$USER@${HOST}", want: "alice@db"},
+		{in: "${MISSING}", want: "", warning: "variable MISSING is not set, using an empty string (use ${MISSING:-} if that is intended, or ${MISSING:?} to require it)"},
+		{in: "${MISSING:-$ALSO_MISSING}", want: "", warning: "variable ALSO_MISSING is not set, using an empty string (use ${ALSO_MISSING:-} if that is intended, or ${ALSO_MISSING:?} to require it)"},
+		{in: "${MISSING:-guest}", want: "guest"},
+		{in: "${EMPTY:-guest}", want: "guest"},
+		{in: "${EMPTY-guest}", want: ""},
+		{in: "${MISSING-$USER}", want: "alice"},
+		{in: "${MISSING:-}", want: ""},
+		{in: "${USER:?set USER}", want: "alice"},
+		{in: "${MISSING:?create a token first}", problem: "required variable MISSING is not set: create a token first"},
+		{in: "${EMPTY:?}", problem: "required variable EMPTY is empty"},
+		{in: "${EMPTY?}", want: ""},
+		{in: "echo $$HOME", want: "echo $HOME"},
+		{in: "costs $5", want: "costs $5"},
+		{in: "$.path and a$", want: "$.path and a$"},
+		{in: "Bearer ${secret:github_token}", want: "Bearer ${secret:github_token}"},
+		{in: "${USER+x}", want: "${USER+x}", problem: "invalid variable reference '${USER+x}'"},
+	}
+	for _, tc := range tests {
+		got, problems, warnings := expandVars(tc.in, lookup)
+		if got != tc.want {
+			t.Errorf("expandVars(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+		if tc.problem == "" && len(problems) > 0 {
+			t.Errorf("expandVars(%q): unexpected problems %v", tc.in, problems)
+		}
+		if tc.problem != "" && (len(problems) != 1 || problems[0] != tc.problem) {
+			t.Errorf("expandVars(%q): expected problem %q, got %v", tc.in, tc.problem, problems)
+		}
+		if tc.warning == "" && len(warnings) > 0 {
+			t.Errorf("expandVars(%q): unexpected warnings %v", tc.in, warnings)
+		}
+		if tc.warning != "" && (len(warnings) != 1 || warnings[0] != tc.warning) {
+			t.Errorf("expandVars(%q): expected warning %q, got %v", tc.in, tc.warning, warnings)
+		}
+	}
+}
+
+func TestReadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := `# Comment
+export TOKEN=abc123
+PLAIN = value with spaces   # trailing comment
+DOUBLE="line1\nline2 # kept"
+SINGLE='$literal # kept'
+EMPTY=
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vars, err := readEnvFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"TOKEN":  "abc123",
+		"PLAIN":  "value with spaces",
+		"DOUBLE": "line1\nline2 # kept",
+		"SINGLE": "$literal # kept",
+		"EMPTY":  "",
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("got %v, want %v", vars, want)
+	}
+
+	if err := os.WriteFile(path, []byte("OK=1\nnot a variable\n"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := readEnvFile(path); err == nil || !strings.Contains(err.Error(), ".env:2: expected KEY=VALUE") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
+
+func TestLoadStack_EnvFiles(t *testing.T) {
+	t.Setenv("GRIDCTL_TEST_REGISTRY", "ghcr.io")
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":          "GRIDCTL_TEST_TAG=1.0\nGRIDCTL_TEST_PORT=9000\nGRIDCTL_TEST_REGISTRY=ignored\n",
+		"override.env":  "GRIDCTL_TEST_TAG=2.0\n",
+		"github.env":    "GITHUB_TOKEN=from-file\nLOG_LEVEL=debug\n",
+		"resources.env": "POSTGRES_DB=app\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	content := `
+name: test
+env_file: .env
+network:
+  name: test-net
+mcp-servers:
+  - name: github
+    image: ${GRIDCTL_TEST_REGISTRY}/github/server:${GRIDCTL_TEST_TAG}
+    port: 3000
+    command: ["serve", "--port", "${GRIDCTL_TEST_PORT}", "--home", "$$HOME"]
+    env_file: [github.env]
+    env:
+      LOG_LEVEL: info
+  - name: remote
+    url: https://${GRIDCTL_TEST_HOST:-example.com}/mcp
+resources:
+  - name: postgres
+    image: postgres:16
+    env_file: resources.env
+    ports: ["${GRIDCTL_TEST_PORT}:5432"]
+`
+	path := filepath.Join(dir, "stack.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("LoadStack failed: %v", err)
+	}
+	github := stack.MCPServers[0]
+	if github.Image != "ghcr.io/github/server:1.0" {
+		t.Errorf("expected the process environment to win over env_file, got image %q", github.Image)
+	}
+	if !reflect.DeepEqual(github.Command, []string{"serve", "--port", "9000", "--home", "$HOME"}) {
+		t.Errorf("unexpected command: %v", github.Command)
+	}
+	if github.Env["GITHUB_TOKEN"] != "from-file" || github.Env["LOG_LEVEL"] != "info" {
+		t.Errorf("expected env_file variables under env, got %v", github.Env)
+	}
+	if stack.MCPServers[1].URL != "https://example.com/mcp" {
+		t.Errorf("unexpected URL: %q", stack.MCPServers[1].URL)
+	}
+	if r := stack.Resources[0]; r.Ports[0] != "9000:5432" || r.Env["POSTGRES_DB"] != "app" {
+		t.Errorf("unexpected resource: %+v", r)
+	}
+
+	// Env files passed when loading override the stack's
+	stack, err = LoadStackWithOptions(path, LoadOptions{EnvFiles: []string{filepath.Join(dir, "override.env")}})
+	if err != nil {
+		t.Fatalf("LoadStackWithOptions failed: %v", err)
+	}
+	if stack.MCPServers[0].Image != "ghcr.io/github/server:2.0" {
+		t.Errorf("expected override.env to win, got image %q", stack.MCPServers[0].Image)
+	}
+}
+
+func TestLoadStack_UnresolvedVars(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+mcp-servers:
+  - name: github
+    image: ghcr.io/github/server:latest
+    port: 3000
+    env:
+      GITHUB_TOKEN: ${GRIDCTL_TEST_UNSET_TOKEN:?create a token at github.com/settings/tokens}
+      ORG: $GRIDCTL_TEST_UNSET_ORG
+`
+	path := writeTempFile(t, content)
+	_, err := LoadStack(path)
+	if err == nil {
+		t.Fatal("expected a missing required variable to fail validation")
+	}
+	if want := "mcp-servers[0].env.GITHUB_TOKEN: required variable GRIDCTL_TEST_UNSET_TOKEN is not set: create a token at github.com/settings/tokens"; !strings.Contains(err.Error(), want) {
+		t.Errorf("expected error containing %q, got: %v", want, err)
+	}
+	if strings.Contains(err.Error(), "GRIDCTL_TEST_UNSET_ORG") {
+		t.Errorf("expected an unset plain variable not to be an error, got: %v", err)
+	}
+
+	// Unset plain variables are warnings, as in compose
+	path = writeTempFile(t, strings.Replace(content, "${GRIDCTL_TEST_UNSET_TOKEN:?create a token at github.com/settings/tokens}", "token", 1))
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("expected an unset plain variable to load, got %v", err)
+	}
+	warnings := stack.LoadWarnings()
+	if len(warnings) != 1 || warnings[0].Field != "mcp-servers[0].env.ORG" || warnings[0].Line != 11 ||
+		!strings.Contains(warnings[0].Message, "variable GRIDCTL_TEST_UNSET_ORG is not set") {
+		t.Errorf("expected a warning for ORG on line 11, got %+v", warnings)
+	}
+
+	stack, err = LoadStackWithOptions(path, LoadOptions{AllowUnresolved: true})
+	if err != nil {
+		t.Fatalf("expected AllowUnresolved to load the stack, got %v", err)
+	}
+	if stack.MCPServers[0].Env["ORG"] != "" {
+		t.Errorf("expected an empty value, got %q", stack.MCPServers[0].Env["ORG"])
+	}
+
+	path = writeTempFile(t, "name: test\nenv_file: missing.env\nnetwork:\n  name: test-net\n")
+	if _, err := LoadStack(path); err == nil || !strings.Contains(err.Error(), "reading env file") {
+		t.Errorf("expected a missing env_file to fail, got %v", err)
+	}
+}
+
+func TestLoadStack_TemplatesNotExpanded(t *testing.T) {
+	content := `
+name: test
+network:
+  name: test-net
+mcp-servers:
+  - name: github
+    url: https://example.com/mcp
+composite_tools:
+  - name: label
+    description: Label several issues
+    steps:
+      - id: each
+        tool: github__add_labels
+        if: "{{ $n := len .inputs.items }}{{ gt $n 0 }}"
+        args:
+          issues: "{{ range $i, $v := .inputs.items }}{{ $v }} {{ end }}"
+    output: "{{ $out := .steps.each.text }}{{ $out }}"
+policies:
+  - name: no-dotfiles
+    action: deny
+    when:
+      - path: $.path
+        regex: /\.[^/]+$
+`
+	stack, err := LoadStack(writeTempFile(t, content))
+	if err != nil {
+		t.Fatalf("expected templates and patterns to load unexpanded, got %v", err)
+	}
+	if w := stack.LoadWarnings(); len(w) > 0 {
+		t.Errorf("unexpected warnings: %v", w)
+	}
+	tool := stack.CompositeTools[0]
+	step := tool.Steps[0]
+	if got := step.Args["issues"]; got != "{{ range $i, $v := .inputs.items }}{{ $v }} {{ end }}" {
+		t.Errorf("args were expanded: %q", got)
+	}
+	if step.If != "{{ $n := len .inputs.items }}{{ gt $n 0 }}" {
+		t.Errorf("if was expanded: %q", step.If)
+	}
+	if tool.Output != "{{ $out := .steps.each.text }}{{ $out }}" {
+		t.Errorf("output was expanded: %q", tool.Output)
+	}
+	if got := stack.Policies[0].When[0].Regex; got != `/\.[^/]+$` {
+		t.Errorf("regex was expanded: %q", got)
+	}
+}
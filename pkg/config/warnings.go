@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretKeyPattern matches env and header names that usually hold
+// credentials.
+var secretKeyPattern = regexp.MustCompile(`(?i)(token|secret|password|passwd|api_?key|private_?key|authorization)`)
+
+// warnings reports likely mistakes in a stack that do not keep it from
+// loading: images that may change under it, credentials written into the
+// stack file, and secrets declared but never used.
+func (d *document) warnings(s *Stack) ValidationErrors {
+	var warns ValidationErrors
+
+	mutable := func(field, image string) {
+		if image != "" && !pinnedImage(image) {
+			warns = append(warns, ValidationError{Field: field, Message: fmt.Sprintf("image '%s' has no tag or uses 'latest'; pin a version so deploys are repeatable", image)})
+		}
+	}
+	for i, server := range s.MCPServers {
+		mutable(fmt.Sprintf("mcp-servers[%d].image", i), server.Image)
+	}
+	for i, res := range s.Resources {
+		mutable(fmt.Sprintf("resources[%d].image", i), res.Image)
+	}
+	for i, agent := range s.Agents {
+		mutable(fmt.Sprintf("agents[%d].image", i), agent.Image)
+	}
+
+	// Expansion has replaced variables in the stack, so look at the values
+	// as written
+	for _, section := range []string{"mcp-servers", "resources", "agents"} {
+		list := mapGet(d.root, section)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for i, item := range list.Content {
+			for _, key := range []string{"env", "headers"} {
+				warns = append(warns, literalSecrets(mapGet(item, key), fmt.Sprintf("%s[%d].%s", section, i, key))...)
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, name := range s.SecretRefs() {
+		used[name] = true
+	}
+	for _, name := range sortedKeys(s.Secrets) {
+		if !used[name] {
+			warns = append(warns, ValidationError{Field: "secrets." + name, Message: "secret is declared but never referenced"})
+		}
+	}
+	return warns
+}
+
+// literalSecrets reports the entries of an env or headers mapping whose
+// names suggest credentials and whose values are written out rather than
+// referenced.
+func literalSecrets(m *yaml.Node, path string) ValidationErrors {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	var warns ValidationErrors
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key, value := m.Content[i].Value, scalarValue(m.Content[i+1])
+		if secretKeyPattern.MatchString(key) && value != "" && !strings.Contains(value, "$") {
+			warns = append(warns, ValidationError{Field: joinField(path, key), Message: fmt.Sprintf("looks like a credential written into the stack; use ${secret:%s} instead", strings.ToLower(key))})
+		}
+	}
+	return warns
+}
+
+// pinnedImage reports whether an image reference names a digest or a tag
+// other than latest.
+func pinnedImage(image string) bool {
+	if strings.Contains(image, "@") {
+		return true
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, ok := strings.Cut(name, ":")
+	return ok && tag != "latest"
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckStack(t *testing.T) {
+	content := `name: test
+network:
+  name: test-net
+secrets:
+  github-token:
+    provider: env
+  unused:
+    provider: env
+mcp-servers:
+  - name: github
+    image: ghcr.io/github/server:latest
+    port: 3000
+    env:
+      GITHUB_TOKEN: ${secret:github-token}
+      API_KEY: abc123
+      LOG_LEVEL: debug
+  - name: pinned
+    image: registry.local:5000/server:1.2
+    port: 3001
+  - name: digest
+    image: server@sha256:0123
+    port: 3002
+  - name: broken
+agents:
+  - name: agent
+    image: agent
+    uses: [github]
+`
+	path := writeTempFile(t, content)
+	errs, warnings, err := CheckStack(path, LoadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) == 0 || errs[0].Field != "mcp-servers[3]" || errs[0].Line != 23 {
+		t.Errorf("expected errors for the broken server at line 23, got %v", errs)
+	}
+
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.Error())
+	}
+	want := []string{
+		path + ":11:5: mcp-servers[0].image: image 'ghcr.io/github/server:latest' has no tag or uses 'latest'",
+		path + ":26:5: agents[0].image: image 'agent' has no tag",
+		path + ":15:7: mcp-servers[0].env.API_KEY: looks like a credential written into the stack",
+		path + ":7:3: secrets.unused: secret is declared but never referenced",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d warnings, got %d:\n%s", len(want), len(got), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("warning %d: expected prefix %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestCheckStack_ParseError(t *testing.T) {
+	path := writeTempFile(t, "name: [unclosed\n")
+	if _, _, err := CheckStack(path, LoadOptions{}); err == nil {
+		t.Error("expected an error for a stack that cannot be parsed")
+	}
+}
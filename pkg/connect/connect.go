@@ -0,0 +1,285 @@
+// Package connect writes the configuration MCP clients need to reach a
+// gridctl gateway.
+package connect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"slices"
+	"time"
+)
+
+// Gateway transports clients can connect with.
+const (
+	TransportHTTP = "http" // Streamable HTTP at /mcp
+	TransportSSE  = "sse"  // Server-Sent Events at /sse
+)
+
+// AgentHeader carries the agent whose tools a client sees. Only the /mcp
+// endpoint reads it.
+const AgentHeader = "X-Agent-Name"
+
+// Options describe the gateway entry to write.
+type Options struct {
+	Name      string // Entry name in the client's config (default: "gridctl")
+	Port      int    // Gateway port
+	Agent     string // Agent whose tools the client sees (empty = all tools)
+	Transport string // TransportHTTP (default) or TransportSSE
+}
+
+// URL returns the gateway endpoint for the options' transport.
+func (o Options) URL() string {
+	if o.Transport == TransportSSE {
+		return fmt.Sprintf("http://localhost:%d/sse", o.Port)
+	}
+	return fmt.Sprintf("http://localhost:%d/mcp", o.Port)
+}
+
+// Headers returns the headers the client sends to the gateway, or nil.
+func (o Options) Headers() map[string]string {
+	if o.Agent == "" {
+		return nil
+	}
+	return map[string]string{AgentHeader: o.Agent}
+}
+
+// Client is an MCP client application and where it keeps its servers.
+type Client struct {
+	Name  string // As passed to gridctl connect
+	Title string
+	Key   string // Top-level config key holding the servers
+
+	// path returns the config file under home for an OS, using appData
+	// (%APPDATA%) on Windows.
+	path func(home, appData, goos string) string
+
+	// entry returns the config entry for a gateway endpoint.
+	entry func(url string, headers map[string]string) map[string]any
+}
+
+// Clients returns the supported clients, in the order they are listed.
+func Clients() []Client {
+	return clients
+}
+
+// Lookup returns the client with the given name.
+func Lookup(name string) (Client, bool) {
+	for _, c := range clients {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Client{}, false
+}
+
+var clients = []Client{
+	{
+		Name:  "claude-desktop",
+		Title: "Claude Desktop",
+		Key:   "mcpServers",
+		path: func(home, appData, goos string) string {
+			switch goos {
+			case "darwin":
+				return filepath.Join(home, "Library", "Application Support", "Claude", "claude_desktop_config.json")
+			case "windows":
+				return filepath.Join(appData, "Claude", "claude_desktop_config.json")
+			}
+			return filepath.Join(home, ".config", "Claude", "claude_desktop_config.json")
+		},
+		// Claude Desktop runs local servers only, so it connects through
+		// the mcp-remote bridge
+		entry: func(url string, headers map[string]string) map[string]any {
+			args := []any{"-y", "mcp-remote", url}
+			for _, k := range slices.Sorted(maps.Keys(headers)) {
+				args = append(args, "--header", k+":"+headers[k])
+			}
+			return map[string]any{"command": "npx", "args": args}
+		},
+	},
+	{
+		Name:  "claude-code",
+		Title: "Claude Code",
+		Key:   "mcpServers",
+		path: func(home, appData, goos string) string {
+			return filepath.Join(home, ".claude.json")
+		},
+		entry: typedEntry,
+	},
+	{
+		Name:  "cursor",
+		Title: "Cursor",
+		Key:   "mcpServers",
+		path: func(home, appData, goos string) string {
+			return filepath.Join(home, ".cursor", "mcp.json")
+		},
+		entry: func(url string, headers map[string]string) map[string]any {
+			return withHeaders(map[string]any{"url": url}, headers)
+		},
+	},
+	{
+		Name:  "vscode",
+		Title: "VS Code",
+		Key:   "servers",
+		path: func(home, appData, goos string) string {
+			switch goos {
+			case "darwin":
+				return filepath.Join(home, "Library", "Application Support", "Code", "User", "mcp.json")
+			case "windows":
+				return filepath.Join(appData, "Code", "User", "mcp.json")
+			}
+			return filepath.Join(home, ".config", "Code", "User", "mcp.json")
+		},
+		entry: typedEntry,
+	},
+	{
+		Name:  "windsurf",
+		Title: "Windsurf",
+		Key:   "mcpServers",
+		path: func(home, appData, goos string) string {
+			return filepath.Join(home, ".codeium", "windsurf", "mcp_config.json")
+		},
+		entry: func(url string, headers map[string]string) map[string]any {
+			return withHeaders(map[string]any{"serverUrl": url}, headers)
+		},
+	},
+}
+
+// typedEntry is the entry of clients that name the transport.
+func typedEntry(url string, headers map[string]string) map[string]any {
+	typ := TransportHTTP
+	if filepath.Base(url) == "sse" {
+		typ = TransportSSE
+	}
+	return withHeaders(map[string]any{"type": typ, "url": url}, headers)
+}
+
+func withHeaders(entry map[string]any, headers map[string]string) map[string]any {
+	if len(headers) > 0 {
+		entry["headers"] = headers
+	}
+	return entry
+}
+
+// ConfigPath returns the client's config file for a home directory on
+// this OS.
+func (c Client) ConfigPath(home string) string {
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		appData = filepath.Join(home, "AppData", "Roaming")
+	}
+	return c.path(home, appData, goruntime.GOOS)
+}
+
+// Entry returns the config entry pointing the client at the gateway.
+func (c Client) Entry(opts Options) (map[string]any, error) {
+	switch opts.Transport {
+	case "", TransportHTTP:
+	case TransportSSE:
+		if opts.Agent != "" {
+			return nil, fmt.Errorf("the SSE endpoint does not filter tools by agent; use the %s transport", TransportHTTP)
+		}
+	default:
+		return nil, fmt.Errorf("unknown transport '%s' (expected %s or %s)", opts.Transport, TransportHTTP, TransportSSE)
+	}
+	return c.entry(opts.URL(), opts.Headers()), nil
+}
+
+// Snippet returns the client config holding only the gateway entry, for
+// pasting into a config file by hand.
+func (c Client) Snippet(opts Options) ([]byte, error) {
+	entry, err := c.Entry(opts)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(map[string]any{c.Key: map[string]any{entryName(opts): entry}}, "", "  ")
+}
+
+// now is replaced in tests.
+var now = time.Now
+
+// Write adds the gateway entry to the client's config file at path,
+// replacing an entry of the same name and keeping everything else. An
+// existing file is copied to a timestamped backup first, whose path is
+// returned; it is "" when there was no file.
+func (c Client) Write(path string, opts Options) (backup string, err error) {
+	entry, err := c.Entry(opts)
+	if err != nil {
+		return "", err
+	}
+
+	config := make(map[string]any)
+	mode := os.FileMode(0o600)
+	data, err := os.ReadFile(path)
+	exists := err == nil
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return "", err
+	case len(data) > 0:
+		if err := json.Unmarshal(data, &config); err != nil {
+			return "", fmt.Errorf("%s is not valid JSON, leaving it unchanged: %w", path, err)
+		}
+	}
+
+	servers, ok := config[c.Key].(map[string]any)
+	if !ok {
+		if config[c.Key] != nil {
+			return "", fmt.Errorf("%s: '%s' is not an object, leaving the file unchanged", path, c.Key)
+		}
+		servers = make(map[string]any)
+		config[c.Key] = servers
+	}
+	servers[entryName(opts)] = entry
+
+	if exists {
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+		backup = path + "." + now().Format("20060102-150405") + ".bak"
+		if err := os.WriteFile(backup, data, mode); err != nil {
+			return "", fmt.Errorf("backing up %s: %w", path, err)
+		}
+	}
+
+	out, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	// Replace the file in one step, so a failed write cannot leave the
+	// client's config half written
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(out, '\n')); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+func entryName(opts Options) string {
+	if opts.Name == "" {
+		return "gridctl"
+	}
+	return opts.Name
+}
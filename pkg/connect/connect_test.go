@@ -0,0 +1,152 @@
+package connect
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Entry(t *testing.T) {
+	tests := []struct {
+		client string
+		opts   Options
+		want   string
+	}{
+		{"claude-desktop", Options{Port: 8180}, `{"args":["-y","mcp-remote","http://localhost:8180/mcp"],"command":"npx"}`},
+		{"claude-desktop", Options{Port: 8180, Agent: "coder"}, `{"args":["-y","mcp-remote","http://localhost:8180/mcp","--header","X-Agent-Name:coder"],"command":"npx"}`},
+		{"claude-code", Options{Port: 9000, Agent: "coder"}, `{"headers":{"X-Agent-Name":"coder"},"type":"http","url":"http://localhost:9000/mcp"}`},
+		{"cursor", Options{Port: 8180, Transport: TransportSSE}, `{"url":"http://localhost:8180/sse"}`},
+		{"vscode", Options{Port: 8180, Transport: TransportSSE}, `{"type":"sse","url":"http://localhost:8180/sse"}`},
+		{"windsurf", Options{Port: 8180}, `{"serverUrl":"http://localhost:8180/mcp"}`},
+	}
+	for _, tt := range tests {
+		c, ok := Lookup(tt.client)
+		if !ok {
+			t.Fatalf("client %s not found", tt.client)
+		}
+		entry, err := c.Entry(tt.opts)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.client, err)
+		}
+		got, _ := json.Marshal(entry)
+		if string(got) != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.client, tt.want, got)
+		}
+	}
+
+	c, _ := Lookup("cursor")
+	if _, err := c.Entry(Options{Port: 8180, Agent: "coder", Transport: TransportSSE}); err == nil {
+		t.Error("expected an error for an agent over SSE")
+	}
+	if _, err := c.Entry(Options{Port: 8180, Transport: "ws"}); err == nil {
+		t.Error("expected an error for an unknown transport")
+	}
+}
+
+func TestClient_ConfigPath(t *testing.T) {
+	home := t.TempDir()
+	for _, c := range Clients() {
+		path := c.ConfigPath(home)
+		if !strings.HasPrefix(path, home) && os.Getenv("APPDATA") == "" {
+			t.Errorf("%s: expected a path under the home directory, got %s", c.Name, path)
+		}
+	}
+}
+
+func TestClient_Write(t *testing.T) {
+	now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	home := t.TempDir()
+	c, _ := Lookup("cursor")
+	path := c.ConfigPath(home)
+
+	// A new file, in a directory that does not exist yet
+	backup, err := c.Write(path, Options{Port: 8180})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if backup != "" {
+		t.Errorf("expected no backup for a new file, got %s", backup)
+	}
+
+	// Other settings and servers are kept, and the entry is replaced
+	existing := `{"theme": "dark", "mcpServers": {"other": {"url": "http://example.com"}, "gridctl": {"url": "old"}}}`
+	if err := os.WriteFile(path, []byte(existing), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	backup, err = c.Write(path, Options{Port: 9000, Agent: "coder"})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if want := path + ".20260102-030405.bak"; backup != want {
+		t.Errorf("expected backup %s, got %s", want, backup)
+	}
+	if data, _ := os.ReadFile(backup); string(data) != existing {
+		t.Errorf("expected the backup to hold the old file, got %s", data)
+	}
+
+	var config map[string]any
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("written file is not JSON: %v", err)
+	}
+	want := map[string]any{
+		"theme": "dark",
+		"mcpServers": map[string]any{
+			"other":   map[string]any{"url": "http://example.com"},
+			"gridctl": map[string]any{"url": "http://localhost:9000/mcp", "headers": map[string]any{"X-Agent-Name": "coder"}},
+		},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("expected %v, got %v", want, config)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o640 {
+		t.Errorf("expected the file mode to be kept, got %v", info.Mode().Perm())
+	}
+}
+
+func TestClient_Write_Invalid(t *testing.T) {
+	c, _ := Lookup("vscode")
+	for _, content := range []string{"{not json", `{"servers": []}`} {
+		path := filepath.Join(t.TempDir(), "mcp.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Write(path, Options{Port: 8180}); err == nil {
+			t.Errorf("expected an error for %s", content)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Errorf("expected %s to be left unchanged, got %s", content, data)
+		}
+		if matches, _ := filepath.Glob(path + ".*"); len(matches) != 0 {
+			t.Errorf("expected no backup or temp files, got %v", matches)
+		}
+	}
+}
+
+func TestClient_Snippet(t *testing.T) {
+	c, _ := Lookup("vscode")
+	out, err := c.Snippet(Options{Name: "dev", Port: 8180})
+	if err != nil {
+		t.Fatalf("Snippet failed: %v", err)
+	}
+	want := `{
+  "servers": {
+    "dev": {
+      "type": "http",
+      "url": "http://localhost:8180/mcp"
+    }
+  }
+}`
+	if string(out) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, out)
+	}
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// MaskingHandler is a slog.Handler that passes the message and attribute
+// values of each record through a mask function, such as one hiding
+// secret values, before handing it to the wrapped handler.
+type MaskingHandler struct {
+	handler slog.Handler
+	mask    func(string) string
+}
+
+// NewMaskingHandler wraps h so that logged text is passed through mask.
+func NewMaskingHandler(h slog.Handler, mask func(string) string) *MaskingHandler {
+	return &MaskingHandler{handler: h, mask: mask}
+}
+
+func (m *MaskingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return m.handler.Enabled(ctx, level)
+}
+
+func (m *MaskingHandler) Handle(ctx context.Context, r slog.Record) error {
+	masked := slog.NewRecord(r.Time, r.Level, m.mask(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		masked.AddAttrs(m.maskAttr(a))
+		return true
+	})
+	return m.handler.Handle(ctx, masked)
+}
+
+func (m *MaskingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		masked[i] = m.maskAttr(a)
+	}
+	return &MaskingHandler{handler: m.handler.WithAttrs(masked), mask: m.mask}
+}
+
+func (m *MaskingHandler) WithGroup(name string) slog.Handler {
+	return &MaskingHandler{handler: m.handler.WithGroup(name), mask: m.mask}
+}
+
+// maskAttr masks string values, and the text of errors and other values
+// that print themselves, in a and any group it holds.
+func (m *MaskingHandler) maskAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, m.mask(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		masked := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			masked[i] = m.maskAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(masked...)}
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, m.mask(x.Error()))
+		case fmt.Stringer:
+			return slog.String(a.Key, m.mask(x.String()))
+		}
+	}
+	return a
+}
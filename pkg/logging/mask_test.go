@@ -0,0 +1,32 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestMaskingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	mask := strings.NewReplacer("hunter2", "***").Replace
+	logger := slog.New(NewMaskingHandler(slog.NewTextHandler(&buf, nil), mask))
+
+	logger.With("url", "https://x?key=hunter2").WithGroup("req").Info("connecting with hunter2",
+		"token", "hunter2",
+		"error", errors.New("dial https://x?key=hunter2: refused"),
+		slog.Group("auth", "header", "Bearer hunter2"),
+		"attempt", 3,
+	)
+
+	out := buf.String()
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("expected secret to be masked, got: %s", out)
+	}
+	for _, want := range []string{`msg="connecting with ***"`, `url="https://x?key=***"`, "req.token=***", `req.auth.header="Bearer ***"`, "req.attempt=3"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output: %s", want, out)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gridctl/gridctl/pkg/audit"
+)
+
+// DefaultApprovalTimeout is how long a call waits for a decision by default.
+const DefaultApprovalTimeout = 5 * time.Minute
+
+// maxDecidedApprovals is how many decided approvals are kept for listing.
+const maxDecidedApprovals = 100
+
+// ApprovalStatus is the state of an approval request.
+type ApprovalStatus string
+
+const (
+	ApprovalPending   ApprovalStatus = "pending"
+	ApprovalApproved  ApprovalStatus = "approved"
+	ApprovalRejected  ApprovalStatus = "rejected"
+	ApprovalExpired   ApprovalStatus = "expired"   // Not decided within the timeout
+	ApprovalCancelled ApprovalStatus = "cancelled" // The caller stopped waiting
+)
+
+// Why a call needs approval.
+const (
+	ReasonRequireApproval = "require_approval" // Matched the server's require_approval patterns
+	ReasonDestructive     = "destructiveHint"  // The server annotates the tool as destructive
+)
+
+// ErrApprovalNotFound is returned when deciding an approval that does not
+// exist or was already decided.
+var ErrApprovalNotFound = errors.New("approval not found or already decided")
+
+// defaultRedactor hides secrets in approval arguments when no audit log,
+// with its own redaction patterns, is set.
+var defaultRedactor, _ = audit.NewRedactor(nil)
+
+// Approval is a tool call waiting for, or decided by, a human.
+type Approval struct {
+	ID        string         `json:"id"`
+	Status    ApprovalStatus `json:"status"`
+	Reason    string         `json:"reason"`
+	SessionID string         `json:"sessionId,omitempty"`
+	Agent     string         `json:"agent,omitempty"`
+	Server    string         `json:"server"`
+	Tool      string         `json:"tool"` // Tool name on the server
+	Name      string         `json:"name"` // Tool name as called
+	Arguments map[string]any `json:"arguments,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+	DecidedAt *time.Time     `json:"decidedAt,omitempty"`
+	DecidedBy string         `json:"decidedBy,omitempty"` // Who decided, e.g. "cli", "web", or "elicitation"
+	Comment   string         `json:"comment,omitempty"`
+}
+
+// ApprovalPolicy configures how calls wait for approval.
+type ApprovalPolicy struct {
+	Timeout     time.Duration // Reject calls not decided within this long (0 = DefaultApprovalTimeout)
+	Destructive bool          // Require approval for tools annotated destructiveHint
+	Elicit      bool          // Ask the calling client via elicitation/create, if it supports it
+}
+
+// approvalDecision is a human's answer to an approval request.
+type approvalDecision struct {
+	approved bool
+	by       string
+	comment  string
+}
+
+// pendingApproval is an approval waiting for a decision.
+type pendingApproval struct {
+	approval Approval
+	decision chan approvalDecision // Buffered; the first decision wins
+}
+
+// approvalQueue holds pending approvals and recently decided ones.
+type approvalQueue struct {
+	mu      sync.Mutex
+	policy  ApprovalPolicy
+	pending map[string]*pendingApproval
+	decided []Approval // Oldest first
+}
+
+func newApprovalQueue() *approvalQueue {
+	return &approvalQueue{
+		policy:  ApprovalPolicy{Timeout: DefaultApprovalTimeout},
+		pending: make(map[string]*pendingApproval),
+	}
+}
+
+// SetApprovalPolicy sets how calls that need approval wait for it.
+func (g *Gateway) SetApprovalPolicy(p ApprovalPolicy) {
+	if p.Timeout <= 0 {
+		p.Timeout = DefaultApprovalTimeout
+	}
+	g.approvals.mu.Lock()
+	defer g.approvals.mu.Unlock()
+	g.approvals.policy = p
+}
+
+// approvalPolicy returns the current approval policy.
+func (g *Gateway) approvalPolicy() ApprovalPolicy {
+	g.approvals.mu.Lock()
+	defer g.approvals.mu.Unlock()
+	return g.approvals.policy
+}
+
+// approvalReason returns why a call to a server's tool needs approval, or
+// "" if it does not.
+func (g *Gateway) approvalReason(client AgentClient, tool string) string {
+	g.mu.RLock()
+	filter := g.approvalFilters[client.Name()]
+	g.mu.RUnlock()
+	if filter != nil && filter.Allows(tool) {
+		return ReasonRequireApproval
+	}
+	if g.approvalPolicy().Destructive {
+		if def, ok := toolDefinition(client, tool); ok && def.Annotations != nil && isTrue(def.Annotations.DestructiveHint) {
+			return ReasonDestructive
+		}
+	}
+	return ""
+}
+
+// Approvals returns pending approvals, oldest first. With decided set,
+// recently decided approvals follow, newest first.
+func (g *Gateway) Approvals(decided bool) []Approval {
+	q := g.approvals
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	out := make([]Approval, 0, len(q.pending))
+	for _, p := range q.pending {
+		out = append(out, p.approval)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	if decided {
+		for i := len(q.decided) - 1; i >= 0; i-- {
+			out = append(out, q.decided[i])
+		}
+	}
+	return out
+}
+
+// DecideApproval approves or rejects a pending call. by names who decided,
+// for the audit log. It returns ErrApprovalNotFound if the approval is not
+// pending.
+func (g *Gateway) DecideApproval(id string, approved bool, by, comment string) (Approval, error) {
+	q := g.approvals
+	q.mu.Lock()
+	p, ok := q.pending[id]
+	q.mu.Unlock()
+	if !ok {
+		return Approval{}, ErrApprovalNotFound
+	}
+	select {
+	case p.decision <- approvalDecision{approved: approved, by: by, comment: comment}:
+	default:
+		return Approval{}, ErrApprovalNotFound // Another decision got there first
+	}
+	a := p.approval
+	a.Status = ApprovalRejected
+	if approved {
+		a.Status = ApprovalApproved
+	}
+	a.DecidedBy, a.Comment = by, comment
+	return a, nil
+}
+
+// awaitApproval holds a call until it is approved, rejected, or the
+// timeout passes. It returns the decision for the audit log, and an error
+// result unless the call was approved.
+func (g *Gateway) awaitApproval(ctx context.Context, reason, server, tool, name string, args map[string]any) (*audit.Approval, *ToolCallResult) {
+	policy := g.approvalPolicy()
+	info, _ := callInfoFrom(ctx)
+	shown := defaultRedactor.Redact(args)
+	if l := g.AuditLog(); l != nil {
+		shown = l.Redact(args)
+	}
+
+	now := time.Now()
+	p := &pendingApproval{
+		approval: Approval{
+			ID:        newApprovalID(),
+			Status:    ApprovalPending,
+			Reason:    reason,
+			SessionID: info.sessionID,
+			Agent:     info.agent,
+			Server:    server,
+			Tool:      tool,
+			Name:      name,
+			Arguments: shown,
+			CreatedAt: now,
+			ExpiresAt: now.Add(policy.Timeout),
+		},
+		decision: make(chan approvalDecision, 1),
+	}
+	q := g.approvals
+	q.mu.Lock()
+	q.pending[p.approval.ID] = p
+	q.mu.Unlock()
+	g.logger.Info("tool call awaiting approval", "id", p.approval.ID, "tool", name, "agent", info.agent, "reason", reason)
+
+	waitCtx, cancel := context.WithTimeout(ctx, policy.Timeout)
+	defer cancel()
+	if policy.Elicit && info.sessionID != "" && g.sessions.CanElicit(info.sessionID) {
+		go g.elicitApproval(waitCtx, info.sessionID, p.approval)
+	}
+
+	a := p.approval
+	select {
+	case d := <-p.decision:
+		a.Status = ApprovalRejected
+		if d.approved {
+			a.Status = ApprovalApproved
+		}
+		a.DecidedBy, a.Comment = d.by, d.comment
+	case <-waitCtx.Done():
+		a.Status = ApprovalExpired
+		if ctx.Err() != nil {
+			a.Status = ApprovalCancelled
+		}
+		// Claim the decision slot so late decisions are refused
+		select {
+		case p.decision <- approvalDecision{}:
+		default:
+		}
+	}
+	decidedAt := time.Now()
+	a.DecidedAt = &decidedAt
+
+	q.mu.Lock()
+	delete(q.pending, a.ID)
+	q.decided = append(q.decided, a)
+	if len(q.decided) > maxDecidedApprovals {
+		q.decided = q.decided[len(q.decided)-maxDecidedApprovals:]
+	}
+	q.mu.Unlock()
+	g.logger.Info("tool call approval decided", "id", a.ID, "tool", name, "status", a.Status, "by", a.DecidedBy)
+
+	record := &audit.Approval{ID: a.ID, Status: string(a.Status), DecidedBy: a.DecidedBy, Comment: a.Comment}
+	var msg string
+	switch a.Status {
+	case ApprovalApproved:
+		return record, nil
+	case ApprovalRejected:
+		msg = fmt.Sprintf("Call to '%s' was rejected (approval %s)", name, a.ID)
+		if a.Comment != "" {
+			msg += ": " + a.Comment
+		}
+	case ApprovalExpired:
+		msg = fmt.Sprintf("Call to '%s' was not approved within %s (approval %s)", name, policy.Timeout, a.ID)
+	default:
+		msg = fmt.Sprintf("Call to '%s' was cancelled while awaiting approval (approval %s)", name, a.ID)
+	}
+	return record, &ToolCallResult{Content: []Content{NewTextContent(msg)}, IsError: true}
+}
+
+// elicitApproval asks the calling client's user to approve a call with an
+// elicitation/create request. Declining rejects the call; dismissing the
+// request leaves it for another approver.
+func (g *Gateway) elicitApproval(ctx context.Context, sessionID string, a Approval) {
+	args, _ := json.MarshalIndent(a.Arguments, "", "  ")
+	params := map[string]any{
+		"message": fmt.Sprintf("Allow a call to %s?\n\nArguments:\n%s", a.Name, args),
+		"requestedSchema": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"approve": map[string]any{"type": "boolean", "title": "Approve this call"},
+			},
+			"required": []string{"approve"},
+		},
+	}
+	raw, err := g.sessions.Request(ctx, sessionID, "elicitation/create", params)
+	if err != nil {
+		if ctx.Err() == nil {
+			g.logger.Warn("elicitation failed", "id", a.ID, "error", err)
+		}
+		return
+	}
+	var result struct {
+		Action  string         `json:"action"` // accept, decline, or cancel
+		Content map[string]any `json:"content"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		g.logger.Warn("invalid elicitation result", "id", a.ID, "error", err)
+		return
+	}
+	switch result.Action {
+	case "accept":
+		approved, _ := result.Content["approve"].(bool)
+		_, _ = g.DecideApproval(a.ID, approved, "elicitation", "")
+	case "decline":
+		_, _ = g.DecideApproval(a.ID, false, "elicitation", "declined by the client's user")
+	}
+}
+
+// newApprovalID returns a short random ID that is easy to type.
+func newApprovalID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// toolDefinition returns a client's tool by its original name.
+func toolDefinition(client AgentClient, name string) (Tool, bool) {
+	for _, t := range client.Tools() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Tool{}, false
+}
@@ -0,0 +1,284 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gridctl/gridctl/pkg/audit"
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+// newApprovalGateway returns a gateway with a mock "github" server whose
+// calls to tools matching patterns need approval. delete_repo is annotated
+// as destructive.
+func newApprovalGateway(t *testing.T, patterns ...string) *Gateway {
+	t.Helper()
+	yes := true
+	g := NewGateway()
+	client := NewMockAgentClient("github", []Tool{
+		{Name: "search"},
+		{Name: "create_issue"},
+		{Name: "delete_repo", Annotations: &ToolAnnotations{DestructiveHint: &yes}},
+	})
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		return &ToolCallResult{Content: []Content{NewTextContent(name + " done")}}, nil
+	})
+	g.Router().AddClient(client)
+	g.Router().RefreshTools()
+
+	filter, err := config.ParseToolFilter(patterns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filter != nil {
+		g.approvalFilters["github"] = filter
+	}
+	return g
+}
+
+// callAsync makes a tool call in the background and returns its result
+// on the channel.
+func callAsync(g *Gateway, ctx context.Context, name string, args map[string]any) <-chan *ToolCallResult {
+	done := make(chan *ToolCallResult, 1)
+	go func() {
+		result, _ := g.HandleToolsCall(ctx, ToolCallParams{Name: name, Arguments: args})
+		done <- result
+	}()
+	return done
+}
+
+// waitForApproval returns the first pending approval once there is one.
+func waitForApproval(t *testing.T, g *Gateway) Approval {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if pending := g.Approvals(false); len(pending) > 0 {
+			return pending[0]
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("no approval became pending")
+	return Approval{}
+}
+
+func TestGateway_Approval(t *testing.T) {
+	g := newApprovalGateway(t, "create_*")
+	l, err := audit.Open(filepath.Join(t.TempDir(), "audit.jsonl"), audit.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer l.Close()
+	g.SetAuditLog(l)
+	ctx := withCallInfo(context.Background(), "", "coder")
+
+	// Tools that do not match run at once
+	if text, isErr := callText(t, g, ctx, "github__search"); isErr || text != "search done" {
+		t.Fatalf("expected search to run without approval, got %q", text)
+	}
+
+	done := callAsync(g, ctx, "github__create_issue", map[string]any{"title": "bug", "token": "abc"})
+	a := waitForApproval(t, g)
+	if a.Status != ApprovalPending || a.Reason != ReasonRequireApproval || a.Agent != "coder" || a.Tool != "create_issue" {
+		t.Errorf("unexpected approval: %+v", a)
+	}
+	if a.Arguments["token"] != "[REDACTED]" || a.Arguments["title"] != "bug" {
+		t.Errorf("expected token redacted in approval arguments, got %v", a.Arguments)
+	}
+
+	decided, err := g.DecideApproval(a.ID, true, "alice", "looks fine")
+	if err != nil || decided.Status != ApprovalApproved {
+		t.Fatalf("unexpected decision: %+v (%v)", decided, err)
+	}
+	if result := <-done; result.IsError || result.Content[0].Text != "create_issue done" {
+		t.Errorf("expected approved call to run, got %+v", result)
+	}
+	if _, err := g.DecideApproval(a.ID, false, "bob", ""); !errors.Is(err, ErrApprovalNotFound) {
+		t.Errorf("expected deciding twice to fail, got %v", err)
+	}
+
+	// Rejection returns the comment to the caller
+	done = callAsync(g, ctx, "github__create_issue", nil)
+	a = waitForApproval(t, g)
+	if _, err := g.DecideApproval(a.ID, false, "alice", "not today"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result := <-done; !result.IsError || !strings.Contains(result.Content[0].Text, "was rejected (approval "+a.ID+"): not today") {
+		t.Errorf("expected rejection, got %+v", result)
+	}
+
+	if pending := g.Approvals(false); len(pending) != 0 {
+		t.Errorf("expected no pending approvals, got %+v", pending)
+	}
+	all := g.Approvals(true)
+	if len(all) != 2 || all[0].Status != ApprovalRejected || all[1].Status != ApprovalApproved || all[0].DecidedAt == nil {
+		t.Errorf("expected decided approvals newest first, got %+v", all)
+	}
+
+	calls := l.Query(audit.Filter{})
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 recorded calls, got %d", len(calls))
+	}
+	if calls[0].Approval == nil || calls[0].Approval.Status != "rejected" || calls[0].Approval.DecidedBy != "alice" || !calls[0].Error {
+		t.Errorf("expected rejection in audit log, got %+v", calls[0])
+	}
+	if calls[1].Approval == nil || calls[1].Approval.Status != "approved" || calls[1].Approval.Comment != "looks fine" {
+		t.Errorf("expected approval in audit log, got %+v", calls[1])
+	}
+	if calls[2].Approval != nil {
+		t.Errorf("expected no approval for search, got %+v", calls[2].Approval)
+	}
+}
+
+func TestGateway_ApprovalTimeout(t *testing.T) {
+	g := newApprovalGateway(t, "create_issue")
+	g.SetApprovalPolicy(ApprovalPolicy{Timeout: 20 * time.Millisecond})
+
+	text, isErr := callText(t, g, context.Background(), "github__create_issue")
+	if !isErr || !strings.Contains(text, "was not approved within 20ms") {
+		t.Errorf("expected expiry, got %q", text)
+	}
+
+	g.SetApprovalPolicy(ApprovalPolicy{Timeout: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := callAsync(g, ctx, "github__create_issue", nil)
+	waitForApproval(t, g)
+	cancel()
+	if result := <-done; !result.IsError || !strings.Contains(result.Content[0].Text, "cancelled while awaiting approval") {
+		t.Errorf("expected cancellation, got %+v", result)
+	}
+	if all := g.Approvals(true); len(all) != 2 || all[0].Status != ApprovalCancelled || all[1].Status != ApprovalExpired {
+		t.Errorf("unexpected approvals: %+v", all)
+	}
+}
+
+func TestGateway_ApprovalDestructive(t *testing.T) {
+	g := newApprovalGateway(t)
+
+	// Destructive tools need approval only when configured
+	if text, isErr := callText(t, g, context.Background(), "github__delete_repo"); isErr {
+		t.Fatalf("expected delete_repo to run, got %q", text)
+	}
+
+	g.SetApprovalPolicy(ApprovalPolicy{Destructive: true})
+	done := callAsync(g, context.Background(), "github__delete_repo", nil)
+	a := waitForApproval(t, g)
+	if a.Reason != ReasonDestructive {
+		t.Errorf("expected destructiveHint reason, got %q", a.Reason)
+	}
+	if _, err := g.DecideApproval(a.ID, true, "alice", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result := <-done; result.IsError {
+		t.Errorf("expected approved call to run, got %+v", result)
+	}
+	if text, isErr := callText(t, g, context.Background(), "github__create_issue"); isErr {
+		t.Errorf("expected create_issue to run, got %q", text)
+	}
+}
+
+func TestGateway_ApprovalElicitation(t *testing.T) {
+	g := newApprovalGateway(t, "create_issue")
+	g.SetApprovalPolicy(ApprovalPolicy{Elicit: true})
+	_, session, err := g.HandleInitializeSession("", InitializeParams{
+		Capabilities: Capabilities{Elicitation: &ElicitationCapability{}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests := make(chan Request, 1)
+	g.Sessions().SetNotifier(session.ID, func(msg Request) { requests <- msg })
+
+	for _, tc := range []struct {
+		result  string
+		wantErr bool
+	}{
+		{`{"action":"accept","content":{"approve":true}}`, false},
+		{`{"action":"accept","content":{"approve":false}}`, true},
+		{`{"action":"decline"}`, true},
+	} {
+		done := make(chan *ToolCallResult, 1)
+		go func() {
+			result, _ := g.HandleToolsCallForSession(context.Background(), session.ID, "", ToolCallParams{Name: "github__create_issue"})
+			done <- result
+		}()
+
+		req := <-requests
+		if req.Method != "elicitation/create" || req.ID == nil || !strings.Contains(string(req.Params), "Allow a call to github__create_issue?") {
+			t.Fatalf("unexpected elicitation request: %+v", req)
+		}
+		if !g.Sessions().HandleResponse(session.ID, Response{JSONRPC: "2.0", ID: req.ID, Result: json.RawMessage(tc.result)}) {
+			t.Fatal("expected the elicitation to be waiting for a response")
+		}
+		if result := <-done; result.IsError != tc.wantErr {
+			t.Errorf("%s: expected error %v, got %+v", tc.result, tc.wantErr, result)
+		}
+		if a := g.Approvals(true)[0]; a.DecidedBy != "elicitation" {
+			t.Errorf("expected decision by elicitation, got %+v", a)
+		}
+	}
+}
+
+func TestGateway_ApprovalElicitationOtherSession(t *testing.T) {
+	g := newApprovalGateway(t, "create_issue")
+	newSession := func() (*Session, chan Request) {
+		_, session, err := g.HandleInitializeSession("", InitializeParams{
+			Capabilities: Capabilities{Elicitation: &ElicitationCapability{}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		requests := make(chan Request, 1)
+		g.Sessions().SetNotifier(session.ID, func(msg Request) { requests <- msg })
+		return session, requests
+	}
+	caller, requests := newSession()
+	other, _ := newSession()
+	call := func() <-chan *ToolCallResult {
+		done := make(chan *ToolCallResult, 1)
+		go func() {
+			result, _ := g.HandleToolsCallForSession(context.Background(), caller.ID, "", ToolCallParams{Name: "github__create_issue"})
+			done <- result
+		}()
+		return done
+	}
+
+	// Elicitation is off unless configured
+	done := call()
+	a := waitForApproval(t, g)
+	select {
+	case req := <-requests:
+		t.Fatalf("expected no elicitation by default, got %+v", req)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := g.DecideApproval(a.ID, false, "cli", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-done
+
+	g.SetApprovalPolicy(ApprovalPolicy{Elicit: true})
+	done = call()
+	req := <-requests
+	accept := json.RawMessage(`{"action":"accept","content":{"approve":true}}`)
+	if g.Sessions().HandleResponse(other.ID, Response{JSONRPC: "2.0", ID: req.ID, Result: accept}) {
+		t.Error("expected a response from another session to be refused")
+	}
+	guess := json.RawMessage(`"gridctl-1"`)
+	if g.Sessions().HandleResponse(caller.ID, Response{JSONRPC: "2.0", ID: &guess, Result: accept}) {
+		t.Error("expected a guessed request ID to be refused")
+	}
+	a = waitForApproval(t, g)
+	if a.Status != ApprovalPending {
+		t.Fatalf("expected the call to still be pending, got %+v", a)
+	}
+	if _, err := g.DecideApproval(a.ID, false, "cli", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result := <-done; !result.IsError {
+		t.Errorf("expected the rejected call to fail, got %+v", result)
+	}
+}
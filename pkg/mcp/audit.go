@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/gridctl/gridctl/pkg/audit"
+)
+
+// callInfoKey is the context key for the client session and agent behind
+// a tool call, recorded in the audit log.
+type callInfoKey struct{}
+
+// callInfo identifies who made a tool call.
+type callInfo struct {
+	sessionID string
+	agent     string
+}
+
+// withCallInfo attaches the calling session and agent to ctx. Calls made
+// while handling it, such as composite tool steps, are recorded under the
+// same caller.
+func withCallInfo(ctx context.Context, sessionID, agent string) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, callInfo{sessionID: sessionID, agent: agent})
+}
+
+// callInfoFrom returns the caller attached to ctx, if any.
+func callInfoFrom(ctx context.Context) (callInfo, bool) {
+	info, ok := ctx.Value(callInfoKey{}).(callInfo)
+	return info, ok
+}
+
+// approvalKey is the context key for the approval decision of a tool
+// call, recorded in the audit log.
+type approvalKey struct{}
+
+// withApproval attaches an approval decision to ctx.
+func withApproval(ctx context.Context, a *audit.Approval) context.Context {
+	return context.WithValue(ctx, approvalKey{}, a)
+}
+
+// SetAuditLog sets the log that tool calls are recorded to. Pass nil to
+// stop recording.
+func (g *Gateway) SetAuditLog(l *audit.Log) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.auditLog = l
+}
+
+// AuditLog returns the audit log, or nil if calls are not recorded.
+func (g *Gateway) AuditLog() *audit.Log {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.auditLog
+}
+
+// recordCall records a finished tool call in the audit log, if one is set.
+// name is the tool name as called; server and tool are empty when it did
+// not resolve.
+func (g *Gateway) recordCall(ctx context.Context, name, server, tool string, args map[string]any, start time.Time, result *ToolCallResult) {
+	l := g.AuditLog()
+	if l == nil {
+		return
+	}
+
+	info, _ := callInfoFrom(ctx)
+	e := audit.Event{
+		Timestamp:  start.UTC(),
+		SessionID:  info.sessionID,
+		Agent:      info.agent,
+		Server:     server,
+		Tool:       tool,
+		Name:       name,
+		Arguments:  args,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	e.Approval, _ = ctx.Value(approvalKey{}).(*audit.Approval)
+	if result != nil {
+		e.Error = result.IsError
+		for _, c := range result.Content {
+			e.ResultSize += len(c.Text)
+		}
+	}
+	if err := l.Record(e); err != nil {
+		g.logger.Warn("failed to record tool call", "tool", name, "error", err)
+	}
+}
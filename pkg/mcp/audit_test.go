@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gridctl/gridctl/pkg/audit"
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+func TestGateway_AuditLog(t *testing.T) {
+	g := NewGateway()
+	ctx := context.Background()
+
+	client := NewMockAgentClient("github", []Tool{
+		{Name: "create_issue", Description: "Create issue"},
+		{Name: "delete_repo", Description: "Delete repository"},
+	})
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		return &ToolCallResult{Content: []Content{NewTextContent("created")}}, nil
+	})
+	g.Router().AddClient(client)
+	g.Router().RefreshTools()
+	g.RegisterAgent("coder", []config.ToolSelector{{Server: "github", Tools: []string{"create_issue"}}})
+
+	l, err := audit.Open(filepath.Join(t.TempDir(), "audit.jsonl"), audit.Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer l.Close()
+	g.SetAuditLog(l)
+
+	_, session, err := g.HandleInitializeSession("", InitializeParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Session call with a redacted argument
+	if _, err := g.HandleToolsCallForSession(ctx, session.ID, "", ToolCallParams{
+		Name:      "github__create_issue",
+		Arguments: map[string]any{"title": "bug", "token": "abc"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Agent call that is denied
+	if _, err := g.HandleToolsCallForAgent(ctx, "coder", ToolCallParams{Name: "github__delete_repo"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Unknown tool
+	if _, err := g.HandleToolsCall(ctx, ToolCallParams{Name: "github__missing"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := l.Query(audit.Filter{})
+	if len(calls) != 3 {
+		t.Fatalf("expected 3 recorded calls, got %d: %+v", len(calls), calls)
+	}
+
+	missing := calls[0]
+	if missing.Name != "github__missing" || missing.Server != "" || !missing.Error {
+		t.Errorf("unexpected event for unknown tool: %+v", missing)
+	}
+
+	denied := calls[1]
+	if denied.Agent != "coder" || denied.Server != "github" || denied.Tool != "delete_repo" || !denied.Error {
+		t.Errorf("unexpected event for denied call: %+v", denied)
+	}
+
+	created := calls[2]
+	if created.SessionID != session.ID || created.Server != "github" || created.Tool != "create_issue" || created.Error {
+		t.Errorf("unexpected event for session call: %+v", created)
+	}
+	if created.ResultSize != len("created") {
+		t.Errorf("expected result size %d, got %d", len("created"), created.ResultSize)
+	}
+	if created.Arguments["title"] != "bug" || created.Arguments["token"] != audit.Redacted {
+		t.Errorf("expected token to be redacted, got %v", created.Arguments)
+	}
+
+	// Nothing is recorded without a log
+	g.SetAuditLog(nil)
+	if _, err := g.HandleToolsCall(ctx, ToolCallParams{Name: "github__create_issue"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(l.Query(audit.Filter{})); got != 3 {
+		t.Errorf("expected 3 recorded calls after removing the log, got %d", got)
+	}
+}
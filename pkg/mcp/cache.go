@@ -0,0 +1,280 @@
+package mcp
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Cache defaults, used when a server's cache leaves them unset.
+const (
+	DefaultCacheTTL  = 5 * time.Minute
+	DefaultCacheSize = 10 * 1024 * 1024
+)
+
+// CacheStats reports the result cache of one MCP server.
+type CacheStats struct {
+	Server        string `json:"server"`
+	Entries       int    `json:"entries"`
+	Size          int64  `json:"size"`    // Bytes of cached results
+	MaxSize       int64  `json:"maxSize"` // Bytes before least recently used results are evicted
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Evictions     uint64 `json:"evictions"`     // Results dropped to make room
+	Invalidations uint64 `json:"invalidations"` // Results dropped through InvalidateCache
+}
+
+// cacheEntry is a cached tool result, kept as JSON so callers cannot
+// modify it and its size is known.
+type cacheEntry struct {
+	key     string
+	tool    string
+	result  []byte
+	expires time.Time
+}
+
+func (e *cacheEntry) size() int64 {
+	return int64(len(e.key) + len(e.result))
+}
+
+// resultCache holds tool results of one MCP server for calls with the same
+// arguments, evicting the least recently used when over its size.
+type resultCache struct {
+	ttl      time.Duration
+	maxSize  int64
+	enabled  bool                     // Whether tools without their own setting are cached
+	tools    map[string]bool          // Original tool name -> cached or not
+	toolTTLs map[string]time.Duration // Original tool name -> TTL
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // key -> element holding *cacheEntry
+	lru     *list.List               // Most recently used first
+	size    int64
+	stats   CacheStats
+}
+
+// newResultCache builds a server's cache from config, or returns nil if no
+// tool is cached.
+func newResultCache(cfg MCPServerConfig) (*resultCache, error) {
+	c := &resultCache{
+		ttl:      DefaultCacheTTL,
+		maxSize:  DefaultCacheSize,
+		enabled:  cfg.Cache.IsEnabled(),
+		tools:    make(map[string]bool),
+		toolTTLs: make(map[string]time.Duration),
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+	if ttl, err := cfg.Cache.TTLDuration(); err != nil {
+		return nil, fmt.Errorf("invalid cache ttl: %w", err)
+	} else if ttl > 0 {
+		c.ttl = ttl
+	}
+	if size, err := cfg.Cache.MaxSizeBytes(); err != nil {
+		return nil, fmt.Errorf("invalid cache max_size: %w", err)
+	} else if size > 0 {
+		c.maxSize = size
+	}
+
+	anyEnabled := c.enabled
+	for tool, tc := range cfg.ToolCache {
+		c.tools[tool] = tc.IsEnabled()
+		anyEnabled = anyEnabled || tc.IsEnabled()
+		ttl, err := tc.TTLDuration()
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: invalid cache ttl: %w", tool, err)
+		}
+		if ttl > 0 {
+			c.toolTTLs[tool] = ttl
+		}
+	}
+	if !anyEnabled {
+		return nil, nil
+	}
+	c.stats.MaxSize = c.maxSize
+	return c, nil
+}
+
+// cacheable reports whether a tool's results are cached. Tools the server
+// annotates are cached only if they are read-only or idempotent, whatever
+// the config says.
+func (c *resultCache) cacheable(tool Tool, name string) bool {
+	enabled, ok := c.tools[name]
+	if !ok {
+		enabled = c.enabled
+	}
+	if !enabled {
+		return false
+	}
+	if a := tool.Annotations; a != nil {
+		return isTrue(a.ReadOnlyHint) || isTrue(a.IdempotentHint)
+	}
+	return true
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+// cacheKey identifies a call by tool and arguments. encoding/json writes
+// map keys in sorted order, so equal arguments give equal keys.
+func cacheKey(tool string, args map[string]any) (string, error) {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	return tool + "\x00" + string(b), nil
+}
+
+// get returns the cached result for key, counting a hit or miss.
+func (c *resultCache) get(key string, now time.Time) (*ToolCallResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*cacheEntry)
+		if now.Before(e.expires) {
+			var result ToolCallResult
+			if err := json.Unmarshal(e.result, &result); err == nil {
+				c.lru.MoveToFront(el)
+				c.stats.Hits++
+				return &result, true
+			}
+		}
+		c.remove(el)
+	}
+	c.stats.Misses++
+	return nil, false
+}
+
+// put caches a result, evicting the least recently used results to keep
+// within the cache's size. Results larger than the cache are not kept.
+func (c *resultCache) put(key, tool string, result *ToolCallResult, now time.Time) {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	ttl := c.ttl
+	if t, ok := c.toolTTLs[tool]; ok {
+		ttl = t
+	}
+	e := &cacheEntry{key: key, tool: tool, result: b, expires: now.Add(ttl)}
+	if e.size() > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	for c.size+e.size() > c.maxSize {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	c.size += e.size()
+}
+
+// remove drops an entry. The caller must hold c.mu.
+func (c *resultCache) remove(el *list.Element) {
+	e := el.Value.(*cacheEntry)
+	c.lru.Remove(el)
+	delete(c.entries, e.key)
+	c.size -= e.size()
+}
+
+// invalidate drops the results of a tool, or all results if tool is
+// empty, and returns how many were dropped.
+func (c *resultCache) invalidate(tool string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		if tool == "" || el.Value.(*cacheEntry).tool == tool {
+			c.remove(el)
+			n++
+		}
+		el = next
+	}
+	c.stats.Invalidations += uint64(n)
+	return n
+}
+
+// snapshot returns the cache's stats.
+func (c *resultCache) snapshot() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	stats.Size = c.size
+	return stats
+}
+
+// cacheFor returns the result cache of a server, or nil if it has none.
+func (g *Gateway) cacheFor(server string) *resultCache {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.caches[server]
+}
+
+// cachedCall looks up a routed call in its server's cache. It returns the
+// cached result on a hit. On a miss for a cacheable tool it returns a
+// function that stores the call's result, to be called only for results
+// that are not errors; otherwise that function is nil.
+func (g *Gateway) cachedCall(client AgentClient, tool string, args map[string]any) (*ToolCallResult, func(*ToolCallResult)) {
+	c := g.cacheFor(client.Name())
+	if c == nil {
+		return nil, nil
+	}
+	def, _ := toolDefinition(client, tool)
+	if !c.cacheable(def, tool) {
+		return nil, nil
+	}
+	key, err := cacheKey(tool, args)
+	if err != nil {
+		return nil, nil
+	}
+
+	if result, ok := c.get(key, time.Now()); ok {
+		g.metrics.cacheHits.WithLabelValues(client.Name(), tool).Inc()
+		return result, nil
+	}
+	g.metrics.cacheMisses.WithLabelValues(client.Name(), tool).Inc()
+	return nil, func(result *ToolCallResult) {
+		c.put(key, tool, result, time.Now())
+	}
+}
+
+// CacheStats returns the result cache stats of each MCP server with
+// caching, sorted by server name.
+func (g *Gateway) CacheStats() []CacheStats {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	stats := make([]CacheStats, 0, len(g.caches))
+	for name, c := range g.caches {
+		s := c.snapshot()
+		s.Server = name
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Server < stats[j].Server })
+	return stats
+}
+
+// InvalidateCache drops cached results and returns how many were dropped.
+// An empty server drops results of all servers; an empty tool drops all of
+// the server's results.
+func (g *Gateway) InvalidateCache(server, tool string) int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	n := 0
+	for name, c := range g.caches {
+		if server == "" || name == server {
+			n += c.invalidate(tool)
+		}
+	}
+	return n
+}
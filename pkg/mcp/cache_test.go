@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gridctl/gridctl/pkg/config"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newCachingGateway returns a gateway with a mock "docs" server whose
+// results are cached as configured, and a count of calls per tool that
+// reached the server.
+func newCachingGateway(t *testing.T, cache *config.Cache, toolCache map[string]config.ToolCache) (*Gateway, map[string]int) {
+	t.Helper()
+	yes, no := true, false
+	g := NewGateway()
+	client := NewMockAgentClient("docs", []Tool{
+		{Name: "search"},
+		{Name: "lookup", Annotations: &ToolAnnotations{IdempotentHint: &yes}},
+		{Name: "publish", Annotations: &ToolAnnotations{ReadOnlyHint: &no}},
+	})
+	calls := make(map[string]int)
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		calls[name]++
+		return &ToolCallResult{
+			Content: []Content{NewTextContent(fmt.Sprintf("%s #%d", name, calls[name]))},
+			IsError: args["fail"] == true,
+		}, nil
+	})
+	g.Router().AddClient(client)
+	g.Router().RefreshTools()
+
+	c, err := newResultCache(MCPServerConfig{Name: "docs", Cache: cache, ToolCache: toolCache})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c != nil {
+		g.caches["docs"] = c
+	}
+	return g, calls
+}
+
+func call(t *testing.T, g *Gateway, name string, args map[string]any) string {
+	t.Helper()
+	result, err := g.HandleToolsCall(context.Background(), ToolCallParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result.Content[0].Text
+}
+
+func TestGateway_ResultCache(t *testing.T) {
+	g, calls := newCachingGateway(t, &config.Cache{}, nil)
+
+	args := map[string]any{"q": "tls", "opts": map[string]any{"b": 1, "a": 2}}
+	if got := call(t, g, "docs__search", args); got != "search #1" {
+		t.Errorf("expected first call to reach the server, got %q", got)
+	}
+	same := map[string]any{"opts": map[string]any{"a": 2, "b": 1}, "q": "tls"}
+	if got := call(t, g, "docs__search", same); got != "search #1" {
+		t.Errorf("expected cached result, got %q", got)
+	}
+	if got := call(t, g, "docs__search", map[string]any{"q": "dns"}); got != "search #2" {
+		t.Errorf("expected different arguments to miss, got %q", got)
+	}
+
+	// Error results are not cached
+	call(t, g, "docs__search", map[string]any{"fail": true})
+	call(t, g, "docs__search", map[string]any{"fail": true})
+	if calls["search"] != 4 {
+		t.Errorf("expected 4 calls to reach the server, got %d", calls["search"])
+	}
+
+	// Annotated tools are cached only if read-only or idempotent
+	call(t, g, "docs__lookup", nil)
+	call(t, g, "docs__lookup", nil)
+	call(t, g, "docs__publish", nil)
+	call(t, g, "docs__publish", nil)
+	if calls["lookup"] != 1 || calls["publish"] != 2 {
+		t.Errorf("expected lookup cached and publish not, got %v", calls)
+	}
+
+	stats := g.CacheStats()
+	if len(stats) != 1 || stats[0].Server != "docs" || stats[0].Hits != 2 || stats[0].Misses != 5 || stats[0].Entries != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if got := testutil.ToFloat64(g.metrics.cacheHits.WithLabelValues("docs", "search")); got != 1 {
+		t.Errorf("expected 1 search cache hit, got %v", got)
+	}
+
+	if n := g.InvalidateCache("docs", "search"); n != 2 {
+		t.Errorf("expected 2 search results invalidated, got %d", n)
+	}
+	if got := call(t, g, "docs__search", args); got != "search #5" {
+		t.Errorf("expected invalidated result to miss, got %q", got)
+	}
+	if n := g.InvalidateCache("", ""); n != 2 {
+		t.Errorf("expected 2 results invalidated, got %d", n)
+	}
+	if stats := g.CacheStats(); stats[0].Entries != 0 || stats[0].Invalidations != 4 {
+		t.Errorf("unexpected stats after invalidation: %+v", stats[0])
+	}
+}
+
+func TestGateway_ToolCache(t *testing.T) {
+	no := false
+	g, calls := newCachingGateway(t, nil, map[string]config.ToolCache{"lookup": {TTL: "1h"}})
+	for i := 0; i < 2; i++ {
+		call(t, g, "docs__search", nil)
+		call(t, g, "docs__lookup", nil)
+	}
+	if calls["search"] != 2 || calls["lookup"] != 1 {
+		t.Errorf("expected only lookup cached, got %v", calls)
+	}
+
+	g, calls = newCachingGateway(t, &config.Cache{}, map[string]config.ToolCache{"search": {Enabled: &no}})
+	for i := 0; i < 2; i++ {
+		call(t, g, "docs__search", nil)
+		call(t, g, "docs__lookup", nil)
+	}
+	if calls["search"] != 2 || calls["lookup"] != 1 {
+		t.Errorf("expected search excluded from the server's cache, got %v", calls)
+	}
+
+	if c, err := newResultCache(MCPServerConfig{Name: "docs", Cache: &config.Cache{Enabled: &no}}); c != nil || err != nil {
+		t.Errorf("expected no cache when disabled, got %v (%v)", c, err)
+	}
+}
+
+func TestResultCache_ExpiryAndEviction(t *testing.T) {
+	c, err := newResultCache(MCPServerConfig{
+		Name:      "docs",
+		Cache:     &config.Cache{TTL: "1m", MaxSize: "1k"},
+		ToolCache: map[string]config.ToolCache{"slow": {TTL: "1h"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Now()
+	result := &ToolCallResult{Content: []Content{NewTextContent(strings.Repeat("x", 300))}}
+
+	c.put("search\x00a", "search", result, now)
+	c.put("slow\x00a", "slow", result, now)
+	if _, ok := c.get("search\x00a", now.Add(2*time.Minute)); ok {
+		t.Error("expected result to expire after the server's TTL")
+	}
+	if _, ok := c.get("slow\x00a", now.Add(2*time.Minute)); !ok {
+		t.Error("expected the tool's TTL to replace the server's")
+	}
+
+	// Each result is ~350 bytes, so a 1 KiB cache holds two; the least
+	// recently used is evicted first
+	c.put("search\x00b", "search", result, now)
+	c.get("slow\x00a", now)
+	c.put("search\x00c", "search", result, now)
+	if _, ok := c.get("search\x00b", now); ok {
+		t.Error("expected least recently used result to be evicted")
+	}
+	if _, ok := c.get("slow\x00a", now); !ok {
+		t.Error("expected recently used result to be kept")
+	}
+	if s := c.snapshot(); s.Evictions != 1 || s.Size > 1024 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+
+	// Results larger than the cache are not kept
+	c.put("big", "search", &ToolCallResult{Content: []Content{NewTextContent(strings.Repeat("x", 2048))}}, now)
+	if _, ok := c.get("big", now); ok {
+		t.Error("expected oversized result not to be cached")
+	}
+}
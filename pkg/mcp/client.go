@@ -11,6 +11,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/gridctl/gridctl/pkg/tracing"
 )
 
 // Client communicates with a downstream MCP server.
@@ -20,22 +22,22 @@ type Client struct {
 	httpClient *http.Client
 	requestID  atomic.Int64
 
-	mu            sync.RWMutex
-	initialized   bool
-	tools         []Tool
-	serverInfo    ServerInfo
-	sessionID     string   // MCP session ID for stateful servers
-	toolWhitelist []string // Tool whitelist (empty = all tools)
+	mu                sync.RWMutex
+	initialized       bool
+	tools             []Tool
+	serverInfo        ServerInfo
+	sessionID         string            // MCP session ID for stateful servers
+	headers           map[string]string // Extra headers sent with every request
+	toolWhitelist     []string          // Tool whitelist (empty = all tools)
+	unmatchedPatterns []string          // Whitelist patterns that matched no tool
 }
 
 // NewClient creates a new MCP client for a downstream agent.
 func NewClient(name, endpoint string) *Client {
 	return &Client{
-		name:     name,
-		endpoint: endpoint,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		name:       name,
+		endpoint:   endpoint,
+		httpClient: &http.Client{},
 	}
 }
 
@@ -49,9 +51,16 @@ func (c *Client) Endpoint() string {
 	return c.endpoint
 }
 
-// SetToolWhitelist sets the list of allowed tool names.
-// Only tools in this list will be returned by Tools() and RefreshTools().
-// An empty or nil list means all tools are allowed.
+// SetHeaders sets headers, such as Authorization, sent with every request.
+func (c *Client) SetHeaders(headers map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.headers = headers
+}
+
+// SetToolWhitelist sets the tool selector patterns (names, globs, "re:"
+// regexes, and "!" exclusions). Only allowed tools will be returned by
+// Tools() after RefreshTools(). An empty or nil list means all tools are allowed.
 func (c *Client) SetToolWhitelist(tools []string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -88,7 +97,7 @@ func (c *Client) Initialize(ctx context.Context) error {
 }
 
 // RefreshTools fetches the current tool list from the agent.
-// If a tool whitelist has been set, only tools matching the whitelist are stored.
+// If a tool whitelist has been set, only tools allowed by its patterns are stored.
 func (c *Client) RefreshTools(ctx context.Context) error {
 	var result ToolsListResult
 	if err := c.call(ctx, "tools/list", nil, &result); err != nil {
@@ -98,23 +107,7 @@ func (c *Client) RefreshTools(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Filter tools if whitelist is set
-	if len(c.toolWhitelist) > 0 {
-		allowed := make(map[string]bool, len(c.toolWhitelist))
-		for _, name := range c.toolWhitelist {
-			allowed[name] = true
-		}
-
-		var filteredTools []Tool
-		for _, tool := range result.Tools {
-			if allowed[tool.Name] {
-				filteredTools = append(filteredTools, tool)
-			}
-		}
-		c.tools = filteredTools
-	} else {
-		c.tools = result.Tools
-	}
+	c.tools, c.unmatchedPatterns = filterTools(result.Tools, c.toolWhitelist)
 
 	return nil
 }
@@ -126,6 +119,14 @@ func (c *Client) Tools() []Tool {
 	return c.tools
 }
 
+// UnmatchedToolPatterns returns the whitelist patterns that matched no tool
+// on the last RefreshTools.
+func (c *Client) UnmatchedToolPatterns() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.unmatchedPatterns
+}
+
 // CallTool invokes a tool on the downstream agent.
 func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]any) (*ToolCallResult, error) {
 	params := ToolCallParams{
@@ -216,8 +217,15 @@ func (c *Client) notify(ctx context.Context, method string, params any) error {
 	return err
 }
 
-// send sends a request to the downstream agent.
-func (c *Client) send(ctx context.Context, req Request) (*Response, error) {
+// send sends a request to the downstream agent, propagating the trace
+// context in the HTTP headers and the request's _meta.
+func (c *Client) send(ctx context.Context, req Request) (out *Response, err error) {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+	ctx, span := startClientSpan(ctx, c.name, req.Method)
+	defer func() { endClientSpan(span, out, err) }()
+	req.Params = withTraceMeta(ctx, req.Params)
+
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request: %w", err)
@@ -227,8 +235,10 @@ func (c *Client) send(ctx context.Context, req Request) (*Response, error) {
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
+	c.setHeaders(httpReq)
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	tracing.InjectHTTP(ctx, httpReq.Header)
 
 	// Include session ID if we have one (for stateful MCP servers)
 	c.mu.RLock()
@@ -301,6 +311,15 @@ func (c *Client) parseSSEResponse(body io.Reader) (*Response, error) {
 	return nil, fmt.Errorf("no response with ID found in SSE stream")
 }
 
+// setHeaders adds the configured headers to req.
+func (c *Client) setHeaders(req *http.Request) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+}
+
 // Ping checks if the agent is reachable.
 func (c *Client) Ping(ctx context.Context) error {
 	// Try to connect with a short timeout
@@ -311,6 +330,7 @@ func (c *Client) Ping(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	c.setHeaders(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
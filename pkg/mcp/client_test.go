@@ -1,11 +1,38 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 )
 
+func TestClient_Headers(t *testing.T) {
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		if r.Method == http.MethodPost {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[]}}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient("remote", srv.URL)
+	c.SetHeaders(map[string]string{"Authorization": "Bearer t0ken"})
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.RefreshTools(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(auth) != 2 || auth[0] != "Bearer t0ken" || auth[1] != "Bearer t0ken" {
+		t.Errorf("expected the header on every request, got %v", auth)
+	}
+}
+
 func TestClient_ParseSSEResponse_Notifications(t *testing.T) {
 	// Simulate an SSE stream with a notification followed by a result
 	sseBody := `event: message
@@ -76,3 +103,23 @@ data: {"jsonrpc":"2.0","id":1,"result":{}}
 		t.Fatal("expected valid response despite previous malformed line")
 	}
 }
+
+func TestFilterTools(t *testing.T) {
+	tools := []Tool{{Name: "get_file"}, {Name: "get_repo"}, {Name: "delete_repo"}, {Name: "search"}}
+
+	got, unmatched := filterTools(tools, []string{"get_*", "re:^sea", "!*_repo", "list_*"})
+	var names []string
+	for _, tool := range got {
+		names = append(names, tool.Name)
+	}
+	if strings.Join(names, ",") != "get_file,search" {
+		t.Errorf("expected [get_file search], got %v", names)
+	}
+	if len(unmatched) != 1 || unmatched[0] != "list_*" {
+		t.Errorf("expected unmatched [list_*], got %v", unmatched)
+	}
+
+	if all, _ := filterTools(tools, nil); len(all) != len(tools) {
+		t.Errorf("expected all tools without patterns, got %d", len(all))
+	}
+}
@@ -0,0 +1,293 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+// compositeServer is the router client name composite tools are served
+// under, so they are exposed as e.g. "gridctl__triage_issue".
+const compositeServer = config.BuiltinToolPrefix
+
+// compositeRef matches a template that is a single field reference, such as
+// "{{ .inputs.count }}", whose value is passed through with its type.
+var compositeRef = regexp.MustCompile(`^\{\{-?\s*((?:\.[A-Za-z_][A-Za-z0-9_]*)+)\s*-?\}\}$`)
+
+// compositeCallsKey is the context key for the composite tools being run,
+// used to stop composites that call themselves.
+type compositeCallsKey struct{}
+
+// compositeClient serves composite tools defined in the stack file. Each
+// step is routed through the gateway like any other tool call.
+type compositeClient struct {
+	gateway *Gateway
+	tools   []config.CompositeTool
+}
+
+// SetCompositeTools registers the stack's composite tools with the router,
+// replacing any registered before.
+func (g *Gateway) SetCompositeTools(tools []config.CompositeTool) {
+	if len(tools) == 0 {
+		if _, ok := g.router.GetClient(compositeServer).(*compositeClient); ok {
+			g.router.RemoveClient(compositeServer)
+		}
+	} else {
+		g.router.AddClient(&compositeClient{gateway: g, tools: tools})
+	}
+	g.router.RefreshTools()
+}
+
+// Name returns the server name composite tools are exposed under.
+func (c *compositeClient) Name() string {
+	return compositeServer
+}
+
+// Initialize is a no-op; composite tools need no connection.
+func (c *compositeClient) Initialize(ctx context.Context) error {
+	return nil
+}
+
+// RefreshTools is a no-op; composite tools are fixed by the stack file.
+func (c *compositeClient) RefreshTools(ctx context.Context) error {
+	return nil
+}
+
+// IsInitialized always returns true.
+func (c *compositeClient) IsInitialized() bool {
+	return true
+}
+
+// ServerInfo returns the server information.
+func (c *compositeClient) ServerInfo() ServerInfo {
+	return ServerInfo{Name: "gridctl-composite"}
+}
+
+// Tools returns the composite tools.
+func (c *compositeClient) Tools() []Tool {
+	tools := make([]Tool, 0, len(c.tools))
+	for _, ct := range c.tools {
+		schema := ct.InputSchema
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		schemaBytes, _ := json.Marshal(schema)
+		tools = append(tools, Tool{
+			Name:        ct.Name,
+			Description: ct.Description,
+			InputSchema: schemaBytes,
+		})
+	}
+	return tools
+}
+
+// CallTool runs a composite tool's steps in order. Step failures are
+// returned as error results, like failures of a single tool.
+func (c *compositeClient) CallTool(ctx context.Context, name string, arguments map[string]any) (*ToolCallResult, error) {
+	var tool *config.CompositeTool
+	for i := range c.tools {
+		if c.tools[i].Name == name {
+			tool = &c.tools[i]
+			break
+		}
+	}
+	if tool == nil {
+		return nil, fmt.Errorf("unknown composite tool: %s", name)
+	}
+
+	running, _ := ctx.Value(compositeCallsKey{}).([]string)
+	for _, r := range running {
+		if r == name {
+			return toolErrorResult("Composite tool '%s' calls itself: %s", name, strings.Join(append(running, name), " -> ")), nil
+		}
+	}
+	ctx = context.WithValue(ctx, compositeCallsKey{}, append(running[:len(running):len(running)], name))
+
+	inputs, err := compositeInputs(tool.InputSchema, arguments)
+	if err != nil {
+		return toolErrorResult("%v", err), nil
+	}
+	steps := make(map[string]any, len(tool.Steps))
+	data := map[string]any{"inputs": inputs, "steps": steps}
+
+	var last map[string]any
+	for _, step := range tool.Steps {
+		if step.If != "" {
+			run, err := compositeCondition(step.If, data)
+			if err != nil {
+				return toolErrorResult("Step '%s': evaluating if: %v", step.ID, err), nil
+			}
+			if !run {
+				steps[step.ID] = map[string]any{"text": "", "json": nil, "is_error": false, "skipped": true}
+				continue
+			}
+		}
+
+		args, err := renderCompositeValue(step.Args, data)
+		if err != nil {
+			return toolErrorResult("Step '%s': rendering args: %v", step.ID, err), nil
+		}
+		stepArgs, _ := args.(map[string]any)
+
+		// Steps are held to the calling agent's uses, like its own calls
+		stepParams := ToolCallParams{Name: step.Tool, Arguments: stepArgs}
+		var result *ToolCallResult
+		if info, ok := callInfoFrom(ctx); ok && info.agent != "" {
+			result, err = c.gateway.HandleToolsCallForAgent(ctx, info.agent, stepParams)
+		} else {
+			result, err = c.gateway.HandleToolsCall(ctx, stepParams)
+		}
+		output := compositeStepOutput(result, err)
+		steps[step.ID] = output
+		if output["is_error"] == true && step.OnError != config.OnErrorContinue {
+			return toolErrorResult("Step '%s' (%s) failed: %s", step.ID, step.Tool, output["text"]), nil
+		}
+		last = output
+	}
+
+	if tool.Output != "" {
+		text, err := renderCompositeTemplate(tool.Output, data)
+		if err != nil {
+			return toolErrorResult("Rendering output: %v", err), nil
+		}
+		return &ToolCallResult{Content: []Content{NewTextContent(text)}}, nil
+	}
+	text := ""
+	if last != nil {
+		text, _ = last["text"].(string)
+	}
+	return &ToolCallResult{Content: []Content{NewTextContent(text)}}, nil
+}
+
+// compositeInputs checks required inputs and fills in schema defaults.
+func compositeInputs(schema map[string]any, arguments map[string]any) (map[string]any, error) {
+	inputs := make(map[string]any, len(arguments))
+	for k, v := range arguments {
+		inputs[k] = v
+	}
+	props, _ := schema["properties"].(map[string]any)
+	for name, p := range props {
+		prop, _ := p.(map[string]any)
+		if def, ok := prop["default"]; ok {
+			if _, set := inputs[name]; !set {
+				inputs[name] = def
+			}
+		}
+	}
+	var required []string
+	switch r := schema["required"].(type) {
+	case []string:
+		required = r
+	case []any:
+		for _, name := range r {
+			if s, ok := name.(string); ok {
+				required = append(required, s)
+			}
+		}
+	}
+	for _, name := range required {
+		if _, ok := inputs[name]; !ok {
+			return nil, fmt.Errorf("missing required argument '%s'", name)
+		}
+	}
+	return inputs, nil
+}
+
+// compositeStepOutput records a step's result for later templates.
+func compositeStepOutput(result *ToolCallResult, err error) map[string]any {
+	if err != nil {
+		return map[string]any{"text": err.Error(), "json": nil, "is_error": true, "skipped": false}
+	}
+	var texts []string
+	for _, content := range result.Content {
+		if content.Type == "text" {
+			texts = append(texts, content.Text)
+		}
+	}
+	text := strings.Join(texts, "\n")
+	var parsed any
+	if json.Unmarshal([]byte(text), &parsed) != nil {
+		parsed = nil
+	}
+	return map[string]any{"text": text, "json": parsed, "is_error": result.IsError, "skipped": false}
+}
+
+// compositeCondition renders an if template and reports whether it is true.
+// Empty output, "false", "0", and "<no value>" are false.
+func compositeCondition(text string, data map[string]any) (bool, error) {
+	out, err := renderCompositeTemplate(text, data)
+	if err != nil {
+		return false, err
+	}
+	switch strings.TrimSpace(out) {
+	case "", "false", "0", "<no value>":
+		return false, nil
+	}
+	return true, nil
+}
+
+// renderCompositeValue renders the templates in a step argument value,
+// including those nested in maps and lists.
+func renderCompositeValue(value any, data map[string]any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if m := compositeRef.FindStringSubmatch(v); m != nil {
+			return lookupCompositeRef(m[1], data)
+		}
+		return renderCompositeTemplate(v, data)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			rendered, err := renderCompositeValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = rendered
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			rendered, err := renderCompositeValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	}
+	return value, nil
+}
+
+// lookupCompositeRef resolves a field reference such as ".steps.search.json"
+// to its value.
+func lookupCompositeRef(ref string, data map[string]any) (any, error) {
+	var current any = data
+	for _, key := range strings.Split(strings.TrimPrefix(ref, "."), ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: cannot read '%s' of a non-object", ref, key)
+		}
+		if current, ok = m[key]; !ok {
+			return nil, fmt.Errorf("%s: no value for '%s'", ref, key)
+		}
+	}
+	return current, nil
+}
+
+// renderCompositeTemplate renders a template to a string.
+func renderCompositeTemplate(text string, data map[string]any) (string, error) {
+	tmpl, err := config.ParseCompositeTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+func TestGateway_CompositeTools(t *testing.T) {
+	g := NewGateway()
+	ctx := context.Background()
+
+	var calls []string
+	var detailsArgs map[string]any
+	client := NewMockAgentClient("github", []Tool{{Name: "search_issues"}, {Name: "get_issue"}, {Name: "fail"}})
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		calls = append(calls, name)
+		switch name {
+		case "search_issues":
+			return &ToolCallResult{Content: []Content{NewTextContent(`{"total":1,"items":[{"number":42}],"first":42}`)}}, nil
+		case "get_issue":
+			detailsArgs = args
+			return &ToolCallResult{Content: []Content{NewTextContent(fmt.Sprintf("issue %v", args["number"]))}}, nil
+		}
+		return &ToolCallResult{Content: []Content{NewTextContent("boom")}, IsError: true}, nil
+	})
+	g.Router().AddClient(client)
+	g.SetCompositeTools([]config.CompositeTool{
+		{
+			Name:        "triage",
+			Description: "Find and summarize an issue",
+			InputSchema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"repo": map[string]any{"type": "string"}, "state": map[string]any{"type": "string", "default": "open"}},
+				"required":   []any{"repo"},
+			},
+			Steps: []config.CompositeStep{
+				{ID: "search", Tool: "github__search_issues", Args: map[string]any{"q": "repo:{{ .inputs.repo }} is:{{ .inputs.state }}"}},
+				{ID: "details", Tool: "github__get_issue", If: "{{ gt .steps.search.json.total 0.0 }}", Args: map[string]any{"number": "{{ .steps.search.json.first }}"}},
+				{ID: "never", Tool: "github__fail", If: "{{ .steps.search.is_error }}"},
+			},
+			Output: "{{ .steps.details.text }} (skipped: {{ .steps.never.skipped }})",
+		},
+		{
+			Name: "tolerant",
+			Steps: []config.CompositeStep{
+				{ID: "first", Tool: "github__fail", OnError: config.OnErrorContinue},
+				{ID: "second", Tool: "github__get_issue", Args: map[string]any{"error": "{{ .steps.first.text }}"}},
+			},
+		},
+		{
+			Name:  "strict",
+			Steps: []config.CompositeStep{{ID: "first", Tool: "github__fail"}, {ID: "second", Tool: "github__get_issue"}},
+		},
+		{
+			Name:  "loop",
+			Steps: []config.CompositeStep{{ID: "again", Tool: "gridctl__loop"}},
+		},
+	})
+
+	// Composites are exposed like server tools
+	list, _ := g.HandleToolsList()
+	found := false
+	for _, tool := range list.Tools {
+		if tool.Name == "gridctl__triage" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected gridctl__triage in tools, got %v", list.Tools)
+	}
+
+	result, err := g.HandleToolsCall(ctx, ToolCallParams{Name: "gridctl__triage", Arguments: map[string]any{"repo": "org/app"}})
+	if err != nil || result.IsError {
+		t.Fatalf("triage failed: %v %v", err, result)
+	}
+	if got := result.Content[0].Text; got != "issue 42 (skipped: true)" {
+		t.Errorf("unexpected output %q", got)
+	}
+	if n, ok := detailsArgs["number"].(float64); !ok || n != 42 {
+		t.Errorf("expected single reference to keep its type, got %#v", detailsArgs["number"])
+	}
+	if strings.Join(calls, ",") != "search_issues,get_issue" {
+		t.Errorf("unexpected calls %v", calls)
+	}
+
+	t.Run("missing required input", func(t *testing.T) {
+		result, _ := g.HandleToolsCall(ctx, ToolCallParams{Name: "gridctl__triage"})
+		if !result.IsError || !strings.Contains(result.Content[0].Text, "missing required argument 'repo'") {
+			t.Errorf("expected missing argument error, got %v", result.Content)
+		}
+	})
+
+	t.Run("on_error continue", func(t *testing.T) {
+		result, _ := g.HandleToolsCall(ctx, ToolCallParams{Name: "gridctl__tolerant"})
+		if result.IsError || result.Content[0].Text != "issue <nil>" {
+			t.Errorf("expected the second step to run, got %v", result.Content)
+		}
+		if detailsArgs["error"] != "boom" {
+			t.Errorf("expected failed step text in later args, got %v", detailsArgs)
+		}
+	})
+
+	t.Run("on_error fail", func(t *testing.T) {
+		calls = nil
+		result, _ := g.HandleToolsCall(ctx, ToolCallParams{Name: "gridctl__strict"})
+		if !result.IsError || !strings.Contains(result.Content[0].Text, "Step 'first' (github__fail) failed: boom") {
+			t.Errorf("expected step failure, got %v", result.Content)
+		}
+		if len(calls) != 1 {
+			t.Errorf("expected to stop after the failed step, got calls %v", calls)
+		}
+	})
+
+	t.Run("agent access", func(t *testing.T) {
+		calls = nil
+		g.RegisterAgent("triager", []config.ToolSelector{
+			{Server: config.BuiltinToolPrefix, Tools: []string{"triage"}},
+			{Server: "github", Tools: []string{"search_issues"}},
+		})
+		result, _ := g.HandleToolsCallForAgent(ctx, "triager", ToolCallParams{Name: "gridctl__triage", Arguments: map[string]any{"repo": "org/app"}})
+		if !result.IsError || !strings.Contains(result.Content[0].Text, "Step 'details' (github__get_issue) failed: Access denied") {
+			t.Errorf("expected the step to be denied, got %v", result.Content)
+		}
+		if strings.Join(calls, ",") != "search_issues" {
+			t.Errorf("expected only the allowed step to reach the server, got calls %v", calls)
+		}
+	})
+
+	t.Run("recursion", func(t *testing.T) {
+		result, _ := g.HandleToolsCall(ctx, ToolCallParams{Name: "gridctl__loop"})
+		if !result.IsError || !strings.Contains(result.Content[0].Text, "calls itself") {
+			t.Errorf("expected recursion error, got %v", result.Content)
+		}
+	})
+}
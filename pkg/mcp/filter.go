@@ -0,0 +1,27 @@
+package mcp
+
+import "github.com/gridctl/gridctl/pkg/config"
+
+// filterTools applies tool selector patterns to a server's tool list.
+// It returns the allowed tools and the patterns that matched no tool.
+// Patterns are validated at config load, so a compile error here fails
+// closed and exposes no tools.
+func filterTools(tools []Tool, patterns []string) ([]Tool, []string) {
+	if len(patterns) == 0 {
+		return tools, nil
+	}
+	filter, err := config.ParseToolFilter(patterns)
+	if err != nil {
+		return nil, nil
+	}
+
+	names := make([]string, len(tools))
+	var filtered []Tool
+	for i, tool := range tools {
+		names[i] = tool.Name
+		if filter.Allows(tool.Name) {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered, filter.Unmatched(names)
+}
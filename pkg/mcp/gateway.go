@@ -2,34 +2,49 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/gridctl/gridctl/pkg/audit"
 	"github.com/gridctl/gridctl/pkg/config"
 	"github.com/gridctl/gridctl/pkg/dockerclient"
 	"github.com/gridctl/gridctl/pkg/logging"
+	"github.com/gridctl/gridctl/pkg/tracing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MCPServerConfig contains configuration for connecting to an MCP server.
 type MCPServerConfig struct {
 	Name            string
 	Transport       Transport
-	Endpoint        string            // For HTTP/SSE transport
-	ContainerID     string            // For Docker Stdio transport
-	External        bool              // True for external URL servers (no container)
-	LocalProcess    bool              // True for local process servers (no container)
-	SSH             bool              // True for SSH servers (remote process over SSH)
-	Command         []string          // For local process or SSH transport
-	WorkDir         string            // For local process transport
-	Env             map[string]string // For local process or SSH transport
-	SSHHost         string            // SSH hostname (for SSH servers)
-	SSHUser         string            // SSH username (for SSH servers)
-	SSHPort         int               // SSH port (for SSH servers, 0 = default 22)
-	SSHIdentityFile string            // SSH identity file path (for SSH servers)
-	Tools           []string          // Tool whitelist (empty = all tools)
+	Endpoint        string                         // For HTTP/SSE transport
+	ContainerID     string                         // For Docker Stdio transport
+	External        bool                           // True for external URL servers (no container)
+	LocalProcess    bool                           // True for local process servers (no container)
+	SSH             bool                           // True for SSH servers (remote process over SSH)
+	Command         []string                       // For local process or SSH transport
+	WorkDir         string                         // For local process transport
+	Env             map[string]string              // For local process or SSH transport
+	Headers         map[string]string              // HTTP headers for external servers
+	SSHHost         string                         // SSH hostname (for SSH servers)
+	SSHUser         string                         // SSH username (for SSH servers)
+	SSHPort         int                            // SSH port (for SSH servers, 0 = default 22)
+	SSHIdentityFile string                         // SSH identity file path (for SSH servers)
+	Tools           []string                       // Tool whitelist (empty = all tools)
+	ToolOverrides   map[string]config.ToolOverride // Renames, descriptions, and parameter rewrites
+	ToolPrefix      string                         // Prefix for exposed tool names (default: Name)
+	Unprefixed      bool                           // Expose tool names without a prefix
+	Limits          config.CallLimits              // Timeout, concurrency and rate limit for all calls
+	ToolLimits      map[string]config.CallLimits   // Original tool name -> limits for calls to that tool
+	Cache           *config.Cache                  // Reuse results of identical calls
+	ToolCache       map[string]config.ToolCache    // Original tool name -> caching of that tool's results
+	RequireApproval []string                       // Original tool name patterns whose calls wait for a human
 }
 
 // Gateway aggregates multiple MCP servers into a single endpoint.
@@ -39,10 +54,25 @@ type Gateway struct {
 	dockerCli dockerclient.DockerClient
 	logger    *slog.Logger
 
-	mu          sync.RWMutex
-	serverInfo  ServerInfo
-	serverMeta  map[string]MCPServerConfig       // name -> config for status reporting
-	agentAccess map[string][]config.ToolSelector // agent name -> allowed MCP servers with tool filtering
+	mu              sync.RWMutex
+	serverInfo      ServerInfo
+	serverMeta      map[string]MCPServerConfig               // name -> config for status reporting
+	agentAccess     map[string][]config.ToolSelector         // agent name -> allowed MCP servers with tool filtering
+	agentFilters    map[string]map[string]*config.ToolFilter // agent name -> server name -> compiled tool patterns
+	toolsets        []toolset                                // toolsets sessions can enable, in config order
+	toolSearch      bool                                     // list only search_tools and call_tool
+	auditLog        *audit.Log                               // records tool calls, if set
+	limits          map[string]*serverLimiters               // server name -> call limits, if any
+	caches          map[string]*resultCache                  // server name -> result cache, if any
+	approvalFilters map[string]*config.ToolFilter            // server name -> tools whose calls need approval, if any
+	approvals       *approvalQueue
+	policies        *config.PolicySet   // Rules on tool call arguments, if any
+	mask            func(string) string // Hides secret values in status, if set
+	metrics         *gatewayMetrics
+
+	searchMu    sync.Mutex
+	searchIndex *ToolIndex // Built from the router's tools, see toolIndex
+	searchGen   uint64     // Router generation searchIndex was built at
 }
 
 // NewGateway creates a new MCP gateway.
@@ -55,8 +85,14 @@ func NewGateway() *Gateway {
 			Name:    "gridctl-gateway",
 			Version: "dev",
 		},
-		serverMeta:  make(map[string]MCPServerConfig),
-		agentAccess: make(map[string][]config.ToolSelector),
+		serverMeta:      make(map[string]MCPServerConfig),
+		agentAccess:     make(map[string][]config.ToolSelector),
+		agentFilters:    make(map[string]map[string]*config.ToolFilter),
+		limits:          make(map[string]*serverLimiters),
+		caches:          make(map[string]*resultCache),
+		approvalFilters: make(map[string]*config.ToolFilter),
+		approvals:       newApprovalQueue(),
+		metrics:         newGatewayMetrics(),
 	}
 }
 
@@ -80,6 +116,14 @@ func (g *Gateway) SetVersion(version string) {
 	g.serverInfo.Version = version
 }
 
+// SetMasker sets a function that hides secret values, such as tokens in
+// server URLs, in the status the gateway reports.
+func (g *Gateway) SetMasker(mask func(string) string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.mask = mask
+}
+
 // Router returns the tool router.
 func (g *Gateway) Router() *Router {
 	return g.router
@@ -98,7 +142,27 @@ func (g *Gateway) ServerInfo() ServerInfo {
 }
 
 // RegisterMCPServer registers and initializes an MCP server.
-func (g *Gateway) RegisterMCPServer(ctx context.Context, cfg MCPServerConfig) error {
+func (g *Gateway) RegisterMCPServer(ctx context.Context, cfg MCPServerConfig) (err error) {
+	op := opInitialize
+	defer func() {
+		if err != nil {
+			g.metrics.serverFailures.WithLabelValues(cfg.Name, op).Inc()
+		}
+	}()
+
+	limits, err := newServerLimiters(cfg)
+	if err != nil {
+		return fmt.Errorf("MCP server %s: %w", cfg.Name, err)
+	}
+	cache, err := newResultCache(cfg)
+	if err != nil {
+		return fmt.Errorf("MCP server %s: %w", cfg.Name, err)
+	}
+	approval, err := config.ParseToolFilter(cfg.RequireApproval)
+	if err != nil {
+		return fmt.Errorf("MCP server %s: require_approval: %w", cfg.Name, err)
+	}
+
 	var agentClient AgentClient
 
 	// Handle SSH servers (they use stdio over SSH)
@@ -139,6 +203,7 @@ func (g *Gateway) RegisterMCPServer(ctx context.Context, cfg MCPServerConfig) er
 		case TransportSSE:
 			// SSE transport - uses same HTTP client which handles text/event-stream responses
 			httpClient := NewClient(cfg.Name, cfg.Endpoint)
+			httpClient.SetHeaders(cfg.Headers)
 			if len(cfg.Tools) > 0 {
 				httpClient.SetToolWhitelist(cfg.Tools)
 			}
@@ -149,6 +214,7 @@ func (g *Gateway) RegisterMCPServer(ctx context.Context, cfg MCPServerConfig) er
 			agentClient = httpClient
 		case TransportHTTP, "": // Default to HTTP
 			httpClient := NewClient(cfg.Name, cfg.Endpoint)
+			httpClient.SetHeaders(cfg.Headers)
 			if len(cfg.Tools) > 0 {
 				httpClient.SetToolWhitelist(cfg.Tools)
 			}
@@ -168,6 +234,7 @@ func (g *Gateway) RegisterMCPServer(ctx context.Context, cfg MCPServerConfig) er
 	}
 
 	// Fetch tools (will be filtered by whitelist if set)
+	op = opRefresh
 	if err := agentClient.RefreshTools(ctx); err != nil {
 		return fmt.Errorf("fetching tools from %s: %w", cfg.Name, err)
 	}
@@ -177,27 +244,97 @@ func (g *Gateway) RegisterMCPServer(ctx context.Context, cfg MCPServerConfig) er
 		g.mu.Lock()
 		defer g.mu.Unlock()
 		g.serverMeta[cfg.Name] = cfg
+		if limits != nil {
+			g.limits[cfg.Name] = limits
+		} else {
+			delete(g.limits, cfg.Name)
+		}
+		if cache != nil {
+			g.caches[cfg.Name] = cache
+		} else {
+			delete(g.caches, cfg.Name)
+		}
+		if approval != nil {
+			g.approvalFilters[cfg.Name] = approval
+		} else {
+			delete(g.approvalFilters, cfg.Name)
+		}
 	}()
 
 	// Add to router
+	g.router.SetToolOverrides(cfg.Name, cfg.ToolOverrides)
+	if cfg.Unprefixed {
+		g.router.SetToolPrefix(cfg.Name, "")
+	} else if cfg.ToolPrefix != "" {
+		g.router.SetToolPrefix(cfg.Name, cfg.ToolPrefix)
+	}
 	g.router.AddClient(agentClient)
 	g.router.RefreshTools()
 
 	g.logger.Info("registered MCP server", "name", cfg.Name, "transport", cfg.Transport, "tools", len(agentClient.Tools()))
+	if c, ok := agentClient.(interface{ UnmatchedToolPatterns() []string }); ok {
+		for _, pattern := range c.UnmatchedToolPatterns() {
+			g.logger.Warn("tool pattern matched no tools", "server", cfg.Name, "pattern", pattern)
+		}
+	}
+	for _, tool := range unknownOverrides(cfg.ToolOverrides, agentClient.Tools()) {
+		g.logger.Warn("tool override matched no tool", "server", cfg.Name, "tool", tool)
+	}
+	for _, c := range g.router.Collisions() {
+		if c.Agent == cfg.Name || c.OtherAgent == cfg.Name {
+			g.logger.Warn("tool name collision, tool not exposed",
+				"name", c.Name, "server", c.OtherAgent, "tool", c.OtherTool,
+				"exposed_server", c.Agent, "exposed_tool", c.Tool)
+		}
+	}
 	return nil
 }
 
+// SetToolNamer sets the naming scheme for aggregated tools.
+func (g *Gateway) SetToolNamer(namer ToolNamer) {
+	g.router.SetToolNamer(namer)
+}
+
 // UnregisterMCPServer removes an MCP server from the gateway.
 func (g *Gateway) UnregisterMCPServer(name string) {
+	g.mu.Lock()
+	delete(g.limits, name)
+	delete(g.caches, name)
+	delete(g.approvalFilters, name)
+	g.mu.Unlock()
 	g.router.RemoveClient(name)
 	g.router.RefreshTools()
 }
 
 // RegisterAgent registers an agent and its allowed MCP servers with optional tool filtering.
+// Tool patterns are compiled once here; patterns that match none of a
+// registered server's tools are logged as warnings.
 func (g *Gateway) RegisterAgent(name string, uses []config.ToolSelector) {
+	filters := make(map[string]*config.ToolFilter, len(uses))
+	for _, selector := range uses {
+		filter, err := config.ParseToolFilter(selector.Tools)
+		if err != nil {
+			// Patterns are validated at load; fail closed if one slips through
+			g.logger.Error("invalid tool pattern, denying all tools", "agent", name, "server", selector.Server, "error", err)
+			filter, _ = config.ParseToolFilter([]string{"!*"})
+		}
+		filters[selector.Server] = filter
+
+		if client := g.router.GetClient(selector.Server); client != nil {
+			var names []string
+			for _, tool := range client.Tools() {
+				names = append(names, tool.Name)
+			}
+			for _, pattern := range filter.Unmatched(names) {
+				g.logger.Warn("tool pattern matched no tools", "agent", name, "server", selector.Server, "pattern", pattern)
+			}
+		}
+	}
+
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	g.agentAccess[name] = uses
+	g.agentFilters[name] = filters
 }
 
 // UnregisterAgent removes an agent's access configuration.
@@ -205,6 +342,7 @@ func (g *Gateway) UnregisterAgent(name string) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	delete(g.agentAccess, name)
+	delete(g.agentFilters, name)
 }
 
 // GetAgentAllowedServers returns the MCP servers an agent can access.
@@ -215,6 +353,14 @@ func (g *Gateway) GetAgentAllowedServers(agentName string) []config.ToolSelector
 	return g.agentAccess[agentName]
 }
 
+// agentToolFilter returns the compiled tool patterns an agent applies to a server.
+// A nil filter allows all tools.
+func (g *Gateway) agentToolFilter(agentName, serverName string) *config.ToolFilter {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.agentFilters[agentName][serverName]
+}
+
 // getAgentServerAccess returns the ToolSelector for a specific server if the agent has access.
 // Returns nil if the agent doesn't have access to this server, or if the agent is not registered
 // (in which case all access is allowed for backward compatibility).
@@ -243,17 +389,8 @@ func (g *Gateway) isToolAllowedForAgent(agentName, serverName, toolName string)
 		// Agent not registered - allow all (backward compatibility)
 		return true
 	}
-	// If no tool list specified, all tools from this server are allowed
-	if len(selector.Tools) == 0 {
-		return true
-	}
-	// Check if tool is in the whitelist
-	for _, t := range selector.Tools {
-		if t == toolName {
-			return true
-		}
-	}
-	return false
+	// An empty tool list allows all tools from this server
+	return g.agentToolFilter(agentName, serverName).Allows(toolName)
 }
 
 // HandleToolsListForAgent returns tools filtered by agent access permissions.
@@ -265,39 +402,33 @@ func (g *Gateway) HandleToolsListForAgent(agentName string) (*ToolsListResult, e
 		return g.HandleToolsList()
 	}
 
-	// Build map of allowed servers to their tool selectors for fast lookup
-	serverSelectors := make(map[string]config.ToolSelector)
+	// Build set of allowed servers for fast lookup
+	hasServer := make(map[string]bool, len(allowed))
 	for _, selector := range allowed {
-		serverSelectors[selector.Server] = selector
+		hasServer[selector.Server] = true
 	}
 
+	g.mu.RLock()
+	filters := g.agentFilters[agentName]
+	g.mu.RUnlock()
+
 	// Filter tools by allowed MCP servers and tool whitelists
 	allTools := g.router.AggregatedTools()
 	var filteredTools []Tool
 	for _, tool := range allTools {
-		serverName, originalToolName, err := ParsePrefixedTool(tool.Name)
+		serverName, originalToolName, err := g.router.ResolveTool(tool.Name)
 		if err != nil {
 			g.logger.Warn("skipping tool with invalid name format", "name", tool.Name, "error", err)
 			continue
 		}
 
-		selector, hasServer := serverSelectors[serverName]
-		if !hasServer {
+		if !hasServer[serverName] {
 			continue
 		}
 
-		// If no tool whitelist, include all tools from this server
-		if len(selector.Tools) == 0 {
+		// Apply the agent's tool patterns (nil filter includes all tools)
+		if filters[serverName].Allows(originalToolName) {
 			filteredTools = append(filteredTools, tool)
-			continue
-		}
-
-		// Check if this specific tool is in the whitelist
-		for _, allowedTool := range selector.Tools {
-			if allowedTool == originalToolName {
-				filteredTools = append(filteredTools, tool)
-				break
-			}
 		}
 	}
 
@@ -307,21 +438,30 @@ func (g *Gateway) HandleToolsListForAgent(agentName string) (*ToolsListResult, e
 // HandleToolsCallForAgent routes a tool call with agent access validation.
 // This validates both server-level and tool-level access.
 func (g *Gateway) HandleToolsCallForAgent(ctx context.Context, agentName string, params ToolCallParams) (*ToolCallResult, error) {
+	start := time.Now()
+	if _, ok := callInfoFrom(ctx); !ok {
+		ctx = withCallInfo(ctx, "", agentName)
+	}
+
 	// Parse the tool name to get the MCP server and original tool name
-	serverName, originalToolName, err := ParsePrefixedTool(params.Name)
+	serverName, originalToolName, err := g.router.ResolveTool(params.Name)
 	if err != nil {
-		return &ToolCallResult{
+		result := &ToolCallResult{
 			Content: []Content{NewTextContent(fmt.Sprintf("Invalid tool name: %v", err))},
 			IsError: true,
-		}, nil
+		}
+		g.recordCall(ctx, params.Name, "", "", params.Arguments, start, result)
+		return result, nil
 	}
 
 	// Check if agent has access to this specific tool
 	if !g.isToolAllowedForAgent(agentName, serverName, originalToolName) {
-		return &ToolCallResult{
+		result := &ToolCallResult{
 			Content: []Content{NewTextContent(fmt.Sprintf("Access denied: agent '%s' cannot use tool '%s' from '%s'", agentName, originalToolName, serverName))},
 			IsError: true,
-		}, nil
+		}
+		g.recordCall(ctx, params.Name, serverName, originalToolName, params.Arguments, start, result)
+		return result, nil
 	}
 
 	// Proceed with the tool call
@@ -351,8 +491,21 @@ func (g *Gateway) waitForHTTPServer(ctx context.Context, client *Client) error {
 
 // HandleInitialize handles the initialize request.
 func (g *Gateway) HandleInitialize(params InitializeParams) (*InitializeResult, error) {
+	result, _, err := g.HandleInitializeSession("", params)
+	return result, err
+}
+
+// HandleInitializeSession handles the initialize request for a transport
+// session. An empty ID creates a session with a new ID.
+func (g *Gateway) HandleInitializeSession(sessionID string, params InitializeParams) (*InitializeResult, *Session, error) {
 	// Create a session for this client
-	g.sessions.Create(params.ClientInfo)
+	var session *Session
+	if sessionID == "" {
+		session = g.sessions.Create(params.ClientInfo)
+	} else {
+		session = g.sessions.CreateWithID(sessionID, params.ClientInfo)
+	}
+	g.sessions.SetCapabilities(session.ID, params.Capabilities)
 
 	return &InitializeResult{
 		ProtocolVersion: "2024-11-05",
@@ -362,7 +515,7 @@ func (g *Gateway) HandleInitialize(params InitializeParams) (*InitializeResult,
 				ListChanged: true,
 			},
 		},
-	}, nil
+	}, session, nil
 }
 
 // HandleToolsList returns all aggregated tools.
@@ -371,23 +524,85 @@ func (g *Gateway) HandleToolsList() (*ToolsListResult, error) {
 	return &ToolsListResult{Tools: tools}, nil
 }
 
-// HandleToolsCall routes a tool call to the appropriate MCP server.
+// HandleToolsCall routes a tool call to the appropriate MCP server and
+// records it in the audit log.
 func (g *Gateway) HandleToolsCall(ctx context.Context, params ToolCallParams) (*ToolCallResult, error) {
-	client, toolName, err := g.router.RouteToolCall(params.Name)
+	start := time.Now()
+	ctx = tracing.ExtractMeta(ctx, params.Meta)
+	ctx, span := tracing.Start(ctx, "tools/call "+params.Name, trace.SpanKindInternal,
+		attrMethod.String("tools/call"), attrTool.String(params.Name))
+	defer span.End()
+
+	// Policies see the arguments as called, before tool overrides rewrite them
+	server, tool, _ := g.router.ResolveTool(params.Name)
+	args, denied := g.applyPolicies(ctx, server, tool, params.Arguments)
+	if denied != nil {
+		span.SetStatus(codes.Error, "denied by policy")
+		g.recordCall(ctx, params.Name, server, tool, params.Arguments, start, denied)
+		return denied, nil
+	}
+	params.Arguments = args
+
+	client, toolName, arguments, err := g.router.RouteToolCall(params.Name, params.Arguments)
 	if err != nil {
-		return &ToolCallResult{
+		result := &ToolCallResult{
 			Content: []Content{NewTextContent(fmt.Sprintf("Error: %v", err))},
 			IsError: true,
-		}, nil
+		}
+		span.SetStatus(codes.Error, err.Error())
+		g.recordCall(ctx, params.Name, "", "", params.Arguments, start, result)
+		return result, nil
+	}
+	span.SetAttributes(attrServer.String(client.Name()))
+
+	// Calls made while handling this one, such as composite tool steps,
+	// are not covered by its approval, so only the audit record carries it
+	auditCtx := ctx
+	if reason := g.approvalReason(client, toolName); reason != "" {
+		approval, rejected := g.awaitApproval(ctx, reason, client.Name(), toolName, params.Name, params.Arguments)
+		auditCtx = withApproval(ctx, approval)
+		if rejected != nil {
+			span.SetStatus(codes.Error, "call not approved")
+			g.recordCall(auditCtx, params.Name, client.Name(), toolName, params.Arguments, start, rejected)
+			return rejected, nil
+		}
 	}
 
-	result, err := client.CallTool(ctx, toolName, params.Arguments)
+	cached, store := g.cachedCall(client, toolName, arguments)
+	if cached != nil {
+		span.SetAttributes(attrCacheHit.Bool(true))
+		g.recordCall(auditCtx, params.Name, client.Name(), toolName, params.Arguments, start, cached)
+		return cached, nil
+	}
+
+	callCtx, release, timeout, limited := g.applyLimits(ctx, client.Name(), toolName)
+	if limited != nil {
+		span.SetStatus(codes.Error, "call limit reached")
+		g.recordCall(auditCtx, params.Name, client.Name(), toolName, params.Arguments, start, limited)
+		return limited, nil
+	}
+	result, err := client.CallTool(callCtx, toolName, arguments)
+	timedOut := timeout > 0 && errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+	release()
 	if err != nil {
-		return &ToolCallResult{
-			Content: []Content{NewTextContent(fmt.Sprintf("Error calling tool: %v", err))},
+		span.RecordError(err)
+		msg := fmt.Sprintf("Error calling tool: %v", err)
+		if timedOut {
+			g.metrics.limited.WithLabelValues(client.Name(), toolName, limitTimeout).Inc()
+			msg = fmt.Sprintf("Error calling tool: timed out after %s", timeout)
+		}
+		result = &ToolCallResult{
+			Content: []Content{NewTextContent(msg)},
 			IsError: true,
-		}, nil
+		}
+	}
+	if result.IsError {
+		span.SetStatus(codes.Error, "tool returned an error")
+	} else if store != nil {
+		store(result)
 	}
+	g.metrics.observeCall(client.Name(), toolName, start, result)
+	g.recordCall(auditCtx, params.Name, client.Name(), toolName, params.Arguments, start, result)
 
 	return result, nil
 }
@@ -396,6 +611,7 @@ func (g *Gateway) HandleToolsCall(ctx context.Context, params ToolCallParams) (*
 func (g *Gateway) RefreshAllTools(ctx context.Context) error {
 	for _, client := range g.router.Clients() {
 		if err := client.RefreshTools(ctx); err != nil {
+			g.metrics.serverFailures.WithLabelValues(client.Name(), opRefresh).Inc()
 			g.logger.Warn("failed to refresh tools", "server", client.Name(), "error", err)
 		}
 	}
@@ -466,10 +682,15 @@ func (g *Gateway) Status() []MCPServerStatus {
 			toolNames[i] = t.Name
 		}
 
+		endpoint := meta.Endpoint
+		if g.mask != nil {
+			endpoint = g.mask(endpoint)
+		}
+
 		statuses = append(statuses, MCPServerStatus{
 			Name:         client.Name(),
 			Transport:    meta.Transport,
-			Endpoint:     meta.Endpoint,
+			Endpoint:     endpoint,
 			ContainerID:  meta.ContainerID,
 			Initialized:  client.IsInitialized(),
 			ToolCount:    len(tools),
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/gridctl/gridctl/pkg/config"
@@ -241,6 +242,12 @@ func TestGateway_Status(t *testing.T) {
 	if !status.Initialized {
 		t.Error("expected initialized to be true")
 	}
+
+	// Secret values are masked in endpoints
+	g.SetMasker(strings.NewReplacer("9000", "****").Replace)
+	if endpoint := g.Status()[0].Endpoint; endpoint != "http://localhost:****/mcp" {
+		t.Errorf("expected masked endpoint, got %q", endpoint)
+	}
 }
 
 func TestGateway_UnregisterMCPServer(t *testing.T) {
@@ -325,6 +332,34 @@ func TestGateway_AgentToolFiltering(t *testing.T) {
 			wantToolCount: 4,
 			wantToolNames: []string{"server1__read", "server1__write", "server2__list", "server2__create"},
 		},
+		{
+			name:      "glob with exclusion",
+			agentName: "glob-agent",
+			uses: []config.ToolSelector{
+				{Server: "server1", Tools: []string{"*", "!delete"}},
+				{Server: "server2", Tools: []string{"l*"}},
+			},
+			wantToolCount: 3,
+			wantToolNames: []string{"server1__read", "server1__write", "server2__list"},
+		},
+		{
+			name:      "regex pattern",
+			agentName: "regex-agent",
+			uses: []config.ToolSelector{
+				{Server: "server1", Tools: []string{"re:^(read|write)$"}},
+			},
+			wantToolCount: 2,
+			wantToolNames: []string{"server1__read", "server1__write"},
+		},
+		{
+			name:      "exclusion only starts from all tools",
+			agentName: "exclude-agent",
+			uses: []config.ToolSelector{
+				{Server: "server2", Tools: []string{"!create"}},
+			},
+			wantToolCount: 1,
+			wantToolNames: []string{"server2__list"},
+		},
 		{
 			name:      "empty selectors returns nothing",
 			agentName: "no-access-agent",
@@ -413,3 +448,318 @@ func TestGateway_AgentToolCallFiltering(t *testing.T) {
 		t.Error("expected access denied message")
 	}
 }
+
+func TestGateway_AgentToolCallFilteringPatterns(t *testing.T) {
+	g := NewGateway()
+	ctx := context.Background()
+
+	client := NewMockAgentClient("server1", []Tool{
+		{Name: "allowed", Description: "Allowed tool"},
+		{Name: "allow_all", Description: "Excluded tool"},
+		{Name: "restricted", Description: "Restricted tool"},
+	})
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		return &ToolCallResult{
+			Content: []Content{NewTextContent("called " + name)},
+		}, nil
+	})
+	g.Router().AddClient(client)
+	g.Router().RefreshTools()
+
+	tests := []struct {
+		name    string
+		tools   []string
+		allowed []string
+		denied  []string
+	}{
+		{name: "glob with exclusion", tools: []string{"allow*", "!allow_all"}, allowed: []string{"allowed"}, denied: []string{"allow_all", "restricted"}},
+		{name: "regex", tools: []string{"re:^(allowed|restricted)$"}, allowed: []string{"allowed", "restricted"}, denied: []string{"allow_all"}},
+		{name: "exclusion only", tools: []string{"!restricted"}, allowed: []string{"allowed", "allow_all"}, denied: []string{"restricted"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g.RegisterAgent("pattern-agent", []config.ToolSelector{{Server: "server1", Tools: tc.tools}})
+			for _, tool := range tc.allowed {
+				result, err := g.HandleToolsCallForAgent(ctx, "pattern-agent", ToolCallParams{Name: "server1__" + tool})
+				if err != nil || result.IsError {
+					t.Errorf("expected %s to be allowed, got %v %v", tool, err, result)
+				}
+			}
+			for _, tool := range tc.denied {
+				result, err := g.HandleToolsCallForAgent(ctx, "pattern-agent", ToolCallParams{Name: "server1__" + tool})
+				if err != nil || !result.IsError {
+					t.Errorf("expected %s to be denied, got %v %v", tool, err, result)
+				}
+			}
+		})
+	}
+}
+
+func TestGateway_ToolOverrides(t *testing.T) {
+	g := NewGateway()
+	ctx := context.Background()
+
+	client := NewMockAgentClient("github", []Tool{
+		{Name: "search_code", Description: "Search code"},
+		{Name: "delete_repo", Description: "Delete repo"},
+	})
+	var gotName string
+	var gotArgs map[string]any
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		gotName, gotArgs = name, args
+		return &ToolCallResult{Content: []Content{NewTextContent("ok")}}, nil
+	})
+	g.Router().SetToolOverrides("github", map[string]config.ToolOverride{
+		"search_code": {Name: "code_search", PinnedParams: map[string]any{"owner": "our-org"}},
+	})
+	g.Router().AddClient(client)
+	g.Router().RefreshTools()
+
+	// Agent selectors refer to original tool names
+	g.RegisterAgent("reviewer", []config.ToolSelector{
+		{Server: "github", Tools: []string{"search_code"}},
+	})
+
+	list, err := g.HandleToolsListForAgent("reviewer")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Tools) != 1 || list.Tools[0].Name != "github__code_search" {
+		t.Fatalf("expected only github__code_search, got %v", list.Tools)
+	}
+
+	result, err := g.HandleToolsCallForAgent(ctx, "reviewer", ToolCallParams{
+		Name:      "github__code_search",
+		Arguments: map[string]any{"owner": "someone-else", "query": "q"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected call to succeed, got %v", result.Content)
+	}
+	if gotName != "search_code" {
+		t.Errorf("expected call to original tool 'search_code', got %q", gotName)
+	}
+	if gotArgs["owner"] != "our-org" || gotArgs["query"] != "q" {
+		t.Errorf("expected pinned owner and caller query, got %v", gotArgs)
+	}
+}
+
+func TestGateway_Toolsets(t *testing.T) {
+	g := NewGateway()
+	ctx := context.Background()
+
+	g.Router().AddClient(NewMockAgentClient("github", []Tool{
+		{Name: "search_code", Description: "Search code"},
+		{Name: "create_issue", Description: "Create issue"},
+	}))
+	g.Router().AddClient(NewMockAgentClient("time", []Tool{
+		{Name: "now", Description: "Current time"},
+	}))
+	g.Router().RefreshTools()
+	g.SetToolsets([]config.Toolset{
+		{Name: "github", Description: "GitHub access", Tools: []config.ToolSelector{{Server: "github"}}},
+		{Name: "github-read", Tools: []config.ToolSelector{{Server: "github", Tools: []string{"search_*"}}}},
+	})
+
+	_, session, err := g.HandleInitializeSession("", InitializeParams{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var notifications []string
+	g.Sessions().SetNotifier(session.ID, func(msg Request) { notifications = append(notifications, msg.Method) })
+
+	listNames := func() map[string]bool {
+		t.Helper()
+		list, err := g.HandleToolsListForSession(session.ID, "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		names := make(map[string]bool)
+		for _, tool := range list.Tools {
+			names[tool.Name] = true
+		}
+		return names
+	}
+	call := func(name string, args map[string]any) *ToolCallResult {
+		t.Helper()
+		result, err := g.HandleToolsCallForSession(ctx, session.ID, "", ToolCallParams{Name: name, Arguments: args})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result
+	}
+
+	// Grouped tools are hidden; ungrouped tools and the built-in tool are listed
+	names := listNames()
+	if !names["gridctl__enable_toolset"] || !names["time__now"] || names["github__search_code"] || len(names) != 2 {
+		t.Fatalf("expected only the built-in tool and time__now, got %v", names)
+	}
+	if result := call("github__search_code", nil); !result.IsError {
+		t.Error("expected call to a hidden tool to fail")
+	}
+
+	// Enabling a narrow toolset exposes only its tools
+	if result := call("gridctl__enable_toolset", map[string]any{"toolset": "github-read"}); result.IsError {
+		t.Fatalf("expected enable to succeed, got %v", result.Content)
+	}
+	if len(notifications) != 1 || notifications[0] != NotificationToolsListChanged {
+		t.Errorf("expected a list_changed notification, got %v", notifications)
+	}
+	names = listNames()
+	if !names["github__search_code"] || names["github__create_issue"] {
+		t.Errorf("expected only github__search_code from github, got %v", names)
+	}
+	if result := call("github__search_code", nil); result.IsError {
+		t.Errorf("expected call to an enabled tool to succeed, got %v", result.Content)
+	}
+
+	// Enabling again changes nothing
+	call("gridctl__enable_toolset", map[string]any{"toolset": "github-read"})
+	if len(notifications) != 1 {
+		t.Errorf("expected no notification for an unchanged toolset, got %v", notifications)
+	}
+
+	// Disabling hides the tools again
+	call("gridctl__enable_toolset", map[string]any{"toolset": "github-read", "enabled": false})
+	if names := listNames(); names["github__search_code"] {
+		t.Errorf("expected github__search_code hidden after disable, got %v", names)
+	}
+
+	// Other sessions are unaffected
+	other := g.Sessions().Create(ClientInfo{})
+	g.Sessions().SetToolset(session.ID, "github", true)
+	list, _ := g.HandleToolsListForSession(other.ID, "")
+	for _, tool := range list.Tools {
+		if tool.Name == "github__search_code" {
+			t.Error("expected toolsets to be per session")
+		}
+	}
+
+	errorCases := []struct {
+		name string
+		args map[string]any
+	}{
+		{name: "unknown toolset", args: map[string]any{"toolset": "slack"}},
+		{name: "missing toolset", args: map[string]any{}},
+		{name: "non-boolean enabled", args: map[string]any{"toolset": "github", "enabled": "yes"}},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := call("gridctl__enable_toolset", tc.args); !result.IsError {
+				t.Errorf("expected error result, got %v", result.Content)
+			}
+		})
+	}
+}
+
+func TestGateway_ToolsetsDisabled(t *testing.T) {
+	g := NewGateway()
+	g.Router().AddClient(NewMockAgentClient("github", []Tool{{Name: "search_code"}}))
+	g.Router().RefreshTools()
+
+	list, err := g.HandleToolsListForSession("", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Tools) != 1 || list.Tools[0].Name != "github__search_code" {
+		t.Errorf("expected tools unchanged without toolsets, got %v", list.Tools)
+	}
+}
+
+func TestGateway_ToolSearch(t *testing.T) {
+	g := NewGateway()
+	ctx := context.Background()
+
+	client := NewMockAgentClient("github", []Tool{
+		{Name: "create_issue", Description: "Create an issue"},
+		{Name: "delete_repo", Description: "Delete a repository"},
+	})
+	var gotArgs map[string]any
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		gotArgs = args
+		return &ToolCallResult{Content: []Content{NewTextContent("called " + name)}}, nil
+	})
+	g.Router().AddClient(client)
+	g.Router().RefreshTools()
+	g.SetToolSearch(true)
+	g.RegisterAgent("triage", []config.ToolSelector{{Server: "github", Tools: []string{"create_issue"}}})
+
+	list, err := g.HandleToolsListForSession("", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Tools) != 2 || list.Tools[0].Name != "gridctl__search_tools" || list.Tools[1].Name != "gridctl__call_tool" {
+		t.Fatalf("expected only the search meta-tools, got %v", list.Tools)
+	}
+
+	search := func(agent, query string) string {
+		t.Helper()
+		result, err := g.HandleToolsCallForSession(ctx, "", agent, ToolCallParams{
+			Name:      "gridctl__search_tools",
+			Arguments: map[string]any{"query": query},
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("search failed: %v %v", err, result)
+		}
+		return result.Content[0].Text
+	}
+
+	if text := search("", "delete repository"); !strings.Contains(text, "github__delete_repo") {
+		t.Errorf("expected github__delete_repo in results, got %s", text)
+	}
+	// Agent access rules apply to search results
+	if text := search("triage", "delete repository"); strings.Contains(text, "github__delete_repo") {
+		t.Errorf("expected github__delete_repo filtered for agent, got %s", text)
+	}
+
+	// The index follows tool changes
+	g.Router().AddClient(NewMockAgentClient("time", []Tool{{Name: "now", Description: "Current time"}}))
+	g.Router().RefreshTools()
+	if text := search("", "current time"); !strings.Contains(text, "time__now") {
+		t.Errorf("expected new tool to be searchable, got %s", text)
+	}
+
+	result, err := g.HandleToolsCallForSession(ctx, "", "", ToolCallParams{
+		Name:      "gridctl__call_tool",
+		Arguments: map[string]any{"name": "github__create_issue", "args": map[string]any{"title": "bug"}},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("call_tool failed: %v %v", err, result)
+	}
+	if gotArgs["title"] != "bug" {
+		t.Errorf("expected args to be passed through, got %v", gotArgs)
+	}
+
+	// call_tool enforces agent access
+	result, _ = g.HandleToolsCallForSession(ctx, "", "triage", ToolCallParams{
+		Name:      "gridctl__call_tool",
+		Arguments: map[string]any{"name": "github__delete_repo"},
+	})
+	if !result.IsError {
+		t.Error("expected call_tool to deny a tool the agent cannot access")
+	}
+
+	errorCases := []struct {
+		name   string
+		params ToolCallParams
+	}{
+		{name: "empty query", params: ToolCallParams{Name: "gridctl__search_tools", Arguments: map[string]any{"query": " "}}},
+		{name: "invalid limit", params: ToolCallParams{Name: "gridctl__search_tools", Arguments: map[string]any{"query": "issue", "limit": 0.0}}},
+		{name: "missing name", params: ToolCallParams{Name: "gridctl__call_tool", Arguments: map[string]any{}}},
+		{name: "nested call", params: ToolCallParams{Name: "gridctl__call_tool", Arguments: map[string]any{"name": "gridctl__call_tool"}}},
+		{name: "invalid args", params: ToolCallParams{Name: "gridctl__call_tool", Arguments: map[string]any{"name": "time__now", "args": "x"}}},
+	}
+	for _, tc := range errorCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := g.HandleToolsCallForSession(ctx, "", "", tc.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.IsError {
+				t.Errorf("expected error result, got %v", result.Content)
+			}
+		})
+	}
+}
@@ -5,8 +5,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
+
+	"github.com/gridctl/gridctl/pkg/tracing"
 )
 
+// SessionHeader carries the gateway session ID on streamable HTTP requests.
+const SessionHeader = "Mcp-Session-Id"
+
 // Handler provides HTTP handlers for the MCP gateway.
 type Handler struct {
 	gateway *Gateway
@@ -37,7 +43,8 @@ func (h *Handler) handlePost(w http.ResponseWriter, r *http.Request) {
 	// Set CORS headers
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Agent-Name")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Agent-Name, Mcp-Session-Id")
+	w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
 	w.Header().Set("Content-Type", "application/json")
 
 	// Read request body
@@ -60,16 +67,25 @@ func (h *Handler) handlePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A response to a request the gateway sent, such as elicitation/create
+	if req.Method == "" && req.ID != nil {
+		var resp Response
+		_ = json.Unmarshal(body, &resp)
+		h.gateway.Sessions().HandleResponse(r.Header.Get(SessionHeader), resp)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
 	// Route to handler based on method
-	resp := h.handleMethod(r, &req)
+	resp := h.handleMethod(w, r, &req)
 	h.writeResponse(w, resp)
 }
 
 // handleMethod routes the request to the appropriate handler.
-func (h *Handler) handleMethod(r *http.Request, req *Request) Response {
+func (h *Handler) handleMethod(w http.ResponseWriter, r *http.Request, req *Request) Response {
 	switch req.Method {
 	case "initialize":
-		return h.handleInitialize(req)
+		return h.handleInitialize(w, req)
 	case "notifications/initialized":
 		// Client notification, just acknowledge
 		return NewSuccessResponse(req.ID, nil)
@@ -84,8 +100,9 @@ func (h *Handler) handleMethod(r *http.Request, req *Request) Response {
 	}
 }
 
-// handleInitialize handles the initialize request.
-func (h *Handler) handleInitialize(req *Request) Response {
+// handleInitialize handles the initialize request. The new session's ID is
+// returned in the Mcp-Session-Id header for the client to send back.
+func (h *Handler) handleInitialize(w http.ResponseWriter, req *Request) Response {
 	var params InitializeParams
 	if req.Params != nil {
 		if err := json.Unmarshal(req.Params, &params); err != nil {
@@ -93,29 +110,21 @@ func (h *Handler) handleInitialize(req *Request) Response {
 		}
 	}
 
-	result, err := h.gateway.HandleInitialize(params)
+	result, session, err := h.gateway.HandleInitializeSession("", params)
 	if err != nil {
 		return NewErrorResponse(req.ID, InternalError, err.Error())
 	}
+	w.Header().Set(SessionHeader, session.ID)
 
 	return NewSuccessResponse(req.ID, result)
 }
 
 // handleToolsList handles the tools/list request.
 func (h *Handler) handleToolsList(r *http.Request, req *Request) Response {
-	// Check for agent identity header for access control
+	// Agent identity header for access control; without it all tools are listed
 	agentName := r.Header.Get("X-Agent-Name")
 
-	var result *ToolsListResult
-	var err error
-	if agentName != "" {
-		// Filter tools based on agent's allowed MCP servers
-		result, err = h.gateway.HandleToolsListForAgent(agentName)
-	} else {
-		// No agent header - return all tools
-		result, err = h.gateway.HandleToolsList()
-	}
-
+	result, err := h.gateway.HandleToolsListForSession(r.Header.Get(SessionHeader), agentName)
 	if err != nil {
 		return NewErrorResponse(req.ID, InternalError, err.Error())
 	}
@@ -129,19 +138,11 @@ func (h *Handler) handleToolsCall(r *http.Request, req *Request) Response {
 		return NewErrorResponse(req.ID, InvalidParams, "Invalid tools/call params")
 	}
 
-	// Check for agent identity header for access control
+	// Agent identity header for access control; without it all tools are allowed
 	agentName := r.Header.Get("X-Agent-Name")
 
-	var result *ToolCallResult
-	var err error
-	if agentName != "" {
-		// Validate agent has access to this tool's MCP server
-		result, err = h.gateway.HandleToolsCallForAgent(r.Context(), agentName, params)
-	} else {
-		// No agent header - allow all tools
-		result, err = h.gateway.HandleToolsCall(r.Context(), params)
-	}
-
+	ctx := tracing.ExtractHTTP(r.Context(), r.Header)
+	result, err := h.gateway.HandleToolsCallForSession(ctx, r.Header.Get(SessionHeader), agentName, params)
 	if err != nil {
 		return NewErrorResponse(req.ID, InternalError, err.Error())
 	}
@@ -149,28 +150,56 @@ func (h *Handler) handleToolsCall(r *http.Request, req *Request) Response {
 	return NewSuccessResponse(req.ID, result)
 }
 
-// handleSSE handles Server-Sent Events connections.
+// handleSSE handles Server-Sent Events connections. When the request
+// carries the Mcp-Session-Id of a known session, server notifications for
+// that session are delivered on the stream.
 func (h *Handler) handleSSE(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
+	flusher, _ := w.(http.Flusher)
+	var mu sync.Mutex
+	closed := false
+	write := func(data []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
 	// Send initial connection message
-	fmt.Fprintf(w, "data: {\"type\":\"connected\"}\n\n")
-	if f, ok := w.(http.Flusher); ok {
-		f.Flush()
+	write([]byte(`{"type":"connected"}`))
+
+	sessions := h.gateway.Sessions()
+	if id := r.Header.Get(SessionHeader); id != "" && sessions.Get(id) != nil {
+		sessions.SetNotifier(id, func(msg Request) {
+			data, _ := json.Marshal(msg)
+			write(data)
+		})
+		defer sessions.SetNotifier(id, nil)
 	}
 
 	// Keep connection open until client disconnects
 	<-r.Context().Done()
+
+	mu.Lock()
+	closed = true
+	mu.Unlock()
 }
 
 // handleCORS handles CORS preflight requests.
 func (h *Handler) handleCORS(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Agent-Name")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Agent-Name, Mcp-Session-Id")
+	w.Header().Set("Access-Control-Expose-Headers", "Mcp-Session-Id")
 	w.WriteHeader(http.StatusOK)
 }
 
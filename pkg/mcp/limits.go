@@ -0,0 +1,239 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gridctl/gridctl/pkg/config"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultCallTimeout bounds requests to MCP servers when the caller has not
+// set a deadline, so a hung server cannot block a call forever.
+const DefaultCallTimeout = 30 * time.Second
+
+// Limits counted in gridctl_tool_calls_limited_total.
+const (
+	limitRate        = "rate_limit"
+	limitConcurrency = "max_concurrency"
+	limitTimeout     = "timeout"
+)
+
+// maxBuckets is how many per-agent rate limit buckets are kept before full
+// ones are dropped. A full bucket is the same as a new one.
+const maxBuckets = 1000
+
+// withDefaultTimeout returns ctx with DefaultCallTimeout applied if it has
+// no deadline.
+func withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, DefaultCallTimeout)
+}
+
+// callLimiter enforces the limits of one MCP server or tool.
+type callLimiter struct {
+	scope    string        // "server 'x'" or "tool 'y' on 'x'", for error messages
+	timeout  time.Duration // 0 = none
+	slots    chan struct{} // Calls in flight; nil = unlimited
+	rate     rate.Limit
+	burst    int
+	perAgent bool
+
+	mu      sync.Mutex
+	buckets map[string]*rate.Limiter // Caller -> bucket, or "" when shared
+}
+
+// newCallLimiter builds a limiter from config, or returns nil if no limit
+// is set.
+func newCallLimiter(scope string, l config.CallLimits) (*callLimiter, error) {
+	if !l.IsSet() {
+		return nil, nil
+	}
+	timeout, err := l.TimeoutDuration()
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout: %w", err)
+	}
+	cl := &callLimiter{scope: scope, timeout: timeout, buckets: make(map[string]*rate.Limiter)}
+	if l.MaxConcurrency > 0 {
+		cl.slots = make(chan struct{}, l.MaxConcurrency)
+	}
+	if r := l.RateLimit; r != nil {
+		calls, per, err := r.Parse()
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+		cl.rate = rate.Limit(float64(calls) / per.Seconds())
+		cl.burst = r.BurstSize()
+		cl.perAgent = r.PerAgent
+	}
+	return cl, nil
+}
+
+// bucket returns the token bucket for a caller, or nil if calls are not
+// rate limited.
+func (l *callLimiter) bucket(caller string) *rate.Limiter {
+	if l.rate == 0 {
+		return nil
+	}
+	if !l.perAgent {
+		caller = ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[caller]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			for k, other := range l.buckets {
+				if other.Tokens() >= float64(l.burst) {
+					delete(l.buckets, k)
+				}
+			}
+		}
+		b = rate.NewLimiter(l.rate, l.burst)
+		l.buckets[caller] = b
+	}
+	return b
+}
+
+// serverLimiters holds the limits of an MCP server and its tools.
+type serverLimiters struct {
+	server *callLimiter
+	tools  map[string]*callLimiter // Original tool name -> limits
+}
+
+// newServerLimiters builds the limiters for an MCP server, or returns nil
+// if it has no limits.
+func newServerLimiters(cfg MCPServerConfig) (*serverLimiters, error) {
+	server, err := newCallLimiter(fmt.Sprintf("server '%s'", cfg.Name), cfg.Limits)
+	if err != nil {
+		return nil, err
+	}
+	tools := make(map[string]*callLimiter)
+	for tool, limits := range cfg.ToolLimits {
+		l, err := newCallLimiter(fmt.Sprintf("tool '%s' on '%s'", tool, cfg.Name), limits)
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: %w", tool, err)
+		}
+		if l != nil {
+			tools[tool] = l
+		}
+	}
+	if server == nil && len(tools) == 0 {
+		return nil, nil
+	}
+	return &serverLimiters{server: server, tools: tools}, nil
+}
+
+// limitError describes a call rejected by a limit.
+type limitError struct {
+	limit   string // One of the limit* constants
+	message string
+}
+
+// acquire admits a call to a server's tool: it takes a concurrency slot and
+// a rate limit token at the server and tool levels, and applies the
+// timeout. Calls over a limit are rejected with a *limitError rather than
+// queued, so a runaway caller gets immediate feedback. The returned
+// function must be called when the call finishes.
+func (s *serverLimiters) acquire(ctx context.Context, tool string) (context.Context, func(), time.Duration, *limitError) {
+	limiters := []*callLimiter{s.server, s.tools[tool]}
+	var held []*callLimiter
+	release := func() {
+		for _, l := range held {
+			<-l.slots
+		}
+	}
+
+	for _, l := range limiters {
+		if l == nil || l.slots == nil {
+			continue
+		}
+		select {
+		case l.slots <- struct{}{}:
+			held = append(held, l)
+		default:
+			release()
+			return ctx, nil, 0, &limitError{limitConcurrency,
+				fmt.Sprintf("Too many concurrent calls to %s (max_concurrency: %d), retry when a call finishes", l.scope, cap(l.slots))}
+		}
+	}
+
+	caller := ""
+	if info, ok := callInfoFrom(ctx); ok {
+		caller = info.agent
+		if caller == "" {
+			caller = info.sessionID
+		}
+	}
+	now := time.Now()
+	var reserved []*rate.Reservation
+	for _, l := range limiters {
+		if l == nil {
+			continue
+		}
+		b := l.bucket(caller)
+		if b == nil {
+			continue
+		}
+		r := b.ReserveN(now, 1)
+		if delay := r.DelayFrom(now); !r.OK() || delay > 0 {
+			r.CancelAt(now)
+			for _, other := range reserved {
+				other.CancelAt(now)
+			}
+			release()
+			return ctx, nil, 0, &limitError{limitRate,
+				fmt.Sprintf("Rate limit exceeded for %s, retry in %s", l.scope, delay.Round(time.Millisecond))}
+		}
+		reserved = append(reserved, r)
+	}
+
+	timeout := s.server.timeoutOrZero()
+	if t := s.tools[tool].timeoutOrZero(); t > 0 {
+		timeout = t
+	}
+	if timeout == 0 {
+		return ctx, release, 0, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() { cancel(); release() }, timeout, nil
+}
+
+// timeoutOrZero returns the limiter's timeout, or 0 for a nil limiter.
+func (l *callLimiter) timeoutOrZero() time.Duration {
+	if l == nil {
+		return 0
+	}
+	return l.timeout
+}
+
+// limitsFor returns the limiters of a server, or nil if it has none.
+func (g *Gateway) limitsFor(server string) *serverLimiters {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.limits[server]
+}
+
+// applyLimits admits a routed tool call under its server's limits. It
+// returns the context to make the call with and a function to call when it
+// finishes, or an error result if the call was rejected.
+func (g *Gateway) applyLimits(ctx context.Context, server, tool string) (context.Context, func(), time.Duration, *ToolCallResult) {
+	limits := g.limitsFor(server)
+	if limits == nil {
+		return ctx, func() {}, 0, nil
+	}
+	ctx, release, timeout, lerr := limits.acquire(ctx, tool)
+	if lerr != nil {
+		g.metrics.limited.WithLabelValues(server, tool, lerr.limit).Inc()
+		return ctx, nil, 0, &ToolCallResult{
+			Content: []Content{NewTextContent(lerr.message)},
+			IsError: true,
+		}
+	}
+	return ctx, release, timeout, nil
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gridctl/gridctl/pkg/config"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newLimitedGateway returns a gateway with a mock "github" server whose
+// calls are limited as configured.
+func newLimitedGateway(t *testing.T, limits config.CallLimits, toolLimits map[string]config.CallLimits) (*Gateway, *MockAgentClient) {
+	t.Helper()
+	g := NewGateway()
+	client := NewMockAgentClient("github", []Tool{{Name: "search"}, {Name: "create_issue"}})
+	g.Router().AddClient(client)
+	g.Router().RefreshTools()
+
+	l, err := newServerLimiters(MCPServerConfig{Name: "github", Limits: limits, ToolLimits: toolLimits})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g.limits["github"] = l
+	return g, client
+}
+
+func callText(t *testing.T, g *Gateway, ctx context.Context, name string) (string, bool) {
+	t.Helper()
+	result, err := g.HandleToolsCall(ctx, ToolCallParams{Name: name})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result.Content[0].Text, result.IsError
+}
+
+func TestGateway_RateLimit(t *testing.T) {
+	g, _ := newLimitedGateway(t, config.CallLimits{RateLimit: &config.RateLimit{Rate: "2/m"}}, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if text, isErr := callText(t, g, ctx, "github__search"); isErr {
+			t.Fatalf("call %d: unexpected error result: %s", i, text)
+		}
+	}
+	text, isErr := callText(t, g, ctx, "github__create_issue")
+	if !isErr || !strings.Contains(text, "Rate limit exceeded for server 'github', retry in") {
+		t.Errorf("expected rate limit error, got %q", text)
+	}
+	if got := testutil.ToFloat64(g.metrics.limited.WithLabelValues("github", "create_issue", limitRate)); got != 1 {
+		t.Errorf("expected 1 rate limited call, got %v", got)
+	}
+	if got := testutil.ToFloat64(g.metrics.toolCalls.WithLabelValues("github", "create_issue")); got != 0 {
+		t.Errorf("expected rejected call not to reach the server, got %v calls", got)
+	}
+}
+
+func TestGateway_RateLimitPerAgent(t *testing.T) {
+	g, _ := newLimitedGateway(t, config.CallLimits{}, map[string]config.CallLimits{
+		"search": {RateLimit: &config.RateLimit{Rate: "1/h", PerAgent: true}},
+	})
+	alice := withCallInfo(context.Background(), "", "alice")
+	bob := withCallInfo(context.Background(), "", "bob")
+
+	if _, isErr := callText(t, g, alice, "github__search"); isErr {
+		t.Fatal("expected first call from alice to succeed")
+	}
+	if _, isErr := callText(t, g, bob, "github__search"); isErr {
+		t.Fatal("expected bob to have a separate bucket")
+	}
+	if text, isErr := callText(t, g, alice, "github__search"); !isErr || !strings.Contains(text, "tool 'search' on 'github'") {
+		t.Errorf("expected alice to be rate limited, got %q", text)
+	}
+	// Other tools are not limited
+	if _, isErr := callText(t, g, alice, "github__create_issue"); isErr {
+		t.Error("expected create_issue to be unlimited")
+	}
+}
+
+func TestGateway_MaxConcurrency(t *testing.T) {
+	g, client := newLimitedGateway(t, config.CallLimits{MaxConcurrency: 1}, nil)
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		if name == "search" {
+			close(started)
+			<-unblock
+		}
+		return &ToolCallResult{Content: []Content{NewTextContent("ok")}}, nil
+	})
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = g.HandleToolsCall(ctx, ToolCallParams{Name: "github__search"})
+	}()
+	<-started
+
+	text, isErr := callText(t, g, ctx, "github__create_issue")
+	if !isErr || !strings.Contains(text, "Too many concurrent calls to server 'github' (max_concurrency: 1)") {
+		t.Errorf("expected concurrency error, got %q", text)
+	}
+	if got := testutil.ToFloat64(g.metrics.limited.WithLabelValues("github", "create_issue", limitConcurrency)); got != 1 {
+		t.Errorf("expected 1 concurrency limited call, got %v", got)
+	}
+
+	close(unblock)
+	<-done
+	if text, isErr := callText(t, g, ctx, "github__create_issue"); isErr {
+		t.Errorf("expected call to succeed once the slot is free, got %q", text)
+	}
+}
+
+func TestGateway_CallTimeout(t *testing.T) {
+	g, client := newLimitedGateway(t, config.CallLimits{Timeout: "1h"}, map[string]config.CallLimits{
+		"search": {Timeout: "20ms"},
+	})
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		if name == "search" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		deadline, ok := ctx.Deadline()
+		if !ok || time.Until(deadline) < time.Minute {
+			t.Errorf("expected the server's 1h timeout, got %v", deadline)
+		}
+		return &ToolCallResult{Content: []Content{NewTextContent("ok")}}, nil
+	})
+	ctx := context.Background()
+
+	text, isErr := callText(t, g, ctx, "github__search")
+	if !isErr || text != "Error calling tool: timed out after 20ms" {
+		t.Errorf("expected timeout error, got %q", text)
+	}
+	if got := testutil.ToFloat64(g.metrics.limited.WithLabelValues("github", "search", limitTimeout)); got != 1 {
+		t.Errorf("expected 1 timed out call, got %v", got)
+	}
+	if _, isErr := callText(t, g, ctx, "github__create_issue"); isErr {
+		t.Error("expected create_issue to succeed")
+	}
+}
+
+func TestNewServerLimiters(t *testing.T) {
+	l, err := newServerLimiters(MCPServerConfig{Name: "github"})
+	if err != nil || l != nil {
+		t.Errorf("expected no limiters without limits, got %v (%v)", l, err)
+	}
+
+	_, err = newServerLimiters(MCPServerConfig{Name: "github", ToolLimits: map[string]config.CallLimits{
+		"search": {RateLimit: &config.RateLimit{Rate: "fast"}},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "tool search: invalid rate limit") {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"time"
+
+	"github.com/gridctl/gridctl/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Downstream operations counted in gridctl_mcp_server_failures_total.
+const (
+	opInitialize = "initialize"
+	opRefresh    = "refresh"
+)
+
+// toolDurationBuckets are the upper bounds, in seconds, of tool call
+// latencies: Prometheus's default buckets, plus one for slow tools.
+var toolDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// gatewayMetrics holds the metrics the gateway updates as it runs.
+type gatewayMetrics struct {
+	toolCalls      *prometheus.CounterVec
+	toolErrors     *prometheus.CounterVec
+	toolDuration   *prometheus.HistogramVec
+	serverFailures *prometheus.CounterVec
+	limited        *prometheus.CounterVec
+	cacheHits      *prometheus.CounterVec
+	cacheMisses    *prometheus.CounterVec
+	denied         *prometheus.CounterVec
+}
+
+func newGatewayMetrics() *gatewayMetrics {
+	return &gatewayMetrics{
+		toolCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gridctl_tool_calls_total",
+			Help: "Tool calls routed to downstream servers.",
+		}, []string{"server", "tool"}),
+		toolErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gridctl_tool_call_errors_total",
+			Help: "Tool calls that returned an error result.",
+		}, []string{"server", "tool"}),
+		toolDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "gridctl_tool_call_duration_seconds",
+			Help:    "Time taken by tool calls, including the downstream server.",
+			Buckets: toolDurationBuckets,
+		}, []string{"server", "tool"}),
+		serverFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gridctl_mcp_server_failures_total",
+			Help: "Failed initialize and tool refresh requests to MCP servers.",
+		}, []string{"server", "operation"}),
+		limited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gridctl_tool_calls_limited_total",
+			Help: "Tool calls rejected by a rate or concurrency limit, or cut off by a timeout.",
+		}, []string{"server", "tool", "limit"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gridctl_tool_cache_hits_total",
+			Help: "Tool calls answered from the result cache.",
+		}, []string{"server", "tool"}),
+		cacheMisses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gridctl_tool_cache_misses_total",
+			Help: "Calls to cached tools that were not in the result cache.",
+		}, []string{"server", "tool"}),
+		denied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gridctl_tool_calls_denied_total",
+			Help: "Tool calls rejected by a policy on their arguments.",
+		}, []string{"server", "tool", "policy"}),
+	}
+}
+
+// observeCall records a routed tool call.
+func (m *gatewayMetrics) observeCall(server, tool string, start time.Time, result *ToolCallResult) {
+	m.toolCalls.WithLabelValues(server, tool).Inc()
+	m.toolDuration.WithLabelValues(server, tool).Observe(time.Since(start).Seconds())
+	if result != nil && result.IsError {
+		m.toolErrors.WithLabelValues(server, tool).Inc()
+	}
+}
+
+// RegisterMetrics adds the gateway's metrics to a registry: tool call
+// counts, errors and latencies, limited and denied calls, cache hits and misses,
+// downstream failures, MCP server health, and open client sessions.
+func (g *Gateway) RegisterMetrics(r prometheus.Registerer) {
+	r.MustRegister(
+		g.metrics.toolCalls,
+		g.metrics.toolErrors,
+		g.metrics.toolDuration,
+		g.metrics.serverFailures,
+		g.metrics.limited,
+		g.metrics.cacheHits,
+		g.metrics.cacheMisses,
+		g.metrics.denied,
+		metrics.NewGaugeFunc("gridctl_mcp_server_up",
+			"Whether an MCP server is registered and initialized (1) or not (0).",
+			[]string{"server"}, func() []metrics.Sample {
+				statuses := g.Status()
+				samples := make([]metrics.Sample, len(statuses))
+				for i, s := range statuses {
+					up := 0.0
+					if s.Initialized {
+						up = 1
+					}
+					samples[i] = metrics.Sample{Labels: []string{s.Name}, Value: up}
+				}
+				return samples
+			}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "gridctl_mcp_sessions",
+			Help: "Open MCP client sessions, over streamable HTTP and SSE.",
+		}, func() float64 {
+			return float64(g.sessions.Count())
+		}),
+	)
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gridctl/gridctl/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGateway_Metrics(t *testing.T) {
+	g := NewGateway()
+	ctx := context.Background()
+
+	client := NewMockAgentClient("github", []Tool{
+		{Name: "search", Description: "Search"},
+		{Name: "fail", Description: "Always fails"},
+	})
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		return &ToolCallResult{Content: []Content{NewTextContent(name)}, IsError: name == "fail"}, nil
+	})
+	g.Router().AddClient(client)
+	g.Router().RefreshTools()
+
+	for _, name := range []string{"github__search", "github__search", "github__fail", "github__missing"} {
+		if _, err := g.HandleToolsCall(ctx, ToolCallParams{Name: name}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	g.Sessions().Create(ClientInfo{Name: "test"})
+
+	if got := testutil.ToFloat64(g.metrics.toolCalls.WithLabelValues("github", "search")); got != 2 {
+		t.Errorf("expected 2 search calls, got %v", got)
+	}
+	if got := testutil.ToFloat64(g.metrics.toolErrors.WithLabelValues("github", "fail")); got != 1 {
+		t.Errorf("expected 1 fail error, got %v", got)
+	}
+
+	reg := prometheus.NewRegistry()
+	g.RegisterMetrics(reg)
+	out := scrape(t, reg)
+	for _, want := range []string{
+		`gridctl_tool_calls_total{server="github",tool="fail"} 1`,
+		`gridctl_tool_call_duration_seconds_count{server="github",tool="search"} 2`,
+		`gridctl_mcp_sessions 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	// Unresolved tools are not counted, keeping label values bounded
+	if strings.Contains(out, "missing") {
+		t.Errorf("unexpected series for unknown tool:\n%s", out)
+	}
+}
+
+func TestGateway_MetricsServerFailures(t *testing.T) {
+	g := NewGateway()
+	err := g.RegisterMCPServer(context.Background(), MCPServerConfig{Name: "broken", Transport: "carrier-pigeon"})
+	if err == nil {
+		t.Fatal("expected error for unknown transport")
+	}
+	if got := testutil.ToFloat64(g.metrics.serverFailures.WithLabelValues("broken", opInitialize)); got != 1 {
+		t.Errorf("expected 1 initialize failure, got %v", got)
+	}
+
+	failing := NewMockAgentClient("flaky", nil)
+	failing.SetRefreshToolsFn(func(ctx context.Context) error { return context.DeadlineExceeded })
+	g.Router().AddClient(failing)
+	_ = g.RefreshAllTools(context.Background())
+	if got := testutil.ToFloat64(g.metrics.serverFailures.WithLabelValues("flaky", opRefresh)); got != 1 {
+		t.Errorf("expected 1 refresh failure, got %v", got)
+	}
+}
+
+// scrape returns the metrics in a registry as served at /metrics.
+func scrape(t *testing.T, reg *prometheus.Registry) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler(reg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d: %s", rec.Code, rec.Body.String())
+	}
+	return rec.Body.String()
+}
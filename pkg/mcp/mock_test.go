@@ -11,6 +11,7 @@ type MockAgentClient struct {
 	initialized bool
 	serverInfo  ServerInfo
 	callToolFn  func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error)
+	refreshFn   func(ctx context.Context) error
 }
 
 // NewMockAgentClient creates a new mock agent client for testing.
@@ -33,6 +34,9 @@ func (m *MockAgentClient) Initialize(ctx context.Context) error {
 }
 
 func (m *MockAgentClient) RefreshTools(ctx context.Context) error {
+	if m.refreshFn != nil {
+		return m.refreshFn(ctx)
+	}
 	return nil
 }
 
@@ -61,3 +65,8 @@ func (m *MockAgentClient) ServerInfo() ServerInfo {
 func (m *MockAgentClient) SetCallToolFn(fn func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error)) {
 	m.callToolFn = fn
 }
+
+// SetRefreshToolsFn sets a custom function to handle RefreshTools invocations.
+func (m *MockAgentClient) SetRefreshToolsFn(fn func(ctx context.Context) error) {
+	m.refreshFn = fn
+}
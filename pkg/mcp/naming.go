@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// DefaultToolNameMaxLength is the longest tool name many MCP clients accept
+// (e.g., Claude Desktop validates ^[a-zA-Z0-9_-]{1,64}$).
+const DefaultToolNameMaxLength = 64
+
+// toolNameHashLength is the number of hex digits kept from a truncated
+// name's hash.
+const toolNameHashLength = 8
+
+// ToolNamer builds the exposed names of aggregated tools.
+type ToolNamer struct {
+	Separator string // Between the prefix and the tool name
+	MaxLength int    // Longer names are truncated with a hash suffix (0 = no limit)
+}
+
+// DefaultToolNamer returns the "server__tool" naming scheme.
+func DefaultToolNamer() ToolNamer {
+	return ToolNamer{Separator: ToolNameDelimiter, MaxLength: DefaultToolNameMaxLength}
+}
+
+// Name joins a prefix and tool name. An empty prefix leaves the tool name
+// as is. Names over MaxLength are cut short and end in "_" plus a hash of
+// the full name, so the result is stable and unlikely to collide.
+func (n ToolNamer) Name(prefix, tool string) string {
+	name := tool
+	if prefix != "" {
+		name = prefix + n.Separator + tool
+	}
+	if n.MaxLength <= 0 || len(name) <= n.MaxLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:])[:toolNameHashLength]
+	keep := n.MaxLength - len(suffix) - 1
+	if keep < 0 {
+		keep = 0
+	}
+	return name[:keep] + "_" + suffix
+}
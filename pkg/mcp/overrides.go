@@ -0,0 +1,215 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+// applyToolOverride returns the tool as it should be advertised under an
+// override: with the description rewritten and the input schema tightened.
+// The tool's name is left unchanged; see config.ToolOverride.ExposedNames.
+func applyToolOverride(tool Tool, ov *config.ToolOverride) Tool {
+	if ov.Description != "" {
+		tool.Description = ov.Description
+	}
+	if ov.DescriptionAppend != "" {
+		if tool.Description != "" {
+			tool.Description += " "
+		}
+		tool.Description += ov.DescriptionAppend
+	}
+	tool.InputSchema = overrideSchema(tool.InputSchema, ov)
+	return tool
+}
+
+// overrideSchema removes hidden and pinned parameters from a JSON schema and
+// merges the override schema into it. Only the constraints config.Validate
+// allows are merged, and property overrides only apply to parameters the
+// server already declares, so they can narrow but not extend the schema. The
+// original schema is returned unchanged if it cannot be parsed.
+func overrideSchema(schema json.RawMessage, ov *config.ToolOverride) json.RawMessage {
+	if len(ov.HiddenParams) == 0 && len(ov.PinnedParams) == 0 && len(ov.Schema) == 0 {
+		return schema
+	}
+	var s map[string]any
+	if err := json.Unmarshal(schema, &s); err != nil || s == nil {
+		return schema
+	}
+
+	props, _ := s["properties"].(map[string]any)
+	removed := make(map[string]bool)
+	for _, param := range ov.HiddenParams {
+		removed[param] = true
+	}
+	for param := range ov.PinnedParams {
+		removed[param] = true
+	}
+	for param := range removed {
+		delete(props, param)
+	}
+
+	var required []any
+	seen := make(map[string]bool)
+	addRequired := func(list any) {
+		items, _ := list.([]any)
+		for _, item := range items {
+			name, ok := item.(string)
+			if !ok || removed[name] || seen[name] {
+				continue
+			}
+			seen[name] = true
+			required = append(required, name)
+		}
+	}
+	addRequired(s["required"])
+
+	for key, value := range ov.Schema {
+		switch key {
+		case "properties":
+			tighten, _ := value.(map[string]any)
+			for param, extra := range tighten {
+				orig, ok := props[param].(map[string]any)
+				if !ok {
+					continue
+				}
+				fields, _ := extra.(map[string]any)
+				for k, v := range fields {
+					tightenProperty(orig, k, v)
+				}
+			}
+		case "required":
+			addRequired(value)
+		case "additionalProperties":
+			if value == false {
+				s[key] = false
+			}
+		}
+	}
+
+	if len(required) > 0 {
+		s["required"] = required
+	} else {
+		delete(s, "required")
+	}
+
+	out, err := json.Marshal(s)
+	if err != nil {
+		return schema
+	}
+	return out
+}
+
+// tightenProperty merges one constraint into a parameter's schema, keeping
+// whichever of the two is stricter: bounds take the narrower value, enums
+// keep the values both allow, and a second pattern must match as well as
+// the server's. Other keys are ignored.
+func tightenProperty(prop map[string]any, key string, value any) {
+	switch key {
+	case "enum":
+		allowed, _ := value.([]any)
+		orig, ok := prop[key].([]any)
+		if !ok {
+			prop[key] = allowed
+			return
+		}
+		both := []any{}
+		for _, v := range orig {
+			if slices.ContainsFunc(allowed, func(a any) bool { return reflect.DeepEqual(normalizeJSON(a), v) }) {
+				both = append(both, v)
+			}
+		}
+		prop[key] = both
+	case "pattern":
+		orig, ok := prop[key].(string)
+		switch {
+		case !ok:
+			prop[key] = value
+		case orig != value:
+			allOf, _ := prop["allOf"].([]any)
+			prop["allOf"] = append(allOf, map[string]any{"pattern": value})
+		}
+	case "minLength", "minimum", "minItems", "maxLength", "maximum", "maxItems":
+		n, ok := number(value)
+		if !ok {
+			return
+		}
+		if orig, ok := number(prop[key]); ok {
+			if strings.HasPrefix(key, "min") {
+				n = max(n, orig)
+			} else {
+				n = min(n, orig)
+			}
+		}
+		prop[key] = n
+	}
+}
+
+// number returns a JSON or YAML number as a float64.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// normalizeJSON returns a YAML-decoded value as encoding/json would decode
+// it, so it can be compared with values from a server's schema.
+func normalizeJSON(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// overrideArguments returns call arguments with hidden parameters dropped and
+// pinned parameters set, so callers cannot override either.
+func overrideArguments(args map[string]any, ov *config.ToolOverride) map[string]any {
+	if len(ov.HiddenParams) == 0 && len(ov.PinnedParams) == 0 {
+		return args
+	}
+	out := make(map[string]any, len(args)+len(ov.PinnedParams))
+	for k, v := range args {
+		out[k] = v
+	}
+	for _, param := range ov.HiddenParams {
+		delete(out, param)
+	}
+	for k, v := range ov.PinnedParams {
+		out[k] = v
+	}
+	return out
+}
+
+// unknownOverrides returns the overridden tool names, in sorted order, that
+// are not among the server's tools.
+func unknownOverrides(overrides map[string]config.ToolOverride, tools []Tool) []string {
+	have := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		have[tool.Name] = true
+	}
+	var unknown []string
+	for name := range overrides {
+		if !have[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+// SetPolicies sets the policies evaluated on the arguments of tool calls.
+func (g *Gateway) SetPolicies(policies []config.Policy) error {
+	set, err := config.CompilePolicies(policies)
+	if err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.policies = set
+	return nil
+}
+
+// applyPolicies evaluates policies on a call to a server's tool. It returns
+// the arguments to call with, which redact policies may have changed, or
+// an error result if a policy denies the call.
+func (g *Gateway) applyPolicies(ctx context.Context, server, tool string, args map[string]any) (map[string]any, *ToolCallResult) {
+	g.mu.RLock()
+	policies := g.policies
+	g.mu.RUnlock()
+	if policies == nil || server == "" {
+		return args, nil
+	}
+
+	info, _ := callInfoFrom(ctx)
+	d := policies.Evaluate(info.agent, server, tool, args)
+	if !d.Denied {
+		return d.Arguments, nil
+	}
+
+	var msg string
+	if d.Policy == "" {
+		msg = "Denied: " + d.Message
+	} else if d.Message == "" {
+		msg = fmt.Sprintf("Denied by policy '%s'", d.Policy)
+	} else {
+		msg = fmt.Sprintf("Denied by policy '%s': %s", d.Policy, d.Message)
+	}
+	policy := d.Policy
+	if policy == "" {
+		policy = "allow"
+	}
+	g.metrics.denied.WithLabelValues(server, tool, policy).Inc()
+	g.logger.Warn("tool call denied by policy", "server", server, "tool", tool, "agent", info.agent, "policy", policy)
+	return nil, &ToolCallResult{Content: []Content{NewTextContent(msg)}, IsError: true}
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gridctl/gridctl/pkg/config"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGateway_Policies(t *testing.T) {
+	g := NewGateway()
+	client := NewMockAgentClient("filesystem", []Tool{{Name: "write_file"}, {Name: "read_file"}})
+	var received map[string]any
+	client.SetCallToolFn(func(ctx context.Context, name string, args map[string]any) (*ToolCallResult, error) {
+		received = args
+		return &ToolCallResult{Content: []Content{NewTextContent("ok")}}, nil
+	})
+	g.Router().AddClient(client)
+	g.Router().RefreshTools()
+	g.RegisterAgent("coder", []config.ToolSelector{{Server: "filesystem"}})
+
+	err := g.SetPolicies([]config.Policy{
+		{
+			Name:    "workspace-only",
+			Action:  config.PolicyDeny,
+			Agents:  []string{"coder"},
+			Server:  "filesystem",
+			Tools:   []string{"write_*"},
+			When:    []config.Condition{{Path: "$.path", Glob: "/workspace/**", Not: true}},
+			Message: "writes are limited to /workspace",
+		},
+		{
+			Name:   "hide-token",
+			Action: config.PolicyRedact,
+			Redact: []string{"$.token"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	result, err := g.HandleToolsCallForAgent(ctx, "coder", ToolCallParams{
+		Name:      "filesystem__write_file",
+		Arguments: map[string]any{"path": "/etc/passwd"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError || result.Content[0].Text != "Denied by policy 'workspace-only': writes are limited to /workspace" {
+		t.Errorf("expected denial, got %+v", result)
+	}
+	if received != nil {
+		t.Error("expected denied call not to reach the server")
+	}
+	if got := testutil.ToFloat64(g.metrics.denied.WithLabelValues("filesystem", "write_file", "workspace-only")); got != 1 {
+		t.Errorf("expected 1 denied call, got %v", got)
+	}
+
+	// Allowed paths, and callers outside the policy's agents, go through
+	// with redacted arguments
+	args := map[string]any{"path": "/workspace/a.txt", "token": "abc"}
+	if result, _ := g.HandleToolsCallForAgent(ctx, "coder", ToolCallParams{Name: "filesystem__write_file", Arguments: args}); result.IsError {
+		t.Fatalf("expected call to succeed, got %+v", result)
+	}
+	if received["token"] != config.PolicyRedacted || received["path"] != "/workspace/a.txt" {
+		t.Errorf("expected token redacted, got %v", received)
+	}
+	if args["token"] != "abc" {
+		t.Error("expected the caller's arguments to be unchanged")
+	}
+	if result, _ := g.HandleToolsCall(ctx, ToolCallParams{Name: "filesystem__write_file", Arguments: map[string]any{"path": "/etc/passwd"}}); result.IsError {
+		t.Errorf("expected policy to cover only coder, got %+v", result)
+	}
+}
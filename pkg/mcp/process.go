@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,6 +13,8 @@ import (
 	"sync/atomic"
 	"syscall"
 	"time"
+
+	"github.com/gridctl/gridctl/pkg/tracing"
 )
 
 // ProcessClient communicates with an MCP server via a local process stdin/stdout.
@@ -22,11 +25,12 @@ type ProcessClient struct {
 	env       []string
 	requestID atomic.Int64
 
-	mu            sync.RWMutex
-	initialized   bool
-	tools         []Tool
-	serverInfo    ServerInfo
-	toolWhitelist []string // Tool whitelist (empty = all tools)
+	mu                sync.RWMutex
+	initialized       bool
+	tools             []Tool
+	serverInfo        ServerInfo
+	toolWhitelist     []string // Tool whitelist (empty = all tools)
+	unmatchedPatterns []string // Whitelist patterns that matched no tool
 
 	// Process state
 	procMu  sync.Mutex
@@ -64,9 +68,9 @@ func (c *ProcessClient) Name() string {
 	return c.name
 }
 
-// SetToolWhitelist sets the list of allowed tool names.
-// Only tools in this list will be returned by Tools() and RefreshTools().
-// An empty or nil list means all tools are allowed.
+// SetToolWhitelist sets the tool selector patterns (names, globs, "re:"
+// regexes, and "!" exclusions). Only allowed tools will be returned by
+// Tools() after RefreshTools(). An empty or nil list means all tools are allowed.
 func (c *ProcessClient) SetToolWhitelist(tools []string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -191,7 +195,7 @@ func (c *ProcessClient) Initialize(ctx context.Context) error {
 }
 
 // RefreshTools fetches the current tool list from the agent.
-// If a tool whitelist has been set, only tools matching the whitelist are stored.
+// If a tool whitelist has been set, only tools allowed by its patterns are stored.
 func (c *ProcessClient) RefreshTools(ctx context.Context) error {
 	var result ToolsListResult
 	if err := c.call(ctx, "tools/list", nil, &result); err != nil {
@@ -201,23 +205,7 @@ func (c *ProcessClient) RefreshTools(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Filter tools if whitelist is set
-	if len(c.toolWhitelist) > 0 {
-		allowed := make(map[string]bool, len(c.toolWhitelist))
-		for _, name := range c.toolWhitelist {
-			allowed[name] = true
-		}
-
-		var filteredTools []Tool
-		for _, tool := range result.Tools {
-			if allowed[tool.Name] {
-				filteredTools = append(filteredTools, tool)
-			}
-		}
-		c.tools = filteredTools
-	} else {
-		c.tools = result.Tools
-	}
+	c.tools, c.unmatchedPatterns = filterTools(result.Tools, c.toolWhitelist)
 
 	return nil
 }
@@ -229,6 +217,14 @@ func (c *ProcessClient) Tools() []Tool {
 	return c.tools
 }
 
+// UnmatchedToolPatterns returns the whitelist patterns that matched no tool
+// on the last RefreshTools.
+func (c *ProcessClient) UnmatchedToolPatterns() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.unmatchedPatterns
+}
+
 // CallTool invokes a tool on the agent.
 func (c *ProcessClient) CallTool(ctx context.Context, name string, arguments map[string]any) (*ToolCallResult, error) {
 	params := ToolCallParams{
@@ -258,8 +254,12 @@ func (c *ProcessClient) ServerInfo() ServerInfo {
 	return c.serverInfo
 }
 
-// call performs a JSON-RPC call via stdin/stdout.
-func (c *ProcessClient) call(ctx context.Context, method string, params any, result any) error {
+// call performs a JSON-RPC call via stdin/stdout. The trace context is
+// propagated in the request's _meta.
+func (c *ProcessClient) call(ctx context.Context, method string, params any, result any) (err error) {
+	ctx, span := startClientSpan(ctx, c.name, method)
+	defer func() { tracing.End(span, err) }()
+
 	id := c.requestID.Add(1)
 	idBytes, _ := json.Marshal(id)
 	rawID := json.RawMessage(idBytes)
@@ -277,7 +277,7 @@ func (c *ProcessClient) call(ctx context.Context, method string, params any, res
 		JSONRPC: "2.0",
 		ID:      &rawID,
 		Method:  method,
-		Params:  paramsBytes,
+		Params:  withTraceMeta(ctx, paramsBytes),
 	}
 
 	// Create response channel
@@ -294,21 +294,19 @@ func (c *ProcessClient) call(ctx context.Context, method string, params any, res
 		return err
 	}
 
-	// Wait for response with timeout to prevent hanging on dead processes
-	timeout := time.NewTimer(30 * time.Second)
-	defer timeout.Stop()
+	// Wait for response, with a default timeout to prevent hanging on dead processes
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
 
 	select {
 	case <-ctx.Done():
 		c.responsesMu.Lock()
 		delete(c.responses, id)
 		c.responsesMu.Unlock()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timeout waiting for response from process: %w", ctx.Err())
+		}
 		return ctx.Err()
-	case <-timeout.C:
-		c.responsesMu.Lock()
-		delete(c.responses, id)
-		c.responsesMu.Unlock()
-		return fmt.Errorf("timeout waiting for response from process")
 	case resp := <-respCh:
 		if resp.Error != nil {
 			return fmt.Errorf("RPC error %d: %s", resp.Error.Code, resp.Error.Message)
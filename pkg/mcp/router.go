@@ -2,25 +2,88 @@ package mcp
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+
+	"github.com/gridctl/gridctl/pkg/config"
 )
 
 // Router routes tool calls to the appropriate agent.
 type Router struct {
-	mu      sync.RWMutex
-	clients map[string]AgentClient // agentName -> client
-	tools   map[string]string      // prefixedToolName -> agentName
+	mu         sync.RWMutex
+	namer      ToolNamer
+	clients    map[string]AgentClient                    // agentName -> client
+	prefixes   map[string]string                         // agentName -> tool name prefix, if not the agent name
+	tools      map[string]toolRoute                      // exposed tool name -> agent and original tool
+	overrides  map[string]map[string]config.ToolOverride // agentName -> original tool name -> override
+	collisions []ToolNameCollision
+	generation uint64 // Incremented whenever the exposed tools change
+}
+
+// ToolNameCollision records two tools that map to the same exposed name.
+// The first tool, in agent name order, keeps the name; the other is not exposed.
+type ToolNameCollision struct {
+	Name       string // Exposed tool name
+	Agent      string // Agent whose tool keeps the name
+	Tool       string
+	OtherAgent string // Agent whose tool is dropped
+	OtherTool  string
+}
+
+// toolRoute identifies the agent tool behind an exposed tool name.
+type toolRoute struct {
+	agent string
+	tool  string // Original tool name on the agent
 }
 
 // NewRouter creates a new tool router.
 func NewRouter() *Router {
 	return &Router{
-		clients: make(map[string]AgentClient),
-		tools:   make(map[string]string),
+		namer:     DefaultToolNamer(),
+		clients:   make(map[string]AgentClient),
+		prefixes:  make(map[string]string),
+		tools:     make(map[string]toolRoute),
+		overrides: make(map[string]map[string]config.ToolOverride),
 	}
 }
 
+// SetToolNamer sets the naming scheme for exposed tools.
+// Takes effect on the next RefreshTools.
+func (r *Router) SetToolNamer(namer ToolNamer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.namer = namer
+}
+
+// ToolNamer returns the naming scheme for exposed tools.
+func (r *Router) ToolNamer() ToolNamer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.namer
+}
+
+// SetToolPrefix sets the prefix for an agent's tool names in place of the
+// agent name. An empty prefix exposes the tools unprefixed.
+// Takes effect on the next RefreshTools.
+func (r *Router) SetToolPrefix(name, prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefixes[name] = prefix
+}
+
+// SetToolOverrides sets how an agent's tools are renamed, described, and
+// called. Takes effect on the next RefreshTools.
+func (r *Router) SetToolOverrides(name string, overrides map[string]config.ToolOverride) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(overrides) == 0 {
+		delete(r.overrides, name)
+		return
+	}
+	r.overrides[name] = overrides
+}
+
 // AddClient adds an agent client to the router.
 func (r *Router) AddClient(client AgentClient) {
 	r.mu.Lock()
@@ -33,13 +96,16 @@ func (r *Router) RemoveClient(name string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	delete(r.clients, name)
+	delete(r.prefixes, name)
+	delete(r.overrides, name)
 
 	// Remove tools for this agent
-	for tool, agent := range r.tools {
-		if agent == name {
+	for tool, route := range r.tools {
+		if route.agent == name {
 			delete(r.tools, tool)
 		}
 	}
+	r.generation++
 }
 
 // GetClient returns a client by agent name.
@@ -66,71 +132,173 @@ func (r *Router) RefreshTools() {
 	defer r.mu.Unlock()
 
 	// Clear existing tool mappings
-	r.tools = make(map[string]string)
+	r.tools = make(map[string]toolRoute)
+	r.collisions = nil
+	r.generation++
 
-	// Register tools from each agent with prefixes
-	for name, client := range r.clients {
-		for _, tool := range client.Tools() {
-			prefixedName := PrefixTool(name, tool.Name)
-			r.tools[prefixedName] = name
+	// Register tools from each agent under every exposed name
+	for _, name := range r.agentNames() {
+		for _, tool := range r.clients[name].Tools() {
+			for _, exposed := range r.exposedNames(name, tool.Name) {
+				route := toolRoute{agent: name, tool: tool.Name}
+				if existing, ok := r.tools[exposed]; ok {
+					r.collisions = append(r.collisions, ToolNameCollision{
+						Name:       exposed,
+						Agent:      existing.agent,
+						Tool:       existing.tool,
+						OtherAgent: route.agent,
+						OtherTool:  route.tool,
+					})
+					continue
+				}
+				r.tools[exposed] = route
+			}
 		}
 	}
 }
 
+// Generation returns a counter that changes whenever the exposed tools may
+// have changed, for caches derived from AggregatedTools.
+func (r *Router) Generation() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.generation
+}
+
+// Collisions returns the tool name collisions found by the last RefreshTools.
+func (r *Router) Collisions() []ToolNameCollision {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.collisions
+}
+
+// agentNames returns the registered agent names in sorted order, so that
+// collisions always resolve the same way. Must be called with r.mu held.
+func (r *Router) agentNames() []string {
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// exposedNames returns the full names an agent's tool is exposed under.
+// Must be called with r.mu held.
+func (r *Router) exposedNames(agentName, toolName string) []string {
+	prefix, ok := r.prefixes[agentName]
+	if !ok {
+		prefix = agentName
+	}
+	names := []string{toolName}
+	if ov, ok := r.overrides[agentName][toolName]; ok {
+		names = ov.ExposedNames(toolName)
+	}
+	for i, name := range names {
+		names[i] = r.namer.Name(prefix, name)
+	}
+	return names
+}
+
 // AggregatedTools returns all tools from all agents with prefixed names.
 func (r *Router) AggregatedTools() []Tool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var tools []Tool
-	for name, client := range r.clients {
-		for _, tool := range client.Tools() {
-			// Use original tool name as title for UI display
-			title := tool.Name
-			if tool.Title != "" {
-				title = tool.Title
+	seen := make(map[string]bool)
+	for _, name := range r.agentNames() {
+		for _, tool := range r.clients[name].Tools() {
+			if ov, ok := r.overrides[name][tool.Name]; ok {
+				tool = applyToolOverride(tool, &ov)
 			}
-			prefixedTool := Tool{
-				Name:        PrefixTool(name, tool.Name),
-				Title:       title,
-				Description: fmt.Sprintf("[%s] %s", name, tool.Description),
-				InputSchema: tool.InputSchema,
+			// Use the unprefixed tool name as title for UI display
+			titles := []string{tool.Name}
+			if ov, ok := r.overrides[name][tool.Name]; ok {
+				titles = ov.ExposedNames(tool.Name)
+			}
+			for i, exposed := range r.exposedNames(name, tool.Name) {
+				if seen[exposed] {
+					continue // Collision; see Collisions
+				}
+				seen[exposed] = true
+				title := titles[i]
+				if tool.Title != "" {
+					title = tool.Title
+				}
+				prefixedTool := Tool{
+					Name:        exposed,
+					Title:       title,
+					Description: fmt.Sprintf("[%s] %s", name, tool.Description),
+					InputSchema: tool.InputSchema,
+					Annotations: tool.Annotations,
+				}
+				tools = append(tools, prefixedTool)
 			}
-			tools = append(tools, prefixedTool)
 		}
 	}
 	return tools
 }
 
-// RouteToolCall routes a tool call to the appropriate agent.
-func (r *Router) RouteToolCall(prefixedName string) (AgentClient, string, error) {
+// RouteToolCall routes a tool call to the appropriate agent. It returns the
+// client, the original tool name, and the arguments to send, with any
+// hidden parameters dropped and pinned parameters set.
+func (r *Router) RouteToolCall(prefixedName string, arguments map[string]any) (AgentClient, string, map[string]any, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	agentName, toolName, err := ParsePrefixedTool(prefixedName)
+	route, err := r.resolve(prefixedName)
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, err
 	}
 
-	client, ok := r.clients[agentName]
+	client, ok := r.clients[route.agent]
 	if !ok {
-		return nil, "", fmt.Errorf("unknown agent: %s", agentName)
+		return nil, "", nil, fmt.Errorf("unknown agent: %s", route.agent)
+	}
+
+	if ov, ok := r.overrides[route.agent][route.tool]; ok {
+		arguments = overrideArguments(arguments, &ov)
 	}
+	return client, route.tool, arguments, nil
+}
+
+// ResolveTool maps an exposed tool name to its agent and original tool name.
+func (r *Router) ResolveTool(prefixedName string) (agentName, toolName string, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	return client, toolName, nil
+	route, err := r.resolve(prefixedName)
+	if err != nil {
+		return "", "", err
+	}
+	return route.agent, route.tool, nil
+}
+
+// resolve looks up an exposed tool name in the registry built by
+// RefreshTools. Names are never re-parsed, since prefixes and tool names
+// may themselves contain the separator. Must be called with r.mu held.
+func (r *Router) resolve(exposedName string) (toolRoute, error) {
+	route, ok := r.tools[exposedName]
+	if !ok {
+		return toolRoute{}, fmt.Errorf("unknown tool: %s", exposedName)
+	}
+	return route, nil
 }
 
-// ToolNameDelimiter is the separator between agent name and tool name in prefixed tool names.
+// ToolNameDelimiter is the default separator between agent name and tool name in prefixed tool names.
 // Format: "agentname__toolname"
 // Uses double underscore to be compatible with Claude Desktop's tool name validation: ^[a-zA-Z0-9_-]{1,64}$
 const ToolNameDelimiter = "__"
 
-// PrefixTool creates a prefixed tool name: "agent__tool"
+// PrefixTool creates a prefixed tool name in the default scheme: "agent__tool"
 func PrefixTool(agentName, toolName string) string {
 	return agentName + ToolNameDelimiter + toolName
 }
 
-// ParsePrefixedTool parses a prefixed tool name into agent and tool names.
+// ParsePrefixedTool parses a prefixed tool name in the default scheme into agent
+// and tool names. The router resolves names through its registry instead,
+// since custom prefixes and separators cannot be parsed reliably.
 func ParsePrefixedTool(prefixed string) (agentName, toolName string, err error) {
 	parts := strings.SplitN(prefixed, ToolNameDelimiter, 2)
 	if len(parts) != 2 {
@@ -1,8 +1,12 @@
 package mcp
 
 import (
+	"encoding/json"
+	"reflect"
 	"sync"
 	"testing"
+
+	"github.com/gridctl/gridctl/pkg/config"
 )
 
 func TestNewRouter(t *testing.T) {
@@ -174,7 +178,7 @@ func TestRouter_RouteToolCall(t *testing.T) {
 	r.AddClient(client)
 	r.RefreshTools()
 
-	gotClient, gotTool, err := r.RouteToolCall("agent1__tool1")
+	gotClient, gotTool, _, err := r.RouteToolCall("agent1__tool1", nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -189,7 +193,7 @@ func TestRouter_RouteToolCall(t *testing.T) {
 func TestRouter_RouteToolCall_UnknownAgent(t *testing.T) {
 	r := NewRouter()
 
-	_, _, err := r.RouteToolCall("unknown__tool1")
+	_, _, _, err := r.RouteToolCall("unknown__tool1", nil)
 	if err == nil {
 		t.Fatal("expected error for unknown agent")
 	}
@@ -198,12 +202,142 @@ func TestRouter_RouteToolCall_UnknownAgent(t *testing.T) {
 func TestRouter_RouteToolCall_InvalidFormat(t *testing.T) {
 	r := NewRouter()
 
-	_, _, err := r.RouteToolCall("invalidformat")
+	_, _, _, err := r.RouteToolCall("invalidformat", nil)
 	if err == nil {
 		t.Fatal("expected error for invalid format")
 	}
 }
 
+func TestRouter_ToolOverrides(t *testing.T) {
+	r := NewRouter()
+	client := NewMockAgentClient("github", []Tool{
+		{
+			Name:        "search_code",
+			Description: "Search code",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"owner":{"type":"string"},"query":{"type":"string"},"per_page":{"type":"integer"}},"required":["owner","query"]}`),
+		},
+		{Name: "list_issues", Description: "List issues"},
+	})
+	r.SetToolOverrides("github", map[string]config.ToolOverride{
+		"search_code": {
+			Name:              "code_search",
+			Aliases:           []string{"find_code"},
+			DescriptionAppend: "Only searches our-org repositories.",
+			HiddenParams:      []string{"per_page"},
+			PinnedParams:      map[string]any{"owner": "our-org"},
+			Schema: map[string]any{
+				"properties": map[string]any{"query": map[string]any{"maxLength": 200}},
+			},
+		},
+	})
+	r.AddClient(client)
+	r.RefreshTools()
+
+	tools := make(map[string]Tool)
+	for _, tool := range r.AggregatedTools() {
+		tools[tool.Name] = tool
+	}
+	if len(tools) != 3 {
+		t.Fatalf("expected 3 exposed tools, got %d: %v", len(tools), tools)
+	}
+	if _, ok := tools["github__search_code"]; ok {
+		t.Error("renamed tool should not be exposed under its original name")
+	}
+	renamed, ok := tools["github__code_search"]
+	if !ok {
+		t.Fatal("expected github__code_search")
+	}
+	if _, ok := tools["github__find_code"]; !ok {
+		t.Error("expected alias github__find_code")
+	}
+	if renamed.Description != "[github] Search code Only searches our-org repositories." {
+		t.Errorf("unexpected description: %q", renamed.Description)
+	}
+
+	var schema struct {
+		Properties map[string]map[string]any `json:"properties"`
+		Required   []string                  `json:"required"`
+	}
+	if err := json.Unmarshal(renamed.InputSchema, &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	if _, ok := schema.Properties["owner"]; ok {
+		t.Error("pinned parameter should be removed from schema")
+	}
+	if _, ok := schema.Properties["per_page"]; ok {
+		t.Error("hidden parameter should be removed from schema")
+	}
+	if schema.Properties["query"]["maxLength"] != float64(200) {
+		t.Errorf("expected query.maxLength 200, got %v", schema.Properties["query"])
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"query"}) {
+		t.Errorf("expected required [query], got %v", schema.Required)
+	}
+
+	for _, name := range []string{"github__code_search", "github__find_code"} {
+		_, tool, args, err := r.RouteToolCall(name, map[string]any{"owner": "someone-else", "query": "q", "per_page": 100})
+		if err != nil {
+			t.Fatalf("RouteToolCall(%s): %v", name, err)
+		}
+		if tool != "search_code" {
+			t.Errorf("expected original tool 'search_code', got %q", tool)
+		}
+		want := map[string]any{"owner": "our-org", "query": "q"}
+		if !reflect.DeepEqual(args, want) {
+			t.Errorf("expected args %v, got %v", want, args)
+		}
+	}
+
+	if _, _, _, err := r.RouteToolCall("github__search_code", nil); err == nil {
+		t.Error("expected error calling renamed tool by its original name")
+	}
+	if _, tool, _, err := r.RouteToolCall("github__list_issues", nil); err != nil || tool != "list_issues" {
+		t.Errorf("expected unaffected tool to route, got %q, %v", tool, err)
+	}
+}
+
+func TestOverrideSchema_OnlyTightens(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","properties":{"q":{"type":"string","maxLength":50,"pattern":"^[a-z]+$"},"n":{"type":"integer","minimum":0,"maximum":10},"mode":{"type":"string","enum":["a","b","c"]}}}`)
+	ov := &config.ToolOverride{Schema: map[string]any{
+		"type":                 "string",
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"q":    map[string]any{"type": "integer", "maxLength": 200, "pattern": "^a"},
+			"n":    map[string]any{"minimum": 5, "maximum": 20},
+			"mode": map[string]any{"enum": []any{"b", "c", "d"}},
+		},
+	}}
+
+	var got map[string]any
+	if err := json.Unmarshal(overrideSchema(schema, ov), &got); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	if got["type"] != "object" {
+		t.Errorf("top-level type should not change, got %v", got["type"])
+	}
+	if got["additionalProperties"] != false {
+		t.Errorf("expected additionalProperties false, got %v", got["additionalProperties"])
+	}
+	props := got["properties"].(map[string]any)
+	q := props["q"].(map[string]any)
+	if q["type"] != "string" {
+		t.Errorf("parameter type should not change, got %v", q["type"])
+	}
+	if q["maxLength"] != float64(50) {
+		t.Errorf("expected the server's stricter maxLength 50, got %v", q["maxLength"])
+	}
+	if q["pattern"] != "^[a-z]+$" || !reflect.DeepEqual(q["allOf"], []any{map[string]any{"pattern": "^a"}}) {
+		t.Errorf("expected both patterns to apply, got %v", q)
+	}
+	n := props["n"].(map[string]any)
+	if n["minimum"] != float64(5) || n["maximum"] != float64(10) {
+		t.Errorf("expected range 5-10, got %v-%v", n["minimum"], n["maximum"])
+	}
+	if mode := props["mode"].(map[string]any); !reflect.DeepEqual(mode["enum"], []any{"b", "c"}) {
+		t.Errorf("expected enum [b c], got %v", mode["enum"])
+	}
+}
+
 func TestPrefixTool(t *testing.T) {
 	tests := []struct {
 		agent    string
@@ -293,10 +427,96 @@ func TestRouter_Concurrent(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			_, _, _ = r.RouteToolCall("agentA__tool")
+			_, _, _, _ = r.RouteToolCall("agentA__tool", nil)
 		}()
 	}
 	wg.Wait()
 
 	// If we get here without deadlock or panic, test passes
 }
+
+func TestToolNamer(t *testing.T) {
+	n := ToolNamer{Separator: "-", MaxLength: 20}
+
+	if got := n.Name("gh", "list_issues"); got != "gh-list_issues" {
+		t.Errorf("expected gh-list_issues, got %s", got)
+	}
+	if got := n.Name("", "list_issues"); got != "list_issues" {
+		t.Errorf("expected unprefixed list_issues, got %s", got)
+	}
+
+	long := n.Name("github", "list_pull_request_review_comments")
+	if len(long) != 20 {
+		t.Errorf("expected truncation to 20 characters, got %d (%s)", len(long), long)
+	}
+	if long != n.Name("github", "list_pull_request_review_comments") {
+		t.Error("truncated names should be stable")
+	}
+	if long == n.Name("github", "list_pull_request_review_threads") {
+		t.Error("names sharing a long prefix should truncate differently")
+	}
+	if got := (ToolNamer{Separator: "__"}).Name("a", "b"); got != "a__b" {
+		t.Errorf("expected a__b with no length limit, got %s", got)
+	}
+}
+
+func TestRouter_ToolNaming(t *testing.T) {
+	r := NewRouter()
+	r.SetToolNamer(ToolNamer{Separator: ".", MaxLength: 64})
+	r.AddClient(NewMockAgentClient("my__server", []Tool{{Name: "read"}}))
+	r.AddClient(NewMockAgentClient("github", []Tool{{Name: "search"}}))
+	r.AddClient(NewMockAgentClient("local", []Tool{{Name: "lint"}}))
+	r.SetToolPrefix("github", "gh")
+	r.SetToolPrefix("local", "")
+	r.RefreshTools()
+
+	tests := map[string]struct{ agent, tool string }{
+		"my__server.read": {"my__server", "read"},
+		"gh.search":       {"github", "search"},
+		"lint":            {"local", "lint"},
+	}
+	names := make(map[string]bool)
+	for _, tool := range r.AggregatedTools() {
+		names[tool.Name] = true
+	}
+	for name, want := range tests {
+		if !names[name] {
+			t.Errorf("expected exposed tool %s, got %v", name, names)
+		}
+		client, tool, _, err := r.RouteToolCall(name, nil)
+		if err != nil {
+			t.Fatalf("RouteToolCall(%s): %v", name, err)
+		}
+		if client.Name() != want.agent || tool != want.tool {
+			t.Errorf("RouteToolCall(%s) = %s/%s, want %s/%s", name, client.Name(), tool, want.agent, want.tool)
+		}
+	}
+
+	if _, _, _, err := r.RouteToolCall("github.search", nil); err == nil {
+		t.Error("expected error for the agent name when a prefix alias is set")
+	}
+}
+
+func TestRouter_Collisions(t *testing.T) {
+	r := NewRouter()
+	r.AddClient(NewMockAgentClient("a", []Tool{{Name: "read"}, {Name: "write"}}))
+	r.AddClient(NewMockAgentClient("b", []Tool{{Name: "read"}}))
+	r.SetToolPrefix("a", "")
+	r.SetToolPrefix("b", "")
+	r.RefreshTools()
+
+	collisions := r.Collisions()
+	if len(collisions) != 1 {
+		t.Fatalf("expected 1 collision, got %v", collisions)
+	}
+	want := ToolNameCollision{Name: "read", Agent: "a", Tool: "read", OtherAgent: "b", OtherTool: "read"}
+	if collisions[0] != want {
+		t.Errorf("expected %+v, got %+v", want, collisions[0])
+	}
+	if got := len(r.AggregatedTools()); got != 2 {
+		t.Errorf("expected colliding tool to be exposed once (2 tools), got %d", got)
+	}
+	if client, _, _, _ := r.RouteToolCall("read", nil); client.Name() != "a" {
+		t.Errorf("expected first agent to keep the name, got %s", client.Name())
+	}
+}
@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BM25 ranking parameters, at their usual values.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field weights: a term in a tool's name says more about it than one in
+// its description.
+const (
+	weightName        = 3
+	weightTitle       = 2
+	weightDescription = 1
+	weightParam       = 1
+)
+
+// stopWords are dropped from indexed text and queries.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "of": true, "to": true, "for": true,
+	"in": true, "on": true, "or": true, "by": true, "with": true, "is": true, "from": true,
+}
+
+// ToolIndex ranks tools against keyword queries with BM25 over their names,
+// titles, descriptions, and input parameter names. It runs locally and
+// needs no embedding service.
+type ToolIndex struct {
+	docs   []indexedTool
+	df     map[string]int // Term -> number of tools containing it
+	avgLen float64
+}
+
+// indexedTool is a tool with its weighted term frequencies.
+type indexedTool struct {
+	tool   Tool
+	terms  map[string]float64
+	length float64
+}
+
+// ToolMatch is a tool ranked by ToolIndex.Search.
+type ToolMatch struct {
+	Tool  Tool
+	Score float64
+}
+
+// NewToolIndex builds an index over tools.
+func NewToolIndex(tools []Tool) *ToolIndex {
+	idx := &ToolIndex{df: make(map[string]int)}
+	var total float64
+	for _, tool := range tools {
+		doc := indexedTool{tool: tool, terms: make(map[string]float64)}
+		add := func(text string, weight float64) {
+			for _, term := range tokenize(text) {
+				doc.terms[term] += weight
+				doc.length += weight
+			}
+		}
+		add(tool.Name, weightName)
+		add(tool.Title, weightTitle)
+		add(tool.Description, weightDescription)
+		for _, param := range schemaParamNames(tool.InputSchema) {
+			add(param, weightParam)
+		}
+
+		for term := range doc.terms {
+			idx.df[term]++
+		}
+		total += doc.length
+		idx.docs = append(idx.docs, doc)
+	}
+	if len(idx.docs) > 0 {
+		idx.avgLen = total / float64(len(idx.docs))
+	}
+	return idx
+}
+
+// Search returns up to limit tools matching query, best first. Tools for
+// which allow returns false are skipped; a nil allow keeps every tool.
+func (idx *ToolIndex) Search(query string, limit int, allow func(name string) bool) []ToolMatch {
+	queryTerms := uniqueTerms(tokenize(query))
+	if len(queryTerms) == 0 || limit <= 0 {
+		return nil
+	}
+
+	n := float64(len(idx.docs))
+	var matches []ToolMatch
+	for i := range idx.docs {
+		doc := &idx.docs[i]
+		if allow != nil && !allow(doc.tool.Name) {
+			continue
+		}
+		var score float64
+		for _, term := range queryTerms {
+			tf := doc.terms[term]
+			if tf == 0 {
+				continue
+			}
+			df := float64(idx.df[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*doc.length/idx.avgLen
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+		if score > 0 {
+			matches = append(matches, ToolMatch{Tool: doc.tool, Score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Tool.Name < matches[j].Tool.Name
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// tokenize splits text into lowercase terms at non-alphanumeric characters
+// and camelCase boundaries, so "getFileContents" and "get_file_contents"
+// index the same way. Stop words are dropped and plurals reduced.
+func tokenize(text string) []string {
+	var terms []string
+	var cur []rune
+	flush := func() {
+		if len(cur) == 0 {
+			return
+		}
+		term := stem(strings.ToLower(string(cur)))
+		cur = cur[:0]
+		if !stopWords[term] {
+			terms = append(terms, term)
+		}
+	}
+
+	var prev rune
+	for _, r := range text {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+		prev = r
+	}
+	flush()
+	return terms
+}
+
+// stem reduces simple plurals, so "issues" matches "issue".
+func stem(term string) string {
+	if len(term) > 3 && strings.HasSuffix(term, "s") && !strings.HasSuffix(term, "ss") {
+		return term[:len(term)-1]
+	}
+	return term
+}
+
+// uniqueTerms returns terms without duplicates, in order.
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
+
+// schemaParamNames returns the top-level property names of an input schema.
+func schemaParamNames(schema json.RawMessage) []string {
+	var s struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if len(schema) == 0 || json.Unmarshal(schema, &s) != nil {
+		return nil
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "get_file_contents", want: []string{"get", "file", "content"}},
+		{text: "getFileContents", want: []string{"get", "file", "content"}},
+		{text: "github__search_issues", want: []string{"github", "search", "issue"}},
+		{text: "Search for the code in a repo", want: []string{"search", "code", "repo"}},
+		{text: "", want: nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			if got := tokenize(tc.text); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("tokenize(%q) = %v, want %v", tc.text, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestToolIndex_Search(t *testing.T) {
+	schema := func(params ...string) json.RawMessage {
+		props := make(map[string]any)
+		for _, p := range params {
+			props[p] = map[string]any{"type": "string"}
+		}
+		b, _ := json.Marshal(map[string]any{"type": "object", "properties": props})
+		return b
+	}
+	idx := NewToolIndex([]Tool{
+		{Name: "github__create_issue", Description: "[github] Create a new issue in a repository", InputSchema: schema("owner", "repo", "title")},
+		{Name: "github__search_code", Description: "[github] Search code across repositories", InputSchema: schema("query")},
+		{Name: "jira__create_ticket", Description: "[jira] Open a ticket for an issue", InputSchema: schema("project", "summary")},
+		{Name: "time__now", Description: "[time] Current time", InputSchema: schema("timezone")},
+	})
+
+	names := func(matches []ToolMatch) []string {
+		var out []string
+		for _, m := range matches {
+			out = append(out, m.Tool.Name)
+		}
+		return out
+	}
+
+	// Name matches outrank description matches
+	got := names(idx.Search("create issue", 10, nil))
+	if len(got) < 2 || got[0] != "github__create_issue" {
+		t.Errorf("expected github__create_issue first, got %v", got)
+	}
+
+	// Schema parameter names are indexed
+	if got := names(idx.Search("timezone", 10, nil)); !reflect.DeepEqual(got, []string{"time__now"}) {
+		t.Errorf("expected time__now for parameter match, got %v", got)
+	}
+
+	// Limit and filtering
+	if got := idx.Search("issue", 1, nil); len(got) != 1 {
+		t.Errorf("expected 1 result with limit 1, got %d", len(got))
+	}
+	allow := func(name string) bool { return name != "github__create_issue" }
+	if got := names(idx.Search("create issue", 10, allow)); len(got) == 0 || got[0] != "jira__create_ticket" {
+		t.Errorf("expected filtered tool to be skipped, got %v", got)
+	}
+
+	if got := idx.Search("kubernetes", 10, nil); len(got) != 0 {
+		t.Errorf("expected no results, got %v", names(got))
+	}
+	if got := idx.Search("the", 10, nil); len(got) != 0 {
+		t.Errorf("expected stop words to match nothing, got %v", names(got))
+	}
+}
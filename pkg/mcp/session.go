@@ -1,8 +1,12 @@
 package mcp
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -14,18 +18,31 @@ type Session struct {
 	Initialized bool
 	CreatedAt   time.Time
 	LastSeen    time.Time
+
+	toolsets    map[string]bool   // Enabled toolset names
+	notify      func(msg Request) // Delivers server messages, if the transport can
+	elicitation bool              // Client accepts elicitation/create requests
 }
 
 // SessionManager manages client sessions.
 type SessionManager struct {
 	mu       sync.RWMutex
 	sessions map[string]*Session
+	pending  map[string]pendingRequest // Request ID -> waiting Request call
+}
+
+// pendingRequest is a request sent to a session's client, waiting for its
+// response.
+type pendingRequest struct {
+	sessionID string // Only this session may answer
+	ch        chan Response
 }
 
 // NewSessionManager creates a new session manager.
 func NewSessionManager() *SessionManager {
 	return &SessionManager{
 		sessions: make(map[string]*Session),
+		pending:  make(map[string]pendingRequest),
 	}
 }
 
@@ -34,18 +51,178 @@ func (m *SessionManager) Create(clientInfo ClientInfo) *Session {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	id := generateSessionID()
+	return m.create(generateSessionID(), clientInfo)
+}
+
+// CreateWithID creates a session with a transport-assigned ID, or updates
+// the client info of an existing session with that ID.
+func (m *SessionManager) CreateWithID(id string, clientInfo ClientInfo) *Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok := m.sessions[id]; ok {
+		s.ClientInfo = clientInfo
+		s.Initialized = true
+		s.LastSeen = time.Now()
+		return s
+	}
+	return m.create(id, clientInfo)
+}
+
+// create adds a new session. Must be called with m.mu held.
+func (m *SessionManager) create(id string, clientInfo ClientInfo) *Session {
 	session := &Session{
 		ID:          id,
 		ClientInfo:  clientInfo,
 		Initialized: true,
 		CreatedAt:   time.Now(),
 		LastSeen:    time.Now(),
+		toolsets:    make(map[string]bool),
 	}
 	m.sessions[id] = session
 	return session
 }
 
+// SetToolset enables or disables a toolset for a session. It returns false
+// if the session does not exist or the toolset was already in that state.
+func (m *SessionManager) SetToolset(id, toolset string, enabled bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok || s.toolsets[toolset] == enabled {
+		return false
+	}
+	if enabled {
+		s.toolsets[toolset] = true
+	} else {
+		delete(s.toolsets, toolset)
+	}
+	return true
+}
+
+// ActiveToolsets returns the toolsets enabled for a session.
+func (m *SessionManager) ActiveToolsets(id string) map[string]bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil
+	}
+	active := make(map[string]bool, len(s.toolsets))
+	for name := range s.toolsets {
+		active[name] = true
+	}
+	return active
+}
+
+// SetNotifier sets how server messages, notifications and requests, reach
+// a session's client. Pass nil when the transport's stream closes.
+func (m *SessionManager) SetNotifier(id string, notify func(msg Request)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[id]; ok {
+		s.notify = notify
+	}
+}
+
+// Notify sends a notification to a session's client. It returns false if
+// the session has no open notification stream.
+func (m *SessionManager) Notify(id, method string) bool {
+	notify := m.notifier(id)
+	if notify == nil {
+		return false
+	}
+	notify(Request{JSONRPC: "2.0", Method: method})
+	return true
+}
+
+// notifier returns how messages reach a session's client, or nil.
+func (m *SessionManager) notifier(id string) func(Request) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if s, ok := m.sessions[id]; ok {
+		return s.notify
+	}
+	return nil
+}
+
+// SetCapabilities records the capabilities a session's client declared
+// in initialize.
+func (m *SessionManager) SetCapabilities(id string, caps Capabilities) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[id]; ok {
+		s.elicitation = caps.Elicitation != nil
+	}
+}
+
+// CanElicit reports whether a session's client accepts elicitation
+// requests and has a stream open to receive them.
+func (m *SessionManager) CanElicit(id string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.sessions[id]
+	return ok && s.elicitation && s.notify != nil
+}
+
+// Request sends a request to a session's client over its stream and waits
+// for the response, which the transport passes to HandleResponse.
+func (m *SessionManager) Request(ctx context.Context, id, method string, params any) (json.RawMessage, error) {
+	notify := m.notifier(id)
+	if notify == nil {
+		return nil, fmt.Errorf("session %s has no open stream", id)
+	}
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling params: %w", err)
+	}
+
+	// Random, so that other clients cannot guess the ID and answer
+	key := strconv.Quote("gridctl-" + generateSessionID())
+	reqID := json.RawMessage(key)
+	ch := make(chan Response, 1)
+	m.mu.Lock()
+	m.pending[key] = pendingRequest{sessionID: id, ch: ch}
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.pending, key)
+		m.mu.Unlock()
+	}()
+
+	notify(Request{JSONRPC: "2.0", ID: &reqID, Method: method, Params: paramsBytes})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case resp := <-ch:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("client error %d: %s", resp.Error.Code, resp.Error.Message)
+		}
+		return resp.Result, nil
+	}
+}
+
+// HandleResponse delivers a response from a session's client to a request
+// sent to that session with Request. It returns false if no request to the
+// session is waiting for it.
+func (m *SessionManager) HandleResponse(sessionID string, resp Response) bool {
+	if resp.ID == nil || sessionID == "" {
+		return false
+	}
+	m.mu.RLock()
+	p, ok := m.pending[string(*resp.ID)]
+	m.mu.RUnlock()
+	if !ok || p.sessionID != sessionID {
+		return false
+	}
+	select {
+	case p.ch <- resp:
+		return true
+	default:
+		return false // Already answered
+	}
+}
+
 // Get retrieves a session by ID.
 func (m *SessionManager) Get(id string) *Session {
 	m.mu.RLock()
@@ -69,6 +246,13 @@ func (m *SessionManager) Delete(id string) {
 	delete(m.sessions, id)
 }
 
+// Count returns the number of sessions.
+func (m *SessionManager) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.sessions)
+}
+
 // List returns all sessions.
 func (m *SessionManager) List() []*Session {
 	m.mu.RLock()
@@ -219,3 +219,61 @@ func TestSessionManager_Concurrent(t *testing.T) {
 		t.Errorf("expected %d sessions, got %d", numGoroutines, len(sessions))
 	}
 }
+
+func TestSessionManager_Toolsets(t *testing.T) {
+	m := NewSessionManager()
+	session := m.CreateWithID("sse-1", ClientInfo{Name: "client"})
+	if session.ID != "sse-1" {
+		t.Fatalf("expected session ID 'sse-1', got '%s'", session.ID)
+	}
+
+	if !m.SetToolset("sse-1", "github", true) {
+		t.Error("enabling a toolset should report a change")
+	}
+	if m.SetToolset("sse-1", "github", true) {
+		t.Error("enabling an enabled toolset should not report a change")
+	}
+	if m.SetToolset("missing", "github", true) {
+		t.Error("unknown session should not report a change")
+	}
+	if active := m.ActiveToolsets("sse-1"); !active["github"] || len(active) != 1 {
+		t.Errorf("expected only 'github' active, got %v", active)
+	}
+
+	if !m.SetToolset("sse-1", "github", false) {
+		t.Error("disabling an enabled toolset should report a change")
+	}
+	if active := m.ActiveToolsets("sse-1"); len(active) != 0 {
+		t.Errorf("expected no active toolsets, got %v", active)
+	}
+
+	// Re-creating with the same ID keeps the session state
+	m.SetToolset("sse-1", "github", true)
+	m.CreateWithID("sse-1", ClientInfo{Name: "renamed"})
+	if active := m.ActiveToolsets("sse-1"); !active["github"] {
+		t.Errorf("expected toolsets to survive re-initialize, got %v", active)
+	}
+}
+
+func TestSessionManager_Notify(t *testing.T) {
+	m := NewSessionManager()
+	session := m.Create(ClientInfo{})
+
+	if m.Notify(session.ID, NotificationToolsListChanged) {
+		t.Error("Notify without a notifier should return false")
+	}
+
+	var got []string
+	m.SetNotifier(session.ID, func(msg Request) { got = append(got, msg.Method) })
+	if !m.Notify(session.ID, NotificationToolsListChanged) {
+		t.Error("Notify with a notifier should return true")
+	}
+	if len(got) != 1 || got[0] != NotificationToolsListChanged {
+		t.Errorf("expected one list_changed notification, got %v", got)
+	}
+
+	m.SetNotifier(session.ID, nil)
+	if m.Notify(session.ID, NotificationToolsListChanged) {
+		t.Error("Notify after clearing the notifier should return false")
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/gridctl/gridctl/pkg/tracing"
 )
 
 // SSEServer handles Server-Sent Events connections for MCP.
@@ -25,6 +28,9 @@ type SSESession struct {
 	Flusher   http.Flusher
 	Done      chan struct{}
 	MessageID atomic.Int64
+
+	writeMu sync.Mutex // Serializes writes to the stream
+	closed  bool       // Set once the stream ends; guarded by writeMu
 }
 
 // NewSSEServer creates a new SSE server.
@@ -62,10 +68,22 @@ func (s *SSEServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.sessions[session.ID] = session
 	s.mu.Unlock()
 
+	// The SSE session ID doubles as the gateway session ID, so per-session
+	// state such as enabled toolsets follows the connection
+	sessions := s.gateway.Sessions()
+	sessions.CreateWithID(session.ID, ClientInfo{})
+	sessions.SetNotifier(session.ID, func(msg Request) {
+		s.sendEvent(session, "message", msg)
+	})
+
 	defer func() {
 		s.mu.Lock()
 		delete(s.sessions, session.ID)
 		s.mu.Unlock()
+		session.writeMu.Lock()
+		session.closed = true
+		session.writeMu.Unlock()
+		sessions.Delete(session.ID)
 		close(session.Done)
 	}()
 
@@ -95,8 +113,10 @@ func (s *SSEServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		case <-ticker.C:
 			// Send keepalive using SSE comment (starts with :)
 			// This doesn't trigger message parsing in MCP clients
+			session.writeMu.Lock()
 			fmt.Fprint(session.Writer, ": keepalive\n\n")
 			session.Flusher.Flush()
+			session.writeMu.Unlock()
 		}
 	}
 }
@@ -132,14 +152,29 @@ func (s *SSEServer) HandleMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse request
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
 	var req Request
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
+	// A response to a request the gateway sent, such as elicitation/create
+	if req.Method == "" && req.ID != nil {
+		var resp Response
+		_ = json.Unmarshal(body, &resp)
+		s.gateway.Sessions().HandleResponse(session.ID, resp)
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
 	// Handle the request
-	resp := s.handleRequest(r.Context(), &req)
+	resp := s.handleRequest(tracing.ExtractHTTP(r.Context(), r.Header), session.ID, &req)
 
 	// Send response via SSE for SSE-only clients
 	s.sendEvent(session, "message", resp)
@@ -151,16 +186,16 @@ func (s *SSEServer) HandleMessage(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleRequest processes an MCP request.
-func (s *SSEServer) handleRequest(ctx context.Context, req *Request) Response {
+func (s *SSEServer) handleRequest(ctx context.Context, sessionID string, req *Request) Response {
 	switch req.Method {
 	case "initialize":
-		return s.handleInitialize(req)
+		return s.handleInitialize(sessionID, req)
 	case "notifications/initialized":
 		return NewSuccessResponse(req.ID, nil)
 	case "tools/list":
-		return s.handleToolsList(req)
+		return s.handleToolsList(sessionID, req)
 	case "tools/call":
-		return s.handleToolsCall(ctx, req)
+		return s.handleToolsCall(ctx, sessionID, req)
 	case "ping":
 		return NewSuccessResponse(req.ID, struct{}{})
 	default:
@@ -168,34 +203,34 @@ func (s *SSEServer) handleRequest(ctx context.Context, req *Request) Response {
 	}
 }
 
-func (s *SSEServer) handleInitialize(req *Request) Response {
+func (s *SSEServer) handleInitialize(sessionID string, req *Request) Response {
 	var params InitializeParams
 	if req.Params != nil {
 		_ = json.Unmarshal(req.Params, &params) // params has defaults, unmarshal errors are non-fatal
 	}
 
-	result, err := s.gateway.HandleInitialize(params)
+	result, _, err := s.gateway.HandleInitializeSession(sessionID, params)
 	if err != nil {
 		return NewErrorResponse(req.ID, InternalError, err.Error())
 	}
 	return NewSuccessResponse(req.ID, result)
 }
 
-func (s *SSEServer) handleToolsList(req *Request) Response {
-	result, err := s.gateway.HandleToolsList()
+func (s *SSEServer) handleToolsList(sessionID string, req *Request) Response {
+	result, err := s.gateway.HandleToolsListForSession(sessionID, "")
 	if err != nil {
 		return NewErrorResponse(req.ID, InternalError, err.Error())
 	}
 	return NewSuccessResponse(req.ID, result)
 }
 
-func (s *SSEServer) handleToolsCall(ctx context.Context, req *Request) Response {
+func (s *SSEServer) handleToolsCall(ctx context.Context, sessionID string, req *Request) Response {
 	var params ToolCallParams
 	if err := json.Unmarshal(req.Params, &params); err != nil {
 		return NewErrorResponse(req.ID, InvalidParams, "Invalid tools/call params")
 	}
 
-	result, err := s.gateway.HandleToolsCall(ctx, params)
+	result, err := s.gateway.HandleToolsCallForSession(ctx, sessionID, "", params)
 	if err != nil {
 		return NewErrorResponse(req.ID, InternalError, err.Error())
 	}
@@ -213,6 +248,12 @@ func (s *SSEServer) sendEvent(session *SSESession, event string, data any) {
 		dataStr = string(b)
 	}
 
+	session.writeMu.Lock()
+	defer session.writeMu.Unlock()
+	if session.closed {
+		return
+	}
+
 	// SSE format: id: <id>\nevent: <name>\ndata: <data>\n\n
 	msgID := session.MessageID.Add(1)
 	fmt.Fprintf(session.Writer, "id: %d\n", msgID)
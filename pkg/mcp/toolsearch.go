@@ -0,0 +1,183 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+// Built-in tools of search mode, before namespacing with config.BuiltinToolPrefix.
+const (
+	searchToolsTool = "search_tools"
+	callToolTool    = "call_tool"
+)
+
+// Result limits for search_tools.
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// SetToolSearch enables search mode, in which clients are only listed the
+// search_tools and call_tool meta-tools and find other tools by searching.
+// This keeps tool lists small for clients with limited context windows.
+func (g *Gateway) SetToolSearch(enabled bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.toolSearch = enabled
+}
+
+// toolSearchEnabled reports whether search mode is on.
+func (g *Gateway) toolSearchEnabled() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.toolSearch
+}
+
+// searchToolsName returns the exposed name of the search_tools tool.
+func (g *Gateway) searchToolsName() string {
+	return g.router.ToolNamer().Name(config.BuiltinToolPrefix, searchToolsTool)
+}
+
+// callToolName returns the exposed name of the call_tool tool.
+func (g *Gateway) callToolName() string {
+	return g.router.ToolNamer().Name(config.BuiltinToolPrefix, callToolTool)
+}
+
+// searchToolDefinitions describes the search mode meta-tools.
+func (g *Gateway) searchToolDefinitions() []Tool {
+	searchSchema, _ := json.Marshal(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query": map[string]any{"type": "string", "description": "Keywords describing the task, e.g. 'create github issue'"},
+			"limit": map[string]any{"type": "integer", "description": "Maximum number of results", "default": defaultSearchLimit, "minimum": 1, "maximum": maxSearchLimit},
+		},
+		"required": []string{"query"},
+	})
+	callSchema, _ := json.Marshal(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name": map[string]any{"type": "string", "description": "Tool name as returned by " + g.searchToolsName()},
+			"args": map[string]any{"type": "object", "description": "Arguments matching the tool's inputSchema"},
+		},
+		"required": []string{"name"},
+	})
+
+	return []Tool{
+		{
+			Name:        g.searchToolsName(),
+			Title:       searchToolsTool,
+			Description: "Search the available tools by keyword. Returns matching tools with their names, descriptions and input schemas, best match first. Call a tool with " + g.callToolName() + ".",
+			InputSchema: searchSchema,
+		},
+		{
+			Name:        g.callToolName(),
+			Title:       callToolTool,
+			Description: "Call a tool found with " + g.searchToolsName() + ".",
+			InputSchema: callSchema,
+		},
+	}
+}
+
+// toolIndex returns the search index over the router's tools, rebuilding
+// it when they have changed. The toolset tool is indexed too, so searches
+// can point to it.
+func (g *Gateway) toolIndex() *ToolIndex {
+	g.searchMu.Lock()
+	defer g.searchMu.Unlock()
+
+	gen := g.router.Generation()
+	if g.searchIndex != nil && g.searchGen == gen {
+		return g.searchIndex
+	}
+	tools := g.router.AggregatedTools()
+	if toolsets := g.getToolsets(); len(toolsets) > 0 {
+		tools = append(tools, g.enableToolsetDefinition(toolsets, nil))
+	}
+	g.searchIndex = NewToolIndex(tools)
+	g.searchGen = gen
+	return g.searchIndex
+}
+
+// resetToolIndex drops the search index so the next search rebuilds it.
+func (g *Gateway) resetToolIndex() {
+	g.searchMu.Lock()
+	defer g.searchMu.Unlock()
+	g.searchIndex = nil
+}
+
+// handleSearchTools runs search_tools. Only tools the session could call
+// are returned: agent access rules and toolsets apply as for tools/list.
+func (g *Gateway) handleSearchTools(sessionID, agentName string, args map[string]any) *ToolCallResult {
+	query, _ := args["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return toolErrorResult("'query' is required")
+	}
+	limit := defaultSearchLimit
+	if v, ok := args["limit"]; ok {
+		n, isNumber := v.(float64)
+		if i, isInt := v.(int); isInt {
+			n, isNumber = float64(i), true
+		}
+		if !isNumber || n < 1 {
+			return toolErrorResult("'limit' must be a positive integer")
+		}
+		limit = min(int(n), maxSearchLimit)
+	}
+
+	available, err := g.sessionTools(sessionID, agentName)
+	if err != nil {
+		return toolErrorResult("Failed to list tools: %v", err)
+	}
+	visible := make(map[string]Tool, len(available))
+	for _, tool := range available {
+		visible[tool.Name] = tool
+	}
+
+	matches := g.toolIndex().Search(query, limit, func(name string) bool {
+		_, ok := visible[name]
+		return ok
+	})
+	if len(matches) == 0 {
+		return &ToolCallResult{Content: []Content{NewTextContent(fmt.Sprintf("No tools matched '%s'. Try other keywords.", query))}}
+	}
+
+	// Return the session's view of each tool, e.g. its current toolset state
+	tools := make([]Tool, len(matches))
+	for i, m := range matches {
+		tools[i] = visible[m.Tool.Name]
+	}
+	data, err := json.Marshal(map[string]any{"tools": tools})
+	if err != nil {
+		return toolErrorResult("Failed to encode results: %v", err)
+	}
+	return &ToolCallResult{Content: []Content{NewTextContent(string(data))}}
+}
+
+// unwrapCallTool turns call_tool arguments into the call they describe.
+func (g *Gateway) unwrapCallTool(args map[string]any) (ToolCallParams, *ToolCallResult) {
+	name, _ := args["name"].(string)
+	if name == "" {
+		return ToolCallParams{}, toolErrorResult("'name' is required")
+	}
+	if name == g.searchToolsName() || name == g.callToolName() {
+		return ToolCallParams{}, toolErrorResult("'%s' cannot be called through %s", name, g.callToolName())
+	}
+
+	params := ToolCallParams{Name: name}
+	if v, ok := args["args"]; ok && v != nil {
+		inner, isObject := v.(map[string]any)
+		if !isObject {
+			return ToolCallParams{}, toolErrorResult("'args' must be an object")
+		}
+		params.Arguments = inner
+	}
+	return params, nil
+}
+
+// toolErrorResult returns a tool error result with a formatted message.
+func toolErrorResult(format string, a ...any) *ToolCallResult {
+	return &ToolCallResult{Content: []Content{NewTextContent(fmt.Sprintf(format, a...))}, IsError: true}
+}
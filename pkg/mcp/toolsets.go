@@ -0,0 +1,282 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+// enableToolsetTool is the built-in tool that switches toolsets, before
+// namespacing with config.BuiltinToolPrefix.
+const enableToolsetTool = "enable_toolset"
+
+// NotificationToolsListChanged tells a client to fetch tools/list again.
+const NotificationToolsListChanged = "notifications/tools/list_changed"
+
+// toolset is a compiled config.Toolset.
+type toolset struct {
+	name        string
+	description string
+	selectors   []toolsetSelector
+}
+
+// toolsetSelector is one server entry of a toolset with its tool patterns.
+type toolsetSelector struct {
+	server string
+	filter *config.ToolFilter // nil allows all tools from the server
+}
+
+// contains reports whether a server's tool belongs to the toolset.
+func (ts *toolset) contains(server, tool string) bool {
+	for _, sel := range ts.selectors {
+		if sel.server == server && sel.filter.Allows(tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetToolsets configures the toolsets that sessions can enable. Tools in
+// any toolset are hidden from a session until it enables one of them.
+func (g *Gateway) SetToolsets(toolsets []config.Toolset) {
+	compiled := make([]toolset, 0, len(toolsets))
+	for _, ts := range toolsets {
+		c := toolset{name: ts.Name, description: ts.Description}
+		for _, selector := range ts.Tools {
+			filter, err := config.ParseToolFilter(selector.Tools)
+			if err != nil {
+				// Patterns are validated at load; fail closed if one slips through
+				g.logger.Error("invalid tool pattern in toolset", "toolset", ts.Name, "server", selector.Server, "error", err)
+				filter, _ = config.ParseToolFilter([]string{"!*"})
+			}
+			c.selectors = append(c.selectors, toolsetSelector{server: selector.Server, filter: filter})
+		}
+		compiled = append(compiled, c)
+	}
+
+	g.mu.Lock()
+	g.toolsets = compiled
+	g.mu.Unlock()
+	g.resetToolIndex()
+}
+
+// getToolsets returns the configured toolsets.
+func (g *Gateway) getToolsets() []toolset {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.toolsets
+}
+
+// toolVisible reports whether a tool is listed for a session with the given
+// active toolsets: tools in no toolset always are, grouped tools only when
+// one of their toolsets is active.
+func toolVisible(toolsets []toolset, active map[string]bool, server, tool string) bool {
+	grouped := false
+	for i := range toolsets {
+		if toolsets[i].contains(server, tool) {
+			if active[toolsets[i].name] {
+				return true
+			}
+			grouped = true
+		}
+	}
+	return !grouped
+}
+
+// enableToolsetName returns the exposed name of the built-in toolset tool.
+func (g *Gateway) enableToolsetName() string {
+	return g.router.ToolNamer().Name(config.BuiltinToolPrefix, enableToolsetTool)
+}
+
+// enableToolsetDefinition describes the built-in toolset tool, listing the
+// available toolsets and which are active for the session.
+func (g *Gateway) enableToolsetDefinition(toolsets []toolset, active map[string]bool) Tool {
+	var b strings.Builder
+	b.WriteString("Enable or disable a group of related tools. Tools in a toolset are hidden until it is enabled. Available toolsets:")
+	names := make([]string, len(toolsets))
+	for i, ts := range toolsets {
+		names[i] = ts.name
+		fmt.Fprintf(&b, "\n- %s", ts.name)
+		if ts.description != "" {
+			fmt.Fprintf(&b, ": %s", ts.description)
+		}
+		if active[ts.name] {
+			b.WriteString(" (enabled)")
+		}
+	}
+
+	schema := InputSchemaObject{
+		Type: "object",
+		Properties: map[string]Property{
+			"toolset": {Type: "string", Description: "Toolset name", Enum: names},
+			"enabled": {Type: "boolean", Description: "true to enable, false to disable", Default: true},
+		},
+		Required: []string{"toolset"},
+	}
+	schemaBytes, _ := json.Marshal(schema)
+	return Tool{
+		Name:        g.enableToolsetName(),
+		Title:       enableToolsetTool,
+		Description: b.String(),
+		InputSchema: schemaBytes,
+	}
+}
+
+// HandleToolsListForSession returns the tools listed for a client session.
+// In search mode these are only the search meta-tools; otherwise see
+// sessionTools.
+func (g *Gateway) HandleToolsListForSession(sessionID, agentName string) (*ToolsListResult, error) {
+	if g.toolSearchEnabled() {
+		return &ToolsListResult{Tools: g.searchToolDefinitions()}, nil
+	}
+	tools, err := g.sessionTools(sessionID, agentName)
+	if err != nil {
+		return nil, err
+	}
+	return &ToolsListResult{Tools: tools}, nil
+}
+
+// sessionTools returns the tools available to a client session. Agent
+// access filtering applies first when agentName is set; with toolsets
+// configured, grouped tools are then limited to the session's active
+// toolsets and the built-in toolset tool is added.
+func (g *Gateway) sessionTools(sessionID, agentName string) ([]Tool, error) {
+	var result *ToolsListResult
+	var err error
+	if agentName != "" {
+		result, err = g.HandleToolsListForAgent(agentName)
+	} else {
+		result, err = g.HandleToolsList()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	toolsets := g.getToolsets()
+	if len(toolsets) == 0 {
+		return result.Tools, nil
+	}
+
+	active := g.sessions.ActiveToolsets(sessionID)
+	tools := []Tool{g.enableToolsetDefinition(toolsets, active)}
+	for _, tool := range result.Tools {
+		server, name, err := g.router.ResolveTool(tool.Name)
+		if err != nil || !toolVisible(toolsets, active, server, name) {
+			continue
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// HandleToolsCallForSession routes a tool call for a client session. It
+// handles the built-in tools and rejects tools whose toolsets are not
+// enabled, then applies agent access validation when agentName is set.
+func (g *Gateway) HandleToolsCallForSession(ctx context.Context, sessionID, agentName string, params ToolCallParams) (*ToolCallResult, error) {
+	start := time.Now()
+	ctx = withCallInfo(ctx, sessionID, agentName)
+
+	if g.toolSearchEnabled() {
+		switch params.Name {
+		case g.searchToolsName():
+			result := g.handleSearchTools(sessionID, agentName, params.Arguments)
+			g.recordCall(ctx, params.Name, config.BuiltinToolPrefix, searchToolsTool, params.Arguments, start, result)
+			return result, nil
+		case g.callToolName():
+			inner, errResult := g.unwrapCallTool(params.Arguments)
+			if errResult != nil {
+				g.recordCall(ctx, params.Name, config.BuiltinToolPrefix, callToolTool, params.Arguments, start, errResult)
+				return errResult, nil
+			}
+			params = inner
+		}
+	}
+
+	if toolsets := g.getToolsets(); len(toolsets) > 0 {
+		if params.Name == g.enableToolsetName() {
+			result := g.handleEnableToolset(sessionID, agentName, toolsets, params.Arguments)
+			g.recordCall(ctx, params.Name, config.BuiltinToolPrefix, enableToolsetTool, params.Arguments, start, result)
+			return result, nil
+		}
+
+		if server, name, err := g.router.ResolveTool(params.Name); err == nil {
+			active := g.sessions.ActiveToolsets(sessionID)
+			if !toolVisible(toolsets, active, server, name) {
+				var groups []string
+				for i := range toolsets {
+					if toolsets[i].contains(server, name) {
+						groups = append(groups, toolsets[i].name)
+					}
+				}
+				result := &ToolCallResult{
+					Content: []Content{NewTextContent(fmt.Sprintf("Tool '%s' is not enabled. Enable one of these toolsets with %s first: %s",
+						params.Name, g.enableToolsetName(), strings.Join(groups, ", ")))},
+					IsError: true,
+				}
+				g.recordCall(ctx, params.Name, server, name, params.Arguments, start, result)
+				return result, nil
+			}
+		}
+	}
+
+	if agentName != "" {
+		return g.HandleToolsCallForAgent(ctx, agentName, params)
+	}
+	return g.HandleToolsCall(ctx, params)
+}
+
+// handleEnableToolset switches a toolset for a session and notifies the
+// client that its tool list changed.
+func (g *Gateway) handleEnableToolset(sessionID, agentName string, toolsets []toolset, args map[string]any) *ToolCallResult {
+	name, _ := args["toolset"].(string)
+	var ts *toolset
+	names := make([]string, len(toolsets))
+	for i := range toolsets {
+		names[i] = toolsets[i].name
+		if toolsets[i].name == name {
+			ts = &toolsets[i]
+		}
+	}
+	if ts == nil {
+		return toolErrorResult("Unknown toolset '%s'. Available toolsets: %s", name, strings.Join(names, ", "))
+	}
+
+	enabled := true
+	if v, ok := args["enabled"]; ok {
+		b, isBool := v.(bool)
+		if !isBool {
+			return toolErrorResult("'enabled' must be a boolean")
+		}
+		enabled = b
+	}
+
+	if g.sessions.Get(sessionID) == nil {
+		return toolErrorResult("Toolsets are tracked per session; initialize a session before enabling toolsets")
+	}
+	if g.sessions.SetToolset(sessionID, name, enabled) {
+		g.sessions.Notify(sessionID, NotificationToolsListChanged)
+		g.logger.Info("toolset switched", "session", sessionID, "toolset", name, "enabled", enabled)
+	}
+
+	if !enabled {
+		return &ToolCallResult{Content: []Content{NewTextContent(fmt.Sprintf("Disabled toolset '%s'.", name))}}
+	}
+
+	// List the tools now available, for clients that ignore list_changed
+	var available []string
+	if tools, err := g.sessionTools(sessionID, agentName); err == nil {
+		for _, tool := range tools {
+			if server, original, err := g.router.ResolveTool(tool.Name); err == nil && ts.contains(server, original) {
+				available = append(available, tool.Name)
+			}
+		}
+	}
+	sort.Strings(available)
+	return &ToolCallResult{Content: []Content{NewTextContent(fmt.Sprintf("Enabled toolset '%s'. Available tools: %s",
+		name, strings.Join(available, ", ")))}}
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gridctl/gridctl/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span attribute keys for MCP requests.
+const (
+	attrMethod   = attribute.Key("mcp.method.name")
+	attrTool     = attribute.Key("mcp.tool.name")
+	attrServer   = attribute.Key("gridctl.mcp.server")
+	attrCacheHit = attribute.Key("gridctl.cache.hit")
+)
+
+// startClientSpan starts a span for a request to a downstream MCP server.
+func startClientSpan(ctx context.Context, server, method string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, method, trace.SpanKindClient, attrMethod.String(method), attrServer.String(server))
+}
+
+// endClientSpan ends a downstream request span, marking JSON-RPC errors.
+func endClientSpan(span trace.Span, resp *Response, err error) {
+	if err == nil && resp != nil && resp.Error != nil {
+		span.SetStatus(codes.Error, resp.Error.Message)
+	}
+	tracing.End(span, err)
+}
+
+// withTraceMeta returns JSON-RPC params with the trace context of ctx added
+// to their _meta object, so stdio servers and servers behind proxies that
+// drop headers can continue the trace. Params that are not a JSON object
+// are returned unchanged.
+func withTraceMeta(ctx context.Context, params json.RawMessage) json.RawMessage {
+	meta := tracing.InjectMeta(ctx, nil)
+	if meta == nil {
+		return params
+	}
+
+	fields := map[string]json.RawMessage{}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &fields); err != nil || fields == nil {
+			return params
+		}
+	}
+	if existing, ok := fields["_meta"]; ok {
+		var m map[string]any
+		if json.Unmarshal(existing, &m) == nil && m != nil {
+			for k, v := range meta {
+				m[k] = v
+			}
+			meta = m
+		}
+	}
+	metaBytes, err := json.Marshal(meta)
+	if err != nil {
+		return params
+	}
+	fields["_meta"] = metaBytes
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return params
+	}
+	return out
+}
@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gridctl/gridctl/pkg/tracing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{
+		SampleRatio: 1,
+		Exporters:   []sdktrace.SpanExporter{exporter},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+	return exporter
+}
+
+func flushSpans(t *testing.T, exporter *tracetest.InMemoryExporter) tracetest.SpanStubs {
+	t.Helper()
+	if err := otel.GetTracerProvider().(*sdktrace.TracerProvider).ForceFlush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return exporter.GetSpans()
+}
+
+func TestWithTraceMeta(t *testing.T) {
+	setupTracing(t)
+	ctx, span := tracing.Start(context.Background(), "test", trace.SpanKindInternal)
+	defer span.End()
+
+	tests := []struct {
+		name   string
+		params string
+		check  func(t *testing.T, out map[string]any)
+	}{
+		{
+			name:   "adds meta",
+			params: `{"name":"search"}`,
+			check: func(t *testing.T, out map[string]any) {
+				meta, _ := out["_meta"].(map[string]any)
+				if out["name"] != "search" || meta["traceparent"] == nil {
+					t.Errorf("unexpected params: %v", out)
+				}
+			},
+		},
+		{
+			name:   "keeps existing meta",
+			params: `{"_meta":{"progressToken":"p1"}}`,
+			check: func(t *testing.T, out map[string]any) {
+				meta, _ := out["_meta"].(map[string]any)
+				if meta["progressToken"] != "p1" || meta["traceparent"] == nil {
+					t.Errorf("unexpected meta: %v", meta)
+				}
+			},
+		},
+		{
+			name:   "empty params",
+			params: ``,
+			check: func(t *testing.T, out map[string]any) {
+				if _, ok := out["_meta"]; !ok {
+					t.Errorf("expected _meta, got %v", out)
+				}
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out map[string]any
+			if err := json.Unmarshal(withTraceMeta(ctx, json.RawMessage(tc.params)), &out); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tc.check(t, out)
+		})
+	}
+
+	// Non-object params and calls without a span are left alone
+	if got := string(withTraceMeta(ctx, json.RawMessage(`[1,2]`))); got != `[1,2]` {
+		t.Errorf("expected array params unchanged, got %s", got)
+	}
+	if got := string(withTraceMeta(context.Background(), json.RawMessage(`{"a":1}`))); got != `{"a":1}` {
+		t.Errorf("expected params unchanged without a span, got %s", got)
+	}
+}
+
+func TestGateway_TracePropagation(t *testing.T) {
+	exporter := setupTracing(t)
+
+	// Downstream MCP server recording the trace context it receives
+	var mu sync.Mutex
+	var headerParent, metaParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var params ToolCallParams
+		_ = json.Unmarshal(req.Params, &params)
+
+		mu.Lock()
+		headerParent = r.Header.Get("traceparent")
+		metaParent, _ = params.Meta["traceparent"].(string)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(NewSuccessResponse(req.ID, ToolCallResult{Content: []Content{NewTextContent("ok")}}))
+	}))
+	defer server.Close()
+
+	g := NewGateway()
+	client := NewClient("github", server.URL)
+	g.Router().AddClient(client)
+	client.tools = []Tool{{Name: "search"}}
+	g.Router().RefreshTools()
+
+	// The caller's trace context arrives in _meta
+	callerCtx, caller := tracing.Start(context.Background(), "agent", trace.SpanKindClient)
+	meta := tracing.InjectMeta(callerCtx, nil)
+	caller.End()
+
+	result, err := g.HandleToolsCall(context.Background(), ToolCallParams{Name: "github__search", Meta: meta})
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected result: %v %v", result, err)
+	}
+
+	spans := flushSpans(t, exporter)
+	byName := map[string]tracetest.SpanStub{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	gatewaySpan, ok := byName["tools/call github__search"]
+	if !ok {
+		t.Fatalf("expected gateway span, got %v", spans)
+	}
+	downstream, ok := byName["tools/call"]
+	if !ok {
+		t.Fatalf("expected downstream span, got %v", spans)
+	}
+
+	traceID := caller.SpanContext().TraceID()
+	if gatewaySpan.SpanContext.TraceID() != traceID || gatewaySpan.Parent.SpanID() != caller.SpanContext().SpanID() {
+		t.Error("expected gateway span to continue the caller's trace")
+	}
+	if downstream.Parent.SpanID() != gatewaySpan.SpanContext.SpanID() || downstream.SpanKind != trace.SpanKindClient {
+		t.Error("expected downstream client span under the gateway span")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for name, tp := range map[string]string{"header": headerParent, "_meta": metaParent} {
+		if !strings.Contains(tp, traceID.String()) || !strings.Contains(tp, downstream.SpanContext.SpanID().String()) {
+			t.Errorf("expected %s traceparent from the downstream span, got %q", name, tp)
+		}
+	}
+}
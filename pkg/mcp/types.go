@@ -75,11 +75,16 @@ type ClientInfo struct {
 
 // Capabilities describes what the server/client can do.
 type Capabilities struct {
-	Tools     *ToolsCapability     `json:"tools,omitempty"`
-	Resources *ResourcesCapability `json:"resources,omitempty"`
-	Prompts   *PromptsCapability   `json:"prompts,omitempty"`
+	Tools       *ToolsCapability       `json:"tools,omitempty"`
+	Resources   *ResourcesCapability   `json:"resources,omitempty"`
+	Prompts     *PromptsCapability     `json:"prompts,omitempty"`
+	Elicitation *ElicitationCapability `json:"elicitation,omitempty"` // Client capability
 }
 
+// ElicitationCapability indicates a client can answer elicitation/create
+// requests by asking its user for input.
+type ElicitationCapability struct{}
+
 // ToolsCapability indicates tools support.
 type ToolsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
@@ -112,10 +117,21 @@ type InitializeResult struct {
 
 // Tool represents an MCP tool definition.
 type Tool struct {
-	Name        string          `json:"name"`
-	Title       string          `json:"title,omitempty"`
-	Description string          `json:"description,omitempty"`
-	InputSchema json.RawMessage `json:"inputSchema"`
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description,omitempty"`
+	InputSchema json.RawMessage  `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are hints from a server about a tool's behavior. They are
+// not guaranteed to be accurate.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`    // Does not modify its environment (default: false)
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"` // May perform destructive updates (default: true)
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`  // Repeated calls with the same arguments have no further effect (default: false)
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`   // Interacts with external entities (default: true)
 }
 
 // InputSchemaObject is a helper for building simple input schemas.
@@ -146,6 +162,7 @@ type ToolsListResult struct {
 type ToolCallParams struct {
 	Name      string         `json:"name"`
 	Arguments map[string]any `json:"arguments,omitempty"`
+	Meta      map[string]any `json:"_meta,omitempty"` // Request metadata, e.g. W3C trace context
 }
 
 // ToolCallResult is the response to tools/call.
@@ -0,0 +1,46 @@
+// Package metrics holds the pieces gridctl adds to the Prometheus client
+// library for exposing the gateway's metrics to Prometheus or Grafana.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves the metrics in a registry for scraping.
+func Handler(r *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(r, promhttp.HandlerOpts{})
+}
+
+// Sample is one value of a GaugeFunc, with values for its labels.
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// GaugeFunc is a gauge family whose values are computed when scraped, for
+// state that is already tracked elsewhere such as open sessions. Unlike
+// prometheus.NewGaugeFunc, it can report one value per set of labels.
+type GaugeFunc struct {
+	desc *prometheus.Desc
+	fn   func() []Sample
+}
+
+// NewGaugeFunc creates a gauge family read from fn at each scrape.
+func NewGaugeFunc(name, help string, labels []string, fn func() []Sample) *GaugeFunc {
+	return &GaugeFunc{desc: prometheus.NewDesc(name, help, labels, nil), fn: fn}
+}
+
+// Describe implements prometheus.Collector.
+func (g *GaugeFunc) Describe(ch chan<- *prometheus.Desc) {
+	ch <- g.desc
+}
+
+// Collect implements prometheus.Collector.
+func (g *GaugeFunc) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range g.fn() {
+		ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, s.Value, s.Labels...)
+	}
+}
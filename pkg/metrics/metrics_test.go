@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGaugeFunc(t *testing.T) {
+	sessions := NewGaugeFunc("sessions", "Open sessions.", []string{"transport"}, func() []Sample {
+		return []Sample{{Labels: []string{"sse"}, Value: 2}, {Labels: []string{"http"}, Value: 3}}
+	})
+
+	want := `# HELP sessions Open sessions.
+# TYPE sessions gauge
+sessions{transport="http"} 3
+sessions{transport="sse"} 2
+`
+	if err := testutil.CollectAndCompare(sessions, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGaugeFunc_Unlabelled(t *testing.T) {
+	up := NewGaugeFunc("up", "Up.", nil, func() []Sample {
+		return []Sample{{Value: 1}}
+	})
+	if got := testutil.ToFloat64(up); got != 1 {
+		t.Errorf("up = %v, want 1", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r := prometheus.NewRegistry()
+	r.MustRegister(NewGaugeFunc("up", "Up.", nil, func() []Sample {
+		return []Sample{{Value: 1}}
+	}))
+
+	rec := httptest.NewRecorder()
+	Handler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "# TYPE up gauge\nup 1\n") {
+		t.Errorf("unexpected body:\n%s", rec.Body.String())
+	}
+}
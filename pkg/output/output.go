@@ -72,6 +72,21 @@ func (p *Printer) Debug(msg string, keyvals ...any) {
 	p.logger.Debug(msg, keyvals...)
 }
 
+// Progress logs a workload startup step (e.g., pulling, starting, ready).
+// A non-nil err is logged at error level.
+func (p *Printer) Progress(name, phase string, elapsed time.Duration, err error) {
+	keyvals := []any{"name", name}
+	if elapsed > 0 {
+		keyvals = append(keyvals, "took", elapsed.Round(100*time.Millisecond))
+	}
+	if err != nil {
+		keyvals = append(keyvals, "error", err)
+		p.logger.Error(phase, keyvals...)
+		return
+	}
+	p.logger.Info(phase, keyvals...)
+}
+
 // SetDebug enables debug-level logging.
 func (p *Printer) SetDebug(enabled bool) {
 	if enabled {
@@ -2,8 +2,10 @@ package output
 
 import (
 	"bytes"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNew_CreatesWithStdout(t *testing.T) {
@@ -133,3 +135,24 @@ func TestPrinter_Section(t *testing.T) {
 		t.Errorf("Section() should contain title, got %q", got)
 	}
 }
+
+func TestPrinter_Progress(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewWithWriter(&buf)
+
+	p.Progress("postgres", "ready", 1500*time.Millisecond, nil)
+	got := buf.String()
+	if !strings.Contains(got, "INFO") || !strings.Contains(got, "ready") || !strings.Contains(got, "postgres") {
+		t.Errorf("Progress() output should contain level, phase and name, got %q", got)
+	}
+	if !strings.Contains(got, "1.5s") {
+		t.Errorf("Progress() output should contain elapsed time, got %q", got)
+	}
+
+	buf.Reset()
+	p.Progress("postgres", "failed", 0, errors.New("pull denied"))
+	got = buf.String()
+	if !strings.Contains(got, "ERRO") || !strings.Contains(got, "pull denied") {
+		t.Errorf("Progress() with error should log at error level, got %q", got)
+	}
+}
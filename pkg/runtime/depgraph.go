@@ -8,6 +8,7 @@ import (
 // DependencyGraph represents a directed acyclic graph of dependencies.
 // It provides topological sorting to determine correct startup order.
 type DependencyGraph struct {
+	order    []string // nodes in insertion order, for deterministic layering
 	nodes    map[string]bool
 	edges    map[string][]string // from -> []to (node depends on these)
 	reversed map[string][]string // to -> []from (nodes that depend on this)
@@ -24,14 +25,17 @@ func NewDependencyGraph() *DependencyGraph {
 
 // AddNode adds a node to the graph.
 func (g *DependencyGraph) AddNode(name string) {
+	if !g.nodes[name] {
+		g.order = append(g.order, name)
+	}
 	g.nodes[name] = true
 }
 
 // AddEdge adds a dependency edge: from depends on to.
 // This means 'to' must be started before 'from'.
 func (g *DependencyGraph) AddEdge(from, to string) {
-	g.nodes[from] = true
-	g.nodes[to] = true
+	g.AddNode(from)
+	g.AddNode(to)
 	g.edges[from] = append(g.edges[from], to)
 	g.reversed[to] = append(g.reversed[to], from)
 }
@@ -88,6 +92,60 @@ func (g *DependencyGraph) Sort() ([]string, error) {
 	return sorted, nil
 }
 
+// Layers groups nodes into startup levels. Every node in a layer depends only
+// on nodes in earlier layers, so nodes within a layer can start concurrently.
+// Nodes within a layer keep the order in which they were added to the graph.
+func (g *DependencyGraph) Layers() ([][]string, error) {
+	inDegree := make(map[string]int, len(g.nodes))
+	for node := range g.nodes {
+		inDegree[node] = len(g.edges[node])
+	}
+
+	var current []string
+	for _, node := range g.order {
+		if inDegree[node] == 0 {
+			current = append(current, node)
+		}
+	}
+
+	var layers [][]string
+	processed := 0
+	for len(current) > 0 {
+		layers = append(layers, current)
+		processed += len(current)
+
+		ready := make(map[string]bool)
+		for _, node := range current {
+			for _, dependent := range g.reversed[node] {
+				inDegree[dependent]--
+				if inDegree[dependent] == 0 {
+					ready[dependent] = true
+				}
+			}
+		}
+
+		var next []string
+		for _, node := range g.order {
+			if ready[node] {
+				next = append(next, node)
+			}
+		}
+		current = next
+	}
+
+	if processed != len(g.nodes) {
+		var remaining []string
+		for _, node := range g.order {
+			if inDegree[node] > 0 {
+				remaining = append(remaining, node)
+			}
+		}
+		return nil, fmt.Errorf("circular dependency detected involving: %s", strings.Join(remaining, ", "))
+	}
+
+	return layers, nil
+}
+
 // GetDependencies returns the direct dependencies of a node.
 func (g *DependencyGraph) GetDependencies(name string) []string {
 	return g.edges[name]
@@ -3,6 +3,7 @@ package docker
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/gridctl/gridctl/pkg/dockerclient"
 
@@ -17,14 +18,28 @@ import (
 type ContainerConfig struct {
 	Name        string
 	Image       string
+	Entrypoint  []string // Override image entrypoint
 	Command     []string // Override container command
 	Env         map[string]string
 	Port        int // Container port
 	HostPort    int // Host port to publish (0 = auto-assign)
 	NetworkName string
+	Networks    []string // Additional networks, connected after create
 	Labels      map[string]string
 	Transport   string   // "http" or "stdio"
-	Volumes     []string // Volume mounts in "host:container" or "host:container:mode" format
+	Volumes     []string // Volume mounts in "source:container" or "source:container:mode" format
+	Tmpfs       []string // tmpfs mounts in "container" or "container:options" format
+	WorkDir     string   // Working directory inside the container
+
+	// Resource limits and security
+	CPUs            float64  // CPU limit in cores (0 = unlimited)
+	MemoryBytes     int64    // Memory limit in bytes (0 = unlimited)
+	PidsLimit       int64    // Maximum number of processes (0 = unlimited)
+	ReadOnly        bool     // Read-only root filesystem
+	CapAdd          []string // Linux capabilities to add
+	CapDrop         []string // Linux capabilities to drop
+	NoNewPrivileges bool     // Adds "no-new-privileges" to the security options
+	User            string   // User to run as
 }
 
 // CreateContainer creates a new container with the given configuration.
@@ -44,10 +59,13 @@ func CreateContainer(ctx context.Context, cli dockerclient.DockerClient, cfg Con
 
 	containerConfig := &container.Config{
 		Image:        cfg.Image,
+		Entrypoint:   cfg.Entrypoint,
 		Cmd:          cfg.Command,
 		Env:          envSlice,
 		Labels:       cfg.Labels,
 		ExposedPorts: exposedPorts,
+		WorkingDir:   cfg.WorkDir,
+		User:         cfg.User,
 		OpenStdin:    cfg.Transport == "stdio",
 		AttachStdin:  cfg.Transport == "stdio",
 		AttachStdout: cfg.Transport == "stdio",
@@ -71,7 +89,22 @@ func CreateContainer(ctx context.Context, cli dockerclient.DockerClient, cfg Con
 		NetworkMode:  container.NetworkMode(cfg.NetworkName),
 		PortBindings: portBindings,
 		Binds:        cfg.Volumes,
+		Tmpfs:        tmpfsMounts(cfg.Tmpfs),
 		ExtraHosts:   []string{"host.docker.internal:host-gateway"},
+		Resources: container.Resources{
+			NanoCPUs: int64(cfg.CPUs * 1e9),
+			Memory:   cfg.MemoryBytes,
+		},
+		ReadonlyRootfs: cfg.ReadOnly,
+		CapAdd:         cfg.CapAdd,
+		CapDrop:        cfg.CapDrop,
+	}
+	if cfg.PidsLimit > 0 {
+		pids := cfg.PidsLimit
+		hostConfig.Resources.PidsLimit = &pids
+	}
+	if cfg.NoNewPrivileges {
+		hostConfig.SecurityOpt = []string{"no-new-privileges:true"}
 	}
 
 	networkConfig := &network.NetworkingConfig{
@@ -87,9 +120,31 @@ func CreateContainer(ctx context.Context, cli dockerclient.DockerClient, cfg Con
 		return "", fmt.Errorf("creating container %s: %w", cfg.Name, err)
 	}
 
+	// Older daemons only accept one network at create time
+	for _, name := range cfg.Networks {
+		if err := cli.NetworkConnect(ctx, name, resp.ID, &network.EndpointSettings{
+			Aliases: []string{cfg.Name},
+		}); err != nil {
+			return "", fmt.Errorf("connecting container %s to network %s: %w", cfg.Name, name, err)
+		}
+	}
+
 	return resp.ID, nil
 }
 
+// tmpfsMounts converts "path[:options]" specs into Docker's tmpfs map.
+func tmpfsMounts(specs []string) map[string]string {
+	if len(specs) == 0 {
+		return nil
+	}
+	mounts := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		path, opts, _ := strings.Cut(spec, ":")
+		mounts[path] = opts
+	}
+	return mounts
+}
+
 // StartContainer starts a container by ID.
 func StartContainer(ctx context.Context, cli dockerclient.DockerClient, containerID string) error {
 	if err := cli.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCreateContainer_Mounts(t *testing.T) {
+	cli := &MockDockerClient{}
+
+	_, err := CreateContainer(context.Background(), cli, ContainerConfig{
+		Name:        "gridctl-test-filesystem",
+		Image:       "mcp/filesystem:latest",
+		NetworkName: "test-net",
+		Volumes:     []string{"/repo:/workspace:ro"},
+		Tmpfs:       []string{"/tmp", "/run:size=64m"},
+		WorkDir:     "/workspace",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hc := cli.LastHostConfig
+	if len(hc.Binds) != 1 || hc.Binds[0] != "/repo:/workspace:ro" {
+		t.Errorf("expected bind /repo:/workspace:ro, got %v", hc.Binds)
+	}
+	if opts, ok := hc.Tmpfs["/tmp"]; !ok || opts != "" {
+		t.Errorf("expected /tmp tmpfs without options, got %v", hc.Tmpfs)
+	}
+	if hc.Tmpfs["/run"] != "size=64m" {
+		t.Errorf("expected /run tmpfs with size=64m, got %v", hc.Tmpfs)
+	}
+}
+
+func TestEnsureVolume(t *testing.T) {
+	cli := &MockDockerClient{}
+	ctx := context.Background()
+
+	if err := EnsureVolume(ctx, cli, "gridctl-test-cache", "local", nil, "test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cli.CreatedVolumes) != 1 {
+		t.Fatalf("expected 1 volume created, got %d", len(cli.CreatedVolumes))
+	}
+	created := cli.CreatedVolumes[0]
+	if created.Labels[LabelManaged] != "true" || created.Labels[LabelStack] != "test" {
+		t.Errorf("expected managed stack labels, got %v", created.Labels)
+	}
+}
+
+func TestCreateContainer_Security(t *testing.T) {
+	cli := &MockDockerClient{}
+
+	_, err := CreateContainer(context.Background(), cli, ContainerConfig{
+		Name:            "gridctl-test-server1",
+		Image:           "alpine:latest",
+		NetworkName:     "test-net",
+		CPUs:            1.5,
+		MemoryBytes:     512 * 1024 * 1024,
+		PidsLimit:       128,
+		ReadOnly:        true,
+		CapDrop:         []string{"ALL"},
+		NoNewPrivileges: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hc := cli.LastHostConfig
+	if hc.NanoCPUs != 1_500_000_000 {
+		t.Errorf("expected 1.5 CPUs as NanoCPUs, got %d", hc.NanoCPUs)
+	}
+	if hc.Memory != 512*1024*1024 {
+		t.Errorf("expected memory limit, got %d", hc.Memory)
+	}
+	if hc.PidsLimit == nil || *hc.PidsLimit != 128 {
+		t.Errorf("expected pids limit 128, got %v", hc.PidsLimit)
+	}
+	if !hc.ReadonlyRootfs {
+		t.Error("expected read-only root filesystem")
+	}
+	if len(hc.CapDrop) != 1 || hc.CapDrop[0] != "ALL" {
+		t.Errorf("expected cap_drop [ALL], got %v", hc.CapDrop)
+	}
+	if len(hc.SecurityOpt) != 1 || hc.SecurityOpt[0] != "no-new-privileges:true" {
+		t.Errorf("expected no-new-privileges security opt, got %v", hc.SecurityOpt)
+	}
+}
+
+func TestCreateContainer_ExtraNetworks(t *testing.T) {
+	cli := &MockDockerClient{}
+
+	id, err := CreateContainer(context.Background(), cli, ContainerConfig{
+		Name:        "gridctl-test-github-egress",
+		Image:       "ubuntu/squid:5.2-22.04_beta",
+		NetworkName: "test-net",
+		Networks:    []string{"gridctl-test-github-egress"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "gridctl-test-github-egress/" + id
+	if len(cli.ConnectedNetworks) != 1 || cli.ConnectedNetworks[0] != want {
+		t.Errorf("expected connection %q, got %v", want, cli.ConnectedNetworks)
+	}
+}
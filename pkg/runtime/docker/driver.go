@@ -56,14 +56,27 @@ func (d *DockerRuntime) Start(ctx context.Context, cfg runtime.WorkloadConfig) (
 	dockerCfg := ContainerConfig{
 		Name:        containerName,
 		Image:       cfg.Image,
+		Entrypoint:  cfg.Entrypoint,
 		Command:     cfg.Command,
 		Env:         cfg.Env,
 		Port:        cfg.ExposedPort,
 		HostPort:    cfg.HostPort,
 		NetworkName: cfg.NetworkName,
+		Networks:    cfg.Networks,
 		Labels:      cfg.Labels,
 		Transport:   cfg.Transport,
 		Volumes:     cfg.Volumes,
+		Tmpfs:       cfg.Tmpfs,
+		WorkDir:     cfg.WorkDir,
+
+		CPUs:            cfg.CPUs,
+		MemoryBytes:     cfg.MemoryBytes,
+		PidsLimit:       cfg.PidsLimit,
+		ReadOnly:        cfg.ReadOnly,
+		CapAdd:          cfg.CapAdd,
+		CapDrop:         cfg.CapDrop,
+		NoNewPrivileges: cfg.NoNewPrivileges,
+		User:            cfg.User,
 	}
 
 	containerID, err = CreateContainer(ctx, d.cli, dockerCfg)
@@ -139,6 +152,12 @@ func (d *DockerRuntime) Status(ctx context.Context, id runtime.WorkloadID) (*run
 		endpoint = fmt.Sprintf("localhost:%d", hostPort)
 	}
 
+	// Healthcheck status (empty if the image defines no healthcheck)
+	health := ""
+	if info.State.Health != nil {
+		health = info.State.Health.Status
+	}
+
 	return &runtime.WorkloadStatus{
 		ID:       id,
 		Name:     name,
@@ -146,6 +165,8 @@ func (d *DockerRuntime) Status(ctx context.Context, id runtime.WorkloadID) (*run
 		Type:     workloadType,
 		State:    state,
 		Message:  info.State.Status,
+		Health:   health,
+		ExitCode: info.State.ExitCode,
 		Endpoint: endpoint,
 		HostPort: hostPort,
 		Image:    info.Config.Image,
@@ -230,7 +251,7 @@ func (d *DockerRuntime) GetHostPort(ctx context.Context, id runtime.WorkloadID,
 
 // EnsureNetwork creates the network if it doesn't exist.
 func (d *DockerRuntime) EnsureNetwork(ctx context.Context, name string, opts runtime.NetworkOptions) error {
-	_, err := EnsureNetwork(ctx, d.cli, name, opts.Driver, opts.Stack)
+	_, err := EnsureNetwork(ctx, d.cli, name, opts.Driver, opts.Stack, opts.Internal)
 	return err
 }
 
@@ -244,6 +265,21 @@ func (d *DockerRuntime) RemoveNetwork(ctx context.Context, name string) error {
 	return RemoveNetwork(ctx, d.cli, name)
 }
 
+// EnsureVolume creates the named volume if it doesn't exist.
+func (d *DockerRuntime) EnsureVolume(ctx context.Context, name string, opts runtime.VolumeOptions) error {
+	return EnsureVolume(ctx, d.cli, name, opts.Driver, opts.DriverOpts, opts.Stack)
+}
+
+// ListVolumes returns all managed volumes for a stack.
+func (d *DockerRuntime) ListVolumes(ctx context.Context, stack string) ([]string, error) {
+	return ListManagedVolumes(ctx, d.cli, stack)
+}
+
+// RemoveVolume removes a volume by name.
+func (d *DockerRuntime) RemoveVolume(ctx context.Context, name string) error {
+	return RemoveVolume(ctx, d.cli, name)
+}
+
 // EnsureImage ensures the image is available locally.
 func (d *DockerRuntime) EnsureImage(ctx context.Context, imageName string) error {
 	return EnsureImage(ctx, d.cli, imageName)
@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gridctl/gridctl/pkg/dockerclient"
+	"github.com/gridctl/gridctl/pkg/runtime"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// WatchEgressLogs follows the access logs of a stack's egress proxies and
+// logs every blocked connection. It returns once all log streams end, which
+// happens when ctx is cancelled or the proxies stop.
+func WatchEgressLogs(ctx context.Context, cli dockerclient.DockerClient, stack string, logger *slog.Logger) error {
+	containers, err := cli.ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("label", LabelManaged+"=true"),
+			filters.Arg("label", LabelStack+"="+stack),
+			filters.Arg("label", runtime.LabelEgressProxy),
+		),
+	})
+	if err != nil {
+		return err
+	}
+
+	since := strconv.FormatInt(time.Now().Unix(), 10)
+	done := make(chan struct{}, len(containers))
+	for _, c := range containers {
+		server := c.Labels[runtime.LabelEgressProxy]
+		go func(id string) {
+			defer func() { done <- struct{}{} }()
+			if err := followEgressLog(ctx, cli, id, server, since, logger); err != nil && ctx.Err() == nil {
+				logger.Warn("egress log stream ended", "server", server, "error", err)
+			}
+		}(c.ID)
+	}
+	for range containers {
+		<-done
+	}
+	return nil
+}
+
+// followEgressLog streams one proxy's stdout and logs blocked requests.
+func followEgressLog(ctx context.Context, cli dockerclient.DockerClient, id, server, since string, logger *slog.Logger) error {
+	rc, err := cli.ContainerLogs(ctx, id, container.LogsOptions{
+		ShowStdout: true,
+		Follow:     true,
+		Since:      since,
+	})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// Demultiplex the stdout stream
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(pw, io.Discard, rc)
+		pw.CloseWithError(err)
+	}()
+
+	scanner := bufio.NewScanner(pr)
+	for scanner.Scan() {
+		entry, ok := runtime.ParseEgressLogLine(scanner.Text())
+		if ok && entry.Blocked {
+			logger.Warn("egress blocked", "server", server, "method", entry.Method, "destination", entry.Destination)
+		}
+	}
+	return scanner.Err()
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/go-connections/nat"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -20,6 +21,7 @@ type MockDockerClient struct {
 	// State
 	Containers []types.Container
 	Networks   []network.Summary
+	Volumes    []*volume.Volume
 	Images     []image.Summary
 
 	// ContainerJSON responses for ContainerInspect (keyed by container ID)
@@ -36,6 +38,9 @@ type MockDockerClient struct {
 	NetworkCreateError    error
 	NetworkRemoveError    error
 	NetworkListError      error
+	VolumeCreateError     error
+	VolumeRemoveError     error
+	VolumeListError       error
 	ImageListError        error
 	ImagePullError        error
 
@@ -54,6 +59,12 @@ type MockDockerClient struct {
 	CreatedNetworks []string
 	// Removed networks
 	RemovedNetworks []string
+	// Network connections as "network/container"
+	ConnectedNetworks []string
+	// Created volumes
+	CreatedVolumes []volume.CreateOptions
+	// Removed volumes
+	RemovedVolumes []string
 	// Pulled images
 	PulledImages []string
 
@@ -181,6 +192,38 @@ func (m *MockDockerClient) NetworkRemove(ctx context.Context, networkID string)
 	return nil
 }
 
+func (m *MockDockerClient) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	m.recordCall("NetworkConnect")
+	m.ConnectedNetworks = append(m.ConnectedNetworks, networkID+"/"+containerID)
+	return nil
+}
+
+func (m *MockDockerClient) VolumeList(ctx context.Context, options volume.ListOptions) (volume.ListResponse, error) {
+	m.recordCall("VolumeList")
+	if m.VolumeListError != nil {
+		return volume.ListResponse{}, m.VolumeListError
+	}
+	return volume.ListResponse{Volumes: m.Volumes}, nil
+}
+
+func (m *MockDockerClient) VolumeCreate(ctx context.Context, options volume.CreateOptions) (volume.Volume, error) {
+	m.recordCall("VolumeCreate")
+	if m.VolumeCreateError != nil {
+		return volume.Volume{}, m.VolumeCreateError
+	}
+	m.CreatedVolumes = append(m.CreatedVolumes, options)
+	return volume.Volume{Name: options.Name, Driver: options.Driver, Labels: options.Labels}, nil
+}
+
+func (m *MockDockerClient) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	m.recordCall("VolumeRemove")
+	if m.VolumeRemoveError != nil {
+		return m.VolumeRemoveError
+	}
+	m.RemovedVolumes = append(m.RemovedVolumes, volumeID)
+	return nil
+}
+
 func (m *MockDockerClient) ImageList(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
 	m.recordCall("ImageList")
 	if m.ImageListError != nil {
@@ -11,8 +11,9 @@ import (
 )
 
 // EnsureNetwork creates the network if it doesn't exist.
-// The stack parameter is used for labeling (for cleanup).
-func EnsureNetwork(ctx context.Context, cli dockerclient.DockerClient, name, driver, stack string) (string, error) {
+// The stack parameter is used for labeling (for cleanup). Internal networks
+// have no route to the outside world.
+func EnsureNetwork(ctx context.Context, cli dockerclient.DockerClient, name, driver, stack string, internal bool) (string, error) {
 	// Check if network exists
 	networks, err := cli.NetworkList(ctx, network.ListOptions{
 		Filters: filters.NewArgs(filters.Arg("name", name)),
@@ -36,8 +37,9 @@ func EnsureNetwork(ctx context.Context, cli dockerclient.DockerClient, name, dri
 	}
 
 	resp, err := cli.NetworkCreate(ctx, name, network.CreateOptions{
-		Driver: driver,
-		Labels: labels,
+		Driver:   driver,
+		Internal: internal,
+		Labels:   labels,
 	})
 	if err != nil {
 		return "", fmt.Errorf("creating network %s: %w", name, err)
@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gridctl/gridctl/pkg/dockerclient"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// EnsureVolume creates the named volume if it doesn't exist.
+// The stack parameter is used for labeling (for cleanup).
+func EnsureVolume(ctx context.Context, cli dockerclient.DockerClient, name, driver string, driverOpts map[string]string, stack string) error {
+	// Check if volume exists
+	resp, err := cli.VolumeList(ctx, volume.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("name", name)),
+	})
+	if err != nil {
+		return fmt.Errorf("listing volumes: %w", err)
+	}
+
+	for _, v := range resp.Volumes {
+		if v != nil && v.Name == name {
+			return nil
+		}
+	}
+
+	// Create volume with stack label for cleanup
+	labels := map[string]string{
+		LabelManaged: "true",
+	}
+	if stack != "" {
+		labels[LabelStack] = stack
+	}
+
+	if _, err := cli.VolumeCreate(ctx, volume.CreateOptions{
+		Name:       name,
+		Driver:     driver,
+		DriverOpts: driverOpts,
+		Labels:     labels,
+	}); err != nil {
+		return fmt.Errorf("creating volume %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// ListManagedVolumes returns all volumes managed by gridctl for a stack.
+func ListManagedVolumes(ctx context.Context, cli dockerclient.DockerClient, stack string) ([]string, error) {
+	filterArgs := filters.NewArgs(
+		filters.Arg("label", LabelManaged+"=true"),
+	)
+	if stack != "" {
+		filterArgs.Add("label", LabelStack+"="+stack)
+	}
+
+	resp, err := cli.VolumeList(ctx, volume.ListOptions{
+		Filters: filterArgs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing volumes: %w", err)
+	}
+
+	var names []string
+	for _, v := range resp.Volumes {
+		if v != nil {
+			names = append(names, v.Name)
+		}
+	}
+	return names, nil
+}
+
+// RemoveVolume removes a volume by name.
+func RemoveVolume(ctx context.Context, cli dockerclient.DockerClient, name string) error {
+	return cli.VolumeRemove(ctx, name, false)
+}
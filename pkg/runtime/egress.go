@@ -0,0 +1,209 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+// EgressProxyImage is the image used for egress proxy containers. It is
+// pinned so a deploy runs the proxy it was tested with.
+const EgressProxyImage = "ubuntu/squid:5.2-22.04_beta"
+
+// LabelEgressProxy marks egress proxy containers with the server they serve.
+const LabelEgressProxy = "gridctl.egress-proxy"
+
+// egressProxyPort is the port the egress proxy listens on.
+const egressProxyPort = 3128
+
+// internalNetworkName returns the internal companion of a stack network.
+// Servers with egress "internal" join it instead of the network itself.
+func internalNetworkName(network string) string {
+	return network + "-internal"
+}
+
+// egressNetworkName returns the isolated network for a server with egress
+// "none" or "allowlist". Only the server and its proxy are attached.
+func egressNetworkName(stack, server string) string {
+	return "gridctl-" + stack + "-" + server + "-egress"
+}
+
+// egressProxyName returns the logical name of a server's egress proxy.
+func egressProxyName(server string) string {
+	return server + "-egress"
+}
+
+// serverNetwork returns the stack network an MCP server is configured to join.
+func serverNetwork(stack *config.Stack, server *config.MCPServer) string {
+	if len(stack.Networks) > 0 && server.Network != "" {
+		return server.Network
+	}
+	return stack.Network.Name
+}
+
+// ensureEgressNetworks creates the internal networks needed by servers with
+// an egress policy. Unrestricted container workloads on a network that has
+// an internal companion also join it, so "internal" servers can reach them.
+func (o *Orchestrator) ensureEgressNetworks(ctx context.Context, stack *config.Stack, existing map[string]bool, state *upState) error {
+	ensure := func(name string) error {
+		o.logger.Info("creating network", "name", name, "internal", true)
+		if err := o.runtime.EnsureNetwork(ctx, name, NetworkOptions{
+			Driver:   "bridge",
+			Stack:    stack.Name,
+			Internal: true,
+		}); err != nil {
+			return fmt.Errorf("ensuring network %s: %w", name, err)
+		}
+		if !existing[name] {
+			state.networks = append(state.networks, name)
+		}
+		return nil
+	}
+
+	for i := range stack.MCPServers {
+		server := &stack.MCPServers[i]
+		switch server.EgressMode() {
+		case config.EgressInternal:
+			primary := serverNetwork(stack, server)
+			if _, ok := state.internalNets[primary]; ok {
+				continue
+			}
+			if err := ensure(internalNetworkName(primary)); err != nil {
+				return err
+			}
+			state.internalNets[primary] = internalNetworkName(primary)
+		case config.EgressNone, config.EgressAllowlist:
+			if err := ensure(egressNetworkName(stack.Name, server.Name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// extraNetworks returns the additional networks an unrestricted workload
+// on the given network should join.
+func (s *upState) extraNetworks(network string) []string {
+	if name, ok := s.internalNets[network]; ok {
+		return []string{name}
+	}
+	return nil
+}
+
+// startEgressProxy starts the proxy that forwards an allowlisted server's
+// outbound traffic. The proxy joins the server's stack network for outbound
+// access and the server's isolated egress network, and returns its address.
+func (o *Orchestrator) startEgressProxy(ctx context.Context, stack *config.Stack, server *config.MCPServer, opts UpOptions, state *upState) (string, error) {
+	name := egressProxyName(server.Name)
+	proxyAddr := fmt.Sprintf("http://%s:%d", containerName(stack.Name, name), egressProxyPort)
+
+	exists, _, err := o.runtime.Exists(ctx, containerName(stack.Name, name))
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		o.logger.Info("egress proxy already exists", "server", server.Name)
+		return proxyAddr, nil
+	}
+
+	if _, err := o.ensureImage(ctx, stack, name, EgressProxyImage, nil, nil, opts, state); err != nil {
+		return "", err
+	}
+	o.logger.Info("starting egress proxy", "server", server.Name, "allow", server.Egress.Allow)
+
+	labels := managedLabels(stack.Name, name, false)
+	labels[LabelEgressProxy] = server.Name
+
+	status, err := o.runtime.Start(ctx, WorkloadConfig{
+		Name:       name,
+		Stack:      stack.Name,
+		Type:       WorkloadTypeResource,
+		Image:      EgressProxyImage,
+		Entrypoint: []string{"/bin/sh", "-c"},
+		Command: []string{
+			`printf '%s\n' "$SQUID_CONF" > /etc/squid/squid.conf && exec squid -N -f /etc/squid/squid.conf`,
+		},
+		Env:         map[string]string{"SQUID_CONF": squidConfig(server.Egress.Allow)},
+		NetworkName: serverNetwork(stack, server),
+		Networks:    []string{egressNetworkName(stack.Name, server.Name)},
+		Labels:      labels,
+	})
+	if err != nil {
+		return "", fmt.Errorf("starting egress proxy: %w", err)
+	}
+	state.recordCreated(name, status.ID)
+	return proxyAddr, nil
+}
+
+// withProxyEnv returns a copy of env that routes HTTP(S) traffic through proxy.
+func withProxyEnv(env map[string]string, proxy string) map[string]string {
+	merged := make(map[string]string, len(env)+6)
+	for k, v := range env {
+		merged[k] = v
+	}
+	for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		merged[key] = proxy
+	}
+	merged["NO_PROXY"] = "localhost,127.0.0.1"
+	merged["no_proxy"] = "localhost,127.0.0.1"
+	return merged
+}
+
+// squidConfig renders a squid configuration that only forwards requests to
+// the allowed hosts. Access log lines use the "gridctl" format parsed by
+// ParseEgressLogLine.
+func squidConfig(allow []string) string {
+	var domains, ips []string
+	for _, host := range allow {
+		if net.ParseIP(host) != nil {
+			ips = append(ips, host)
+		} else {
+			// "*.example.com" and ".example.com" both match subdomains in squid
+			domains = append(domains, strings.TrimPrefix(host, "*"))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "http_port %d\n", egressProxyPort)
+	b.WriteString("pid_filename none\n")
+	b.WriteString("cache deny all\n")
+	b.WriteString("logformat gridctl %Ss %>Hs %rm %ru\n")
+	b.WriteString("access_log stdio:/dev/stdout gridctl\n")
+	b.WriteString("cache_log /dev/stderr\n")
+	if len(domains) > 0 {
+		fmt.Fprintf(&b, "acl allowed_domains dstdomain %s\n", strings.Join(domains, " "))
+		b.WriteString("http_access allow allowed_domains\n")
+	}
+	if len(ips) > 0 {
+		fmt.Fprintf(&b, "acl allowed_ips dst %s\n", strings.Join(ips, " "))
+		b.WriteString("http_access allow allowed_ips\n")
+	}
+	b.WriteString("http_access deny all\n")
+	return b.String()
+}
+
+// EgressLogEntry is a parsed egress proxy access log line.
+type EgressLogEntry struct {
+	Blocked     bool
+	Status      string // Squid result code, e.g. "TCP_DENIED"
+	Method      string // HTTP method, "CONNECT" for HTTPS
+	Destination string // Requested URL or host:port
+}
+
+// ParseEgressLogLine parses an access log line written by an egress proxy.
+// It returns false for lines that are not access log entries.
+func ParseEgressLogLine(line string) (EgressLogEntry, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 4 || !strings.HasPrefix(fields[0], "TCP_") {
+		return EgressLogEntry{}, false
+	}
+	return EgressLogEntry{
+		Blocked:     strings.Contains(fields[0], "DENIED"),
+		Status:      fields[0],
+		Method:      fields[2],
+		Destination: fields[3],
+	}, true
+}
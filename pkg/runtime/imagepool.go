@@ -0,0 +1,72 @@
+package runtime
+
+import (
+	"context"
+	"sync"
+)
+
+// imagePool pulls and builds images with bounded concurrency.
+// Requests for the same key share a single task, so an image used by
+// several workloads is only pulled or built once per Up.
+type imagePool struct {
+	sem chan struct{}
+
+	mu    sync.Mutex
+	tasks map[string]*imageTask
+}
+
+// imageTask is a pull or build that may still be in progress.
+type imageTask struct {
+	done  chan struct{}
+	image string
+	err   error
+}
+
+// newImagePool creates a pool that runs at most size tasks at once.
+func newImagePool(size int) *imagePool {
+	if size < 1 {
+		size = 1
+	}
+	return &imagePool{
+		sem:   make(chan struct{}, size),
+		tasks: make(map[string]*imageTask),
+	}
+}
+
+// submit schedules fn under key unless a task for key already exists,
+// and returns the task. fn runs in its own goroutine once a slot is free.
+func (p *imagePool) submit(ctx context.Context, key string, fn func(ctx context.Context) (string, error)) *imageTask {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if task, ok := p.tasks[key]; ok {
+		return task
+	}
+
+	task := &imageTask{done: make(chan struct{})}
+	p.tasks[key] = task
+
+	go func() {
+		defer close(task.done)
+		select {
+		case p.sem <- struct{}{}:
+			defer func() { <-p.sem }()
+		case <-ctx.Done():
+			task.err = ctx.Err()
+			return
+		}
+		task.image, task.err = fn(ctx)
+	}()
+
+	return task
+}
+
+// wait blocks until the task finishes or ctx is cancelled.
+func (t *imageTask) wait(ctx context.Context) (string, error) {
+	select {
+	case <-t.done:
+		return t.image, t.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
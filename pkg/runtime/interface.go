@@ -40,16 +40,30 @@ type WorkloadConfig struct {
 	Image string // Container image or artifact reference
 
 	// Execution
-	Command []string          // Override entrypoint/command
+	Entrypoint []string          // Override image entrypoint
+	Command    []string          // Override command
 	Env     map[string]string // Environment variables
 
 	// Networking
-	NetworkName string // Network to join
+	NetworkName string   // Network to join
+	Networks    []string // Additional networks to join (same DNS alias)
 	ExposedPort int    // Port the workload exposes (0 if none)
 	HostPort    int    // Desired host port (0 for auto-assign)
 
 	// Storage
-	Volumes []string // Volume mounts (format: "host:container" or "host:container:mode")
+	Volumes []string // Volume mounts (format: "source:container" or "source:container:mode")
+	Tmpfs   []string // tmpfs mounts (format: "container" or "container:options")
+	WorkDir string   // Working directory inside the workload
+
+	// Resource limits and security
+	CPUs            float64  // CPU limit in cores (0 = unlimited)
+	MemoryBytes     int64    // Memory limit in bytes (0 = unlimited)
+	PidsLimit       int64    // Maximum number of processes (0 = unlimited)
+	ReadOnly        bool     // Read-only root filesystem
+	CapAdd          []string // Linux capabilities to add
+	CapDrop         []string // Linux capabilities to drop
+	NoNewPrivileges bool     // Disallow privilege escalation
+	User            string   // User to run as ("user", "uid", or "uid:gid")
 
 	// Transport-specific
 	Transport string // "http", "stdio", "sse"
@@ -67,8 +81,10 @@ type WorkloadStatus struct {
 	Type  WorkloadType // Type of workload
 
 	// State
-	State   WorkloadState // Running, Stopped, Failed, etc.
-	Message string        // Human-readable status message (e.g., "Up 5 minutes")
+	State    WorkloadState // Running, Stopped, Failed, etc.
+	Message  string        // Human-readable status message (e.g., "Up 5 minutes")
+	Health   string        // Healthcheck status: "healthy", "unhealthy", "starting", or empty if none
+	ExitCode int           // Exit code once stopped
 
 	// Networking
 	Endpoint string // How to reach this workload (e.g., "localhost:9000")
@@ -87,8 +103,16 @@ type WorkloadFilter struct {
 
 // NetworkOptions for network creation.
 type NetworkOptions struct {
-	Driver string // Network driver (e.g., "bridge")
-	Stack  string // For labeling/cleanup purposes
+	Driver   string // Network driver (e.g., "bridge")
+	Stack    string // For labeling/cleanup purposes
+	Internal bool   // No external connectivity
+}
+
+// VolumeOptions for named volume creation.
+type VolumeOptions struct {
+	Driver     string            // Volume driver (e.g., "local")
+	DriverOpts map[string]string // Driver-specific options
+	Stack      string            // For labeling/cleanup purposes
 }
 
 // WorkloadRuntime is the interface for managing workload lifecycles.
@@ -124,6 +148,15 @@ type WorkloadRuntime interface {
 	// RemoveNetwork removes a network by name.
 	RemoveNetwork(ctx context.Context, name string) error
 
+	// EnsureVolume creates the named volume if it doesn't exist.
+	EnsureVolume(ctx context.Context, name string, opts VolumeOptions) error
+
+	// ListVolumes returns all managed volumes for a stack.
+	ListVolumes(ctx context.Context, stack string) ([]string, error)
+
+	// RemoveVolume removes a volume by name.
+	RemoveVolume(ctx context.Context, name string) error
+
 	// EnsureImage ensures the image is available locally.
 	EnsureImage(ctx context.Context, imageName string) error
 
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/gridctl/gridctl/pkg/config"
 	"github.com/gridctl/gridctl/pkg/logging"
@@ -12,11 +14,20 @@ import (
 // Orchestrator manages the lifecycle of gridctl workloads.
 // It uses a WorkloadRuntime to start/stop workloads and a Builder for image builds.
 type Orchestrator struct {
-	runtime WorkloadRuntime
-	builder Builder
-	logger  *slog.Logger
+	runtime      WorkloadRuntime
+	builder      Builder
+	logger       *slog.Logger
+	pollInterval time.Duration // How often to check dependency conditions
 }
 
+// defaultDependencyTimeout bounds how long a workload waits for a
+// "healthy" or "completed" dependency.
+const defaultDependencyTimeout = 2 * time.Minute
+
+// defaultParallelism is the default number of workloads started, and images
+// pulled or built, at the same time.
+const defaultParallelism = 4
+
 // Builder handles image/artifact building.
 // This is kept separate from WorkloadRuntime as image building is a distinct concern.
 type Builder interface {
@@ -46,6 +57,39 @@ type UpOptions struct {
 	NoCache     bool // Force rebuild of source-based images
 	BasePort    int  // Base port for host port allocation (default: 9000)
 	GatewayPort int  // Port for MCP gateway (for agent MCP_ENDPOINT injection)
+
+	DependencyTimeout time.Duration // Max wait for a healthy/completed dependency (default: 2m)
+	Parallelism       int           // Max concurrent starts and image pulls/builds (default: 4)
+
+	// Progress, if set, receives per-workload startup events.
+	// Calls are serialized, so the callback does not need to be thread-safe.
+	Progress func(ProgressEvent)
+}
+
+// ProgressPhase identifies a step in a workload's startup.
+type ProgressPhase string
+
+const (
+	ProgressPulling    ProgressPhase = "pulling"     // Pulling the workload's image
+	ProgressBuilding   ProgressPhase = "building"    // Building the workload's image from source
+	ProgressWaiting    ProgressPhase = "waiting"     // Waiting on a healthy/completed dependency
+	ProgressStarting   ProgressPhase = "starting"    // Creating and starting the workload
+	ProgressReady      ProgressPhase = "ready"       // Workload started successfully
+	ProgressFailed     ProgressPhase = "failed"      // Workload failed to start
+	ProgressRolledBack ProgressPhase = "rolled back" // Workload removed after a failed Up
+)
+
+// ProgressEvent reports a workload startup step.
+type ProgressEvent struct {
+	Name    string        // Workload name
+	Phase   ProgressPhase // Current step
+	Elapsed time.Duration // Time since the workload began starting (set for ready/failed)
+	Err     error         // Failure cause (set for failed)
+}
+
+// DownOptions contains options for the Down operation.
+type DownOptions struct {
+	RemoveVolumes bool // Also remove named volumes declared by the stack
 }
 
 // UpResult contains the result of starting a stack.
@@ -85,9 +129,10 @@ type AgentResult struct {
 // NewOrchestrator creates an Orchestrator with the given runtime and builder.
 func NewOrchestrator(runtime WorkloadRuntime, builder Builder) *Orchestrator {
 	return &Orchestrator{
-		runtime: runtime,
-		builder: builder,
-		logger:  logging.NewDiscardLogger(),
+		runtime:      runtime,
+		builder:      builder,
+		logger:       logging.NewDiscardLogger(),
+		pollInterval: time.Second,
 	}
 }
 
@@ -108,7 +153,8 @@ func (o *Orchestrator) Runtime() WorkloadRuntime {
 	return o.runtime
 }
 
-// Up starts all MCP servers and resources defined in the stack.
+// Up starts all resources, MCP servers, and agents defined in the stack.
+// Workloads are started in dependency order; independent workloads start in parallel.
 func (o *Orchestrator) Up(ctx context.Context, stack *config.Stack, opts UpOptions) (*UpResult, error) {
 	// Check runtime
 	if err := o.runtime.Ping(ctx); err != nil {
@@ -121,109 +167,516 @@ func (o *Orchestrator) Up(ctx context.Context, stack *config.Stack, opts UpOptio
 
 	o.logger.Info("starting stack", "name", stack.Name)
 
+	// Resolve startup order across resources, MCP servers, and agents
+	deps := stack.WorkloadDependencies()
+	layers, err := buildWorkloadGraph(stack, deps).Layers()
+	if err != nil {
+		return nil, fmt.Errorf("resolving dependencies: %w", err)
+	}
+
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+
+	// The first failure cancels everything still in flight
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	state := &upState{
+		ids:      make(map[string]WorkloadID),
+		servers:  make(map[string]MCPServerResult),
+		agents:   make(map[string]AgentResult),
+		images:   newImagePool(parallelism),
+		progress: opts.Progress,
+		failed:   make(map[string]bool),
+
+		internalNets: make(map[string]string),
+	}
+
+	// Image prefetches may outlive a failed Up; cancel them and wait, so
+	// nothing reports progress after Up returns
+	defer func() {
+		cancel(nil)
+		state.prefetches.Wait()
+	}()
+
+	// Remember networks and volumes that already exist so rollback only removes new ones
+	existingNetworks := make(map[string]bool)
+	if names, err := o.runtime.ListNetworks(ctx, stack.Name); err == nil {
+		for _, name := range names {
+			existingNetworks[name] = true
+		}
+	}
+	existingVolumes := make(map[string]bool)
+	if names, err := o.runtime.ListVolumes(ctx, stack.Name); err == nil {
+		for _, name := range names {
+			existingVolumes[name] = true
+		}
+	}
+
 	// Create network(s)
+	var networks []config.Network
 	if len(stack.Networks) > 0 {
 		// Advanced mode: create multiple networks
-		for _, net := range stack.Networks {
-			o.logger.Info("creating network", "name", net.Name)
-			if err := o.runtime.EnsureNetwork(ctx, net.Name, NetworkOptions{
-				Driver: net.Driver,
-				Stack:  stack.Name,
-			}); err != nil {
-				return nil, fmt.Errorf("ensuring network %s: %w", net.Name, err)
-			}
-		}
+		networks = stack.Networks
 	} else {
 		// Simple mode: single network
-		o.logger.Info("creating network", "name", stack.Network.Name)
-		if err := o.runtime.EnsureNetwork(ctx, stack.Network.Name, NetworkOptions{
-			Driver: stack.Network.Driver,
+		networks = []config.Network{stack.Network}
+	}
+	for _, net := range networks {
+		o.logger.Info("creating network", "name", net.Name)
+		if err := o.runtime.EnsureNetwork(ctx, net.Name, NetworkOptions{
+			Driver: net.Driver,
 			Stack:  stack.Name,
 		}); err != nil {
-			return nil, fmt.Errorf("ensuring network: %w", err)
+			o.rollback(ctx, state)
+			return nil, fmt.Errorf("ensuring network %s: %w", net.Name, err)
+		}
+		if !existingNetworks[net.Name] {
+			state.networks = append(state.networks, net.Name)
+		}
+	}
+	if err := o.ensureEgressNetworks(ctx, stack, existingNetworks, state); err != nil {
+		o.rollback(ctx, state)
+		return nil, err
+	}
+
+	// Create named volumes
+	for _, vol := range stack.Volumes {
+		name := volumeName(stack.Name, vol.Name)
+		o.logger.Info("creating volume", "name", name)
+		if err := o.runtime.EnsureVolume(ctx, name, VolumeOptions{
+			Driver:     vol.Driver,
+			DriverOpts: vol.DriverOpts,
+			Stack:      stack.Name,
+		}); err != nil {
+			o.rollback(ctx, state)
+			return nil, fmt.Errorf("ensuring volume %s: %w", vol.Name, err)
+		}
+		if !existingVolumes[name] {
+			state.volumes = append(state.volumes, name)
 		}
 	}
 
-	// Start resources first (databases, etc.)
-	for _, res := range stack.Resources {
-		if err := o.startResource(ctx, stack, &res); err != nil {
-			return nil, fmt.Errorf("starting resource %s: %w", res.Name, err)
+	// Allocate host ports in stack order so they don't depend on startup order
+	hostPorts := make(map[string]int)
+	containerIndex := 0 // Track container-based servers for port allocation
+	for _, server := range stack.MCPServers {
+		if server.IsContainerBased() {
+			hostPorts[server.Name] = opts.BasePort + containerIndex
+			containerIndex++
 		}
 	}
 
-	// Start MCP servers and collect info
+	// Pull and build images for every layer up front; workloads wait on
+	// their own image when their layer starts
+	o.prefetchImages(ctx, cancel, stack, opts, state)
+
+	// Start each layer concurrently; a layer only depends on earlier layers
 	result := &UpResult{}
-	containerIndex := 0 // Track container-based servers for port allocation
+	starts := make(chan struct{}, parallelism)
+	for i, layer := range layers {
+		o.logger.Debug("starting dependency layer", "layer", i, "workloads", layer)
+
+		var wg sync.WaitGroup
+		for _, name := range layer {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				begin := time.Now()
+				err := o.waitForDependencies(ctx, name, deps[name], state, opts)
+				if err == nil {
+					select {
+					case starts <- struct{}{}:
+						o.report(state, ProgressEvent{Name: name, Phase: ProgressStarting})
+						err = o.startWorkload(ctx, stack, name, opts, hostPorts[name], state)
+						<-starts
+					case <-ctx.Done():
+						err = context.Cause(ctx)
+					}
+				}
+				if err != nil {
+					// Don't report workloads that were only cancelled by another failure
+					if ctx.Err() == nil {
+						o.report(state, ProgressEvent{Name: name, Phase: ProgressFailed, Elapsed: time.Since(begin), Err: err})
+					}
+					cancel(err)
+					return
+				}
+				o.report(state, ProgressEvent{Name: name, Phase: ProgressReady, Elapsed: time.Since(begin)})
+			}(name)
+		}
+		wg.Wait()
+
+		if ctx.Err() != nil {
+			cause := context.Cause(ctx)
+			o.rollback(ctx, state)
+			return nil, cause
+		}
+
+		// Agents are reported in startup order
+		for _, name := range layer {
+			if info, ok := state.agents[name]; ok {
+				result.Agents = append(result.Agents, info)
+			}
+		}
+	}
+
+	// MCP servers are reported in stack order
 	for _, server := range stack.MCPServers {
-		// Skip container creation for external servers
-		if server.IsExternal() {
-			o.logger.Info("registering external MCP server", "name", server.Name, "url", server.URL)
-			result.MCPServers = append(result.MCPServers, MCPServerResult{
-				Name:     server.Name,
-				External: true,
-				URL:      server.URL,
-			})
+		result.MCPServers = append(result.MCPServers, state.servers[server.Name])
+	}
+
+	o.logger.Info("all workloads started successfully")
+	return result, nil
+}
+
+// upState collects workload results from concurrent startup goroutines.
+type upState struct {
+	mu       sync.Mutex
+	ids      map[string]WorkloadID // Container workloads, for dependency condition checks
+	servers  map[string]MCPServerResult
+	agents   map[string]AgentResult
+	created  []createdWorkload // Workloads created by this Up, for rollback
+	networks []string          // Networks created by this Up, for rollback
+	volumes  []string          // Volumes created by this Up, for rollback
+
+	internalNets map[string]string // Stack network -> internal companion for egress "internal"
+
+	images     *imagePool
+	prefetches sync.WaitGroup // Image prefetches still running
+
+	progressMu sync.Mutex
+	progress   func(ProgressEvent)
+	failed     map[string]bool // Workloads already reported as failed
+}
+
+// createdWorkload records a workload created during Up.
+type createdWorkload struct {
+	name string
+	id   WorkloadID
+}
+
+func (s *upState) workloadID(name string) (WorkloadID, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.ids[name]
+	return id, ok
+}
+
+func (s *upState) recordCreated(name string, id WorkloadID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.created = append(s.created, createdWorkload{name: name, id: id})
+}
+
+// report logs a progress event and forwards it to the Progress callback.
+func (o *Orchestrator) report(state *upState, ev ProgressEvent) {
+	state.progressMu.Lock()
+	defer state.progressMu.Unlock()
+
+	if ev.Phase == ProgressFailed {
+		// An image failure can surface both from prefetch and from the workload itself
+		if state.failed[ev.Name] {
+			return
+		}
+		state.failed[ev.Name] = true
+	}
+
+	switch ev.Phase {
+	case ProgressReady:
+		o.logger.Info("workload ready", "name", ev.Name, "elapsed", ev.Elapsed.Round(time.Millisecond))
+	case ProgressFailed:
+		o.logger.Error("workload failed", "name", ev.Name, "elapsed", ev.Elapsed.Round(time.Millisecond), "error", ev.Err)
+	default:
+		o.logger.Debug("workload progress", "name", ev.Name, "phase", ev.Phase)
+	}
+
+	if state.progress != nil {
+		state.progress(ev)
+	}
+}
+
+// rollback stops and removes everything this Up created, newest first.
+// Pre-existing workloads and networks are left untouched.
+func (o *Orchestrator) rollback(ctx context.Context, state *upState) {
+	// The Up context is usually cancelled by now; cleanup must still run
+	ctx = context.WithoutCancel(ctx)
+
+	state.mu.Lock()
+	created := append([]createdWorkload(nil), state.created...)
+	networks := append([]string(nil), state.networks...)
+	volumes := append([]string(nil), state.volumes...)
+	state.mu.Unlock()
+
+	if len(created) == 0 && len(networks) == 0 && len(volumes) == 0 {
+		return
+	}
+
+	o.logger.Warn("startup failed, rolling back", "workloads", len(created), "networks", len(networks), "volumes", len(volumes))
+
+	for i := len(created) - 1; i >= 0; i-- {
+		w := created[i]
+		if err := o.runtime.Stop(ctx, w.id); err != nil {
+			o.logger.Warn("failed to stop workload during rollback", "name", w.name, "error", err)
+		}
+		if err := o.runtime.Remove(ctx, w.id); err != nil {
+			o.logger.Warn("failed to remove workload during rollback", "name", w.name, "error", err)
 			continue
 		}
+		o.report(state, ProgressEvent{Name: w.name, Phase: ProgressRolledBack})
+	}
+
+	for i := len(networks) - 1; i >= 0; i-- {
+		if err := o.runtime.RemoveNetwork(ctx, networks[i]); err != nil {
+			o.logger.Warn("failed to remove network during rollback", "name", networks[i], "error", err)
+		}
+	}
+
+	for _, name := range volumes {
+		if err := o.runtime.RemoveVolume(ctx, name); err != nil {
+			o.logger.Warn("failed to remove volume during rollback", "name", name, "error", err)
+		}
+	}
+}
+
+// prefetchImages starts pulls and builds for every container workload that
+// doesn't exist yet. A failed pull or build cancels the whole Up.
+func (o *Orchestrator) prefetchImages(ctx context.Context, cancel context.CancelCauseFunc, stack *config.Stack, opts UpOptions, state *upState) {
+	prefetch := func(name, image string, source *config.Source, buildArgs map[string]string) {
+		state.prefetches.Add(1)
+		go func() {
+			defer state.prefetches.Done()
+			exists, _, err := o.runtime.Exists(ctx, containerName(stack.Name, name))
+			if err != nil || exists {
+				return // Exists errors surface when the workload starts
+			}
+			if _, err := o.ensureImage(ctx, stack, name, image, source, buildArgs, opts, state); err != nil && ctx.Err() == nil {
+				o.report(state, ProgressEvent{Name: name, Phase: ProgressFailed, Err: err})
+				cancel(fmt.Errorf("preparing image for %s: %w", name, err))
+			}
+		}()
+	}
+
+	for _, res := range stack.Resources {
+		prefetch(res.Name, res.Image, nil, nil)
+	}
+	for _, server := range stack.MCPServers {
+		if server.IsContainerBased() {
+			prefetch(server.Name, server.Image, server.Source, server.BuildArgs)
+		}
+	}
+	for _, agent := range stack.Agents {
+		prefetch(agent.Name, agent.Image, agent.Source, agent.BuildArgs)
+	}
+}
 
-		// Skip container creation for local process servers
-		if server.IsLocalProcess() {
-			o.logger.Info("registering local process MCP server", "name", server.Name, "command", server.Command)
-			result.MCPServers = append(result.MCPServers, MCPServerResult{
-				Name:         server.Name,
-				LocalProcess: true,
-				Command:      server.Command,
+// ensureImage returns the image to run for a workload, building it from
+// source or pulling it through the shared image pool.
+func (o *Orchestrator) ensureImage(ctx context.Context, stack *config.Stack, name, image string, source *config.Source, buildArgs map[string]string, opts UpOptions, state *upState) (string, error) {
+	if source != nil {
+		tag := generateTag(stack.Name, name)
+		return state.images.submit(ctx, "build:"+tag, func(ctx context.Context) (string, error) {
+			o.report(state, ProgressEvent{Name: name, Phase: ProgressBuilding})
+			o.logger.Info("building from source", "name", name, "sourceType", source.Type)
+			result, err := o.builder.Build(ctx, BuildOptions{
+				SourceType: source.Type,
+				URL:        source.URL,
+				Ref:        source.Ref,
+				Path:       source.Path,
+				Dockerfile: source.Dockerfile,
+				Tag:        tag,
+				BuildArgs:  buildArgs,
+				NoCache:    opts.NoCache,
 			})
+			if err != nil {
+				return "", fmt.Errorf("building image: %w", err)
+			}
+			return result.ImageTag, nil
+		}).wait(ctx)
+	}
+
+	return state.images.submit(ctx, "pull:"+image, func(ctx context.Context) (string, error) {
+		o.report(state, ProgressEvent{Name: name, Phase: ProgressPulling})
+		if err := o.runtime.EnsureImage(ctx, image); err != nil {
+			return "", err
+		}
+		return image, nil
+	}).wait(ctx)
+}
+
+// startWorkload starts (or registers) a single named workload from the stack.
+func (o *Orchestrator) startWorkload(ctx context.Context, stack *config.Stack, name string, opts UpOptions, hostPort int, state *upState) error {
+	for i := range stack.Resources {
+		res := &stack.Resources[i]
+		if res.Name != name {
 			continue
 		}
+		id, err := o.startResource(ctx, stack, res, opts, state)
+		if err != nil {
+			return fmt.Errorf("starting resource %s: %w", res.Name, err)
+		}
+		state.mu.Lock()
+		state.ids[name] = id
+		state.mu.Unlock()
+		return nil
+	}
 
-		// Skip container creation for SSH servers
-		if server.IsSSH() {
-			o.logger.Info("registering SSH MCP server",
-				"name", server.Name,
-				"host", server.SSH.Host,
-				"user", server.SSH.User,
-				"command", server.Command)
-			result.MCPServers = append(result.MCPServers, MCPServerResult{
-				Name:            server.Name,
-				SSH:             true,
-				Command:         server.Command,
-				SSHHost:         server.SSH.Host,
-				SSHUser:         server.SSH.User,
-				SSHPort:         server.SSH.Port,
-				SSHIdentityFile: server.SSH.IdentityFile,
-			})
+	for i := range stack.MCPServers {
+		server := &stack.MCPServers[i]
+		if server.Name != name {
 			continue
 		}
+		var info *MCPServerResult
+		if server.IsContainerBased() {
+			var err error
+			info, err = o.startMCPServer(ctx, stack, server, opts, hostPort, state)
+			if err != nil {
+				return fmt.Errorf("starting MCP server %s: %w", server.Name, err)
+			}
+		} else {
+			info = o.registerMCPServer(server)
+		}
+		state.mu.Lock()
+		if info.WorkloadID != "" {
+			state.ids[name] = info.WorkloadID
+		}
+		state.servers[name] = *info
+		state.mu.Unlock()
+		return nil
+	}
 
-		hostPort := opts.BasePort + containerIndex
-		containerIndex++
-		info, err := o.startMCPServer(ctx, stack, &server, opts, hostPort)
+	for i := range stack.Agents {
+		agent := &stack.Agents[i]
+		if agent.Name != name {
+			continue
+		}
+		info, err := o.startAgent(ctx, stack, agent, opts, state)
 		if err != nil {
-			return nil, fmt.Errorf("starting MCP server %s: %w", server.Name, err)
+			return fmt.Errorf("starting agent %s: %w", agent.Name, err)
 		}
-		result.MCPServers = append(result.MCPServers, *info)
+		state.mu.Lock()
+		state.ids[name] = info.WorkloadID
+		state.agents[name] = *info
+		state.mu.Unlock()
+		return nil
 	}
 
-	// Start agents in dependency order (topologically sorted)
-	sortedAgents, err := sortAgentsByDependency(stack)
-	if err != nil {
-		return nil, fmt.Errorf("resolving agent dependencies: %w", err)
+	return fmt.Errorf("unknown workload %q", name)
+}
+
+// registerMCPServer returns the result for an MCP server that has no container
+// (external URL, local process, or SSH). These are connected to by the gateway.
+func (o *Orchestrator) registerMCPServer(server *config.MCPServer) *MCPServerResult {
+	switch {
+	case server.IsExternal():
+		o.logger.Info("registering external MCP server", "name", server.Name, "url", server.URL)
+		return &MCPServerResult{
+			Name:     server.Name,
+			External: true,
+			URL:      server.URL,
+		}
+	case server.IsLocalProcess():
+		o.logger.Info("registering local process MCP server", "name", server.Name, "command", server.Command)
+		return &MCPServerResult{
+			Name:         server.Name,
+			LocalProcess: true,
+			Command:      server.Command,
+		}
+	default:
+		o.logger.Info("registering SSH MCP server",
+			"name", server.Name,
+			"host", server.SSH.Host,
+			"user", server.SSH.User,
+			"command", server.Command)
+		return &MCPServerResult{
+			Name:            server.Name,
+			SSH:             true,
+			Command:         server.Command,
+			SSHHost:         server.SSH.Host,
+			SSHUser:         server.SSH.User,
+			SSHPort:         server.SSH.Port,
+			SSHIdentityFile: server.SSH.IdentityFile,
+		}
 	}
+}
 
-	for _, agent := range sortedAgents {
-		info, err := o.startAgent(ctx, stack, &agent, opts)
-		if err != nil {
-			return nil, fmt.Errorf("starting agent %s: %w", agent.Name, err)
+// waitForDependencies blocks until every dependency of a workload satisfies its
+// condition. Dependencies in earlier layers have already been started, so only
+// "healthy" and "completed" conditions require polling.
+func (o *Orchestrator) waitForDependencies(ctx context.Context, name string, deps []config.Dependency, state *upState, opts UpOptions) error {
+	waiting := false
+	for _, dep := range deps {
+		if dep.Condition == "" || dep.Condition == config.DependencyStarted {
+			continue
+		}
+		id, ok := state.workloadID(dep.Name)
+		if !ok {
+			continue // Non-container workloads have no health or exit status
+		}
+		if !waiting {
+			o.report(state, ProgressEvent{Name: name, Phase: ProgressWaiting})
+			waiting = true
 		}
-		result.Agents = append(result.Agents, *info)
+		o.logger.Info("waiting for dependency", "name", name, "dependency", dep.Name, "condition", dep.Condition)
+		if err := o.waitForCondition(ctx, dep, id, opts.DependencyTimeout); err != nil {
+			return fmt.Errorf("%s: waiting for %s: %w", name, dep.Name, err)
+		}
+	}
+	return nil
+}
+
+// waitForCondition polls the runtime until the dependency reaches the
+// requested condition, fails, or the timeout expires.
+func (o *Orchestrator) waitForCondition(ctx context.Context, dep config.Dependency, id WorkloadID, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = defaultDependencyTimeout
 	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	o.logger.Info("all workloads started successfully")
-	return result, nil
+	for {
+		status, err := o.runtime.Status(waitCtx, id)
+		if err != nil {
+			return err
+		}
+
+		switch dep.Condition {
+		case config.DependencyHealthy:
+			switch {
+			case status.Health == "healthy":
+				return nil
+			case status.Health == "unhealthy":
+				return fmt.Errorf("dependency is unhealthy")
+			case status.State == WorkloadStateStopped:
+				return fmt.Errorf("dependency exited before becoming healthy (exit code %d)", status.ExitCode)
+			case status.Health == "" && status.State == WorkloadStateRunning:
+				o.logger.Warn("dependency has no healthcheck, treating running as healthy", "dependency", dep.Name)
+				return nil
+			}
+		case config.DependencyCompleted:
+			if status.State == WorkloadStateStopped {
+				if status.ExitCode != 0 {
+					return fmt.Errorf("dependency exited with code %d", status.ExitCode)
+				}
+				return nil
+			}
+		default:
+			return nil
+		}
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("timed out after %s waiting for condition '%s'", timeout, dep.Condition)
+		case <-time.After(o.pollInterval):
+		}
+	}
 }
 
-func (o *Orchestrator) startMCPServer(ctx context.Context, stack *config.Stack, server *config.MCPServer, opts UpOptions, hostPort int) (*MCPServerResult, error) {
+func (o *Orchestrator) startMCPServer(ctx context.Context, stack *config.Stack, server *config.MCPServer, opts UpOptions, hostPort int, state *upState) (*MCPServerResult, error) {
 	containerName := containerName(stack.Name, server.Name)
 
 	// Check if container already exists
@@ -244,42 +697,29 @@ func (o *Orchestrator) startMCPServer(ctx context.Context, stack *config.Stack,
 		}, nil
 	}
 
-	// Determine image
-	var imageName string
-	if server.Source != nil {
-		// Build from source
-		o.logger.Info("building MCP server from source", "name", server.Name, "sourceType", server.Source.Type)
-
-		buildOpts := BuildOptions{
-			SourceType: server.Source.Type,
-			URL:        server.Source.URL,
-			Ref:        server.Source.Ref,
-			Path:       server.Source.Path,
-			Dockerfile: server.Source.Dockerfile,
-			Tag:        generateTag(stack.Name, server.Name),
-			BuildArgs:  server.BuildArgs,
-			NoCache:    opts.NoCache,
-		}
-
-		result, err := o.builder.Build(ctx, buildOpts)
+	// Determine image (built from source or pulled)
+	imageName, err := o.ensureImage(ctx, stack, server.Name, server.Image, server.Source, server.BuildArgs, opts, state)
+	if err != nil {
+		return nil, err
+	}
+	o.logger.Info("starting MCP server", "name", server.Name, "image", imageName)
+
+	// Determine network name; egress policies swap in an internal network
+	networkName := serverNetwork(stack, server)
+	extraNetworks := state.extraNetworks(networkName)
+	env := server.Env
+	switch server.EgressMode() {
+	case config.EgressInternal:
+		networkName, extraNetworks = internalNetworkName(networkName), nil
+	case config.EgressNone:
+		networkName, extraNetworks = egressNetworkName(stack.Name, server.Name), nil
+	case config.EgressAllowlist:
+		proxy, err := o.startEgressProxy(ctx, stack, server, opts, state)
 		if err != nil {
-			return nil, fmt.Errorf("building image: %w", err)
-		}
-		imageName = result.ImageTag
-	} else {
-		imageName = server.Image
-		o.logger.Info("starting MCP server", "name", server.Name, "image", imageName)
-
-		// Pull image if needed
-		if err := o.runtime.EnsureImage(ctx, imageName); err != nil {
 			return nil, err
 		}
-	}
-
-	// Determine network name
-	networkName := stack.Network.Name
-	if len(stack.Networks) > 0 && server.Network != "" {
-		networkName = server.Network
+		networkName, extraNetworks = egressNetworkName(stack.Name, server.Name), nil
+		env = withProxyEnv(server.Env, proxy)
 	}
 
 	// Create workload config
@@ -290,18 +730,26 @@ func (o *Orchestrator) startMCPServer(ctx context.Context, stack *config.Stack,
 		Type:        WorkloadTypeMCPServer,
 		Image:       imageName,
 		Command:     server.Command,
-		Env:         server.Env,
+		Env:         env,
 		NetworkName: networkName,
+		Networks:    extraNetworks,
 		ExposedPort: server.Port,
 		HostPort:    hostPort,
+		Volumes:     resolveVolumes(stack, server.Volumes),
+		Tmpfs:       server.Tmpfs,
+		WorkDir:     server.WorkDir,
 		Transport:   server.Transport,
 		Labels:      managedLabels(stack.Name, server.Name, true),
 	}
+	if err := applySecurity(&cfg, &server.ContainerSecurity); err != nil {
+		return nil, err
+	}
 
 	status, err := o.runtime.Start(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
+	state.recordCreated(server.Name, status.ID)
 
 	// Get actual host port (in case it was auto-assigned)
 	actualHostPort := status.HostPort
@@ -319,13 +767,13 @@ func (o *Orchestrator) startMCPServer(ctx context.Context, stack *config.Stack,
 	}, nil
 }
 
-func (o *Orchestrator) startResource(ctx context.Context, stack *config.Stack, res *config.Resource) error {
+func (o *Orchestrator) startResource(ctx context.Context, stack *config.Stack, res *config.Resource, opts UpOptions, state *upState) (WorkloadID, error) {
 	containerName := containerName(stack.Name, res.Name)
 
 	// Check if container already exists
 	exists, workloadID, err := o.runtime.Exists(ctx, containerName)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if exists {
@@ -333,22 +781,21 @@ func (o *Orchestrator) startResource(ctx context.Context, stack *config.Stack, r
 		// Attempt to start (may already be running)
 		status, err := o.runtime.Status(ctx, workloadID)
 		if err != nil {
-			return err
+			return "", err
 		}
 		if status.State != WorkloadStateRunning {
 			// Need to start using the runtime's Start which handles existing containers
 			_, err = o.runtime.Start(ctx, WorkloadConfig{Name: res.Name, Stack: stack.Name})
-			return err
+			return workloadID, err
 		}
-		return nil
+		return workloadID, nil
 	}
 
-	o.logger.Info("starting resource", "name", res.Name, "image", res.Image)
-
 	// Pull image if needed
-	if err := o.runtime.EnsureImage(ctx, res.Image); err != nil {
-		return err
+	if _, err := o.ensureImage(ctx, stack, res.Name, res.Image, nil, nil, opts, state); err != nil {
+		return "", err
 	}
+	o.logger.Info("starting resource", "name", res.Name, "image", res.Image)
 
 	// Determine network name
 	networkName := stack.Network.Name
@@ -363,18 +810,27 @@ func (o *Orchestrator) startResource(ctx context.Context, stack *config.Stack, r
 		Stack:       stack.Name,
 		Type:        WorkloadTypeResource,
 		Image:       res.Image,
+		Command:     res.Command,
 		Env:         res.Env,
 		NetworkName: networkName,
+		Networks:    state.extraNetworks(networkName),
 		ExposedPort: 0, // Resources don't expose MCP ports
-		Volumes:     res.Volumes,
+		Volumes:     resolveVolumes(stack, res.Volumes),
 		Labels:      managedLabels(stack.Name, res.Name, false),
 	}
+	if err := applySecurity(&cfg, &res.ContainerSecurity); err != nil {
+		return "", err
+	}
 
-	_, err = o.runtime.Start(ctx, cfg)
-	return err
+	status, err := o.runtime.Start(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+	state.recordCreated(res.Name, status.ID)
+	return status.ID, nil
 }
 
-func (o *Orchestrator) startAgent(ctx context.Context, stack *config.Stack, agent *config.Agent, opts UpOptions) (*AgentResult, error) {
+func (o *Orchestrator) startAgent(ctx context.Context, stack *config.Stack, agent *config.Agent, opts UpOptions, state *upState) (*AgentResult, error) {
 	containerName := containerName(stack.Name, agent.Name)
 
 	// Check if container already exists
@@ -392,37 +848,12 @@ func (o *Orchestrator) startAgent(ctx context.Context, stack *config.Stack, agen
 		}, nil
 	}
 
-	// Determine image
-	var imageName string
-	if agent.Source != nil {
-		// Build from source
-		o.logger.Info("building agent from source", "name", agent.Name, "sourceType", agent.Source.Type)
-
-		buildOpts := BuildOptions{
-			SourceType: agent.Source.Type,
-			URL:        agent.Source.URL,
-			Ref:        agent.Source.Ref,
-			Path:       agent.Source.Path,
-			Dockerfile: agent.Source.Dockerfile,
-			Tag:        generateTag(stack.Name, agent.Name),
-			BuildArgs:  agent.BuildArgs,
-			NoCache:    opts.NoCache,
-		}
-
-		result, err := o.builder.Build(ctx, buildOpts)
-		if err != nil {
-			return nil, fmt.Errorf("building image: %w", err)
-		}
-		imageName = result.ImageTag
-	} else {
-		imageName = agent.Image
-		o.logger.Info("starting agent", "name", agent.Name, "image", imageName)
-
-		// Pull image if needed
-		if err := o.runtime.EnsureImage(ctx, imageName); err != nil {
-			return nil, err
-		}
+	// Determine image (built from source or pulled)
+	imageName, err := o.ensureImage(ctx, stack, agent.Name, agent.Image, agent.Source, agent.BuildArgs, opts, state)
+	if err != nil {
+		return nil, err
 	}
+	o.logger.Info("starting agent", "name", agent.Name, "image", imageName)
 
 	// Determine network name
 	networkName := stack.Network.Name
@@ -450,14 +881,22 @@ func (o *Orchestrator) startAgent(ctx context.Context, stack *config.Stack, agen
 		Command:     agent.Command,
 		Env:         env,
 		NetworkName: networkName,
+		Networks:    state.extraNetworks(networkName),
 		ExposedPort: 0, // Agents don't expose ports
+		Volumes:     resolveVolumes(stack, agent.Volumes),
+		Tmpfs:       agent.Tmpfs,
+		WorkDir:     agent.WorkDir,
 		Labels:      agentLabels(stack.Name, agent.Name),
 	}
+	if err := applySecurity(&cfg, &agent.ContainerSecurity); err != nil {
+		return nil, err
+	}
 
 	status, err := o.runtime.Start(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
+	state.recordCreated(agent.Name, status.ID)
 
 	o.logger.Info("agent started", "name", agent.Name, "uses", agent.Uses)
 
@@ -469,7 +908,8 @@ func (o *Orchestrator) startAgent(ctx context.Context, stack *config.Stack, agen
 }
 
 // Down stops and removes all managed workloads and networks for a stack.
-func (o *Orchestrator) Down(ctx context.Context, stack string) error {
+// Named volumes are kept unless opts.RemoveVolumes is set.
+func (o *Orchestrator) Down(ctx context.Context, stack string, opts DownOptions) error {
 	// Check runtime
 	if err := o.runtime.Ping(ctx); err != nil {
 		return err
@@ -513,6 +953,22 @@ func (o *Orchestrator) Down(ctx context.Context, stack string) error {
 		}
 	}
 
+	// Clean up named volumes (only on request, they hold user data)
+	if opts.RemoveVolumes {
+		volumes, err := o.runtime.ListVolumes(ctx, stack)
+		if err != nil {
+			o.logger.Warn("failed to list volumes", "error", err)
+		} else if len(volumes) > 0 {
+			o.logger.Info("removing managed volumes")
+			for _, name := range volumes {
+				o.logger.Info("removing volume", "name", name)
+				if err := o.runtime.RemoveVolume(ctx, name); err != nil {
+					o.logger.Warn("failed to remove volume", "name", name, "error", err)
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -526,50 +982,29 @@ func (o *Orchestrator) Status(ctx context.Context, stack string) ([]WorkloadStat
 	return o.runtime.List(ctx, WorkloadFilter{Stack: stack})
 }
 
-// sortAgentsByDependency returns agents sorted in dependency order.
-// Agents with no agent dependencies come first, dependent agents come later.
-func sortAgentsByDependency(stack *config.Stack) ([]config.Agent, error) {
-	if len(stack.Agents) == 0 {
-		return nil, nil
+// buildWorkloadGraph builds the startup dependency graph for all workloads.
+// Nodes are added in stack order (resources, MCP servers, agents) so that
+// workloads within a layer start in a predictable order.
+func buildWorkloadGraph(stack *config.Stack, deps map[string][]config.Dependency) *DependencyGraph {
+	graph := NewDependencyGraph()
+	for _, res := range stack.Resources {
+		graph.AddNode(res.Name)
 	}
-
-	// Build set of A2A-enabled agent names (these are the only valid agent dependencies)
-	a2aAgents := make(map[string]bool)
-	for _, agent := range stack.Agents {
-		if agent.IsA2AEnabled() {
-			a2aAgents[agent.Name] = true
-		}
+	for _, server := range stack.MCPServers {
+		graph.AddNode(server.Name)
 	}
-
-	// Build dependency graph
-	graph := NewDependencyGraph()
-	agentsByName := make(map[string]config.Agent)
-
 	for _, agent := range stack.Agents {
 		graph.AddNode(agent.Name)
-		agentsByName[agent.Name] = agent
+	}
 
-		// Add edges for agent-to-agent dependencies only (not MCP server dependencies)
-		for _, selector := range agent.Uses {
-			if a2aAgents[selector.Server] {
-				graph.AddEdge(agent.Name, selector.Server)
+	for _, name := range graph.order {
+		for _, dep := range deps[name] {
+			if graph.HasNode(dep.Name) {
+				graph.AddEdge(name, dep.Name)
 			}
 		}
 	}
-
-	// Topological sort
-	sortedNames, err := graph.Sort()
-	if err != nil {
-		return nil, err
-	}
-
-	// Convert sorted names back to agent configs
-	sortedAgents := make([]config.Agent, len(sortedNames))
-	for i, name := range sortedNames {
-		sortedAgents[i] = agentsByName[name]
-	}
-
-	return sortedAgents, nil
+	return graph
 }
 
 // Helper functions that don't need Docker-specific code
@@ -578,6 +1013,52 @@ func containerName(stack, name string) string {
 	return "gridctl-" + stack + "-" + name
 }
 
+// applySecurity copies resource limits and security options into cfg.
+func applySecurity(cfg *WorkloadConfig, sec *config.ContainerSecurity) error {
+	memory, err := sec.MemoryBytes()
+	if err != nil {
+		return fmt.Errorf("parsing memory limit %q: %w", sec.Memory, err)
+	}
+	cfg.CPUs = sec.CPUs
+	cfg.MemoryBytes = memory
+	cfg.PidsLimit = sec.PidsLimit
+	cfg.ReadOnly = sec.ReadOnly != nil && *sec.ReadOnly
+	cfg.CapAdd = sec.CapAdd
+	cfg.CapDrop = sec.CapDrop
+	cfg.NoNewPrivileges = sec.NoNewPrivileges != nil && *sec.NoNewPrivileges
+	cfg.User = sec.User
+	return nil
+}
+
+// volumeName returns the runtime name of a stack's named volume.
+func volumeName(stack, name string) string {
+	return "gridctl-" + stack + "-" + name
+}
+
+// resolveVolumes rewrites the sources of named volumes declared in the
+// stack to their runtime names. Host paths, and volumes a resource names
+// without declaring them, are passed through unchanged.
+func resolveVolumes(stack *config.Stack, volumes []string) []string {
+	if len(volumes) == 0 {
+		return nil
+	}
+	declared := make(map[string]bool, len(stack.Volumes))
+	for _, vol := range stack.Volumes {
+		declared[vol.Name] = true
+	}
+	resolved := make([]string, len(volumes))
+	for i, spec := range volumes {
+		m, err := config.ParseVolumeMount(spec)
+		if err != nil || !m.IsNamedVolume() || !declared[m.Source] {
+			resolved[i] = spec
+			continue
+		}
+		m.Source = volumeName(stack.Name, m.Source)
+		resolved[i] = m.String()
+	}
+	return resolved
+}
+
 func generateTag(stack, name string) string {
 	return fmt.Sprintf("gridctl-%s-%s:latest", stack, name)
 }
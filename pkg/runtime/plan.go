@@ -0,0 +1,220 @@
+package runtime
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+// Plan describes what Up would create for a stack on a runtime where none
+// of it exists yet.
+type Plan struct {
+	Images    []ImagePlan    `json:"images"`
+	Networks  []NetworkPlan  `json:"networks"`
+	Volumes   []string       `json:"volumes,omitempty"`
+	Workloads []WorkloadPlan `json:"workloads"`
+}
+
+// ImagePlan is an image Up would pull or build.
+type ImagePlan struct {
+	Image      string   `json:"image"`                 // Image pulled, or tag built
+	Build      bool     `json:"build,omitempty"`       // Built from source rather than pulled
+	SourceType string   `json:"source_type,omitempty"` // "git" or "local", for builds
+	Source     string   `json:"source,omitempty"`      // Git URL or local path, for builds
+	Ref        string   `json:"ref,omitempty"`         // Git ref, for builds
+	UsedBy     []string `json:"used_by"`               // Workloads that run the image
+}
+
+// NetworkPlan is a network Up would create.
+type NetworkPlan struct {
+	Name     string `json:"name"`
+	Driver   string `json:"driver,omitempty"`
+	Internal bool   `json:"internal,omitempty"` // No external connectivity
+}
+
+// WorkloadPlan is a workload Up would start or register.
+type WorkloadPlan struct {
+	Name        string       `json:"name"`
+	Type        WorkloadType `json:"type"`
+	Kind        string       `json:"kind"`                   // "container", "external", "local process" or "ssh"
+	Image       string       `json:"image,omitempty"`        // For containers
+	Network     string       `json:"network,omitempty"`      // For containers
+	Networks    []string     `json:"networks,omitempty"`     // Additional networks
+	ExposedPort int          `json:"exposed_port,omitempty"` // Container port
+	HostPort    int          `json:"host_port,omitempty"`    // Host port it is published on
+	Endpoint    string       `json:"endpoint,omitempty"`     // URL, command or SSH target for servers without a container
+}
+
+// PlanUp returns what Up would do for a stack with opts, without doing it.
+// It runs Up against a runtime and builder that record what they are asked
+// to do, so the plan follows the same decisions as a real deploy.
+func PlanUp(ctx context.Context, stack *config.Stack, opts UpOptions) (*Plan, error) {
+	rt := &planRuntime{}
+	o := NewOrchestrator(rt, rt)
+	result, err := o.Up(ctx, stack, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Networks: rt.networks, Volumes: rt.volumes}
+
+	// Images in stack order of the workloads that first use them
+	images := make(map[string]int)
+	addImage := func(image, usedBy string) {
+		i, ok := images[image]
+		if !ok {
+			i = len(plan.Images)
+			images[image] = i
+			img := ImagePlan{Image: image}
+			if b, ok := rt.builds[image]; ok {
+				img.Build, img.SourceType, img.Ref = true, b.SourceType, b.Ref
+				img.Source = b.URL
+				if b.SourceType == "local" {
+					img.Source = b.Path
+				}
+			}
+			plan.Images = append(plan.Images, img)
+		}
+		plan.Images[i].UsedBy = append(plan.Images[i].UsedBy, usedBy)
+	}
+
+	started := make(map[string]WorkloadConfig, len(rt.started))
+	for _, cfg := range rt.started {
+		started[cfg.Name] = cfg
+	}
+	addContainer := func(name string) {
+		cfg, ok := started[name]
+		if !ok {
+			return
+		}
+		addImage(cfg.Image, name)
+		plan.Workloads = append(plan.Workloads, WorkloadPlan{
+			Name:        name,
+			Type:        cfg.Type,
+			Kind:        "container",
+			Image:       cfg.Image,
+			Network:     cfg.NetworkName,
+			Networks:    cfg.Networks,
+			ExposedPort: cfg.ExposedPort,
+			HostPort:    cfg.HostPort,
+		})
+	}
+
+	for _, res := range stack.Resources {
+		addContainer(res.Name)
+	}
+	for i, server := range stack.MCPServers {
+		if server.IsContainerBased() {
+			addContainer(server.Name)
+			addContainer(egressProxyName(server.Name))
+			continue
+		}
+		r := result.MCPServers[i]
+		w := WorkloadPlan{Name: server.Name, Type: WorkloadTypeMCPServer}
+		switch {
+		case r.External:
+			w.Kind, w.Endpoint = "external", r.URL
+		case r.LocalProcess:
+			w.Kind, w.Endpoint = "local process", strings.Join(r.Command, " ")
+		default:
+			w.Kind, w.Endpoint = "ssh", r.SSHUser+"@"+r.SSHHost
+		}
+		plan.Workloads = append(plan.Workloads, w)
+	}
+	for _, agent := range stack.Agents {
+		addContainer(agent.Name)
+	}
+	return plan, nil
+}
+
+// planRuntime is a WorkloadRuntime and Builder that records what it is
+// asked to do instead of doing it. No workload exists, every start
+// succeeds, and every dependency is immediately healthy or completed.
+type planRuntime struct {
+	mu       sync.Mutex
+	networks []NetworkPlan
+	volumes  []string
+	started  []WorkloadConfig
+	builds   map[string]BuildOptions // Image tag -> build
+}
+
+func (r *planRuntime) Start(ctx context.Context, cfg WorkloadConfig) (*WorkloadStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = append(r.started, cfg)
+	return &WorkloadStatus{
+		ID:       WorkloadID("planned-" + cfg.Name),
+		Name:     cfg.Name,
+		Stack:    cfg.Stack,
+		Type:     cfg.Type,
+		State:    WorkloadStateRunning,
+		HostPort: cfg.HostPort,
+		Image:    cfg.Image,
+	}, nil
+}
+
+func (r *planRuntime) Stop(ctx context.Context, id WorkloadID) error   { return nil }
+func (r *planRuntime) Remove(ctx context.Context, id WorkloadID) error { return nil }
+
+// Status reports a workload that is both healthy and exited successfully,
+// which satisfies every dependency condition.
+func (r *planRuntime) Status(ctx context.Context, id WorkloadID) (*WorkloadStatus, error) {
+	return &WorkloadStatus{ID: id, State: WorkloadStateStopped, Health: "healthy"}, nil
+}
+
+func (r *planRuntime) Exists(ctx context.Context, name string) (bool, WorkloadID, error) {
+	return false, "", nil
+}
+
+func (r *planRuntime) List(ctx context.Context, filter WorkloadFilter) ([]WorkloadStatus, error) {
+	return nil, nil
+}
+
+func (r *planRuntime) GetHostPort(ctx context.Context, id WorkloadID, exposedPort int) (int, error) {
+	return 0, nil
+}
+
+func (r *planRuntime) EnsureNetwork(ctx context.Context, name string, opts NetworkOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.networks = append(r.networks, NetworkPlan{Name: name, Driver: opts.Driver, Internal: opts.Internal})
+	return nil
+}
+
+func (r *planRuntime) ListNetworks(ctx context.Context, stack string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *planRuntime) RemoveNetwork(ctx context.Context, name string) error { return nil }
+
+func (r *planRuntime) EnsureVolume(ctx context.Context, name string, opts VolumeOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.volumes = append(r.volumes, name)
+	return nil
+}
+
+func (r *planRuntime) ListVolumes(ctx context.Context, stack string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *planRuntime) RemoveVolume(ctx context.Context, name string) error { return nil }
+
+func (r *planRuntime) EnsureImage(ctx context.Context, imageName string) error { return nil }
+
+func (r *planRuntime) Ping(ctx context.Context) error { return nil }
+
+func (r *planRuntime) Close() error { return nil }
+
+// Build records a build, which produces the requested tag.
+func (r *planRuntime) Build(ctx context.Context, opts BuildOptions) (*BuildResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.builds == nil {
+		r.builds = make(map[string]BuildOptions)
+	}
+	r.builds[opts.Tag] = opts
+	return &BuildResult{ImageTag: opts.Tag}, nil
+}
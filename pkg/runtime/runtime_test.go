@@ -3,8 +3,14 @@ package runtime
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gridctl/gridctl/pkg/config"
 	"github.com/gridctl/gridctl/pkg/logging"
@@ -28,6 +34,8 @@ type MockWorkloadRuntime struct {
 	EnsureNetworkErr error
 	ListNetworksErr  error
 	RemoveNetworkErr error
+	EnsureVolumeErr  error
+	RemoveVolumeErr  error
 	EnsureImageError error
 
 	// Call tracking
@@ -36,25 +44,58 @@ type MockWorkloadRuntime struct {
 	RemovedWorkloads []WorkloadID
 	CreatedNetworks  []string
 	RemovedNetworks  []string
+	CreatedVolumes   []string
+	RemovedVolumes   []string
 	EnsuredImages    []string
 
 	// State
 	ExistingWorkloads map[string]WorkloadID
 	ListedWorkloads   []WorkloadStatus
 	HostPorts         map[WorkloadID]int
+	Statuses          map[WorkloadID]*WorkloadStatus // Status overrides (default: running)
+	StartErrors       map[string]error               // Per-workload start errors (by name)
+	StartDelay        time.Duration                  // Simulated start latency
+
+	// Concurrency tracking
+	inFlight       int
+	MaxConcurrency int
+
+	mu sync.Mutex // Up starts independent workloads concurrently
 }
 
 func NewMockWorkloadRuntime() *MockWorkloadRuntime {
 	return &MockWorkloadRuntime{
 		ExistingWorkloads: make(map[string]WorkloadID),
 		HostPorts:         make(map[WorkloadID]int),
+		Statuses:          make(map[WorkloadID]*WorkloadStatus),
+		StartErrors:       make(map[string]error),
 	}
 }
 
 func (m *MockWorkloadRuntime) Start(ctx context.Context, cfg WorkloadConfig) (*WorkloadStatus, error) {
+	if m.StartDelay > 0 {
+		m.mu.Lock()
+		m.inFlight++
+		if m.inFlight > m.MaxConcurrency {
+			m.MaxConcurrency = m.inFlight
+		}
+		m.mu.Unlock()
+		time.Sleep(m.StartDelay)
+		defer func() {
+			m.mu.Lock()
+			m.inFlight--
+			m.mu.Unlock()
+		}()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.StartError != nil {
 		return nil, m.StartError
 	}
+	if err, ok := m.StartErrors[cfg.Name]; ok {
+		return nil, err
+	}
 	m.StartedWorkloads = append(m.StartedWorkloads, cfg)
 	id := WorkloadID("mock-" + cfg.Name)
 	return &WorkloadStatus{
@@ -70,6 +111,8 @@ func (m *MockWorkloadRuntime) Start(ctx context.Context, cfg WorkloadConfig) (*W
 }
 
 func (m *MockWorkloadRuntime) Stop(ctx context.Context, id WorkloadID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.StopError != nil {
 		return m.StopError
 	}
@@ -78,6 +121,8 @@ func (m *MockWorkloadRuntime) Stop(ctx context.Context, id WorkloadID) error {
 }
 
 func (m *MockWorkloadRuntime) Remove(ctx context.Context, id WorkloadID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.RemoveError != nil {
 		return m.RemoveError
 	}
@@ -86,6 +131,11 @@ func (m *MockWorkloadRuntime) Remove(ctx context.Context, id WorkloadID) error {
 }
 
 func (m *MockWorkloadRuntime) Status(ctx context.Context, id WorkloadID) (*WorkloadStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if status, ok := m.Statuses[id]; ok {
+		return status, nil
+	}
 	return &WorkloadStatus{
 		ID:    id,
 		State: WorkloadStateRunning,
@@ -93,6 +143,8 @@ func (m *MockWorkloadRuntime) Status(ctx context.Context, id WorkloadID) (*Workl
 }
 
 func (m *MockWorkloadRuntime) Exists(ctx context.Context, name string) (bool, WorkloadID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.ExistsError != nil {
 		return false, "", m.ExistsError
 	}
@@ -103,6 +155,8 @@ func (m *MockWorkloadRuntime) Exists(ctx context.Context, name string) (bool, Wo
 }
 
 func (m *MockWorkloadRuntime) List(ctx context.Context, filter WorkloadFilter) ([]WorkloadStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.ListError != nil {
 		return nil, m.ListError
 	}
@@ -110,6 +164,8 @@ func (m *MockWorkloadRuntime) List(ctx context.Context, filter WorkloadFilter) (
 }
 
 func (m *MockWorkloadRuntime) GetHostPort(ctx context.Context, id WorkloadID, exposedPort int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.GetHostPortError != nil {
 		return 0, m.GetHostPortError
 	}
@@ -120,6 +176,8 @@ func (m *MockWorkloadRuntime) GetHostPort(ctx context.Context, id WorkloadID, ex
 }
 
 func (m *MockWorkloadRuntime) EnsureNetwork(ctx context.Context, name string, opts NetworkOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.EnsureNetworkErr != nil {
 		return m.EnsureNetworkErr
 	}
@@ -128,6 +186,8 @@ func (m *MockWorkloadRuntime) EnsureNetwork(ctx context.Context, name string, op
 }
 
 func (m *MockWorkloadRuntime) ListNetworks(ctx context.Context, stack string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.ListNetworksErr != nil {
 		return nil, m.ListNetworksErr
 	}
@@ -135,6 +195,8 @@ func (m *MockWorkloadRuntime) ListNetworks(ctx context.Context, stack string) ([
 }
 
 func (m *MockWorkloadRuntime) RemoveNetwork(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.RemoveNetworkErr != nil {
 		return m.RemoveNetworkErr
 	}
@@ -142,7 +204,35 @@ func (m *MockWorkloadRuntime) RemoveNetwork(ctx context.Context, name string) er
 	return nil
 }
 
+func (m *MockWorkloadRuntime) EnsureVolume(ctx context.Context, name string, opts VolumeOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.EnsureVolumeErr != nil {
+		return m.EnsureVolumeErr
+	}
+	m.CreatedVolumes = append(m.CreatedVolumes, name)
+	return nil
+}
+
+func (m *MockWorkloadRuntime) ListVolumes(ctx context.Context, stack string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.CreatedVolumes, nil
+}
+
+func (m *MockWorkloadRuntime) RemoveVolume(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.RemoveVolumeErr != nil {
+		return m.RemoveVolumeErr
+	}
+	m.RemovedVolumes = append(m.RemovedVolumes, name)
+	return nil
+}
+
 func (m *MockWorkloadRuntime) EnsureImage(ctx context.Context, imageName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.EnsureImageError != nil {
 		return m.EnsureImageError
 	}
@@ -446,7 +536,7 @@ func TestOrchestrator_Down_Success(t *testing.T) {
 	orch.SetLogger(testLogger())
 
 	ctx := context.Background()
-	err := orch.Down(ctx, "test")
+	err := orch.Down(ctx, "test", DownOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -476,7 +566,7 @@ func TestOrchestrator_Down_NoWorkloads(t *testing.T) {
 	orch.SetLogger(testLogger())
 
 	ctx := context.Background()
-	err := orch.Down(ctx, "test")
+	err := orch.Down(ctx, "test", DownOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -496,7 +586,7 @@ func TestOrchestrator_Down_PingError(t *testing.T) {
 	orch.SetLogger(testLogger())
 
 	ctx := context.Background()
-	err := orch.Down(ctx, "test")
+	err := orch.Down(ctx, "test", DownOptions{})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -516,7 +606,7 @@ func TestOrchestrator_Down_StopError_Continues(t *testing.T) {
 
 	ctx := context.Background()
 	// Should not return error, just log warning
-	err := orch.Down(ctx, "test")
+	err := orch.Down(ctx, "test", DownOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -554,3 +644,686 @@ func TestOrchestrator_Status(t *testing.T) {
 		t.Errorf("expected type 'mcp-server', got '%s'", statuses[0].Type)
 	}
 }
+
+func TestDependencyGraph_Layers(t *testing.T) {
+	graph := NewDependencyGraph()
+	graph.AddNode("postgres")
+	graph.AddNode("redis")
+	graph.AddNode("server1")
+	graph.AddNode("agent1")
+	graph.AddEdge("server1", "postgres")
+	graph.AddEdge("agent1", "server1")
+	graph.AddEdge("agent1", "redis")
+
+	layers, err := graph.Layers()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][]string{{"postgres", "redis"}, {"server1"}, {"agent1"}}
+	if len(layers) != len(want) {
+		t.Fatalf("expected %d layers, got %v", len(want), layers)
+	}
+	for i := range want {
+		if len(layers[i]) != len(want[i]) {
+			t.Fatalf("layer %d: expected %v, got %v", i, want[i], layers[i])
+		}
+		for j := range want[i] {
+			if layers[i][j] != want[i][j] {
+				t.Errorf("layer %d: expected %v, got %v", i, want[i], layers[i])
+			}
+		}
+	}
+}
+
+func TestDependencyGraph_Layers_Cycle(t *testing.T) {
+	graph := NewDependencyGraph()
+	graph.AddEdge("a", "b")
+	graph.AddEdge("b", "a")
+	graph.AddNode("c")
+
+	if _, err := graph.Layers(); err == nil {
+		t.Fatal("expected cycle error, got nil")
+	}
+}
+
+func TestOrchestrator_Up_DependencyOrder(t *testing.T) {
+	mockRT := NewMockWorkloadRuntime()
+	orch := NewOrchestrator(mockRT, &MockBuilder{})
+	orch.SetLogger(testLogger())
+
+	topo := &config.Stack{
+		Name:    "test-topo",
+		Network: config.Network{Name: "test-net"},
+		Resources: []config.Resource{
+			{Name: "postgres", Image: "postgres:16"},
+			{Name: "migrate", Image: "migrate:latest", DependsOn: []config.Dependency{{Name: "postgres"}}},
+		},
+		MCPServers: []config.MCPServer{
+			{Name: "server1", Image: "mcp-server:latest", Port: 3000, DependsOn: []config.Dependency{{Name: "migrate"}}},
+			{Name: "server2", Image: "mcp-server:latest", Port: 3001},
+		},
+		Agents: []config.Agent{
+			{Name: "agent1", Image: "agent:latest", Uses: []config.ToolSelector{{Server: "server1"}}},
+		},
+	}
+
+	result, err := orch.Up(context.Background(), topo, UpOptions{BasePort: 9000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	started := make(map[string]int)
+	for i, w := range mockRT.StartedWorkloads {
+		started[w.Name] = i
+	}
+	order := [][2]string{
+		{"postgres", "migrate"},
+		{"migrate", "server1"},
+		{"postgres", "server2"},
+		{"server1", "agent1"},
+	}
+	for _, pair := range order {
+		if started[pair[0]] > started[pair[1]] {
+			t.Errorf("expected %s to start before %s, got order %v", pair[0], pair[1], mockRT.StartedWorkloads)
+		}
+	}
+
+	// Host ports follow stack order, not startup order
+	if result.MCPServers[0].Name != "server1" || result.MCPServers[0].HostPort != 9000 {
+		t.Errorf("expected server1 on port 9000, got %+v", result.MCPServers[0])
+	}
+	if result.MCPServers[1].Name != "server2" || result.MCPServers[1].HostPort != 9001 {
+		t.Errorf("expected server2 on port 9001, got %+v", result.MCPServers[1])
+	}
+	if len(result.Agents) != 1 || result.Agents[0].Name != "agent1" {
+		t.Errorf("expected agent1 in result, got %+v", result.Agents)
+	}
+}
+
+func TestOrchestrator_Up_DependencyConditions(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		status    *WorkloadStatus
+		wantErr   bool
+	}{
+		{
+			name:      "completed with exit 0",
+			condition: config.DependencyCompleted,
+			status:    &WorkloadStatus{State: WorkloadStateStopped, ExitCode: 0},
+		},
+		{
+			name:      "completed with non-zero exit",
+			condition: config.DependencyCompleted,
+			status:    &WorkloadStatus{State: WorkloadStateStopped, ExitCode: 1},
+			wantErr:   true,
+		},
+		{
+			name:      "healthy",
+			condition: config.DependencyHealthy,
+			status:    &WorkloadStatus{State: WorkloadStateRunning, Health: "healthy"},
+		},
+		{
+			name:      "healthy without healthcheck",
+			condition: config.DependencyHealthy,
+			status:    &WorkloadStatus{State: WorkloadStateRunning},
+		},
+		{
+			name:      "unhealthy",
+			condition: config.DependencyHealthy,
+			status:    &WorkloadStatus{State: WorkloadStateRunning, Health: "unhealthy"},
+			wantErr:   true,
+		},
+		{
+			name:      "never completes",
+			condition: config.DependencyCompleted,
+			status:    &WorkloadStatus{State: WorkloadStateRunning},
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRT := NewMockWorkloadRuntime()
+			mockRT.Statuses["mock-init"] = tc.status
+			orch := NewOrchestrator(mockRT, &MockBuilder{})
+			orch.SetLogger(testLogger())
+			orch.pollInterval = time.Millisecond
+
+			topo := &config.Stack{
+				Name:    "test-topo",
+				Network: config.Network{Name: "test-net"},
+				Resources: []config.Resource{
+					{Name: "init", Image: "init:latest"},
+				},
+				MCPServers: []config.MCPServer{
+					{Name: "server1", Image: "mcp-server:latest", Port: 3000, DependsOn: []config.Dependency{{Name: "init", Condition: tc.condition}}},
+				},
+			}
+
+			_, err := orch.Up(context.Background(), topo, UpOptions{DependencyTimeout: 20 * time.Millisecond})
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if len(mockRT.StartedWorkloads) != 1 {
+					t.Errorf("expected dependent not to start, got %d workloads started", len(mockRT.StartedWorkloads))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(mockRT.StartedWorkloads) != 2 {
+				t.Errorf("expected 2 workloads started, got %d", len(mockRT.StartedWorkloads))
+			}
+		})
+	}
+}
+
+func TestOrchestrator_Up_WaitingProgress(t *testing.T) {
+	mockRT := NewMockWorkloadRuntime()
+	mockRT.Statuses["mock-db"] = &WorkloadStatus{State: WorkloadStateRunning, Health: "healthy"}
+	orch := NewOrchestrator(mockRT, &MockBuilder{})
+	orch.SetLogger(testLogger())
+
+	topo := &config.Stack{
+		Name:    "test-topo",
+		Network: config.Network{Name: "test-net"},
+		Resources: []config.Resource{
+			{Name: "db", Image: "postgres:16"},
+		},
+		MCPServers: []config.MCPServer{
+			{Name: "server1", Image: "mcp-server:latest", Port: 3000, DependsOn: []config.Dependency{{Name: "db", Condition: config.DependencyHealthy}}},
+			{Name: "server2", Image: "mcp-server:latest", Port: 3001, DependsOn: []config.Dependency{{Name: "db"}}},
+		},
+	}
+
+	var waiting []string
+	progress := func(ev ProgressEvent) {
+		if ev.Phase == ProgressWaiting {
+			waiting = append(waiting, ev.Name)
+		}
+	}
+	if _, err := orch.Up(context.Background(), topo, UpOptions{Progress: progress}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(waiting, []string{"server1"}) {
+		t.Errorf("expected only server1 to wait on a dependency, got %v", waiting)
+	}
+}
+
+func TestOrchestrator_Up_ParallelismLimit(t *testing.T) {
+	mockRT := NewMockWorkloadRuntime()
+	mockRT.StartDelay = 20 * time.Millisecond
+	orch := NewOrchestrator(mockRT, &MockBuilder{})
+	orch.SetLogger(testLogger())
+
+	topo := &config.Stack{
+		Name:    "test-topo",
+		Network: config.Network{Name: "test-net"},
+	}
+	for i := 0; i < 6; i++ {
+		topo.MCPServers = append(topo.MCPServers, config.MCPServer{
+			Name:  fmt.Sprintf("server%d", i),
+			Image: "mcp-server:latest",
+			Port:  3000,
+		})
+	}
+
+	_, err := orch.Up(context.Background(), topo, UpOptions{Parallelism: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mockRT.StartedWorkloads) != 6 {
+		t.Errorf("expected 6 workloads started, got %d", len(mockRT.StartedWorkloads))
+	}
+	if mockRT.MaxConcurrency != 2 {
+		t.Errorf("expected 2 concurrent starts, got %d", mockRT.MaxConcurrency)
+	}
+
+	// Shared image is only pulled once
+	if len(mockRT.EnsuredImages) != 1 {
+		t.Errorf("expected image to be pulled once, got %v", mockRT.EnsuredImages)
+	}
+}
+
+func TestOrchestrator_Up_RollbackOnFailure(t *testing.T) {
+	mockRT := NewMockWorkloadRuntime()
+	mockRT.StartErrors["server2"] = errors.New("port in use")
+	mockRT.ExistingWorkloads["gridctl-test-topo-cache"] = "existing-cache"
+	orch := NewOrchestrator(mockRT, &MockBuilder{})
+	orch.SetLogger(testLogger())
+
+	topo := &config.Stack{
+		Name:    "test-topo",
+		Network: config.Network{Name: "test-net"},
+		Resources: []config.Resource{
+			{Name: "postgres", Image: "postgres:16"},
+			{Name: "cache", Image: "redis:7"},
+		},
+		MCPServers: []config.MCPServer{
+			{Name: "server1", Image: "mcp-server:latest", Port: 3000},
+			{Name: "server2", Image: "mcp-server:latest", Port: 3001, DependsOn: []config.Dependency{{Name: "server1"}}},
+		},
+		Agents: []config.Agent{
+			{Name: "agent1", Image: "agent:latest", Uses: []config.ToolSelector{{Server: "server2"}}},
+		},
+	}
+
+	var events []ProgressEvent
+	_, err := orch.Up(context.Background(), topo, UpOptions{
+		Progress: func(ev ProgressEvent) { events = append(events, ev) },
+	})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "starting MCP server server2: port in use") {
+		t.Errorf("expected server2 start error, got: %v", err)
+	}
+
+	// Everything created by this Up is removed; the pre-existing resource is kept
+	removed := make(map[WorkloadID]bool)
+	for _, id := range mockRT.RemovedWorkloads {
+		removed[id] = true
+	}
+	for _, id := range []WorkloadID{"mock-postgres", "mock-server1"} {
+		if !removed[id] {
+			t.Errorf("expected %s to be rolled back, removed: %v", id, mockRT.RemovedWorkloads)
+		}
+	}
+	if removed["existing-cache"] {
+		t.Error("expected pre-existing workload to be left alone")
+	}
+	if len(mockRT.RemovedNetworks) != 1 || mockRT.RemovedNetworks[0] != "test-net" {
+		t.Errorf("expected network created by Up to be removed, got %v", mockRT.RemovedNetworks)
+	}
+
+	// The dependent agent never starts
+	for _, w := range mockRT.StartedWorkloads {
+		if w.Name == "agent1" {
+			t.Error("expected agent1 not to start after server2 failed")
+		}
+	}
+
+	phases := make(map[string][]ProgressPhase)
+	for _, ev := range events {
+		phases[ev.Name] = append(phases[ev.Name], ev.Phase)
+	}
+	if !slices.Contains(phases["server2"], ProgressFailed) {
+		t.Errorf("expected failed event for server2, got %v", phases["server2"])
+	}
+	if !slices.Contains(phases["server1"], ProgressReady) || !slices.Contains(phases["server1"], ProgressRolledBack) {
+		t.Errorf("expected ready and rolled back events for server1, got %v", phases["server1"])
+	}
+}
+
+func TestOrchestrator_Up_ImageFailureCancels(t *testing.T) {
+	mockRT := NewMockWorkloadRuntime()
+	orch := NewOrchestrator(mockRT, &MockBuilder{BuildError: errors.New("dockerfile not found")})
+	orch.SetLogger(testLogger())
+
+	topo := &config.Stack{
+		Name:    "test-topo",
+		Network: config.Network{Name: "test-net"},
+		MCPServers: []config.MCPServer{
+			{Name: "server1", Image: "mcp-server:latest", Port: 3000},
+		},
+		Agents: []config.Agent{
+			{
+				Name:   "agent1",
+				Source: &config.Source{Type: "local", Path: "./agent"},
+				Uses:   []config.ToolSelector{{Server: "server1"}},
+			},
+		},
+	}
+
+	// Prefetches are finished by the time Up returns, so nothing reports after it
+	var returned atomic.Bool
+	progress := func(ev ProgressEvent) {
+		if returned.Load() {
+			t.Errorf("progress reported after Up returned: %+v", ev)
+		}
+	}
+	_, err := orch.Up(context.Background(), topo, UpOptions{Progress: progress})
+	returned.Store(true)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "dockerfile not found") {
+		t.Errorf("expected build error, got: %v", err)
+	}
+	for _, w := range mockRT.StartedWorkloads {
+		if w.Name == "agent1" {
+			t.Error("expected agent1 not to start")
+		}
+	}
+}
+
+func TestOrchestrator_Up_Volumes(t *testing.T) {
+	mockRT := NewMockWorkloadRuntime()
+	mockBuilder := &MockBuilder{}
+
+	orch := NewOrchestrator(mockRT, mockBuilder)
+	orch.SetLogger(testLogger())
+
+	topo := &config.Stack{
+		Name:    "test",
+		Network: config.Network{Name: "test-net", Driver: "bridge"},
+		Volumes: []config.Volume{{Name: "cache", Driver: "local"}},
+		MCPServers: []config.MCPServer{
+			{
+				Name:      "filesystem",
+				Image:     "mcp/filesystem:latest",
+				Transport: "stdio",
+				Volumes:   []string{"/repo:/workspace:ro", "cache:/cache"},
+				Tmpfs:     []string{"/tmp"},
+				WorkDir:   "/workspace",
+			},
+		},
+	}
+
+	ctx := context.Background()
+	if _, err := orch.Up(ctx, topo, UpOptions{BasePort: 9000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(mockRT.CreatedVolumes, []string{"gridctl-test-cache"}) {
+		t.Errorf("expected volume gridctl-test-cache to be created, got %v", mockRT.CreatedVolumes)
+	}
+
+	cfg := mockRT.StartedWorkloads[0]
+	wantVolumes := []string{"/repo:/workspace:ro", "gridctl-test-cache:/cache"}
+	if !slices.Equal(cfg.Volumes, wantVolumes) {
+		t.Errorf("expected volumes %v, got %v", wantVolumes, cfg.Volumes)
+	}
+	if cfg.WorkDir != "/workspace" {
+		t.Errorf("expected workdir '/workspace', got %q", cfg.WorkDir)
+	}
+	if !slices.Equal(cfg.Tmpfs, []string{"/tmp"}) {
+		t.Errorf("expected tmpfs [/tmp], got %v", cfg.Tmpfs)
+	}
+}
+
+func TestOrchestrator_Up_ResourceVolumesUndeclared(t *testing.T) {
+	mockRT := NewMockWorkloadRuntime()
+	orch := NewOrchestrator(mockRT, &MockBuilder{})
+	orch.SetLogger(testLogger())
+
+	topo := &config.Stack{
+		Name:    "test",
+		Network: config.Network{Name: "test-net", Driver: "bridge"},
+		Volumes: []config.Volume{{Name: "cache", Driver: "local"}},
+		Resources: []config.Resource{
+			{Name: "postgres", Image: "postgres:16", Volumes: []string{"pgdata:/var/lib/postgresql/data", "cache:/cache"}},
+		},
+	}
+
+	if _, err := orch.Up(context.Background(), topo, UpOptions{BasePort: 9000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Undeclared volumes keep their Docker name; declared ones are the stack's
+	want := []string{"pgdata:/var/lib/postgresql/data", "gridctl-test-cache:/cache"}
+	if got := mockRT.StartedWorkloads[0].Volumes; !slices.Equal(got, want) {
+		t.Errorf("expected volumes %v, got %v", want, got)
+	}
+	if !slices.Equal(mockRT.CreatedVolumes, []string{"gridctl-test-cache"}) {
+		t.Errorf("expected only the declared volume to be created, got %v", mockRT.CreatedVolumes)
+	}
+}
+
+func TestOrchestrator_Down_RemoveVolumes(t *testing.T) {
+	mockRT := NewMockWorkloadRuntime()
+	mockRT.CreatedVolumes = []string{"gridctl-test-cache"}
+	mockBuilder := &MockBuilder{}
+
+	orch := NewOrchestrator(mockRT, mockBuilder)
+	orch.SetLogger(testLogger())
+
+	ctx := context.Background()
+	if err := orch.Down(ctx, "test", DownOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mockRT.RemovedVolumes) != 0 {
+		t.Errorf("expected volumes to be kept by default, removed %v", mockRT.RemovedVolumes)
+	}
+
+	if err := orch.Down(ctx, "test", DownOptions{RemoveVolumes: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(mockRT.RemovedVolumes, []string{"gridctl-test-cache"}) {
+		t.Errorf("expected gridctl-test-cache to be removed, got %v", mockRT.RemovedVolumes)
+	}
+}
+
+func TestOrchestrator_Up_Security(t *testing.T) {
+	mockRT := NewMockWorkloadRuntime()
+	mockBuilder := &MockBuilder{}
+
+	orch := NewOrchestrator(mockRT, mockBuilder)
+	orch.SetLogger(testLogger())
+
+	readOnly := true
+	topo := &config.Stack{
+		Name:    "test",
+		Network: config.Network{Name: "test-net", Driver: "bridge"},
+		MCPServers: []config.MCPServer{
+			{
+				Name:  "server1",
+				Image: "mcp-server:latest",
+				Port:  3000,
+				ContainerSecurity: config.ContainerSecurity{
+					CPUs:      0.5,
+					Memory:    "256m",
+					PidsLimit: 64,
+					ReadOnly:  &readOnly,
+					CapDrop:   []string{"ALL"},
+					User:      "1000",
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	if _, err := orch.Up(ctx, topo, UpOptions{BasePort: 9000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg := mockRT.StartedWorkloads[0]
+	if cfg.CPUs != 0.5 || cfg.MemoryBytes != 256*1024*1024 || cfg.PidsLimit != 64 {
+		t.Errorf("unexpected limits: cpus=%v memory=%d pids=%d", cfg.CPUs, cfg.MemoryBytes, cfg.PidsLimit)
+	}
+	if !cfg.ReadOnly || cfg.NoNewPrivileges {
+		t.Errorf("expected read-only without no-new-privileges, got read_only=%v no_new_privileges=%v", cfg.ReadOnly, cfg.NoNewPrivileges)
+	}
+	if !slices.Equal(cfg.CapDrop, []string{"ALL"}) || cfg.User != "1000" {
+		t.Errorf("unexpected cap_drop=%v user=%q", cfg.CapDrop, cfg.User)
+	}
+}
+
+func TestOrchestrator_Up_Egress(t *testing.T) {
+	mockRT := NewMockWorkloadRuntime()
+	mockBuilder := &MockBuilder{}
+
+	orch := NewOrchestrator(mockRT, mockBuilder)
+	orch.SetLogger(testLogger())
+
+	topo := &config.Stack{
+		Name:    "test",
+		Network: config.Network{Name: "test-net", Driver: "bridge"},
+		MCPServers: []config.MCPServer{
+			{Name: "open", Image: "mcp-server:latest", Port: 3000},
+			{Name: "internal", Image: "mcp-server:latest", Transport: "stdio", Egress: &config.Egress{Mode: config.EgressInternal}},
+			{Name: "sealed", Image: "mcp-server:latest", Transport: "stdio", Egress: &config.Egress{Mode: config.EgressNone}},
+			{Name: "github", Image: "mcp-server:latest", Transport: "stdio", Env: map[string]string{"TOKEN": "x"},
+				Egress: &config.Egress{Mode: config.EgressAllowlist, Allow: []string{"api.github.com"}}},
+		},
+		Resources: []config.Resource{
+			{Name: "postgres", Image: "postgres:16"},
+		},
+	}
+
+	ctx := context.Background()
+	if _, err := orch.Up(ctx, topo, UpOptions{BasePort: 9000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"test-net-internal", "gridctl-test-sealed-egress", "gridctl-test-github-egress"} {
+		if !slices.Contains(mockRT.CreatedNetworks, want) {
+			t.Errorf("expected network %s to be created, got %v", want, mockRT.CreatedNetworks)
+		}
+	}
+
+	started := make(map[string]WorkloadConfig)
+	for _, cfg := range mockRT.StartedWorkloads {
+		started[cfg.Name] = cfg
+	}
+
+	if cfg := started["open"]; cfg.NetworkName != "test-net" || !slices.Equal(cfg.Networks, []string{"test-net-internal"}) {
+		t.Errorf("expected open server on test-net and test-net-internal, got %s %v", cfg.NetworkName, cfg.Networks)
+	}
+	if cfg := started["postgres"]; !slices.Equal(cfg.Networks, []string{"test-net-internal"}) {
+		t.Errorf("expected resource to join test-net-internal, got %v", cfg.Networks)
+	}
+	if cfg := started["internal"]; cfg.NetworkName != "test-net-internal" || len(cfg.Networks) != 0 {
+		t.Errorf("expected internal server only on test-net-internal, got %s %v", cfg.NetworkName, cfg.Networks)
+	}
+	if cfg := started["sealed"]; cfg.NetworkName != "gridctl-test-sealed-egress" || len(cfg.Networks) != 0 {
+		t.Errorf("expected sealed server only on its egress network, got %s %v", cfg.NetworkName, cfg.Networks)
+	}
+
+	proxy, ok := started["github-egress"]
+	if !ok {
+		t.Fatal("expected egress proxy to be started")
+	}
+	if proxy.Image != EgressProxyImage || proxy.NetworkName != "test-net" || !slices.Equal(proxy.Networks, []string{"gridctl-test-github-egress"}) {
+		t.Errorf("unexpected proxy config: image=%s network=%s networks=%v", proxy.Image, proxy.NetworkName, proxy.Networks)
+	}
+	if proxy.Labels[LabelEgressProxy] != "github" {
+		t.Errorf("expected proxy label for server github, got %v", proxy.Labels)
+	}
+	if !strings.Contains(proxy.Env["SQUID_CONF"], "dstdomain api.github.com") {
+		t.Errorf("expected allowlist in proxy config, got:\n%s", proxy.Env["SQUID_CONF"])
+	}
+
+	github := started["github"]
+	if github.NetworkName != "gridctl-test-github-egress" {
+		t.Errorf("expected github server on its egress network, got %s", github.NetworkName)
+	}
+	if github.Env["HTTPS_PROXY"] != "http://gridctl-test-github-egress:3128" || github.Env["TOKEN"] != "x" {
+		t.Errorf("expected proxy env alongside server env, got %v", github.Env)
+	}
+	if _, ok := topo.MCPServers[3].Env["HTTPS_PROXY"]; ok {
+		t.Error("expected stack config env to be left unmodified")
+	}
+}
+
+func TestSquidConfig(t *testing.T) {
+	conf := squidConfig([]string{"api.github.com", "*.githubusercontent.com", "10.0.0.5"})
+
+	for _, want := range []string{
+		"acl allowed_domains dstdomain api.github.com .githubusercontent.com\n",
+		"acl allowed_ips dst 10.0.0.5\n",
+		"http_access deny all\n",
+	} {
+		if !strings.Contains(conf, want) {
+			t.Errorf("expected config to contain %q, got:\n%s", want, conf)
+		}
+	}
+	if !strings.HasSuffix(conf, "http_access deny all\n") {
+		t.Error("expected deny all to be the last rule")
+	}
+}
+
+func TestParseEgressLogLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		want   EgressLogEntry
+		wantOK bool
+	}{
+		{
+			line:   "TCP_DENIED 403 CONNECT evil.example.com:443",
+			want:   EgressLogEntry{Blocked: true, Status: "TCP_DENIED", Method: "CONNECT", Destination: "evil.example.com:443"},
+			wantOK: true,
+		},
+		{
+			line:   "TCP_TUNNEL 200 CONNECT api.github.com:443",
+			want:   EgressLogEntry{Status: "TCP_TUNNEL", Method: "CONNECT", Destination: "api.github.com:443"},
+			wantOK: true,
+		},
+		{line: "2024/01/01 12:00:00| Accepting HTTP Socket connections", wantOK: false},
+	}
+
+	for _, tc := range tests {
+		got, ok := ParseEgressLogLine(tc.line)
+		if ok != tc.wantOK {
+			t.Errorf("%q: expected ok=%v, got %v", tc.line, tc.wantOK, ok)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: expected %+v, got %+v", tc.line, tc.want, got)
+		}
+	}
+}
+
+func TestPlanUp(t *testing.T) {
+	topo := &config.Stack{
+		Name:    "test",
+		Network: config.Network{Name: "test-net", Driver: "bridge"},
+		Volumes: []config.Volume{{Name: "cache"}},
+		Resources: []config.Resource{
+			{Name: "postgres", Image: "postgres:16"},
+		},
+		MCPServers: []config.MCPServer{
+			{Name: "server1", Image: "mcp-server:1.0", Port: 3000, DependsOn: []config.Dependency{{Name: "postgres", Condition: "healthy"}}},
+			{Name: "built", Source: &config.Source{Type: "git", URL: "https://github.com/example/server", Ref: "main"}, Port: 3001},
+			{Name: "remote", URL: "https://example.com/mcp"},
+			{Name: "local", Command: []string{"server", "--stdio"}},
+		},
+		Agents: []config.Agent{
+			{Name: "agent", Image: "mcp-server:1.0", Uses: []config.ToolSelector{{Server: "server1"}}},
+		},
+	}
+
+	plan, err := PlanUp(context.Background(), topo, UpOptions{BasePort: 9000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(plan.Images) != 3 {
+		t.Fatalf("expected 3 images, got %+v", plan.Images)
+	}
+	if img := plan.Images[1]; img.Image != "mcp-server:1.0" || !slices.Equal(img.UsedBy, []string{"server1", "agent"}) {
+		t.Errorf("expected mcp-server:1.0 used by server1 and agent, got %+v", img)
+	}
+	if img := plan.Images[2]; !img.Build || img.SourceType != "git" || img.Source != "https://github.com/example/server" || img.Ref != "main" {
+		t.Errorf("expected a git build for the built server, got %+v", img)
+	}
+	if len(plan.Networks) != 1 || plan.Networks[0].Name != "test-net" {
+		t.Errorf("expected network test-net, got %+v", plan.Networks)
+	}
+	if !slices.Equal(plan.Volumes, []string{"gridctl-test-cache"}) {
+		t.Errorf("expected volume gridctl-test-cache, got %v", plan.Volumes)
+	}
+
+	var names, kinds []string
+	for _, w := range plan.Workloads {
+		names = append(names, w.Name)
+		kinds = append(kinds, w.Kind)
+	}
+	if want := []string{"postgres", "server1", "built", "remote", "local", "agent"}; !slices.Equal(names, want) {
+		t.Errorf("expected workloads %v, got %v", want, names)
+	}
+	if want := []string{"container", "container", "container", "external", "local process", "container"}; !slices.Equal(kinds, want) {
+		t.Errorf("expected kinds %v, got %v", want, kinds)
+	}
+	if w := plan.Workloads[1]; w.HostPort != 9000 || w.ExposedPort != 3000 {
+		t.Errorf("expected server1 on host port 9000, got %+v", w)
+	}
+	if w := plan.Workloads[3]; w.Endpoint != "https://example.com/mcp" {
+		t.Errorf("expected the external server's URL, got %+v", w)
+	}
+}
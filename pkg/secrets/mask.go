@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Masked replaces secret values in masked text.
+const Masked = "********"
+
+// minMaskLength is the length below which values are not masked, since
+// masking them would garble unrelated text.
+const minMaskLength = 4
+
+// Masker replaces secret values in text. A nil Masker masks nothing.
+type Masker struct {
+	replacer *strings.Replacer
+}
+
+// NewMasker returns a masker for the given values. Values are also masked
+// in their JSON-escaped form.
+func NewMasker(values ...string) *Masker {
+	seen := make(map[string]bool)
+	var all []string
+	for _, v := range values {
+		if len(v) < minMaskLength {
+			continue
+		}
+		quoted, _ := json.Marshal(v)
+		for _, s := range []string{v, string(quoted[1 : len(quoted)-1])} {
+			if !seen[s] {
+				seen[s] = true
+				all = append(all, s)
+			}
+		}
+	}
+	if len(all) == 0 {
+		return nil
+	}
+
+	// Longer values first, so a value containing another is masked whole
+	sort.Slice(all, func(i, j int) bool { return len(all[i]) > len(all[j]) })
+	pairs := make([]string, 0, 2*len(all))
+	for _, s := range all {
+		pairs = append(pairs, s, Masked)
+	}
+	return &Masker{replacer: strings.NewReplacer(pairs...)}
+}
+
+// Mask returns s with secret values replaced.
+func (m *Masker) Mask(s string) string {
+	if m == nil {
+		return s
+	}
+	return m.replacer.Replace(s)
+}
@@ -0,0 +1,146 @@
+// Package secrets resolves the ${secret:name} references in stack files.
+// Values come from providers: the local encrypted store, environment
+// variables, files, or commands.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+// execTimeout bounds how long an exec provider command may run.
+const execTimeout = 30 * time.Second
+
+// Provider looks up the value of a secret.
+type Provider interface {
+	Lookup(ctx context.Context, name string, secret config.Secret) (string, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface.
+type ProviderFunc func(ctx context.Context, name string, secret config.Secret) (string, error)
+
+// Lookup calls f.
+func (f ProviderFunc) Lookup(ctx context.Context, name string, secret config.Secret) (string, error) {
+	return f(ctx, name, secret)
+}
+
+// Resolver looks up secrets declared in a stack, remembering their values
+// so they can be masked in output.
+type Resolver struct {
+	declared  map[string]config.Secret
+	providers map[string]Provider
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewResolver returns a resolver for the declared secrets, with the
+// built-in providers reading from store.
+func NewResolver(declared map[string]config.Secret, store *Store) *Resolver {
+	return &Resolver{
+		declared: declared,
+		providers: map[string]Provider{
+			config.SecretProviderStore: storeProvider{store},
+			config.SecretProviderEnv:   ProviderFunc(lookupEnv),
+			config.SecretProviderFile:  ProviderFunc(lookupFile),
+			config.SecretProviderExec:  ProviderFunc(lookupExec),
+		},
+		values: make(map[string]string),
+	}
+}
+
+// SetProvider replaces or adds the provider used for secrets declared
+// with the given provider name.
+func (r *Resolver) SetProvider(name string, p Provider) {
+	r.providers[name] = p
+}
+
+// Resolve returns the value of the named secret. Secrets that are not
+// declared are read from the store.
+func (r *Resolver) Resolve(name string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.values[name]; ok {
+		return v, nil
+	}
+
+	secret := r.declared[name]
+	p, ok := r.providers[secret.ProviderName()]
+	if !ok {
+		return "", fmt.Errorf("unknown provider '%s'", secret.ProviderName())
+	}
+	v, err := p.Lookup(context.Background(), name, secret)
+	if err != nil {
+		return "", err
+	}
+	r.values[name] = v
+	return v, nil
+}
+
+// Masker returns a masker for the values resolved so far.
+func (r *Resolver) Masker() *Masker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	values := make([]string, 0, len(r.values))
+	for _, v := range r.values {
+		values = append(values, v)
+	}
+	return NewMasker(values...)
+}
+
+// storeProvider reads secrets from the encrypted store.
+type storeProvider struct {
+	store *Store
+}
+
+func (p storeProvider) Lookup(_ context.Context, name string, secret config.Secret) (string, error) {
+	key := secret.StoreKey(name)
+	v, err := p.store.Get(key)
+	if errors.Is(err, ErrNotFound) {
+		return "", fmt.Errorf("not in the secret store (set it with 'gridctl secret set %s')", key)
+	}
+	return v, err
+}
+
+func lookupEnv(_ context.Context, name string, secret config.Secret) (string, error) {
+	env := secret.EnvVar(name)
+	v, ok := os.LookupEnv(env)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", env)
+	}
+	return v, nil
+}
+
+func lookupFile(_ context.Context, _ string, secret config.Secret) (string, error) {
+	data, err := os.ReadFile(secret.Path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func lookupExec(ctx context.Context, _ string, secret config.Secret) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, secret.Command[0], secret.Command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("running %s: %w: %s", secret.Command[0], err, msg)
+		}
+		return "", fmt.Errorf("running %s: %w", secret.Command[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gridctl/gridctl/pkg/config"
+)
+
+func TestResolver(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	t.Setenv("GH_TOKEN", "ghp_from_env")
+	t.Setenv("API_KEY", "key_from_env")
+	dir := t.TempDir()
+	store := NewStore(dir)
+	if err := store.Set("db", "store-password"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file := filepath.Join(dir, "cert")
+	if err := os.WriteFile(file, []byte("file-value\n"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := NewResolver(map[string]config.Secret{
+		"github_token": {Provider: config.SecretProviderEnv, Env: "GH_TOKEN"},
+		"api-key":      {Provider: config.SecretProviderEnv},
+		"password":     {Key: "db"},
+		"cert":         {Provider: config.SecretProviderFile, Path: file},
+		"cmd":          {Provider: config.SecretProviderExec, Command: []string{"echo", "  exec-value "}},
+		"failing":      {Provider: config.SecretProviderExec, Command: []string{"sh", "-c", "echo nope >&2; exit 3"}},
+		"unset":        {Provider: config.SecretProviderEnv, Env: "GRIDCTL_TEST_UNSET"},
+	}, store)
+
+	for name, want := range map[string]string{
+		"github_token": "ghp_from_env",
+		"api-key":      "key_from_env",
+		"password":     "store-password",
+		"db":           "store-password", // Undeclared secrets come from the store
+		"cert":         "file-value",
+		"cmd":          "exec-value",
+	} {
+		if got, err := r.Resolve(name); err != nil || got != want {
+			t.Errorf("%s: got %q (%v), want %q", name, got, err, want)
+		}
+	}
+
+	for name, errSubstr := range map[string]string{
+		"failing": "exit status 3: nope",
+		"unset":   "environment variable GRIDCTL_TEST_UNSET is not set",
+		"missing": "set it with 'gridctl secret set missing'",
+	} {
+		if _, err := r.Resolve(name); err == nil || !strings.Contains(err.Error(), errSubstr) {
+			t.Errorf("%s: expected error containing %q, got %v", name, errSubstr, err)
+		}
+	}
+
+	// Values are cached, and the masker covers each one
+	calls := 0
+	r.SetProvider(config.SecretProviderEnv, ProviderFunc(func(context.Context, string, config.Secret) (string, error) {
+		calls++
+		return "other", nil
+	}))
+	if v, _ := r.Resolve("github_token"); v != "ghp_from_env" || calls != 0 {
+		t.Errorf("expected cached value, got %q after %d calls", v, calls)
+	}
+	masked := r.Masker().Mask("token=ghp_from_env pw=store-password")
+	if masked != "token="+Masked+" pw="+Masked {
+		t.Errorf("unexpected masked text: %q", masked)
+	}
+}
+
+func TestMasker(t *testing.T) {
+	m := NewMasker("abc", "s3cret", "s3cret-longer", `quo"te`)
+	tests := map[string]string{
+		"abc stays, too short":    "abc stays, too short",
+		"key=s3cret":              "key=" + Masked,
+		"key=s3cret-longer":       "key=" + Masked,
+		`{"password": "quo\"te"}`: `{"password": "` + Masked + `"}`,
+		"nothing to hide":         "nothing to hide",
+	}
+	for in, want := range tests {
+		if got := m.Mask(in); got != want {
+			t.Errorf("Mask(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	var none *Masker
+	if none.Mask("s3cret") != "s3cret" || NewMasker("ab") != nil {
+		t.Error("expected a nil masker to mask nothing")
+	}
+}
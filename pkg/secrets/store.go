@@ -0,0 +1,245 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gridctl/gridctl/pkg/state"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// PassphraseEnv names the environment variable holding the passphrase of
+// a passphrase-protected store.
+const PassphraseEnv = "GRIDCTL_SECRETS_PASSPHRASE"
+
+// How a store's encryption key is obtained.
+const (
+	kdfKeyFile = "keyfile" // Random key in a file readable only by the user
+	kdfScrypt  = "scrypt"  // Derived from a passphrase
+)
+
+// ErrNotFound is returned for secrets that are not in the store.
+var ErrNotFound = errors.New("secret not found")
+
+// Store is a file of secrets encrypted with AES-256-GCM. The key is
+// derived from the passphrase in GRIDCTL_SECRETS_PASSPHRASE when the store
+// is created with it set, and is otherwise a random key kept beside the
+// store in a file only the user can read.
+type Store struct {
+	path       string
+	keyPath    string
+	passphrase string
+}
+
+// storeFile is the on-disk form of a store.
+type storeFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Salt    []byte `json:"salt,omitempty"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// NewStore returns the store kept in dir as secrets.enc, with its key file
+// secrets.key.
+func NewStore(dir string) *Store {
+	return &Store{
+		path:       filepath.Join(dir, "secrets.enc"),
+		keyPath:    filepath.Join(dir, "secrets.key"),
+		passphrase: os.Getenv(PassphraseEnv),
+	}
+}
+
+// DefaultStore returns the store under ~/.gridctl.
+func DefaultStore() *Store {
+	return NewStore(state.BaseDir())
+}
+
+// Path returns the path of the store file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Get returns the value of a secret.
+func (s *Store) Get(name string) (string, error) {
+	values, _, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	v, ok := values[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+// Set stores the value of a secret, creating the store if needed.
+func (s *Store) Set(name, value string) error {
+	values, kdf, err := s.read()
+	if err != nil {
+		return err
+	}
+	values[name] = value
+	return s.write(values, kdf)
+}
+
+// Delete removes a secret from the store.
+func (s *Store) Delete(name string) error {
+	values, kdf, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := values[name]; !ok {
+		return ErrNotFound
+	}
+	delete(values, name)
+	return s.write(values, kdf)
+}
+
+// List returns the names of the secrets in the store, sorted.
+func (s *Store) List() ([]string, error) {
+	values, _, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// read decrypts the store. A store that does not exist yet is empty, and
+// will be protected by a passphrase if one is set.
+func (s *Store) read() (map[string]string, string, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		kdf := kdfKeyFile
+		if s.passphrase != "" {
+			kdf = kdfScrypt
+		}
+		return make(map[string]string), kdf, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("reading secret store: %w", err)
+	}
+
+	var f storeFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, "", fmt.Errorf("parsing secret store %s: %w", s.path, err)
+	}
+	key, err := s.key(f.KDF, f.Salt, false)
+	if err != nil {
+		return nil, "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, "", err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("decrypting secret store %s: wrong passphrase or key", s.path)
+	}
+
+	values := make(map[string]string)
+	if err := json.Unmarshal(plain, &values); err != nil {
+		return nil, "", fmt.Errorf("parsing secret store %s: %w", s.path, err)
+	}
+	return values, f.KDF, nil
+}
+
+// write encrypts values into the store with a fresh nonce and salt.
+func (s *Store) write(values map[string]string, kdf string) error {
+	plain, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	f := storeFile{Version: 1, KDF: kdf}
+	if kdf == kdfScrypt {
+		f.Salt = make([]byte, 16)
+		if _, err := rand.Read(f.Salt); err != nil {
+			return err
+		}
+	}
+	key, err := s.key(kdf, f.Salt, true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	f.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(f.Nonce); err != nil {
+		return err
+	}
+	f.Data = gcm.Seal(nil, f.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("creating secret store directory: %w", err)
+	}
+	// Write to a temporary file first so a failed write keeps the old store
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("writing secret store: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// key returns the encryption key, creating the key file if create is set
+// and it does not exist.
+func (s *Store) key(kdf string, salt []byte, create bool) ([]byte, error) {
+	switch kdf {
+	case kdfScrypt:
+		if s.passphrase == "" {
+			return nil, fmt.Errorf("secret store %s is protected by a passphrase: set %s", s.path, PassphraseEnv)
+		}
+		return scrypt.Key([]byte(s.passphrase), salt, 1<<15, 8, 1, 32)
+	case kdfKeyFile:
+		key, err := os.ReadFile(s.keyPath)
+		if os.IsNotExist(err) && create {
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(filepath.Dir(s.keyPath), 0700); err != nil {
+				return nil, fmt.Errorf("creating secret store directory: %w", err)
+			}
+			if err := os.WriteFile(s.keyPath, key, 0600); err != nil {
+				return nil, fmt.Errorf("writing secret store key: %w", err)
+			}
+			return key, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading secret store key: %w", err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("secret store key %s is not 32 bytes", s.keyPath)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("secret store %s: unknown key derivation '%s'", s.path, kdf)
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,89 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	dir := t.TempDir()
+	s := NewStore(dir)
+
+	if _, err := s.Get("github_token"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound from an empty store, got %v", err)
+	}
+	if err := s.Set("github_token", "ghp_secret"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Set("db_password", "hunter22"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A new store on the same directory reads the same secrets
+	s = NewStore(dir)
+	if v, err := s.Get("github_token"); err != nil || v != "ghp_secret" {
+		t.Errorf("unexpected value %q (%v)", v, err)
+	}
+	if names, err := s.List(); err != nil || !reflect.DeepEqual(names, []string{"db_password", "github_token"}) {
+		t.Errorf("unexpected names %v (%v)", names, err)
+	}
+
+	data, err := os.ReadFile(s.Path())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "ghp_secret") {
+		t.Error("expected the store to be encrypted")
+	}
+	for _, path := range []string{s.Path(), filepath.Join(dir, "secrets.key")} {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("expected %s to be readable only by the user, got %v (%v)", path, info.Mode(), err)
+		}
+	}
+
+	if err := s.Delete("github_token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Delete("github_token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if names, _ := s.List(); !reflect.DeepEqual(names, []string{"db_password"}) {
+		t.Errorf("unexpected names after delete: %v", names)
+	}
+
+	// Another key cannot decrypt the store
+	if err := os.WriteFile(filepath.Join(dir, "secrets.key"), make([]byte, 32), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := s.Get("db_password"); err == nil || !strings.Contains(err.Error(), "wrong passphrase or key") {
+		t.Errorf("expected decryption error, got %v", err)
+	}
+}
+
+func TestStore_Passphrase(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(PassphraseEnv, "correct horse")
+	if err := NewStore(dir).Set("token", "abc123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "secrets.key")); !os.IsNotExist(err) {
+		t.Errorf("expected no key file for a passphrase store, got %v", err)
+	}
+	if v, err := NewStore(dir).Get("token"); err != nil || v != "abc123" {
+		t.Errorf("unexpected value %q (%v)", v, err)
+	}
+
+	t.Setenv(PassphraseEnv, "wrong")
+	if _, err := NewStore(dir).Get("token"); err == nil || !strings.Contains(err.Error(), "wrong passphrase or key") {
+		t.Errorf("expected decryption error, got %v", err)
+	}
+	t.Setenv(PassphraseEnv, "")
+	if _, err := NewStore(dir).Get("token"); err == nil || !strings.Contains(err.Error(), "set "+PassphraseEnv) {
+		t.Errorf("expected missing passphrase error, got %v", err)
+	}
+}
@@ -16,6 +16,11 @@ type DaemonState struct {
 	PID       int       `json:"pid"`
 	Port      int       `json:"port"`
 	StartedAt time.Time `json:"started_at"`
+
+	// ApprovalToken authorizes approval decisions on the gateway's API. It
+	// is generated for each deploy and only readable by the user, so agents
+	// talking to the gateway cannot approve their own calls.
+	ApprovalToken string `json:"approval_token,omitempty"`
 }
 
 // BaseDir returns the base gridctl directory (~/.gridctl/).
@@ -44,6 +49,11 @@ func LogPath(name string) string {
 	return filepath.Join(LogDir(), name+".log")
 }
 
+// AuditLogPath returns the path to the tool call audit log for a stack.
+func AuditLogPath(name string) string {
+	return filepath.Join(LogDir(), name+"-audit.jsonl")
+}
+
 // LockPath returns the path to a lock file for a stack.
 func LockPath(name string) string {
 	return filepath.Join(StateDir(), name+".lock")
@@ -76,7 +86,12 @@ func Save(state *DaemonState) error {
 		return fmt.Errorf("marshaling state: %w", err)
 	}
 
-	if err := os.WriteFile(StatePath(state.StackName), data, 0644); err != nil {
+	// The file holds the approval token, so only the user may read it
+	path := StatePath(state.StackName)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
 		return fmt.Errorf("writing state file: %w", err)
 	}
 
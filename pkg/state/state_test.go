@@ -97,6 +97,37 @@ func TestSave_CreatesFile(t *testing.T) {
 	}
 }
 
+func TestSave_PrivateFile(t *testing.T) {
+	cleanup := setTempHome(t)
+	defer cleanup()
+
+	// An existing, world-readable file is made private too
+	if err := os.MkdirAll(StateDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(StatePath("my-topo"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Save(&DaemonState{StackName: "my-topo", ApprovalToken: "secret"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	info, err := os.Stat(StatePath("my-topo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("state file mode = %o, want 600", mode)
+	}
+	loaded, err := Load("my-topo")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.ApprovalToken != "secret" {
+		t.Errorf("ApprovalToken = %q, want %q", loaded.ApprovalToken, "secret")
+	}
+}
+
 func TestSave_CreatesDirectory(t *testing.T) {
 	cleanup := setTempHome(t)
 	defer cleanup()
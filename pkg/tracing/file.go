@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// FileExporter writes finished spans to a file as JSON lines. It is meant
+// for local debugging and tests, without running a collector.
+type FileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// FileSpan is the JSON form of a span written by FileExporter.
+type FileSpan struct {
+	TraceID      string            `json:"traceId"`
+	SpanID       string            `json:"spanId"`
+	ParentSpanID string            `json:"parentSpanId,omitempty"`
+	Name         string            `json:"name"`
+	Kind         string            `json:"kind"`
+	Start        time.Time         `json:"start"`
+	End          time.Time         `json:"end"`
+	Status       string            `json:"status,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+}
+
+// NewFileExporter opens path for appending spans, creating it if needed.
+func NewFileExporter(path string) (*FileExporter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating trace file directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening trace file: %w", err)
+	}
+	return &FileExporter{file: f}, nil
+}
+
+// ExportSpans writes spans to the file.
+func (e *FileExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return fmt.Errorf("trace file is closed")
+	}
+
+	enc := json.NewEncoder(e.file)
+	for _, s := range spans {
+		out := FileSpan{
+			TraceID: s.SpanContext().TraceID().String(),
+			SpanID:  s.SpanContext().SpanID().String(),
+			Name:    s.Name(),
+			Kind:    s.SpanKind().String(),
+			Start:   s.StartTime(),
+			End:     s.EndTime(),
+		}
+		if s.Parent().IsValid() {
+			out.ParentSpanID = s.Parent().SpanID().String()
+		}
+		if code := s.Status().Code; code != 0 {
+			out.Status = code.String()
+			out.Error = s.Status().Description
+		}
+		if attrs := s.Attributes(); len(attrs) > 0 {
+			out.Attributes = make(map[string]string, len(attrs))
+			for _, kv := range attrs {
+				out.Attributes[string(kv.Key)] = kv.Value.Emit()
+			}
+		}
+		if err := enc.Encode(out); err != nil {
+			return fmt.Errorf("writing trace file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Shutdown closes the file.
+func (e *FileExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return nil
+	}
+	err := e.file.Close()
+	e.file = nil
+	return err
+}
@@ -0,0 +1,147 @@
+// Package tracing sets up OpenTelemetry tracing for the gateway and
+// propagates W3C trace context across HTTP requests and the MCP _meta field,
+// so a call can be followed from an agent through the gateway to MCP
+// servers and other agents.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name of gridctl's spans.
+const TracerName = "github.com/gridctl/gridctl"
+
+// DefaultServiceName is the service.name resource attribute by default.
+const DefaultServiceName = "gridctl"
+
+// Options configures tracing.
+type Options struct {
+	ServiceName string            // service.name of the spans (default: DefaultServiceName)
+	Endpoint    string            // OTLP/HTTP collector URL, e.g. http://localhost:4318
+	Headers     map[string]string // Headers sent to the collector, e.g. for auth
+	File        string            // Also write spans as JSON lines to this file
+	SampleRatio float64           // Fraction of new traces recorded, 0 to 1
+
+	// Exporters receive spans in addition to those configured above. Tests
+	// use this with an in-memory exporter.
+	Exporters []sdktrace.SpanExporter
+}
+
+// Setup installs a global tracer provider exporting spans as configured,
+// and the W3C trace context propagator. Calling the returned function
+// flushes pending spans and shuts tracing down.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	exporters := append([]sdktrace.SpanExporter(nil), opts.Exporters...)
+	if opts.Endpoint != "" {
+		exporter, err := otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpointURL(opts.Endpoint),
+			otlptracehttp.WithHeaders(opts.Headers),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+		}
+		exporters = append(exporters, exporter)
+	}
+	if opts.File != "" {
+		exporter, err := NewFileExporter(opts.File)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, exporter)
+	}
+
+	serviceName := opts.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	}
+	for _, exporter := range exporters {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		return errors.Join(provider.ForceFlush(ctx), provider.Shutdown(ctx))
+	}, nil
+}
+
+// Tracer returns gridctl's tracer from the global provider. Spans are
+// no-ops until Setup is called.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Start starts a span with gridctl's tracer.
+func Start(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// InjectHTTP adds the trace context of ctx to outgoing request headers.
+func InjectHTTP(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// ExtractHTTP returns ctx with the trace context of incoming request headers.
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// InjectMeta adds the trace context of ctx to an MCP _meta object, such as
+// {"traceparent": "00-..."}, and returns it. A nil meta is allocated when
+// there is a trace context to add.
+func InjectMeta(ctx context.Context, meta map[string]any) map[string]any {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return meta
+	}
+	if meta == nil {
+		meta = make(map[string]any, len(carrier))
+	}
+	for k, v := range carrier {
+		meta[k] = v
+	}
+	return meta
+}
+
+// ExtractMeta returns ctx with the trace context of an MCP _meta object.
+// ctx is returned unchanged if meta carries none.
+func ExtractMeta(ctx context.Context, meta map[string]any) context.Context {
+	carrier := propagation.MapCarrier{}
+	for k, v := range meta {
+		if s, ok := v.(string); ok {
+			carrier[k] = s
+		}
+	}
+	if carrier.Get("traceparent") == "" {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
@@ -0,0 +1,170 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setupTest installs tracing with an in-memory exporter for one test.
+func setupTest(t *testing.T, opts Options) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	opts.Exporters = append(opts.Exporters, exporter)
+	if opts.SampleRatio == 0 {
+		opts.SampleRatio = 1
+	}
+	shutdown, err := Setup(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+	return exporter
+}
+
+// flush exports pending spans and returns all exported so far.
+func flush(t *testing.T, exporter *tracetest.InMemoryExporter) tracetest.SpanStubs {
+	t.Helper()
+	provider, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	if !ok {
+		t.Fatal("tracer provider not installed")
+	}
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+	return exporter.GetSpans()
+}
+
+func TestPropagation_HTTP(t *testing.T) {
+	setupTest(t, Options{})
+
+	ctx, span := Start(context.Background(), "parent", trace.SpanKindInternal)
+	defer span.End()
+
+	header := http.Header{}
+	InjectHTTP(ctx, header)
+	if header.Get("traceparent") == "" {
+		t.Fatal("expected traceparent header")
+	}
+
+	remote := trace.SpanContextFromContext(ExtractHTTP(context.Background(), header))
+	if remote.TraceID() != span.SpanContext().TraceID() || remote.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("extracted %v, want %v", remote, span.SpanContext())
+	}
+}
+
+func TestPropagation_Meta(t *testing.T) {
+	setupTest(t, Options{})
+
+	ctx, span := Start(context.Background(), "parent", trace.SpanKindInternal)
+	defer span.End()
+
+	meta := InjectMeta(ctx, map[string]any{"progressToken": "p1"})
+	if meta["traceparent"] == nil || meta["progressToken"] != "p1" {
+		t.Fatalf("unexpected meta: %v", meta)
+	}
+
+	// Survives a JSON round trip, as it does over the wire
+	data, _ := json.Marshal(meta)
+	var decoded map[string]any
+	_ = json.Unmarshal(data, &decoded)
+
+	remote := trace.SpanContextFromContext(ExtractMeta(context.Background(), decoded))
+	if remote.TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("extracted trace %s, want %s", remote.TraceID(), span.SpanContext().TraceID())
+	}
+
+	if InjectMeta(context.Background(), nil) != nil {
+		t.Error("expected nil meta without a span")
+	}
+	ctx = context.Background()
+	if ExtractMeta(ctx, map[string]any{"other": "x"}) != ctx {
+		t.Error("expected ctx unchanged without traceparent")
+	}
+}
+
+func TestSetup_ParentChild(t *testing.T) {
+	exporter := setupTest(t, Options{})
+
+	ctx, parent := Start(context.Background(), "parent", trace.SpanKindServer)
+	_, child := Start(ctx, "child", trace.SpanKindClient)
+	End(child, os.ErrNotExist)
+	End(parent, nil)
+
+	spans := flush(t, exporter)
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	byName := map[string]tracetest.SpanStub{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	if byName["child"].Parent.SpanID() != byName["parent"].SpanContext.SpanID() {
+		t.Error("expected child to be parented to parent")
+	}
+	if byName["child"].SpanKind != trace.SpanKindClient {
+		t.Errorf("expected client span, got %v", byName["child"].SpanKind)
+	}
+	if byName["child"].Status.Description != os.ErrNotExist.Error() {
+		t.Errorf("expected child error status, got %+v", byName["child"].Status)
+	}
+	if byName["parent"].Resource.String() != "service.name=gridctl" {
+		t.Errorf("unexpected resource: %s", byName["parent"].Resource)
+	}
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "spans.jsonl")
+	shutdown, err := Setup(context.Background(), Options{SampleRatio: 1, File: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := Start(context.Background(), "tools/call github__search", trace.SpanKindInternal)
+	_, child := Start(ctx, "tools/call", trace.SpanKindClient)
+	child.End()
+	parent.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 spans, got %d:\n%s", len(lines), data)
+	}
+	var first FileSpan
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Name != "tools/call" || first.Kind != "client" || first.ParentSpanID != parent.SpanContext().SpanID().String() {
+		t.Errorf("unexpected span: %+v", first)
+	}
+}
+
+func TestSetup_SampleRatioZero(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	shutdown, err := Setup(context.Background(), Options{Exporters: []sdktrace.SpanExporter{exporter}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(context.Background())
+
+	_, span := Start(context.Background(), "dropped", trace.SpanKindInternal)
+	span.End()
+	if n := len(flush(t, exporter)); n != 0 {
+		t.Errorf("expected no spans with a zero sample ratio, got %d", n)
+	}
+}
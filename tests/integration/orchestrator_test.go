@@ -2,6 +2,7 @@ package integration
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/gridctl/gridctl/pkg/config"
@@ -22,6 +23,8 @@ type MockWorkloadRuntime struct {
 	EnsureNetworkErr error
 	ListNetworksErr  error
 	RemoveNetworkErr error
+	EnsureVolumeErr  error
+	RemoveVolumeErr  error
 	EnsureImageError error
 
 	// Call tracking
@@ -30,22 +33,30 @@ type MockWorkloadRuntime struct {
 	RemovedWorkloads []runtime.WorkloadID
 	CreatedNetworks  []string
 	RemovedNetworks  []string
+	CreatedVolumes   []string
+	RemovedVolumes   []string
 	EnsuredImages    []string
 
 	// State
 	ExistingWorkloads map[string]runtime.WorkloadID
 	ListedWorkloads   []runtime.WorkloadStatus
 	HostPorts         map[runtime.WorkloadID]int
+	Statuses          map[runtime.WorkloadID]*runtime.WorkloadStatus // Status overrides (default: running)
+
+	mu sync.Mutex // Up starts independent workloads concurrently
 }
 
 func NewMockWorkloadRuntime() *MockWorkloadRuntime {
 	return &MockWorkloadRuntime{
 		ExistingWorkloads: make(map[string]runtime.WorkloadID),
 		HostPorts:         make(map[runtime.WorkloadID]int),
+		Statuses:          make(map[runtime.WorkloadID]*runtime.WorkloadStatus),
 	}
 }
 
 func (m *MockWorkloadRuntime) Start(ctx context.Context, cfg runtime.WorkloadConfig) (*runtime.WorkloadStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.StartError != nil {
 		return nil, m.StartError
 	}
@@ -64,6 +75,8 @@ func (m *MockWorkloadRuntime) Start(ctx context.Context, cfg runtime.WorkloadCon
 }
 
 func (m *MockWorkloadRuntime) Stop(ctx context.Context, id runtime.WorkloadID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.StopError != nil {
 		return m.StopError
 	}
@@ -72,6 +85,8 @@ func (m *MockWorkloadRuntime) Stop(ctx context.Context, id runtime.WorkloadID) e
 }
 
 func (m *MockWorkloadRuntime) Remove(ctx context.Context, id runtime.WorkloadID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.RemoveError != nil {
 		return m.RemoveError
 	}
@@ -80,6 +95,11 @@ func (m *MockWorkloadRuntime) Remove(ctx context.Context, id runtime.WorkloadID)
 }
 
 func (m *MockWorkloadRuntime) Status(ctx context.Context, id runtime.WorkloadID) (*runtime.WorkloadStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if status, ok := m.Statuses[id]; ok {
+		return status, nil
+	}
 	return &runtime.WorkloadStatus{
 		ID:    id,
 		State: runtime.WorkloadStateRunning,
@@ -87,6 +107,8 @@ func (m *MockWorkloadRuntime) Status(ctx context.Context, id runtime.WorkloadID)
 }
 
 func (m *MockWorkloadRuntime) Exists(ctx context.Context, name string) (bool, runtime.WorkloadID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.ExistsError != nil {
 		return false, "", m.ExistsError
 	}
@@ -97,6 +119,8 @@ func (m *MockWorkloadRuntime) Exists(ctx context.Context, name string) (bool, ru
 }
 
 func (m *MockWorkloadRuntime) List(ctx context.Context, filter runtime.WorkloadFilter) ([]runtime.WorkloadStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.ListError != nil {
 		return nil, m.ListError
 	}
@@ -104,6 +128,8 @@ func (m *MockWorkloadRuntime) List(ctx context.Context, filter runtime.WorkloadF
 }
 
 func (m *MockWorkloadRuntime) GetHostPort(ctx context.Context, id runtime.WorkloadID, exposedPort int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.GetHostPortError != nil {
 		return 0, m.GetHostPortError
 	}
@@ -114,6 +140,8 @@ func (m *MockWorkloadRuntime) GetHostPort(ctx context.Context, id runtime.Worklo
 }
 
 func (m *MockWorkloadRuntime) EnsureNetwork(ctx context.Context, name string, opts runtime.NetworkOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.EnsureNetworkErr != nil {
 		return m.EnsureNetworkErr
 	}
@@ -122,6 +150,8 @@ func (m *MockWorkloadRuntime) EnsureNetwork(ctx context.Context, name string, op
 }
 
 func (m *MockWorkloadRuntime) ListNetworks(ctx context.Context, stack string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.ListNetworksErr != nil {
 		return nil, m.ListNetworksErr
 	}
@@ -129,6 +159,8 @@ func (m *MockWorkloadRuntime) ListNetworks(ctx context.Context, stack string) ([
 }
 
 func (m *MockWorkloadRuntime) RemoveNetwork(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.RemoveNetworkErr != nil {
 		return m.RemoveNetworkErr
 	}
@@ -136,7 +168,35 @@ func (m *MockWorkloadRuntime) RemoveNetwork(ctx context.Context, name string) er
 	return nil
 }
 
+func (m *MockWorkloadRuntime) EnsureVolume(ctx context.Context, name string, opts runtime.VolumeOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.EnsureVolumeErr != nil {
+		return m.EnsureVolumeErr
+	}
+	m.CreatedVolumes = append(m.CreatedVolumes, name)
+	return nil
+}
+
+func (m *MockWorkloadRuntime) ListVolumes(ctx context.Context, stack string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.CreatedVolumes, nil
+}
+
+func (m *MockWorkloadRuntime) RemoveVolume(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.RemoveVolumeErr != nil {
+		return m.RemoveVolumeErr
+	}
+	m.RemovedVolumes = append(m.RemovedVolumes, name)
+	return nil
+}
+
 func (m *MockWorkloadRuntime) EnsureImage(ctx context.Context, imageName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.EnsureImageError != nil {
 		return m.EnsureImageError
 	}
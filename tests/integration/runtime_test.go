@@ -69,7 +69,7 @@ func TestFullStackLifecycle(t *testing.T) {
 	}
 
 	// Stop the stack
-	if err := rt.Down(ctx, "integration-test"); err != nil {
+	if err := rt.Down(ctx, "integration-test", runtime.DownOptions{}); err != nil {
 		t.Fatalf("Down() error = %v", err)
 	}
 
@@ -140,7 +140,7 @@ func TestStackWithResources(t *testing.T) {
 	}
 
 	// Cleanup
-	if err := rt.Down(ctx, "integration-resources"); err != nil {
+	if err := rt.Down(ctx, "integration-resources", runtime.DownOptions{}); err != nil {
 		t.Fatalf("Down() error = %v", err)
 	}
 }
@@ -196,7 +196,7 @@ func TestMultipleNetworks(t *testing.T) {
 	}
 
 	// Cleanup
-	if err := rt.Down(ctx, "integration-multinetwork"); err != nil {
+	if err := rt.Down(ctx, "integration-multinetwork", runtime.DownOptions{}); err != nil {
 		t.Fatalf("Down() error = %v", err)
 	}
 }
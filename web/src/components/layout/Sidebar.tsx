@@ -1,8 +1,10 @@
 import { useState } from 'react';
-import { X, Terminal, Box, Bot, ChevronDown, ChevronRight, Wrench, FileText, Sparkles, Globe, Server, Zap, Cpu, KeyRound, Network } from 'lucide-react';
+import { X, Terminal, Box, Bot, ChevronDown, ChevronRight, Wrench, FileText, Sparkles, Globe, Server, Zap, Cpu, KeyRound, Network, History, ShieldAlert } from 'lucide-react';
 import { cn } from '../../lib/cn';
 import { Badge } from '../ui/Badge';
 import { ToolList } from '../ui/ToolList';
+import { CallList } from '../ui/CallList';
+import { ApprovalList } from '../ui/ApprovalList';
 import { ControlBar } from '../ui/ControlBar';
 import { getTransportIcon, getTransportColorClasses } from '../../lib/transport';
 import { useStackStore, useSelectedNodeData } from '../../stores/useStackStore';
@@ -313,6 +315,20 @@ export function Sidebar() {
           </Section>
         )}
 
+        {/* Pending Approvals Section (MCP servers and agents) */}
+        {(isServer || isAgent) && (
+          <Section title="Pending Approvals" icon={ShieldAlert} defaultOpen>
+            <ApprovalList {...(isServer ? { server: data.name } : { agent: data.name })} />
+          </Section>
+        )}
+
+        {/* Recent Calls Section (MCP servers and agents, from the audit log) */}
+        {(isServer || isAgent) && (
+          <Section title="Recent Calls" icon={History}>
+            <CallList filter={isServer ? { server: data.name } : { agent: data.name }} />
+          </Section>
+        )}
+
         {/* Skills Section (Agents with A2A) */}
         {isAgent && hasA2A && (agentData?.skills?.length ?? 0) > 0 && (
           <Section
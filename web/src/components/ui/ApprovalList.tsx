@@ -0,0 +1,141 @@
+import { useEffect, useState, useCallback } from 'react';
+import { ShieldAlert, Check, X } from 'lucide-react';
+import { cn } from '../../lib/cn';
+import { fetchApprovals, decideApproval } from '../../lib/api';
+import { POLLING } from '../../lib/constants';
+import type { Approval } from '../../types';
+
+interface ApprovalListProps {
+  // Only show calls to this server, or from this agent
+  server?: string;
+  agent?: string;
+}
+
+export function ApprovalList({ server, agent }: ApprovalListProps) {
+  const [approvals, setApprovals] = useState<Approval[]>([]);
+  const [error, setError] = useState<string | null>(null);
+
+  const loadApprovals = useCallback(async () => {
+    try {
+      const result = await fetchApprovals();
+      setApprovals(
+        result.approvals.filter(
+          (a) => (!server || a.server === server) && (!agent || a.agent === agent)
+        )
+      );
+      setError(null);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to fetch approvals');
+    }
+  }, [server, agent]);
+
+  useEffect(() => {
+    loadApprovals();
+    const interval = window.setInterval(loadApprovals, POLLING.APPROVALS);
+    return () => clearInterval(interval);
+  }, [loadApprovals]);
+
+  const handleDecide = async (id: string, approve: boolean) => {
+    try {
+      await decideApproval(id, approve);
+      setError(null);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to decide approval');
+    }
+    loadApprovals();
+  };
+
+  if (error) {
+    return <p className="text-sm text-status-error px-4 py-2">{error}</p>;
+  }
+
+  if (approvals.length === 0) {
+    return (
+      <p className="text-sm text-text-muted italic px-4 py-2">
+        No calls awaiting approval
+      </p>
+    );
+  }
+
+  return (
+    <div className="space-y-1">
+      {approvals.map((approval) => (
+        <ApprovalItem key={approval.id} approval={approval} onDecide={handleDecide} />
+      ))}
+    </div>
+  );
+}
+
+interface ApprovalItemProps {
+  approval: Approval;
+  onDecide: (id: string, approve: boolean) => void;
+}
+
+function ApprovalItem({ approval, onDecide }: ApprovalItemProps) {
+  const expires = new Date(approval.expiresAt).toLocaleTimeString();
+  const hasArgs = approval.arguments && Object.keys(approval.arguments).length > 0;
+
+  return (
+    <div className="rounded-lg bg-status-pending/5 border border-status-pending/30 px-3 py-2 space-y-1">
+      <div className="flex items-center gap-2">
+        <ShieldAlert size={12} className="text-status-pending flex-shrink-0" />
+        <span className="text-xs font-mono text-text-primary truncate flex-1">
+          {approval.name}
+        </span>
+        <span className="text-[10px] font-mono text-text-muted flex-shrink-0">
+          {approval.id}
+        </span>
+      </div>
+      <div className="text-[11px] text-text-secondary space-y-1">
+        {approval.agent && (
+          <div className="flex justify-between">
+            <span className="text-text-muted">Agent</span>
+            <span>{approval.agent}</span>
+          </div>
+        )}
+        <div className="flex justify-between">
+          <span className="text-text-muted">Reason</span>
+          <span>{approval.reason}</span>
+        </div>
+        <div className="flex justify-between">
+          <span className="text-text-muted">Expires</span>
+          <span>{expires}</span>
+        </div>
+        {hasArgs && (
+          <pre
+            className={cn(
+              'mt-1 p-2 rounded bg-background/60 font-mono text-[10px]',
+              'overflow-x-auto scrollbar-dark'
+            )}
+          >
+            {JSON.stringify(approval.arguments, null, 2)}
+          </pre>
+        )}
+      </div>
+      <div className="flex gap-2 pt-1">
+        <button
+          onClick={() => onDecide(approval.id, true)}
+          className={cn(
+            'flex-1 flex items-center justify-center gap-1 py-1.5 rounded text-xs',
+            'bg-status-running/10 text-status-running border border-status-running/30',
+            'hover:bg-status-running/20 transition-colors'
+          )}
+        >
+          <Check size={12} />
+          Approve
+        </button>
+        <button
+          onClick={() => onDecide(approval.id, false)}
+          className={cn(
+            'flex-1 flex items-center justify-center gap-1 py-1.5 rounded text-xs',
+            'bg-status-error/10 text-status-error border border-status-error/30',
+            'hover:bg-status-error/20 transition-colors'
+          )}
+        >
+          <X size={12} />
+          Reject
+        </button>
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,126 @@
+import { useEffect, useState, useCallback } from 'react';
+import { ChevronDown, ChevronRight, CheckCircle2, XCircle } from 'lucide-react';
+import { cn } from '../../lib/cn';
+import { fetchCalls, type CallsFilter } from '../../lib/api';
+import { POLLING } from '../../lib/constants';
+import type { CallEvent } from '../../types';
+
+interface CallListProps {
+  // Filter for the calls to show, e.g. { server: 'github' } or { agent: 'coder' }
+  filter: CallsFilter;
+  limit?: number;
+}
+
+export function CallList({ filter, limit = 20 }: CallListProps) {
+  const [calls, setCalls] = useState<CallEvent[]>([]);
+  const [error, setError] = useState<string | null>(null);
+  const { session, agent, server, tool } = filter;
+
+  const loadCalls = useCallback(async () => {
+    try {
+      const result = await fetchCalls({ session, agent, server, tool, limit });
+      setCalls(result.calls);
+      setError(null);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to fetch calls');
+    }
+  }, [session, agent, server, tool, limit]);
+
+  useEffect(() => {
+    loadCalls();
+    const interval = window.setInterval(loadCalls, POLLING.CALLS);
+    return () => clearInterval(interval);
+  }, [loadCalls]);
+
+  if (error) {
+    return <p className="text-sm text-status-error px-4 py-2">{error}</p>;
+  }
+
+  if (calls.length === 0) {
+    return (
+      <p className="text-sm text-text-muted italic px-4 py-2">
+        No calls recorded
+      </p>
+    );
+  }
+
+  return (
+    <div className="space-y-1">
+      {calls.map((call, idx) => (
+        <CallItem key={`${call.timestamp}-${idx}`} call={call} />
+      ))}
+    </div>
+  );
+}
+
+function CallItem({ call }: { call: CallEvent }) {
+  const [expanded, setExpanded] = useState(false);
+  const time = new Date(call.timestamp).toLocaleTimeString();
+  const hasArgs = call.arguments && Object.keys(call.arguments).length > 0;
+
+  return (
+    <div className="rounded-lg bg-surface-elevated/60 border border-border/30">
+      <button
+        onClick={() => setExpanded(!expanded)}
+        className="w-full flex items-center gap-2 px-3 py-2 text-left"
+      >
+        {expanded ? (
+          <ChevronDown size={12} className="text-text-muted flex-shrink-0" />
+        ) : (
+          <ChevronRight size={12} className="text-text-muted flex-shrink-0" />
+        )}
+        {call.error ? (
+          <XCircle size={12} className="text-status-error flex-shrink-0" />
+        ) : (
+          <CheckCircle2 size={12} className="text-status-running flex-shrink-0" />
+        )}
+        <span className="text-xs font-mono text-text-primary truncate flex-1">
+          {call.tool ?? call.name}
+        </span>
+        <span className="text-[10px] text-text-muted flex-shrink-0">
+          {call.durationMs}ms
+        </span>
+      </button>
+      {expanded && (
+        <div className="px-3 pb-2 space-y-1 text-[11px] text-text-secondary">
+          <div className="flex justify-between">
+            <span className="text-text-muted">Time</span>
+            <span>{time}</span>
+          </div>
+          {call.agent && (
+            <div className="flex justify-between">
+              <span className="text-text-muted">Agent</span>
+              <span>{call.agent}</span>
+            </div>
+          )}
+          {call.server && (
+            <div className="flex justify-between">
+              <span className="text-text-muted">Server</span>
+              <span>{call.server}</span>
+            </div>
+          )}
+          {call.sessionId && (
+            <div className="flex justify-between gap-2">
+              <span className="text-text-muted">Session</span>
+              <span className="font-mono truncate">{call.sessionId}</span>
+            </div>
+          )}
+          <div className="flex justify-between">
+            <span className="text-text-muted">Result</span>
+            <span>{call.resultSize} bytes</span>
+          </div>
+          {hasArgs && (
+            <pre
+              className={cn(
+                'mt-1 p-2 rounded bg-background/60 font-mono text-[10px]',
+                'overflow-x-auto scrollbar-dark'
+              )}
+            >
+              {JSON.stringify(call.arguments, null, 2)}
+            </pre>
+          )}
+        </div>
+      )}
+    </div>
+  );
+}
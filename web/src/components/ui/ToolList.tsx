@@ -39,17 +39,19 @@ interface ToolListProps {
 export function ToolList({ serverName, whitelist }: ToolListProps) {
   const tools = useStackStore((s) => s.tools);
 
-  // Filter tools for this server (prefixed with serverName__)
+  // Filter tools for this server
   let serverTools = tools.filter((t) =>
-    t.name.startsWith(`${serverName}${TOOL_NAME_DELIMITER}`)
+    t.server !== undefined
+      ? t.server === serverName
+      : t.name.startsWith(`${serverName}${TOOL_NAME_DELIMITER}`)
   );
 
   // If whitelist provided, further filter to only whitelisted tools
   if (whitelist && whitelist.length > 0) {
-    const allowedPrefixed = new Set(
-      whitelist.map((name) => `${serverName}${TOOL_NAME_DELIMITER}${name}`)
+    const allowed = new Set(whitelist);
+    serverTools = serverTools.filter((t) =>
+      allowed.has(t.originalName ?? parsePrefixedToolName(t.name).toolName)
     );
-    serverTools = serverTools.filter((t) => allowedPrefixed.has(t.name));
   }
 
   if (serverTools.length === 0) {
@@ -85,8 +87,8 @@ export interface ToolItemProps {
 export function ToolItem({ tool }: ToolItemProps) {
   const [expanded, setExpanded] = useState(false);
 
-  // Remove agent prefix for display
-  const { toolName } = parsePrefixedToolName(tool.name);
+  // Remove agent prefix for display (the gateway sets title to the unprefixed name)
+  const toolName = tool.title || parsePrefixedToolName(tool.name).toolName;
 
   const properties = getSchemaProperties(tool.inputSchema);
   const required = getSchemaRequired(tool.inputSchema);
@@ -1,4 +1,4 @@
-import type { GatewayStatus, MCPServerStatus, ToolsListResult } from '../types';
+import type { Approval, ApprovalsResult, CallsResult, GatewayStatus, MCPServerStatus, ToolsListResult } from '../types';
 
 // Base URL for API calls - empty for same origin
 const API_BASE = '';
@@ -14,6 +14,40 @@ async function fetchJSON<T>(endpoint: string): Promise<T> {
   return response.json();
 }
 
+// Approval and call log requests carry the token gridctl deploy generates.
+// It arrives in the fragment of the Web UI link deploy prints (#token=...),
+// which is never sent to the server, and is kept for later visits.
+const APPROVAL_TOKEN_KEY = 'gridctl.approvalToken';
+const APPROVAL_TOKEN_HEADER = 'X-Gridctl-Approval-Token';
+
+function approvalToken(): string {
+  const match = window.location.hash.match(/(?:^#|&)token=([^&]+)/);
+  if (match) {
+    localStorage.setItem(APPROVAL_TOKEN_KEY, decodeURIComponent(match[1]));
+    history.replaceState(null, '', window.location.pathname + window.location.search);
+  }
+  return localStorage.getItem(APPROVAL_TOKEN_KEY) ?? '';
+}
+
+// Capture the token as soon as the UI loads, before any routing changes the URL
+approvalToken();
+
+// Fetch wrapper for routes that need the approval token
+async function fetchPrivateJSON<T>(endpoint: string): Promise<T> {
+  const response = await fetch(`${API_BASE}${endpoint}`, {
+    headers: { [APPROVAL_TOKEN_HEADER]: approvalToken() },
+  });
+
+  if (response.status === 401) {
+    throw new Error('Open the Web UI link printed by gridctl deploy to authorize this page');
+  }
+  if (!response.ok) {
+    throw new Error(`API error: ${response.status} ${response.statusText}`);
+  }
+
+  return response.json();
+}
+
 // === API Functions ===
 
 /**
@@ -40,6 +74,60 @@ export async function fetchTools(): Promise<ToolsListResult> {
   return fetchJSON<ToolsListResult>('/api/tools');
 }
 
+// Filters for GET /api/calls
+export interface CallsFilter {
+  session?: string;
+  agent?: string;
+  server?: string;
+  tool?: string;
+  error?: boolean;
+  since?: string; // Duration such as "1h" or an RFC 3339 time
+  limit?: number;
+}
+
+/**
+ * Fetch recorded tool calls from the audit log, newest first
+ * GET /api/calls
+ */
+export async function fetchCalls(filter: CallsFilter = {}): Promise<CallsResult> {
+  const params = new URLSearchParams();
+  for (const [key, value] of Object.entries(filter)) {
+    if (value !== undefined && value !== '') {
+      params.set(key, String(value));
+    }
+  }
+  const query = params.toString();
+  return fetchPrivateJSON<CallsResult>(query ? `/api/calls?${query}` : '/api/calls');
+}
+
+/**
+ * Fetch tool calls awaiting approval, oldest first
+ * GET /api/approvals
+ */
+export async function fetchApprovals(): Promise<ApprovalsResult> {
+  return fetchPrivateJSON<ApprovalsResult>('/api/approvals');
+}
+
+/**
+ * Approve or reject a tool call awaiting approval
+ * POST /api/approvals/{id}/{approve|reject}
+ */
+export async function decideApproval(id: string, approve: boolean, comment?: string): Promise<Approval> {
+  const action = approve ? 'approve' : 'reject';
+  const response = await fetch(`${API_BASE}/api/approvals/${encodeURIComponent(id)}/${action}`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', [APPROVAL_TOKEN_HEADER]: approvalToken() },
+    body: JSON.stringify({ by: 'web', comment }),
+  });
+
+  if (!response.ok) {
+    const errorData = await response.json().catch(() => null);
+    throw new Error(errorData?.error ?? `Decision failed: ${response.status} ${response.statusText}`);
+  }
+
+  return response.json();
+}
+
 // === Agent Control Functions (require backend endpoints) ===
 
 /**
@@ -104,11 +104,14 @@ export const POLLING = {
   STATUS: 3000,      // Poll status every 3 seconds
   TOOLS: 30000,      // Poll tools every 30 seconds
   LOGS: 2000,        // Poll logs every 2 seconds
+  CALLS: 5000,       // Poll recent tool calls every 5 seconds
+  APPROVALS: 2000,   // Poll tool calls awaiting approval every 2 seconds
 } as const;
 
 // Tool naming
 // Delimiter between agent name and tool name in prefixed tool names.
 // Format: "agentname__toolname"
 // Uses double underscore for Claude Desktop compatibility: ^[a-zA-Z0-9_-]{1,64}$
-// Must match the Go backend constant in pkg/mcp/router.go
+// Must match the Go backend default in pkg/mcp/router.go. Stacks can change the
+// separator, so prefer the server/originalName fields from GET /api/tools.
 export const TOOL_NAME_DELIMITER = '__';
@@ -268,12 +268,14 @@ export function parsePrefixedToolName(prefixed: string): {
  * Group tools by their owning MCP server
  */
 export function groupToolsByServer(
-  tools: { name: string }[]
+  tools: { name: string; server?: string; originalName?: string }[]
 ): Map<string, string[]> {
   const grouped = new Map<string, string[]>();
 
   for (const tool of tools) {
-    const { agentName, toolName } = parsePrefixedToolName(tool.name);
+    const parsed = parsePrefixedToolName(tool.name);
+    const agentName = tool.server ?? parsed.agentName;
+    const toolName = tool.originalName ?? parsed.toolName;
     const existing = grouped.get(agentName) || [];
     existing.push(toolName);
     grouped.set(agentName, existing);
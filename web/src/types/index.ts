@@ -80,6 +80,10 @@ export interface Tool {
   // InputSchema is now a raw JSON object to preserve full JSON Schema
   // from MCP servers without loss (supports JSON Schema draft 2020-12)
   inputSchema: Record<string, unknown>;
+  // Set by GET /api/tools: the providing server and the tool's name on it.
+  // Prefer these over parsing the name, since namespacing is configurable.
+  server?: string;
+  originalName?: string;
 }
 
 // Tools list response from GET /api/tools
@@ -88,6 +92,54 @@ export interface ToolsListResult {
   nextCursor?: string;
 }
 
+// Recorded tool call matching audit.Event, from GET /api/calls
+export interface CallEvent {
+  timestamp: string;
+  sessionId?: string;
+  agent?: string;
+  server?: string;
+  tool?: string; // Tool name on the server
+  name: string; // Tool name as called
+  arguments?: Record<string, unknown>;
+  durationMs: number;
+  error: boolean;
+  resultSize: number;
+  approval?: {
+    id: string;
+    status: Approval['status'];
+    decidedBy?: string;
+    comment?: string;
+  };
+}
+
+// Calls response from GET /api/calls
+export interface CallsResult {
+  calls: CallEvent[];
+}
+
+// Tool call awaiting or decided by a human, matching mcp.Approval
+export interface Approval {
+  id: string;
+  status: 'pending' | 'approved' | 'rejected' | 'expired' | 'cancelled';
+  reason: 'require_approval' | 'destructiveHint';
+  sessionId?: string;
+  agent?: string;
+  server: string;
+  tool: string; // Tool name on the server
+  name: string; // Tool name as called
+  arguments?: Record<string, unknown>;
+  createdAt: string;
+  expiresAt: string;
+  decidedAt?: string;
+  decidedBy?: string;
+  comment?: string;
+}
+
+// Approvals response from GET /api/approvals
+export interface ApprovalsResult {
+  approvals: Approval[];
+}
+
 // Node status for UI display
 export type NodeStatus = 'running' | 'stopped' | 'error' | 'initializing';
 